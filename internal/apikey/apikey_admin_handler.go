@@ -0,0 +1,127 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AdminHandler manages api keys, routes are expected to be restricted to administrators.
+type AdminHandler struct {
+	logger      *zap.Logger
+	errRegistry *httpx.ErrorRegistry
+	manager     KeyManager
+	mapper      Mapper
+}
+
+func NewAdminHandler(
+	logger *zap.Logger,
+	errRegistry *httpx.ErrorRegistry,
+	manager KeyManager,
+	mapper Mapper,
+) *AdminHandler {
+	return &AdminHandler{logger: logger, errRegistry: errRegistry, manager: manager, mapper: mapper}
+}
+
+// Create issues a key, the response holds the plaintext key which is not shown again.
+func (a *AdminHandler) Create(w http.ResponseWriter, r *http.Request) {
+	a.errRegistry.Handle(a.create)(w, r)
+}
+
+func (a *AdminHandler) List(w http.ResponseWriter, r *http.Request) {
+	a.errRegistry.Handle(a.list)(w, r)
+}
+
+// Rotate replaces the secret of the key of URL parameter "id", the response holds the new plaintext key.
+func (a *AdminHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	a.errRegistry.Handle(a.rotate)(w, r)
+}
+
+// Revoke disables the key of URL parameter "id".
+func (a *AdminHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	a.errRegistry.Handle(a.revoke)(w, r)
+}
+
+func (a *AdminHandler) create(w http.ResponseWriter, r *http.Request) error {
+	defer func() { _ = r.Body.Close() }()
+	cRequest, err := httpx.DecodeJSON[CreateRequest](w, r)
+	if err != nil {
+		return err
+	}
+
+	created, key, err := a.manager.Create(r.Context(), a.mapper.CreateRequestToModel(cRequest))
+	if err != nil {
+		return err
+	}
+
+	httpx.JsonResponse(http.StatusCreated, SecretResponse{APIKey: a.mapper.ModelToResponse(created), Key: key}, w)
+	return nil
+}
+
+func (a *AdminHandler) list(w http.ResponseWriter, r *http.Request) error {
+	keys, err := a.manager.List(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := ListResponse{APIKeys: make([]Response, 0, len(keys))}
+	for _, k := range keys {
+		resp.APIKeys = append(resp.APIKeys, a.mapper.ModelToResponse(k))
+	}
+
+	httpx.JsonResponse(http.StatusOK, resp, w)
+	return nil
+}
+
+func (a *AdminHandler) rotate(w http.ResponseWriter, r *http.Request) error {
+	id, err := a.keyID(r)
+	if err != nil {
+		return err
+	}
+
+	rotated, key, err := a.manager.Rotate(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	httpx.JsonResponse(http.StatusOK, SecretResponse{APIKey: a.mapper.ModelToResponse(rotated), Key: key}, w)
+	return nil
+}
+
+func (a *AdminHandler) revoke(w http.ResponseWriter, r *http.Request) error {
+	id, err := a.keyID(r)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := a.manager.Revoke(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	httpx.JsonResponse(http.StatusOK, a.mapper.ModelToResponse(revoked), w)
+	return nil
+}
+
+func (a *AdminHandler) keyID(r *http.Request) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, &errorx.BadRequestError{
+			Message:    "invalid id",
+			Properties: map[string]string{"error": err.Error()},
+		}
+	}
+	return id, nil
+}
+
+type KeyManager interface {
+	Create(ctx context.Context, input APIKey) (APIKey, string, error)
+	List(ctx context.Context) ([]APIKey, error)
+	Rotate(ctx context.Context, id uuid.UUID) (APIKey, string, error)
+	Revoke(ctx context.Context, id uuid.UUID) (APIKey, error)
+}
@@ -0,0 +1,145 @@
+package apikey_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/apikey"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type managerStub struct {
+	created apikey.APIKey
+}
+
+func (m *managerStub) Create(_ context.Context, input apikey.APIKey) (apikey.APIKey, string, error) {
+	m.created = input
+	input.ID = uuid.New()
+	input.Prefix = "0123456789ab"
+	return input, "bbk_0123456789ab_secret", nil
+}
+
+func (m *managerStub) List(context.Context) ([]apikey.APIKey, error) {
+	return []apikey.APIKey{{ID: uuid.New(), Name: "batch", SecretHash: "hash"}}, nil
+}
+
+func (m *managerStub) Rotate(context.Context, uuid.UUID) (apikey.APIKey, string, error) {
+	return apikey.APIKey{}, "", errorx.ErrNotFound
+}
+
+func (m *managerStub) Revoke(_ context.Context, id uuid.UUID) (apikey.APIKey, error) {
+	now := time.Now()
+	return apikey.APIKey{ID: id, RevokeTime: &now}, nil
+}
+
+var testTenantID = uuid.MustParse("6f1c1b52-4bb4-4a8e-9d9e-0b6a3cf1d9f1")
+
+func newAdminRouter() (http.Handler, *managerStub) {
+	logger := zap.NewNop()
+	manager := &managerStub{}
+	h := apikey.NewAdminHandler(logger, httpx.NewErrorRegistry(logger), manager, &apikey.APIKeyMapper{})
+
+	r := chi.NewRouter()
+	r.Post("/", h.Create)
+	r.Get("/", h.List)
+	r.Post("/{id}/rotate", h.Rotate)
+	r.Delete("/{id}", h.Revoke)
+	return r, manager
+}
+
+func TestAdminHandler_Create(t *testing.T) {
+	router, manager := newAdminRouter()
+
+	body := `{"name":"batch","owner":"jobs","tenantId":"` + testTenantID.String() + `","scopes":["batch","admin"]}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, apikey.APIKey{
+		Name: "batch", Owner: "jobs", Scopes: []string{"batch", "admin"}, TenantID: testTenantID,
+	}, manager.created)
+
+	var resp apikey.SecretResponse
+	err := json.NewDecoder(w.Body).Decode(&resp)
+	if err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	assert.Equal(t, "bbk_0123456789ab_secret", resp.Key)
+	assert.Equal(t, "0123456789ab", resp.APIKey.Prefix)
+}
+
+func TestAdminHandler_Create_Invalid(t *testing.T) {
+	router, _ := newAdminRouter()
+
+	r := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"name":"","owner":"jobs","expiryTime":"2000-01-01T00:00:00Z"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp httpx.ErrorResponse
+	err := json.NewDecoder(w.Body).Decode(&resp)
+	if err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	assert.Equal(t, map[string]string{
+		"name":       "is required",
+		"tenantId":   "is required",
+		"expiryTime": "must be in the future",
+	}, resp.Details)
+}
+
+func TestAdminHandler_List_OmitsSecretHash(t *testing.T) {
+	router, _ := newAdminRouter()
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"batch"`)
+	assert.NotContains(t, w.Body.String(), "hash")
+}
+
+func TestAdminHandler_Rotate(t *testing.T) {
+	router, _ := newAdminRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/not-a-uuid/rotate", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/"+uuid.NewString()+"/rotate", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminHandler_Revoke(t *testing.T) {
+	router, _ := newAdminRouter()
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/"+uuid.NewString(), nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp apikey.Response
+	err := json.NewDecoder(w.Body).Decode(&resp)
+	if err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	assert.NotNil(t, resp.RevokeTime)
+}
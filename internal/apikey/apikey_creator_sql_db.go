@@ -0,0 +1,70 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/audit"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type CreatorSQLDB struct {
+	logger *zap.Logger
+	sqlE   sqldb.Executable
+}
+
+func NewCreatorSQLDB(logger *zap.Logger, sqlE sqldb.Executable) *CreatorSQLDB {
+	return &CreatorSQLDB{
+		logger: logger,
+		sqlE:   sqlE,
+	}
+}
+
+// InsertAPIKey inserts a new api key into the database.
+// Ignores and automatically sets ID, CreateTime, UpdateTime, Version fields from input.
+func (c *CreatorSQLDB) InsertAPIKey(ctx context.Context, input entity.APIKey) (entity.APIKey, error) {
+	logx.FromContext(ctx, c.logger).Debug("inserting api key", zap.String("prefix", input.Prefix))
+
+	inputAuditable := apiKeyAuditableEntity{E: &input}
+	audit.SetInsertFields(inputAuditable)
+
+	stmt := c.buildStatement(input)
+
+	_, err := stmt.ExecContext(ctx, c.sqlE)
+	if err != nil {
+		return entity.APIKey{}, c.resolveError(err)
+	}
+
+	logx.FromContext(ctx, c.logger).Debug("inserted api key", zap.String("prefix", input.Prefix))
+
+	return input, nil
+}
+
+func (c *CreatorSQLDB) buildStatement(input entity.APIKey) postgres.InsertStatement {
+	return table.APIKey.
+		INSERT(table.APIKey.AllColumns).
+		MODEL(input)
+}
+
+func (c *CreatorSQLDB) resolveError(err error) error {
+	var pqErr *pq.Error
+	isPqErr := errors.As(err, &pqErr)
+	if isPqErr && sqldb.IsUniqueViolationError(pqErr) {
+		if pqErr.Constraint == dbcUkPrefix {
+			return &errorx.UniqueViolationError{
+				Properties: map[string]string{
+					"prefix": "prefix already exists",
+				},
+			}
+		}
+		return &errorx.UniqueViolationError{}
+	}
+	return err
+}
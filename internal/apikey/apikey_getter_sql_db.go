@@ -0,0 +1,66 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"go.uber.org/zap"
+)
+
+type GetterSQLDB struct {
+	logger *zap.Logger
+	sqlQ   sqldb.Queryable
+}
+
+func NewGetterSQLDB(logger *zap.Logger, sqlQ sqldb.Queryable) *GetterSQLDB {
+	return &GetterSQLDB{
+		logger: logger,
+		sqlQ:   sqlQ,
+	}
+}
+
+// FindAPIKeyByPrefix retrieves an api key by prefix, including revoked and expired keys.
+func (g *GetterSQLDB) FindAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error) {
+	logx.FromContext(ctx, g.logger).Debug("get api key", zap.String("prefix", prefix))
+
+	stmt := table.APIKey.
+		SELECT(table.APIKey.AllColumns).
+		FROM(table.APIKey).
+		WHERE(table.APIKey.Prefix.EQ(postgres.String(prefix)))
+
+	var result entity.APIKey
+	err := stmt.QueryContext(ctx, g.sqlQ, &result)
+	if err != nil {
+		if errors.Is(err, qrm.ErrNoRows) {
+			return entity.APIKey{}, errorx.ErrNotFound
+		}
+		return entity.APIKey{}, err
+	}
+
+	logx.FromContext(ctx, g.logger).Debug("found api key", zap.String("prefix", prefix))
+
+	return result, nil
+}
+
+// ListAPIKeys retrieves all api keys, newest first.
+func (g *GetterSQLDB) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	stmt := table.APIKey.
+		SELECT(table.APIKey.AllColumns).
+		FROM(table.APIKey).
+		ORDER_BY(table.APIKey.CreateTime.DESC())
+
+	var results []entity.APIKey
+	err := stmt.QueryContext(ctx, g.sqlQ, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
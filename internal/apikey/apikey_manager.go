@@ -0,0 +1,160 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SubjectPrefix prefixes the key ID in the subject of principals authenticated by api key,
+// distinguishing service callers from users.
+const SubjectPrefix = "apikey:"
+
+// Manager issues, rotates and revokes api keys, and verifies keys presented by callers.
+type Manager struct {
+	logger      *zap.Logger
+	creatorRepo CreatorRepo
+	getterRepo  GetterRepo
+	updaterRepo UpdaterRepo
+	recorder    Recorder
+	mapper      Mapper
+	now         func() time.Time
+}
+
+func NewManager(
+	logger *zap.Logger,
+	creatorRepo CreatorRepo,
+	getterRepo GetterRepo,
+	updaterRepo UpdaterRepo,
+	recorder Recorder,
+	mapper Mapper,
+) *Manager {
+	return &Manager{
+		logger: logger, creatorRepo: creatorRepo, getterRepo: getterRepo, updaterRepo: updaterRepo,
+		recorder: recorder, mapper: mapper, now: time.Now,
+	}
+}
+
+// Create issues a key, the returned plaintext key is not stored and cannot be retrieved again.
+func (m *Manager) Create(ctx context.Context, input APIKey) (APIKey, string, error) {
+	key, prefix, secret := generateKey()
+	input.Prefix = prefix
+	input.SecretHash = hashSecret(secret)
+
+	created, err := m.creatorRepo.InsertAPIKey(ctx, m.mapper.ModelToEntity(input))
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	logx.FromContext(ctx, m.logger).Info("created api key",
+		zap.Stringer("id", created.ID), zap.String("prefix", prefix), zap.String("owner", created.Owner))
+
+	return m.mapper.EntityToModel(created), key, nil
+}
+
+func (m *Manager) List(ctx context.Context) ([]APIKey, error) {
+	entities, err := m.getterRepo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, 0, len(entities))
+	for _, e := range entities {
+		keys = append(keys, m.mapper.EntityToModel(e))
+	}
+	return keys, nil
+}
+
+// Rotate replaces the secret of a key, the previous key stops working immediately.
+// Returns errorx.ErrNotFound when the key does not exist or is revoked.
+func (m *Manager) Rotate(ctx context.Context, id uuid.UUID) (APIKey, string, error) {
+	key, prefix, secret := generateKey()
+
+	updated, err := m.updaterRepo.UpdateSecret(ctx, id, prefix, hashSecret(secret))
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	logx.FromContext(ctx, m.logger).Info("rotated api key", zap.Stringer("id", id), zap.String("prefix", prefix))
+
+	return m.mapper.EntityToModel(updated), key, nil
+}
+
+// Revoke permanently disables a key, revoking a revoked key succeeds.
+func (m *Manager) Revoke(ctx context.Context, id uuid.UUID) (APIKey, error) {
+	updated, err := m.updaterRepo.Revoke(ctx, id)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	logx.FromContext(ctx, m.logger).Info("revoked api key", zap.Stringer("id", id))
+
+	return m.mapper.EntityToModel(updated), nil
+}
+
+// VerifyAPIKey implements auth.APIKeyVerifier, usage of accepted keys is recorded asynchronously.
+// Malformed, unknown, revoked and expired keys are all rejected with auth.ErrInvalidAPIKey.
+func (m *Manager) VerifyAPIKey(ctx context.Context, key string) (auth.Principal, error) {
+	prefix, secret, err := parseKey(key)
+	if err != nil {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+
+	found, err := m.getterRepo.FindAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, errorx.ErrNotFound) {
+			return auth.Principal{}, auth.ErrInvalidAPIKey
+		}
+		return auth.Principal{}, fmt.Errorf("failed to find api key: %w", err)
+	}
+
+	apiKey := m.mapper.EntityToModel(found)
+	if !apiKey.MatchesSecret(secret) {
+		return auth.Principal{}, auth.ErrInvalidAPIKey
+	}
+
+	now := m.now()
+	if !apiKey.IsActive(now) {
+		return auth.Principal{}, fmt.Errorf("%w: revoked or expired", auth.ErrInvalidAPIKey)
+	}
+
+	m.recorder.Record(apiKey.ID, now)
+
+	// Keys hold no roles, scopes name the permissions granted, e.g. "webhooks:manage"
+	p := auth.Principal{
+		Subject:     SubjectPrefix + apiKey.ID.String(),
+		Scopes:      apiKey.Scopes,
+		Permissions: apiKey.Scopes,
+	}
+	// Keys issued before tenancy claim no tenant, see tenant.WithHeaderGuard
+	if apiKey.TenantID != uuid.Nil {
+		p.Claims.Tenant = apiKey.TenantID.String()
+	}
+	return p, nil
+}
+
+type CreatorRepo interface {
+	InsertAPIKey(ctx context.Context, input entity.APIKey) (entity.APIKey, error)
+}
+
+type GetterRepo interface {
+	FindAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+}
+
+type UpdaterRepo interface {
+	UpdateSecret(ctx context.Context, id uuid.UUID, prefix string, secretHash string) (entity.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) (entity.APIKey, error)
+}
+
+type Recorder interface {
+	Record(id uuid.UUID, t time.Time)
+}
@@ -0,0 +1,183 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/authz"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/rbac"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type keyRepoStub struct {
+	keys map[string]entity.APIKey
+	err  error
+}
+
+func (s *keyRepoStub) InsertAPIKey(_ context.Context, input entity.APIKey) (entity.APIKey, error) {
+	input.ID = uuid.New()
+	s.keys[input.Prefix] = input
+	return input, nil
+}
+
+func (s *keyRepoStub) FindAPIKeyByPrefix(_ context.Context, prefix string) (entity.APIKey, error) {
+	if s.err != nil {
+		return entity.APIKey{}, s.err
+	}
+	k, ok := s.keys[prefix]
+	if !ok {
+		return entity.APIKey{}, errorx.ErrNotFound
+	}
+	return k, nil
+}
+
+func (s *keyRepoStub) ListAPIKeys(context.Context) ([]entity.APIKey, error) {
+	return nil, nil
+}
+
+type recorderStub struct {
+	recorded []uuid.UUID
+}
+
+func (r *recorderStub) Record(id uuid.UUID, _ time.Time) {
+	r.recorded = append(r.recorded, id)
+}
+
+func TestManager_VerifyAPIKey(t *testing.T) {
+	repo := &keyRepoStub{keys: make(map[string]entity.APIKey)}
+	recorder := &recorderStub{}
+	m := NewManager(zap.NewNop(), repo, repo, nil, recorder, &APIKeyMapper{})
+
+	tenantID := uuid.New()
+	created, key, err := m.Create(t.Context(),
+		APIKey{Name: "batch", Owner: "jobs", Scopes: []string{"batch"}, TenantID: tenantID})
+	if err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+	_, secret, _ := parseKey(key)
+	assert.Equal(t, hashSecret(secret), repo.keys[created.Prefix].SecretHash)
+
+	p, err := m.VerifyAPIKey(t.Context(), key)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Principal{
+		Subject:     "apikey:" + created.ID.String(),
+		Scopes:      []string{"batch"},
+		Permissions: []string{"batch"},
+		Claims:      auth.Claims{Tenant: tenantID.String()},
+	}, p)
+	assert.Equal(t, []uuid.UUID{created.ID}, recorder.recorded)
+
+	_, otherPrefixKey, _ := generateKey()
+	_, _, otherSecret := generateKey()
+	wrongSecretKey := keyPrefix + "_" + created.Prefix + "_" + otherSecret
+
+	for name, k := range map[string]string{
+		"malformed":     "bbk_invalid",
+		"unknown":       otherPrefixKey,
+		"wrong secret":  wrongSecretKey,
+		"bearer format": "eyJhbGciOiJFUzI1NiJ9.e30.sig",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := m.VerifyAPIKey(t.Context(), k)
+			assert.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		m.now = func() time.Time { return time.Now().Add(time.Hour) }
+		defer func() { m.now = time.Now }()
+
+		expired := repo.keys[created.Prefix]
+		expiry := time.Now()
+		expired.ExpiryTime = &expiry
+		repo.keys[created.Prefix] = expired
+
+		_, err := m.VerifyAPIKey(t.Context(), key)
+		assert.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+	})
+
+	assert.Len(t, recorder.recorded, 1)
+}
+
+func TestManager_VerifyAPIKey_WithoutTenant(t *testing.T) {
+	repo := &keyRepoStub{keys: make(map[string]entity.APIKey)}
+	m := NewManager(zap.NewNop(), repo, repo, nil, &recorderStub{}, &APIKeyMapper{})
+
+	_, key, err := m.Create(t.Context(), APIKey{Name: "legacy", Owner: "jobs"})
+	if err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	p, err := m.VerifyAPIKey(t.Context(), key)
+	assert.NoError(t, err)
+	assert.Empty(t, p.Claims.Tenant)
+}
+
+func TestManager_VerifyAPIKey_RepoError(t *testing.T) {
+	repoErr := errors.New("connection refused")
+	repo := &keyRepoStub{err: repoErr}
+	m := NewManager(zap.NewNop(), repo, repo, nil, &recorderStub{}, &APIKeyMapper{})
+
+	key, _, _ := generateKey()
+	_, err := m.VerifyAPIKey(t.Context(), key)
+
+	// Rendered as a server error rather than an authentication failure
+	assert.ErrorIs(t, err, repoErr)
+	assert.NotErrorIs(t, err, errorx.ErrUnauthorized)
+}
+
+func TestManager_VerifyAPIKey_PermissionGuardedRoute(t *testing.T) {
+	repo := &keyRepoStub{keys: make(map[string]entity.APIKey)}
+	m := NewManager(zap.NewNop(), repo, repo, nil, &recorderStub{}, &APIKeyMapper{})
+
+	errWriter := func(err error, w http.ResponseWriter, _ *http.Request) {
+		status := http.StatusForbidden
+		if errors.Is(err, errorx.ErrUnauthorized) {
+			status = http.StatusUnauthorized
+		}
+		w.WriteHeader(status)
+	}
+	policy := rbac.NewPolicy(map[string][]rbac.Permission{"operator": {authz.WebhooksManage}})
+	enforcer := rbac.NewEnforcer(zap.NewNop(), rbac.StaticLoader(policy), errWriter)
+	authenticate := auth.NewMiddleware(zap.NewNop(), nil, errWriter, auth.WithAPIKeyVerifier(m)).Authenticate
+
+	handler := authenticate(enforcer.RequirePermission(authz.WebhooksManage)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }),
+	))
+
+	tests := []struct {
+		name           string
+		scopes         []string
+		expectedStatus int
+	}{
+		{name: "scope granting permission", scopes: []string{string(authz.WebhooksManage)}, expectedStatus: http.StatusOK},
+		{name: "other scope", scopes: []string{string(authz.ProfilesReadAny)}, expectedStatus: http.StatusForbidden},
+		{name: "role name as scope", scopes: []string{"operator"}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, key, err := m.Create(t.Context(), APIKey{Name: "hooks", Owner: "jobs", Scopes: tt.scopes})
+			if err != nil {
+				t.Fatalf("failed to create api key: %v", err)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+			r.Header.Set(auth.HeaderKeyAuthorization, "ApiKey "+key)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
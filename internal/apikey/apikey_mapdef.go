@@ -0,0 +1,20 @@
+package apikey
+
+import "github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+
+// goverter:converter
+// goverter:output:file ./apikey_mapper.go
+// goverter:name APIKeyMapper
+// goverter:extend github.com/dyxj/bigbackend/pkg/mapx:MapTime
+// goverter:extend github.com/dyxj/bigbackend/pkg/mapx:MapUUID
+// goverter:extend SplitScopes
+// goverter:extend JoinScopes
+type Mapper interface {
+	// goverter:map Scopes Scope
+	ModelToEntity(source APIKey) entity.APIKey
+	// goverter:map Scope Scopes
+	EntityToModel(source entity.APIKey) APIKey
+	// goverter:ignoreMissing
+	CreateRequestToModel(source CreateRequest) APIKey
+	ModelToResponse(source APIKey) Response
+}
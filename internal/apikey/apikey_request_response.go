@@ -0,0 +1,53 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/validx"
+	"github.com/google/uuid"
+)
+
+type CreateRequest struct {
+	Name       string     `json:"name" validate:"required,max=100"`
+	Owner      string     `json:"owner" validate:"required,max=100"`
+	TenantID   uuid.UUID  `json:"tenantId" validate:"required"`
+	Scopes     []string   `json:"scopes" validate:"max=20"`
+	ExpiryTime *time.Time `json:"expiryTime"`
+}
+
+func (r *CreateRequest) Validate() *errorx.ValidationError {
+	vErr := validx.Struct(r)
+	if r.ExpiryTime != nil && !r.ExpiryTime.After(time.Now()) {
+		if vErr == nil {
+			vErr = &errorx.ValidationError{Properties: map[string]string{}}
+		}
+		vErr.Properties["expiryTime"] = "must be in the future"
+	}
+	return vErr
+}
+
+type Response struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Owner        string     `json:"owner"`
+	Prefix       string     `json:"prefix"`
+	Scopes       []string   `json:"scopes"`
+	ExpiryTime   *time.Time `json:"expiryTime"`
+	LastUsedTime *time.Time `json:"lastUsedTime"`
+	RevokeTime   *time.Time `json:"revokeTime"`
+	CreateTime   time.Time  `json:"createTime"`
+	UpdateTime   time.Time  `json:"updateTime"`
+	Version      int32      `json:"version"`
+	TenantID     uuid.UUID  `json:"tenantId"`
+}
+
+// SecretResponse returned on create and rotate, Key is the only time the plaintext key is shown.
+type SecretResponse struct {
+	APIKey Response `json:"apiKey"`
+	Key    string   `json:"key"`
+}
+
+type ListResponse struct {
+	APIKeys []Response `json:"apiKeys"`
+}
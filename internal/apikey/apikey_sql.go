@@ -0,0 +1,19 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/google/uuid"
+)
+
+const dbcUkPrefix = "api_key_prefix_uk"
+
+type apiKeyAuditableEntity struct{ E *entity.APIKey }
+
+func (a apiKeyAuditableEntity) GetID() uuid.UUID          { return a.E.ID }
+func (a apiKeyAuditableEntity) SetID(id uuid.UUID)        { a.E.ID = id }
+func (a apiKeyAuditableEntity) SetCreateTime(t time.Time) { a.E.CreateTime = t }
+func (a apiKeyAuditableEntity) SetUpdateTime(t time.Time) { a.E.UpdateTime = t }
+func (a apiKeyAuditableEntity) GetVersion() int32         { return a.E.Version }
+func (a apiKeyAuditableEntity) SetVersion(v int32)        { a.E.Version = v }
@@ -0,0 +1,74 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKey(t *testing.T) {
+	key, prefix, secret := generateKey()
+
+	assert.True(t, strings.HasPrefix(key, "bbk_"+prefix+"_"))
+	assert.Len(t, prefix, 12)
+
+	parsedPrefix, parsedSecret, err := parseKey(key)
+	assert.NoError(t, err)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.Equal(t, secret, parsedSecret)
+
+	otherKey, otherPrefix, _ := generateKey()
+	assert.NotEqual(t, key, otherKey)
+	assert.NotEqual(t, prefix, otherPrefix)
+}
+
+func TestParseKey_Malformed(t *testing.T) {
+	_, prefix, secret := generateKey()
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "empty", key: ""},
+		{name: "other prefix", key: "abc_" + prefix + "_" + secret},
+		{name: "missing secret", key: "bbk_" + prefix},
+		{name: "short prefix", key: "bbk_" + prefix[1:] + "_" + secret},
+		{name: "short secret", key: "bbk_" + prefix + "_" + secret[1:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseKey(tt.key)
+			assert.ErrorIs(t, err, errMalformedKey)
+		})
+	}
+}
+
+func TestAPIKey_MatchesSecret(t *testing.T) {
+	_, _, secret := generateKey()
+	k := APIKey{SecretHash: hashSecret(secret)}
+
+	assert.True(t, k.MatchesSecret(secret))
+	assert.False(t, k.MatchesSecret(secret+"x"))
+	assert.False(t, k.MatchesSecret(""))
+}
+
+func TestAPIKey_IsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.True(t, (&APIKey{}).IsActive(now))
+	assert.True(t, (&APIKey{ExpiryTime: &future}).IsActive(now))
+	assert.False(t, (&APIKey{ExpiryTime: &past}).IsActive(now))
+	assert.False(t, (&APIKey{ExpiryTime: &now}).IsActive(now))
+	assert.False(t, (&APIKey{RevokeTime: &past, ExpiryTime: &future}).IsActive(now))
+}
+
+func TestScopes(t *testing.T) {
+	assert.Equal(t, []string{"batch", "profiles:read"}, SplitScopes(" batch  profiles:read "))
+	assert.Empty(t, SplitScopes(""))
+	assert.Equal(t, "batch profiles:read", JoinScopes([]string{"batch", "profiles:read"}))
+}
@@ -0,0 +1,91 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type UpdaterSQLDB struct {
+	logger *zap.Logger
+	sqlQ   sqldb.Queryable
+}
+
+func NewUpdaterSQLDB(logger *zap.Logger, sqlQ sqldb.Queryable) *UpdaterSQLDB {
+	return &UpdaterSQLDB{
+		logger: logger,
+		sqlQ:   sqlQ,
+	}
+}
+
+// UpdateSecret replaces prefix and secret hash of a key that is not revoked,
+// returns errorx.ErrNotFound when the key does not exist or is revoked.
+func (u *UpdaterSQLDB) UpdateSecret(
+	ctx context.Context, id uuid.UUID, prefix string, secretHash string,
+) (entity.APIKey, error) {
+	logx.FromContext(ctx, u.logger).Debug("updating api key secret", zap.Stringer("id", id))
+
+	stmt := table.APIKey.
+		UPDATE().
+		SET(
+			table.APIKey.Prefix.SET(postgres.String(prefix)),
+			table.APIKey.SecretHash.SET(postgres.String(secretHash)),
+			table.APIKey.UpdateTime.SET(postgres.TimestampzT(time.Now())),
+			table.APIKey.Version.SET(table.APIKey.Version.ADD(postgres.Int32(1))),
+		).
+		WHERE(postgres.AND(
+			table.APIKey.ID.EQ(postgres.UUID(id)),
+			table.APIKey.RevokeTime.IS_NULL(),
+		)).
+		RETURNING(table.APIKey.AllColumns)
+
+	var updated entity.APIKey
+	err := stmt.QueryContext(ctx, u.sqlQ, &updated)
+	if err != nil {
+		return entity.APIKey{}, u.resolveError(err)
+	}
+
+	return updated, nil
+}
+
+// Revoke sets the revoke time of a key, revoking an already revoked key keeps the original time.
+func (u *UpdaterSQLDB) Revoke(ctx context.Context, id uuid.UUID) (entity.APIKey, error) {
+	logx.FromContext(ctx, u.logger).Debug("revoking api key", zap.Stringer("id", id))
+
+	now := postgres.TimestampzT(time.Now())
+
+	stmt := table.APIKey.
+		UPDATE().
+		SET(
+			table.APIKey.RevokeTime.SET(postgres.TimestampzExp(postgres.COALESCE(table.APIKey.RevokeTime, now))),
+			table.APIKey.UpdateTime.SET(now),
+			table.APIKey.Version.SET(table.APIKey.Version.ADD(postgres.Int32(1))),
+		).
+		WHERE(table.APIKey.ID.EQ(postgres.UUID(id))).
+		RETURNING(table.APIKey.AllColumns)
+
+	var updated entity.APIKey
+	err := stmt.QueryContext(ctx, u.sqlQ, &updated)
+	if err != nil {
+		return entity.APIKey{}, u.resolveError(err)
+	}
+
+	return updated, nil
+}
+
+func (u *UpdaterSQLDB) resolveError(err error) error {
+	if errors.Is(err, qrm.ErrNoRows) {
+		return errorx.ErrNotFound
+	}
+	return err
+}
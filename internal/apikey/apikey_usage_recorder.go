@@ -0,0 +1,127 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultUsageFlushInterval = 30 * time.Second
+	DefaultUsageBufferSize    = 1024
+	// usageShutdownFlushTimeout bounds the final flush, as the run context is already cancelled.
+	usageShutdownFlushTimeout = 5 * time.Second
+)
+
+type usage struct {
+	id   uuid.UUID
+	time time.Time
+}
+
+// UsageRecorder records last used times of keys asynchronously, so that verifying a key does not write to the database.
+// Usage is batched per key and flushed periodically by Run.
+type UsageRecorder struct {
+	logger        *zap.Logger
+	repo          UsageRepo
+	usages        chan usage
+	flushInterval time.Duration
+}
+
+type UsageRecorderOption func(*UsageRecorder)
+
+// WithUsageFlushInterval overrides DefaultUsageFlushInterval.
+func WithUsageFlushInterval(d time.Duration) UsageRecorderOption {
+	return func(r *UsageRecorder) {
+		r.flushInterval = d
+	}
+}
+
+// WithUsageBufferSize overrides DefaultUsageBufferSize, usage recorded while the buffer is full is dropped.
+func WithUsageBufferSize(n int) UsageRecorderOption {
+	return func(r *UsageRecorder) {
+		r.usages = make(chan usage, n)
+	}
+}
+
+func NewUsageRecorder(logger *zap.Logger, repo UsageRepo, options ...UsageRecorderOption) *UsageRecorder {
+	r := &UsageRecorder{
+		logger:        logger,
+		repo:          repo,
+		usages:        make(chan usage, DefaultUsageBufferSize),
+		flushInterval: DefaultUsageFlushInterval,
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+// Record queues usage of key id without blocking, usage is dropped when the buffer is full
+// as last used times are informational.
+func (r *UsageRecorder) Record(id uuid.UUID, t time.Time) {
+	select {
+	case r.usages <- usage{id: id, time: t}:
+	default:
+		r.logger.Debug("dropped api key usage, buffer is full", zap.Stringer("id", id))
+	}
+}
+
+// Run flushes recorded usage every flush interval until ctx is done,
+// then flushes the remaining usage and returns.
+func (r *UsageRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[uuid.UUID]time.Time)
+	for {
+		select {
+		case u := <-r.usages:
+			addUsage(pending, u)
+		case <-ticker.C:
+			r.flush(ctx, pending)
+			pending = make(map[uuid.UUID]time.Time)
+		case <-ctx.Done():
+			r.drain(pending)
+			return
+		}
+	}
+}
+
+func (r *UsageRecorder) drain(pending map[uuid.UUID]time.Time) {
+	for {
+		select {
+		case u := <-r.usages:
+			addUsage(pending, u)
+		default:
+			ctx, cancel := context.WithTimeout(context.Background(), usageShutdownFlushTimeout)
+			defer cancel()
+			r.flush(ctx, pending)
+			return
+		}
+	}
+}
+
+func (r *UsageRecorder) flush(ctx context.Context, pending map[uuid.UUID]time.Time) {
+	if len(pending) == 0 {
+		return
+	}
+	err := r.repo.UpdateLastUsedTimes(ctx, pending)
+	if err != nil {
+		r.logger.Error("failed to record api key usage", zap.Error(err), zap.Int("keys", len(pending)))
+		return
+	}
+	r.logger.Debug("recorded api key usage", zap.Int("keys", len(pending)))
+}
+
+// addUsage keeps the latest usage per key.
+func addUsage(pending map[uuid.UUID]time.Time, u usage) {
+	if t, ok := pending[u.id]; !ok || u.time.After(t) {
+		pending[u.id] = u.time
+	}
+}
+
+type UsageRepo interface {
+	UpdateLastUsedTimes(ctx context.Context, lastUsed map[uuid.UUID]time.Time) error
+}
@@ -0,0 +1,86 @@
+package apikey
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type usageRepoStub struct {
+	mu      sync.Mutex
+	batches []map[uuid.UUID]time.Time
+}
+
+func (s *usageRepoStub) UpdateLastUsedTimes(_ context.Context, lastUsed map[uuid.UUID]time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, lastUsed)
+	return nil
+}
+
+func (s *usageRepoStub) Batches() []map[uuid.UUID]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.batches
+}
+
+func TestUsageRecorder_FlushesLatestUsagePerKey(t *testing.T) {
+	repo := &usageRepoStub{}
+	recorder := NewUsageRecorder(zap.NewNop(), repo, WithUsageFlushInterval(10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		recorder.Run(ctx)
+		close(done)
+	}()
+
+	id1, id2 := uuid.New(), uuid.New()
+	t1 := time.Now()
+	t2 := t1.Add(time.Second)
+	recorder.Record(id1, t2)
+	recorder.Record(id1, t1)
+	recorder.Record(id2, t1)
+
+	assert.Eventually(t, func() bool { return len(repo.Batches()) > 0 }, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	merged := make(map[uuid.UUID]time.Time)
+	for _, batch := range repo.Batches() {
+		for id, t := range batch {
+			addUsage(merged, usage{id: id, time: t})
+		}
+	}
+	assert.Equal(t, map[uuid.UUID]time.Time{id1: t2, id2: t1}, merged)
+}
+
+func TestUsageRecorder_FlushesOnShutdown(t *testing.T) {
+	repo := &usageRepoStub{}
+	recorder := NewUsageRecorder(zap.NewNop(), repo, WithUsageFlushInterval(time.Hour))
+
+	id := uuid.New()
+	now := time.Now()
+	recorder.Record(id, now)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	recorder.Run(ctx)
+
+	assert.Equal(t, []map[uuid.UUID]time.Time{{id: now}}, repo.Batches())
+}
+
+func TestUsageRecorder_Record_DropsWhenFull(t *testing.T) {
+	recorder := NewUsageRecorder(zap.NewNop(), &usageRepoStub{}, WithUsageBufferSize(1))
+
+	recorder.Record(uuid.New(), time.Now())
+	// Must not block without a running recorder
+	recorder.Record(uuid.New(), time.Now())
+
+	assert.Len(t, recorder.usages, 1)
+}
@@ -0,0 +1,60 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type UsageSQLDB struct {
+	logger *zap.Logger
+	sqlE   sqldb.Executable
+}
+
+func NewUsageSQLDB(logger *zap.Logger, sqlE sqldb.Executable) *UsageSQLDB {
+	return &UsageSQLDB{
+		logger: logger,
+		sqlE:   sqlE,
+	}
+}
+
+// UpdateLastUsedTimes sets last used times in a single statement, times older than the stored one are ignored.
+// Version and update time are left unchanged as usage is not a modification of the key.
+func (u *UsageSQLDB) UpdateLastUsedTimes(ctx context.Context, lastUsed map[uuid.UUID]time.Time) error {
+	if len(lastUsed) == 0 {
+		return nil
+	}
+
+	var rows []postgres.RowExpression
+	for id, t := range lastUsed {
+		rows = append(rows, postgres.WRAP(
+			postgres.UUID(id),
+			postgres.TimestampzT(t),
+		))
+	}
+
+	uId := postgres.StringColumn("id")
+	uLastUsedTime := postgres.TimestampzColumn("last_used_time")
+
+	usageData := postgres.VALUES(rows...).AS("u", uId, uLastUsedTime)
+
+	stmt := table.APIKey.
+		UPDATE().
+		SET(table.APIKey.LastUsedTime.SET(uLastUsedTime)).
+		FROM(usageData).
+		WHERE(postgres.AND(
+			table.APIKey.ID.EQ(uId),
+			postgres.OR(
+				table.APIKey.LastUsedTime.IS_NULL(),
+				table.APIKey.LastUsedTime.LT(uLastUsedTime),
+			),
+		))
+
+	_, err := stmt.ExecContext(ctx, u.sqlE)
+	return err
+}
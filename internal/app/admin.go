@@ -0,0 +1,94 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dyxj/bigbackend/internal/authz"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/monitoring"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// BuildAdminRouter returns the router of the admin listener, serving operators and scrapers on a private port:
+// metrics, pprof, build info, the effective config, runtime toggles, verbose probes and the management of
+// idempotency keys and API keys. Requests require AdminToken when set.
+func (s *Server) BuildAdminRouter() http.Handler {
+	router := chi.NewRouter()
+	c := s.components()
+	errRegistry := c.errRegistry
+
+	router.Use(httpx.RequestIDMiddleware)
+	// Toggles are logged, scrapes are not
+	router.Use(httpx.AccessLogMiddleware(s.logger, httpx.WithAccessLogSkipPaths("/metrics")))
+	router.Use(s.buildAdminSecurity())
+	if token := s.httpConfig.AdminToken(); token != "" {
+		router.Use(auth.StaticTokenMiddleware(token, errRegistry.WriteError))
+	}
+
+	router.Handle("/metrics", promhttp.Handler())
+	monitoring.RegisterPprofRoutes(router)
+	router.Get("/buildinfo", monitoring.BuildInfoHandler())
+	if s.effectiveConfig != nil {
+		router.Get("/config", func(w http.ResponseWriter, r *http.Request) {
+			httpx.JsonResponse(http.StatusOK, s.effectiveConfig, w)
+		})
+	}
+
+	router.Route("/toggles", func(r chi.Router) {
+		if s.logLevel != nil {
+			// Responds and accepts {"level":"debug"}
+			r.Get("/log-level", s.logLevel.ServeHTTP)
+			r.Put("/log-level", s.logLevel.ServeHTTP)
+		}
+		profiling := monitoring.ProfilingToggleHandler(errRegistry.WriteError)
+		r.Get("/profiling", profiling)
+		r.Put("/profiling", profiling)
+	})
+
+	// Reports expose check names and errors, probes of the API listener respond in plain text
+	router.Get("/readyz", monitoring.ReadinessCheckHandler(
+		func() bool { return s.isShuttingDown.Load() },
+		c.readiness,
+		monitoring.WithVerbose(),
+	))
+	router.Get("/startupz", monitoring.StartupCheckHandler(c.startup, monitoring.WithVerbose()))
+
+	s.mountAdminManagement(router, c)
+
+	return router
+}
+
+// mountAdminManagement mounts the management of idempotency keys and API keys, authorized by AdminToken when set,
+// by the permissions of the authenticated principal otherwise. Not mounted when neither is enabled, cached
+// responses may hold personal data and keys grant API access.
+func (s *Server) mountAdminManagement(router chi.Router, c *components) {
+	var idemAdmin, apiKeysAdmin []func(http.Handler) http.Handler
+	switch {
+	case s.httpConfig.AdminToken() != "":
+		// Already required by every route of the admin listener, operators hold every permission
+	case s.authConfig.Enabled():
+		idemAdmin = append(idemAdmin, c.authenticate, c.enforcer.RequirePermission(authz.IdempotencyAdmin))
+		apiKeysAdmin = append(apiKeysAdmin, c.authenticate, c.enforcer.RequirePermission(authz.APIKeysManage))
+	default:
+		s.logger.Warn("admin management routes not mounted, neither admin token nor authentication enabled")
+		return
+	}
+
+	idemAdminHandler := idempotency.NewAdminHandler(s.logger, c.idemStore, idempotency.ScopeAdminKey)
+	router.Route("/admin/idempotency", func(r chi.Router) {
+		r.Use(idemAdmin...)
+		r.Get("/{key}", idemAdminHandler.Get)
+		r.Delete("/{key}", idemAdminHandler.Delete)
+	})
+
+	router.Route("/admin/api-keys", func(r chi.Router) {
+		r.Use(apiKeysAdmin...)
+		r.Post("/", c.apiKeyAdminHandler.Create)
+		r.Get("/", c.apiKeyAdminHandler.List)
+		r.Post("/{id}/rotate", c.apiKeyAdminHandler.Rotate)
+		r.Delete("/{id}", c.apiKeyAdminHandler.Revoke)
+	})
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/pkg/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const testAdminToken = "admin-token"
+
+func serveAdmin(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testAdminToken)
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func newAdminRouter(options ...ServerOption) http.Handler {
+	httpConfig := &config.HTTPServerConfig{AdminConfig: config.AdminConfig{AdminTokenEV: testAdminToken}}
+	return NewServer(zap.NewNop(), nil, httpConfig, &config.AuthConfig{}, nil, options...).BuildAdminRouter()
+}
+
+func TestBuildAdminRouter_Token(t *testing.T) {
+	router := newAdminRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serveAdmin(router, http.MethodGet, "/metrics", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+
+	w = serveAdmin(router, http.MethodGet, "/debug/pprof/cmdline", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBuildAdminRouter_BuildInfo(t *testing.T) {
+	w := serveAdmin(newAdminRouter(), http.MethodGet, "/buildinfo", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var info monitoring.BuildInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
+
+func TestBuildAdminRouter_Config(t *testing.T) {
+	w := serveAdmin(newAdminRouter(), http.MethodGet, "/config", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	cfg := &config.Config{DBConfig: &config.DBConfig{UserEV: "app", PasswordEV: "secret"}}
+	w = serveAdmin(newAdminRouter(WithEffectiveConfig(config.Redacted(cfg))), http.MethodGet, "/config", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var values map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &values))
+	assert.Equal(t, "app", values["DB_USER"])
+	assert.Equal(t, config.RedactedValue, values["DB_PASSWORD"])
+}
+
+func TestBuildAdminRouter_LogLevelToggle(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	router := newAdminRouter(WithLogLevel(level))
+
+	w := serveAdmin(router, http.MethodPut, "/toggles/log-level", `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+
+	w = serveAdmin(router, http.MethodGet, "/toggles/log-level", "")
+	assert.JSONEq(t, `{"level":"debug"}`, w.Body.String())
+}
+
+func TestBuildAdminRouter_ProfilingToggle(t *testing.T) {
+	router := newAdminRouter()
+	t.Cleanup(func() {
+		runtime.SetBlockProfileRate(0)
+		runtime.SetMutexProfileFraction(0)
+	})
+
+	w := serveAdmin(router, http.MethodPut, "/toggles/profiling", `{"blockProfileRate":1000,"mutexProfileFraction":5}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"blockProfileRate":1000,"mutexProfileFraction":5}`, w.Body.String())
+	assert.Equal(t, 5, runtime.SetMutexProfileFraction(-1))
+
+	w = serveAdmin(router, http.MethodPut, "/toggles/profiling", `{"blockProfileRate":-1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBuildAdminRouter_AdminManagementToken(t *testing.T) {
+	router := newAdminRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/idempotency/unknown-key", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serveAdmin(router, http.MethodGet, "/admin/idempotency/unknown-key", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
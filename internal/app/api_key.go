@@ -0,0 +1,27 @@
+package app
+
+import (
+	"context"
+
+	"github.com/dyxj/bigbackend/internal/apikey"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+)
+
+// buildAPIKeys returns the api key manager, its admin handler and the worker recording usage.
+func (s *Server) buildAPIKeys(
+	errRegistry *httpx.ErrorRegistry,
+) (*apikey.Manager, *apikey.AdminHandler, func(ctx context.Context)) {
+	mapper := &apikey.APIKeyMapper{}
+
+	usageRecorder := apikey.NewUsageRecorder(s.logger, apikey.NewUsageSQLDB(s.logger, s.dbConn))
+
+	manager := apikey.NewManager(s.logger,
+		apikey.NewCreatorSQLDB(s.logger, s.dbConn),
+		apikey.NewGetterSQLDB(s.logger, s.dbConn),
+		apikey.NewUpdaterSQLDB(s.logger, s.dbConn),
+		usageRecorder,
+		mapper,
+	)
+
+	return manager, apikey.NewAdminHandler(s.logger, errRegistry, manager, mapper), usageRecorder.Run
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const apiPrefix = "/api"
+const apiV1Prefix = apiPrefix + "/v1"
+
+func (s *Server) apiV1() httpx.APIVersion {
+	return httpx.APIVersion{
+		Name:            "v1",
+		DeprecationTime: s.httpConfig.APIV1DeprecationTime(),
+		SunsetTime:      s.httpConfig.APIV1SunsetTime(),
+		Link:            s.httpConfig.APIV1DeprecationLink(),
+	}
+}
+
+// buildAPIRouter returns the router of an API version with the middlewares shared by every version,
+// routes registers the routes of the version, served with its own response types.
+func (s *Server) buildAPIRouter(
+	version httpx.APIVersion,
+	errRegistry *httpx.ErrorRegistry,
+	authenticate func(http.Handler) http.Handler,
+	routes func(r chi.Router),
+) chi.Router {
+	var options []httpx.APIVersionOption
+	if s.metrics != nil {
+		options = append(options, httpx.WithAPIVersionMetrics(s.metrics))
+	}
+
+	apiRouter := chi.NewRouter()
+
+	apiRouter.Use(httpx.APIVersionMiddleware(version, options...))
+	// Before authentication, preflight requests carry no credentials
+	apiRouter.Use(s.buildAPISecurity())
+	apiRouter.Use(s.buildTimeout(errRegistry))
+	apiRouter.Use(middleware.Recoverer)
+	// Before authentication, so that rejected credentials are limited as well
+	apiRouter.Use(s.components().rateLimits.ip)
+	apiRouter.Use(authenticate)
+	apiRouter.Use(tenant.Middleware(auth.PrincipalTenant, errRegistry.WriteError,
+		tenantHeaderGuard(s.components().enforcer),
+	))
+	apiRouter.Use(s.LogContext)
+
+	routes(apiRouter)
+
+	return apiRouter
+}
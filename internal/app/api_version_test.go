@@ -0,0 +1,52 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/openapi"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBuildRouter_APIV1Deprecated(t *testing.T) {
+	httpConfig := &config.HTTPServerConfig{APIVersionConfig: config.APIVersionConfig{
+		APIV1DeprecationTimeEV: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		APIV1SunsetTimeEV:      time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		APIV1DeprecationLinkEV: "https://docs.test/migrate-v2",
+	}}
+	router := NewServer(zap.NewNop(), nil, httpConfig, &config.AuthConfig{}, nil).BuildRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, apiV1Prefix+"/user/"+uuid.NewString()+"/profile", nil))
+
+	assert.Equal(t, "@1767225600", w.Header().Get(httpx.HeaderKeyDeprecation))
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", w.Header().Get(httpx.HeaderKeySunset))
+	assert.Equal(t, `<https://docs.test/migrate-v2>; rel="deprecation"; type="text/html"`, w.Header().Get(httpx.HeaderKeyLink))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var doc openapi.Document
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+	assert.True(t, doc.Paths[apiV1Prefix+"/user/{id}/profile"]["get"].Deprecated)
+	assert.Empty(t, w.Header().Get(httpx.HeaderKeyDeprecation))
+}
+
+func TestBuildRouter_APIV1Current(t *testing.T) {
+	router := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil).BuildRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, apiV1Prefix+"/user/"+uuid.NewString()+"/profile", nil))
+
+	assert.Empty(t, w.Header().Get(httpx.HeaderKeyDeprecation))
+	assert.Empty(t, w.Header().Get(httpx.HeaderKeySunset))
+}
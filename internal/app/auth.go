@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/authz"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/rbac"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"go.uber.org/zap"
+)
+
+const jwksFetchTimeout = 5 * time.Second
+
+// buildAuth returns the authentication middleware and authorizer of API routes and gRPC services,
+// accepting bearer tokens and keys verified by apiKeys.
+// When authentication is disabled the middleware is nil and every subject is authorized.
+func (s *Server) buildAuth(
+	errRegistry *httpx.ErrorRegistry,
+	apiKeys auth.APIKeyVerifier,
+) (*auth.Middleware, auth.Authorizer) {
+	if !s.authConfig.Enabled() {
+		s.logger.Warn("authentication is disabled, API routes and gRPC services are publicly accessible")
+		return nil, auth.AllowAll()
+	}
+
+	source := auth.FileSource(s.authConfig.JWKSFile())
+	if s.authConfig.JWKSURL() != "" {
+		source = auth.URLSource(&http.Client{Timeout: jwksFetchTimeout}, s.authConfig.JWKSURL())
+	}
+	keySet := auth.NewCachedKeySet(s.logger, source, auth.WithCacheTTL(s.authConfig.JWKSCacheTTL()))
+
+	// Keys are loaded lazily on failure, the error surfaces misconfiguration early
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	if err := keySet.Refresh(ctx); err != nil {
+		s.logger.Error("failed to load JWKS", zap.Error(err))
+	}
+
+	verifier := auth.NewVerifier(keySet, s.authConfig.Issuer(), s.authConfig.Audience(),
+		auth.WithClockSkew(s.authConfig.ClockSkew()),
+	)
+	middleware := auth.NewMiddleware(s.logger, verifier, errRegistry.WriteError, auth.WithAPIKeyVerifier(apiKeys))
+
+	return middleware, auth.NewSubjectAuthorizer(s.authConfig.AdminScope())
+}
+
+// buildEnforcer returns the role permission enforcer, with the policy of AuthConfig.PolicyFile or the database.
+func (s *Server) buildEnforcer(errRegistry *httpx.ErrorRegistry) *rbac.Enforcer {
+	loader := rbac.FileLoader(s.authConfig.PolicyFile())
+	if s.authConfig.PolicyFile() == "" {
+		loader = authz.NewPolicyGetterSQLDB(s.logger, s.dbConn).LoadPolicy
+	}
+	return rbac.NewEnforcer(s.logger, loader, errRegistry.WriteError,
+		rbac.WithReloadInterval(s.authConfig.PolicyReloadInterval()),
+	)
+}
+
+// tenantHeaderGuard allows principals granted authz.TenantsAny to name the tenant by header without claiming it.
+// Requests are anonymous only while authentication is disabled, their header is trusted.
+func tenantHeaderGuard(enforcer *rbac.Enforcer) tenant.Option {
+	return tenant.WithHeaderGuard(func(ctx context.Context) bool {
+		if _, ok := auth.PrincipalFromContext(ctx); !ok {
+			return true
+		}
+		return enforcer.Check(ctx, authz.TenantsAny) == nil
+	})
+}
@@ -0,0 +1,278 @@
+package app
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newAuthEnabledServer(t *testing.T) (*Server, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "OKP", "kid": "test", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(pub)},
+	}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	policyPath := filepath.Join(t.TempDir(), "policy.json")
+	policy := `{"roles": {"operator": ["idempotency:admin"]}}`
+	require.NoError(t, os.WriteFile(policyPath, []byte(policy), 0o600))
+
+	authConfig := &config.AuthConfig{
+		EnabledEV:      true,
+		IssuerEV:       "https://issuer.test",
+		AudienceEV:     "bigbackend",
+		JWKSFileEV:     path,
+		JWKSCacheTTLEV: time.Minute,
+		AdminScopeEV:   "admin",
+		PolicyFileEV:   policyPath,
+	}
+	return NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, authConfig, nil), priv
+}
+
+// testTenant claimed by tokens of signTestToken.
+const testTenant = "6f1c1b52-4bb4-4a8e-9d9e-0b6a3cf1d9f1"
+
+func signTestToken(t *testing.T, priv ed25519.PrivateKey, subject string, roles ...string) string {
+	t.Helper()
+	return signTestTokenOfTenant(t, priv, testTenant, subject, roles...)
+}
+
+// signTestTokenOfTenant signs a token claiming tenantID, none when empty.
+func signTestTokenOfTenant(t *testing.T, priv ed25519.PrivateKey, tenantID, subject string, roles ...string) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": "EdDSA", "kid": "test"})
+	require.NoError(t, err)
+	claims, err := json.Marshal(map[string]any{
+		"iss":       "https://issuer.test",
+		"aud":       "bigbackend",
+		"sub":       subject,
+		"roles":     roles,
+		"tenant_id": tenantID,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, []byte(signed)))
+}
+
+func TestBuildRouter_AuthEnabled(t *testing.T) {
+	s, priv := newAuthEnabledServer(t)
+	router := s.BuildRouter()
+	profileURL := apiV1Prefix + "/user/" + uuid.NewString() + "/profile"
+
+	tests := []struct {
+		name           string
+		token          string
+		tenantHeader   string
+		expectedStatus int
+	}{
+		{name: "missing token", expectedStatus: http.StatusUnauthorized},
+		{name: "invalid token", token: "invalid", expectedStatus: http.StatusUnauthorized},
+		{name: "other subject", token: signTestToken(t, priv, uuid.NewString()), expectedStatus: http.StatusForbidden},
+		{
+			name:           "other tenant than claimed",
+			token:          signTestToken(t, priv, uuid.NewString()),
+			tenantHeader:   uuid.NewString(),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "tenant not claimed",
+			token:          signTestTokenOfTenant(t, priv, "", uuid.NewString()),
+			tenantHeader:   uuid.NewString(),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, profileURL, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.tenantHeader != "" {
+				r.Header.Set(tenant.HeaderKey, tt.tenantHeader)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestBuildRouter_AuthEnabled_HealthUnauthenticated(t *testing.T) {
+	s, _ := newAuthEnabledServer(t)
+	router := s.BuildRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBuildAdminRouter_AuthEnabled_AdminRequiresPermission(t *testing.T) {
+	s, priv := newAuthEnabledServer(t)
+	router := s.BuildAdminRouter()
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "missing token", expectedStatus: http.StatusUnauthorized},
+		{name: "missing permission", token: signTestToken(t, priv, uuid.NewString()), expectedStatus: http.StatusForbidden},
+		{name: "granted", token: signTestToken(t, priv, uuid.NewString(), "operator"), expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/admin/idempotency/unknown-key", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestBuildAdminRouter_AuthEnabled_APIKeys(t *testing.T) {
+	s, priv := newAuthEnabledServer(t)
+	router := s.BuildAdminRouter()
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "missing token", expectedStatus: http.StatusUnauthorized},
+		{
+			name:           "missing permission",
+			authorization:  "Bearer " + signTestToken(t, priv, uuid.NewString()),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/admin/api-keys/", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestBuildRouter_AuthEnabled_APIKeyMalformed(t *testing.T) {
+	s, _ := newAuthEnabledServer(t)
+	router := s.BuildRouter()
+
+	// Malformed keys are rejected without a lookup
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/user/"+uuid.NewString()+"/profile", nil)
+	r.Header.Set("Authorization", "ApiKey bbk_malformed")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestBuildRouter_AdminManagementNotMounted(t *testing.T) {
+	s, priv := newAuthEnabledServer(t)
+	router := s.BuildRouter()
+	token := signTestToken(t, priv, uuid.NewString(), "operator")
+
+	for _, path := range []string{"/admin/api-keys/", "/admin/idempotency/some-key"} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
+
+func TestBuildAdminRouter_AuthDisabled_AdminManagementNotMounted(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+	router := s.BuildAdminRouter()
+
+	for _, path := range []string{"/admin/api-keys/", "/admin/idempotency/some-key"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
+
+func TestBuildRouter_AuthDisabled_WebhooksNotMounted(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+	router := s.BuildRouter()
+
+	r := httptest.NewRequest(http.MethodGet, apiV1Prefix+"/webhooks/", nil)
+	r.Header.Set(tenant.HeaderKey, testTenant)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBuildRouter_RateLimitsBeforeAuthentication(t *testing.T) {
+	s, _ := newAuthEnabledServer(t)
+	s.httpConfig = &config.HTTPServerConfig{
+		RateLimitEnabledEV:       true,
+		RateLimitStoreEV:         config.RateLimitStoreMemory,
+		RateLimitRequestsEV:      10,
+		RateLimitWriteRequestsEV: 10,
+		RateLimitIPRequestsEV:    2,
+		RateLimitPeriodEV:        time.Minute,
+	}
+	router := s.BuildRouter()
+	profileURL := apiV1Prefix + "/user/" + uuid.NewString() + "/profile"
+
+	serve := func(remoteAddr string) int {
+		r := httptest.NewRequest(http.MethodGet, profileURL, nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("Authorization", "Bearer invalid")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Rejected credentials count against the client IP
+	assert.Equal(t, http.StatusUnauthorized, serve("192.0.2.1:1234"))
+	assert.Equal(t, http.StatusUnauthorized, serve("192.0.2.1:1234"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("192.0.2.1:1234"))
+
+	assert.Equal(t, http.StatusUnauthorized, serve("192.0.2.2:1234"))
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/apikey"
+	"github.com/dyxj/bigbackend/internal/notification"
+	"github.com/dyxj/bigbackend/internal/user/invitation"
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/monitoring"
+	"github.com/dyxj/bigbackend/pkg/rbac"
+)
+
+// components shared by the HTTP router and the gRPC server, built once so that both APIs share stores,
+// the event broker, shutdown hooks and workers.
+type components struct {
+	errRegistry *httpx.ErrorRegistry
+
+	// readiness and startup checks registered by subsystems, see registerHealthChecks
+	readiness *monitoring.HealthRegistry
+	startup   *monitoring.HealthRegistry
+
+	apiKeyManager      *apikey.Manager
+	apiKeyAdminHandler *apikey.AdminHandler
+	// authMiddleware nil when authentication is disabled
+	authMiddleware *auth.Middleware
+	authorizer     auth.Authorizer
+	enforcer       *rbac.Enforcer
+
+	idemStore      idempotency.Store
+	idemMiddleware *idempotency.Middleware
+
+	rateLimits rateLimits
+
+	webhookPublisher       *webhook.Publisher
+	webhookHandler         *webhook.Handler
+	notifier               *notification.Notifier
+	userEventStreamHandler *notification.StreamHandler
+
+	userProfile       userProfileComponents
+	invitationCreator invitation.Creator
+
+	// workers run with the server until ongoing requests are stopped, see addWorker
+	workers []func(ctx context.Context)
+}
+
+// components returns the shared components, built on first use.
+func (s *Server) components() *components {
+	s.componentsOnce.Do(func() {
+		s.comps = s.buildComponents()
+	})
+	return s.comps
+}
+
+func (s *Server) buildComponents() *components {
+	c := &components{
+		errRegistry: s.buildErrorRegistry(),
+		readiness:   s.newHealthRegistry(),
+		startup:     s.newHealthRegistry(),
+	}
+
+	var usageWorker func(ctx context.Context)
+	c.apiKeyManager, c.apiKeyAdminHandler, usageWorker = s.buildAPIKeys(c.errRegistry)
+	c.addWorker(usageWorker)
+	c.authMiddleware, c.authorizer = s.buildAuth(c.errRegistry, c.apiKeyManager)
+	c.enforcer = s.buildEnforcer(c.errRegistry)
+
+	c.idemStore = idempotency.NewMemStore(idempotency.DefaultLockConfig)
+	idemOptions := []idempotency.Option{
+		idempotency.WithCacheExpiry(24 * time.Hour),
+		idempotency.WithLockOptions(
+			idempotency.WithLockRetry(3, 100*time.Millisecond),
+			idempotency.WithLockExpiry(5*time.Second),
+		),
+		idempotency.WithErrorResponseWriter(c.errRegistry.WriteError),
+		idempotency.WithKeyScope(idempotency.PrincipalRouteScope(auth.PrincipalSubject)),
+	}
+	if s.metrics != nil {
+		idemOptions = append(idemOptions, idempotency.WithMetrics(s.metrics))
+	}
+	c.idemMiddleware = idempotency.NewMiddleware(s.logger, c.idemStore, idemOptions...)
+
+	var rateLimitWorker func(ctx context.Context)
+	c.rateLimits, rateLimitWorker = s.buildRateLimits(c.errRegistry)
+	c.addWorker(rateLimitWorker)
+
+	var dispatchWorker func(ctx context.Context)
+	c.webhookPublisher, c.webhookHandler, dispatchWorker = s.buildWebhooks(c.errRegistry, c.readiness)
+	c.addWorker(dispatchWorker)
+	c.notifier, c.userEventStreamHandler = s.buildNotifications(c.errRegistry, c.authorizer, c.enforcer)
+	c.userProfile = s.buildUserProfile(c.authorizer, c.enforcer, c.webhookPublisher)
+	c.invitationCreator = s.buildInvitationCreator(c.notifier)
+
+	s.registerHealthChecks(c)
+
+	return c
+}
+
+// addWorker registers a background worker run with the server, worker must return once ctx is done.
+// Nil workers are skipped.
+func (c *components) addWorker(worker func(ctx context.Context)) {
+	if worker != nil {
+		c.workers = append(c.workers, worker)
+	}
+}
+
+// authenticate API requests, requests pass through when authentication is disabled.
+func (c *components) authenticate(next http.Handler) http.Handler {
+	if c.authMiddleware == nil {
+		return next
+	}
+	return c.authMiddleware.Authenticate(next)
+}
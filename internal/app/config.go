@@ -1,6 +1,9 @@
 package app
 
-import "time"
+import (
+	"net/netip"
+	"time"
+)
 
 type HttpConfig interface {
 	Host() string
@@ -13,4 +16,103 @@ type HttpConfig interface {
 	ShutDownTimeout() time.Duration
 	ShutDownHardTimeout() time.Duration
 	ShutDownReadyDelay() time.Duration
+
+	OpenAPIUIEnabled() bool
+
+	// AccessLogSampleRate fraction of successful requests logged, between 0 and 1.
+	AccessLogSampleRate() float64
+	AccessLogSlowThreshold() time.Duration
+	// TrustedProxies allowed to set X-Forwarded-For.
+	TrustedProxies() []netip.Prefix
+
+	CompressionEnabled() bool
+	// CompressionMinSize in bytes, smaller responses are not compressed.
+	CompressionMinSize() int
+
+	RateLimitEnabled() bool
+	// RateLimitStore memory or postgres, postgres shares limits between instances.
+	RateLimitStore() string
+	// RateLimitRequests allowed per client per RateLimitPeriod, RateLimitWriteRequests for mutating routes.
+	RateLimitRequests() int
+	RateLimitWriteRequests() int
+	// RateLimitIPRequests allowed per client IP per RateLimitPeriod, before authentication.
+	RateLimitIPRequests() int
+	RateLimitPeriod() time.Duration
+	// RateLimitBurst defaults to the route limit when zero.
+	RateLimitBurst() int
+
+	// CORSAllowedOrigins of API routes, CORS is disabled when empty.
+	CORSAllowedOrigins() []string
+	CORSAllowedMethods() []string
+	CORSAllowedHeaders() []string
+	CORSExposedHeaders() []string
+	CORSAllowCredentials() bool
+	CORSMaxAge() time.Duration
+	// HSTSMaxAge Strict-Transport-Security is not set when zero.
+	HSTSMaxAge() time.Duration
+	HSTSIncludeSubdomains() bool
+
+	// APIV1DeprecationTime and APIV1SunsetTime are zero when not planned.
+	APIV1DeprecationTime() time.Time
+	APIV1SunsetTime() time.Time
+	// APIV1DeprecationLink to the migration guide from v1.
+	APIV1DeprecationLink() string
+
+	// WebhookDispatchEnabled runs the webhook dispatcher, deliveries are queued regardless.
+	WebhookDispatchEnabled() bool
+	WebhookPollInterval() time.Duration
+	// WebhookBatchSize deliveries attempted concurrently.
+	WebhookBatchSize() int
+	WebhookTimeout() time.Duration
+	WebhookMaxAttempts() int
+	// WebhookBackoffBase delay before the first retry, doubling up to WebhookBackoffMax.
+	WebhookBackoffBase() time.Duration
+	WebhookBackoffMax() time.Duration
+	// WebhookDisableAfter consecutive failed attempts of a subscription.
+	WebhookDisableAfter() int
+	// WebhookMaxLag of the oldest due delivery above which readiness is degraded.
+	WebhookMaxLag() time.Duration
+	// WebhookAllowedNetworks receivers may resolve to, besides public addresses.
+	WebhookAllowedNetworks() []netip.Prefix
+
+	// SSEHeartbeatInterval of comments keeping idle event streams open through proxies.
+	SSEHeartbeatInterval() time.Duration
+	// SSEWriteTimeout event stream clients not reading within it are disconnected.
+	SSEWriteTimeout() time.Duration
+	// SSEReplaySize latest events kept for clients resuming with Last-Event-ID.
+	SSEReplaySize() int
+	// SSESubscriberBuffer events queued per client before a slow client is disconnected.
+	SSESubscriberBuffer() int
+
+	// GRPCEnabled serves the gRPC API on GRPCPort, sharing Host.
+	GRPCEnabled() bool
+	GRPCPort() int
+
+	// HealthCheckTimeout of every readiness and startup check.
+	HealthCheckTimeout() time.Duration
+	// HealthCheckCacheTTL check results are reused within, bounding the load of probes on dependencies.
+	HealthCheckCacheTTL() time.Duration
+
+	// AdminEnabled serves metrics, profiling and diagnostics on AdminHost and AdminPort.
+	AdminEnabled() bool
+	AdminHost() string
+	AdminPort() int
+	// AdminToken bearer token required by the admin listener, none when empty.
+	AdminToken() string
+}
+
+type AuthConfig interface {
+	Enabled() bool
+	Issuer() string
+	Audience() string
+	// JWKSURL and JWKSFile are exclusive sources of the key set.
+	JWKSURL() string
+	JWKSFile() string
+	JWKSCacheTTL() time.Duration
+	ClockSkew() time.Duration
+	// AdminScope grants access to resources of every user.
+	AdminScope() string
+	// PolicyFile of role permissions, loaded from the database when empty.
+	PolicyFile() string
+	PolicyReloadInterval() time.Duration
 }
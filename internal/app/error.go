@@ -0,0 +1,87 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/sse"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+)
+
+// buildErrorRegistry maps errors of packages used by the server to responses.
+func (s *Server) buildErrorRegistry() *httpx.ErrorRegistry {
+	errRegistry := httpx.NewErrorRegistry(s.logger)
+
+	errRegistry.RegisterIs(idempotency.ErrInProgress, httpx.ErrorMapping{
+		Status:   http.StatusConflict,
+		Code:     httpx.CodeIdempotencyError,
+		Message:  "processing of idempotency key is in progress",
+		LogLevel: zapcore.WarnLevel,
+	})
+	errRegistry.RegisterIs(idempotency.ErrKeyRequired, httpx.ErrorMapping{
+		Status:   http.StatusBadRequest,
+		Code:     httpx.CodeIdempotencyError,
+		Message:  idempotency.ErrKeyRequired.Error(),
+		LogLevel: zapcore.WarnLevel,
+	})
+	errRegistry.RegisterIs(idempotency.ErrInvalidKey, httpx.ErrorMapping{
+		Status:   http.StatusBadRequest,
+		Code:     httpx.CodeIdempotencyError,
+		Message:  idempotency.ErrInvalidKey.Error(),
+		LogLevel: zapcore.WarnLevel,
+	})
+	errRegistry.RegisterIs(idempotency.ErrKeyMismatch, httpx.ErrorMapping{
+		Status:   http.StatusUnprocessableEntity,
+		Code:     httpx.CodeIdempotencyError,
+		Message:  idempotency.ErrKeyMismatch.Error(),
+		LogLevel: zapcore.WarnLevel,
+	})
+	errRegistry.RegisterIs(tenant.ErrRequired, httpx.ErrorMapping{
+		Status:   http.StatusBadRequest,
+		Code:     httpx.CodeBadRequest,
+		Message:  tenant.ErrRequired.Error(),
+		LogLevel: zapcore.WarnLevel,
+	})
+	errRegistry.RegisterIs(tenant.ErrInvalid, httpx.ErrorMapping{
+		Status:   http.StatusBadRequest,
+		Code:     httpx.CodeBadRequest,
+		Message:  tenant.ErrInvalid.Error(),
+		LogLevel: zapcore.WarnLevel,
+	})
+	errRegistry.RegisterIs(sse.ErrClosed, httpx.ErrorMapping{
+		Status:   http.StatusServiceUnavailable,
+		Code:     httpx.CodeUnavailable,
+		Message:  "server is shutting down, reconnect to stream events",
+		LogLevel: zapcore.InfoLevel,
+	})
+
+	return errRegistry
+}
+
+// buildGRPCErrorRegistry maps errors of packages used by the gRPC server to statuses.
+func (s *Server) buildGRPCErrorRegistry() *grpcx.ErrorRegistry {
+	errRegistry := grpcx.NewErrorRegistry(s.logger)
+
+	errRegistry.RegisterIs(idempotency.ErrInProgress, grpcx.ErrorMapping{
+		Code:     codes.Aborted,
+		Message:  "processing of idempotency key is in progress",
+		LogLevel: zapcore.WarnLevel,
+	})
+	invalidArguments := []error{
+		idempotency.ErrKeyRequired, idempotency.ErrInvalidKey, idempotency.ErrKeyMismatch,
+		tenant.ErrRequired, tenant.ErrInvalid,
+	}
+	for _, err := range invalidArguments {
+		errRegistry.RegisterIs(err, grpcx.ErrorMapping{
+			Code:     codes.InvalidArgument,
+			Message:  err.Error(),
+			LogLevel: zapcore.WarnLevel,
+		})
+	}
+
+	return errRegistry
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+
+	"github.com/dyxj/bigbackend/internal/authz"
+	userv1 "github.com/dyxj/bigbackend/internal/grpcgen/bigbackend/user/v1"
+	"github.com/dyxj/bigbackend/internal/user/invitation"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/rbac"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// BuildGRPCServer returns the gRPC server of internal services and its health server, sharing stores,
+// authentication and authorization with BuildRouter. Services are served as NOT_SERVING once shutdown starts.
+func (s *Server) BuildGRPCServer() (*grpc.Server, *health.Server) {
+	c := s.components()
+	errRegistry := s.buildGRPCErrorRegistry()
+
+	services := []string{
+		userv1.UserProfileService_ServiceDesc.ServiceName,
+		userv1.UserInvitationService_ServiceDesc.ServiceName,
+	}
+
+	interceptors := []grpc.UnaryServerInterceptor{
+		grpcx.RequestIDInterceptor,
+		grpcx.AccessLogInterceptor(s.logger,
+			grpcx.WithSuccessSampleRate(s.httpConfig.AccessLogSampleRate()),
+			grpcx.WithSlowThreshold(s.httpConfig.AccessLogSlowThreshold()),
+		),
+	}
+	if s.metrics != nil {
+		interceptors = append(interceptors, s.metrics.GRPCMetricsInterceptor)
+	}
+	interceptors = append(interceptors, errRegistry.UnaryServerInterceptor, grpcx.RecoveryInterceptor(s.logger))
+
+	// Mirrors the middlewares of API routes, health checks and reflection are served without them
+	var serviceInterceptors []grpc.UnaryServerInterceptor
+	if c.authMiddleware != nil {
+		serviceInterceptors = append(serviceInterceptors, c.authMiddleware.UnaryServerInterceptor)
+	}
+	serviceInterceptors = append(serviceInterceptors,
+		tenant.UnaryServerInterceptor(auth.PrincipalTenant, tenantHeaderGuard(c.enforcer)),
+		s.logContextInterceptor,
+		s.timeoutInterceptor,
+	)
+	if s.authConfig.Enabled() {
+		serviceInterceptors = append(serviceInterceptors, c.enforcer.UnaryServerInterceptor(map[string][]rbac.Permission{
+			userv1.UserInvitationService_CreateUserInvitation_FullMethodName: {authz.InvitationsCreate},
+		}))
+	}
+	serviceInterceptors = append(serviceInterceptors, c.idemMiddleware.UnaryServerInterceptor(
+		map[string]idempotency.Policy{
+			userv1.UserProfileService_CreateUserProfile_FullMethodName:       idempotency.PolicyRequired,
+			userv1.UserInvitationService_CreateUserInvitation_FullMethodName: idempotency.PolicyOptional,
+		},
+		auth.ContextSubject,
+	))
+	for _, interceptor := range serviceInterceptors {
+		interceptors = append(interceptors, grpcx.ForServices(interceptor, services...))
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+
+	userv1.RegisterUserProfileServiceServer(server, s.buildUserProfileGRPCService(c.userProfile, c.notifier))
+	userv1.RegisterUserInvitationServiceServer(server,
+		invitation.NewGRPCService(s.logger, c.invitationCreator, &invitation.UserInvitationMapper{}))
+
+	healthServer := health.NewServer()
+	for _, service := range services {
+		healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return server, healthServer
+}
+
+// logContextInterceptor attaches the tenant of the call to logx.FromContext loggers, as LogContext does.
+func (s *Server) logContextInterceptor(
+	ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		ctx = logx.WithFields(ctx, logx.TenantID(tenantID.String()))
+	}
+	return handler(ctx, req)
+}
+
+// timeoutInterceptor bounds calls by HandlerTimeout as API routes are, shorter client deadlines still apply.
+func (s *Server) timeoutInterceptor(
+	ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	if s.httpConfig.HandlerTimeout() <= 0 {
+		return handler(ctx, req)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.httpConfig.HandlerTimeout())
+	defer cancel()
+	return handler(ctx, req)
+}
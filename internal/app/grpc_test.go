@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	userv1 "github.com/dyxj/bigbackend/internal/grpcgen/bigbackend/user/v1"
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialGRPC serves BuildGRPCServer in memory, returning a connection and the server.
+func dialGRPC(t *testing.T) (*grpc.ClientConn, *Server) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+	server, healthServer := s.BuildGRPCServer()
+	s.grpcHealth = healthServer
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn, s
+}
+
+func TestBuildGRPCServer_Health(t *testing.T) {
+	conn, s := dialGRPC(t)
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	resp, err := client.Check(t.Context(), &grpc_health_v1.HealthCheckRequest{
+		Service: userv1.UserProfileService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	// Load balancers stop sending new calls once shutdown starts
+	s.grpcHealth.Shutdown()
+	resp, err = client.Check(t.Context(), &grpc_health_v1.HealthCheckRequest{
+		Service: userv1.UserProfileService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
+
+func TestBuildGRPCServer_Reflection(t *testing.T) {
+	conn, _ := dialGRPC(t)
+
+	stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(t.Context())
+	require.NoError(t, err)
+	err = stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+	})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, userv1.UserProfileService_ServiceDesc.ServiceName)
+	assert.Contains(t, services, userv1.UserInvitationService_ServiceDesc.ServiceName)
+}
+
+func TestBuildGRPCServer_TenantRequired(t *testing.T) {
+	conn, _ := dialGRPC(t)
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), grpcx.MetadataKeyRequestID, "request-1")
+	var header metadata.MD
+	_, err := userv1.NewUserProfileServiceClient(conn).GetUserProfile(ctx,
+		&userv1.GetUserProfileRequest{UserId: uuid.NewString()}, grpc.Header(&header))
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, tenant.ErrRequired.Error(), st.Message())
+	assert.Equal(t, []string{"request-1"}, header.Get(grpcx.MetadataKeyRequestID))
+}
+
+func TestBuildGRPCServer_IdempotencyKeyRequired(t *testing.T) {
+	conn, _ := dialGRPC(t)
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), tenant.MetadataKey, uuid.NewString())
+	_, err := userv1.NewUserProfileServiceClient(conn).CreateUserProfile(ctx, &userv1.CreateUserProfileRequest{
+		UserId:      uuid.NewString(),
+		FirstName:   "first",
+		LastName:    "last",
+		DateOfBirth: "1990-01-02",
+	})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, idempotency.ErrKeyRequired.Error(), st.Message())
+}
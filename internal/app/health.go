@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/monitoring"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+)
+
+// healthCheckKey looked up by the idempotency store check, never stored.
+const healthCheckKey = "healthcheck"
+
+// newHealthRegistry applies the configured timeout and cache TTL to every check.
+func (s *Server) newHealthRegistry() *monitoring.HealthRegistry {
+	options := []monitoring.HealthRegistryOption{
+		monitoring.WithCheckDefaults(
+			monitoring.WithCheckTimeout(s.httpConfig.HealthCheckTimeout()),
+			monitoring.WithCheckCacheTTL(s.httpConfig.HealthCheckCacheTTL()),
+		),
+	}
+	if s.metrics != nil {
+		options = append(options, monitoring.WithHealthMetrics(s.metrics))
+	}
+	return monitoring.NewHealthRegistry(options...)
+}
+
+// registerHealthChecks of the database and the idempotency store, other subsystems register their own,
+// e.g. the webhook outbox lag. Startup waits for migrations, applied by this or another instance.
+func (s *Server) registerHealthChecks(c *components) {
+	c.readiness.Register("database", s.dbConn.PingContext)
+	// Writes requiring a key fail without the store, reads are still served
+	c.readiness.Register("idempotency_store", idempotencyStoreCheck(c.idemStore), monitoring.WithCritical(false))
+
+	c.startup.Register("migrations", func(ctx context.Context) error {
+		return sqldb.CheckMigration(ctx, s.dbConn)
+	})
+}
+
+func idempotencyStoreCheck(store idempotency.Store) monitoring.HealthCheckFunc {
+	return func(ctx context.Context) error {
+		_, err := store.Get(ctx, healthCheckKey)
+		return err
+	}
+}
+
+// lagCheck fails once lag exceeds maxLag.
+func lagCheck(lag func(ctx context.Context) (time.Duration, error), maxLag time.Duration) monitoring.HealthCheckFunc {
+	return func(ctx context.Context) error {
+		d, err := lag(ctx)
+		if err != nil {
+			return err
+		}
+		if d > maxLag {
+			return fmt.Errorf("lag %v exceeds %v", d.Truncate(time.Second), maxLag)
+		}
+		return nil
+	}
+}
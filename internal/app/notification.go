@@ -0,0 +1,37 @@
+package app
+
+import (
+	"github.com/dyxj/bigbackend/internal/authz"
+	"github.com/dyxj/bigbackend/internal/notification"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/rbac"
+	"github.com/dyxj/bigbackend/pkg/sse"
+)
+
+// buildNotifications returns the notifier pushing events to users and the handler streaming them,
+// streams are ended once the server shuts down so that clients reconnect to another instance.
+func (s *Server) buildNotifications(
+	errRegistry *httpx.ErrorRegistry,
+	authorizer auth.Authorizer,
+	enforcer *rbac.Enforcer,
+) (*notification.Notifier, *notification.StreamHandler) {
+	options := []sse.BrokerOption{
+		sse.WithReplaySize(s.httpConfig.SSEReplaySize()),
+		sse.WithSubscriberBuffer(s.httpConfig.SSESubscriberBuffer()),
+	}
+	if s.metrics != nil {
+		options = append(options, sse.WithMetrics(s.metrics))
+	}
+	broker := sse.NewBroker(options...)
+	s.addShutdownHook(broker.Close)
+
+	// Staff holding the permission stream events of other users, as they read their profiles
+	streamAuthorizer := rbac.SubjectOrPermission(authorizer, enforcer, authz.ProfilesReadAny)
+
+	return notification.NewNotifier(s.logger, broker),
+		notification.NewStreamHandler(s.logger, errRegistry, streamAuthorizer, broker,
+			sse.WithHeartbeat(s.httpConfig.SSEHeartbeatInterval()),
+			sse.WithWriteTimeout(s.httpConfig.SSEWriteTimeout()),
+		)
+}
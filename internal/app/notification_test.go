@@ -0,0 +1,69 @@
+package app
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBuildRouter_UserEventStream(t *testing.T) {
+	httpConfig := &config.HTTPServerConfig{
+		HandlerTimeoutEV:     10 * time.Millisecond,
+		CompressionEnabledEV: true,
+		CompressionMinSizeEV: 1,
+		SSEConfig: config.SSEConfig{
+			SSEHeartbeatIntervalEV: 50 * time.Millisecond,
+			SSEWriteTimeoutEV:      time.Second,
+			SSEReplaySizeEV:        10,
+			SSESubscriberBufferEV:  10,
+		},
+	}
+	s := NewServer(zap.NewNop(), nil, httpConfig, &config.AuthConfig{}, nil)
+	server := httptest.NewServer(s.BuildRouter())
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet,
+		server.URL+apiV1Prefix+"/user/"+uuid.NewString()+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set(tenant.HeaderKey, uuid.NewString())
+	req.Header.Set(httpx.HeaderKeyAcceptEncoding, httpx.EncodingGzip)
+
+	resp, err := server.Client().Transport.RoundTrip(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Empty(t, resp.Header.Get(httpx.HeaderKeyContentEncoding))
+
+	// Heartbeats outlive the handler timeout
+	body := bufio.NewReader(resp.Body)
+	var heartbeats int
+	for heartbeats < 2 {
+		line, err := body.ReadString('\n')
+		require.NoError(t, err)
+		if line == ": heartbeat\n" {
+			heartbeats++
+		}
+	}
+
+	// Streams end once shutdown starts
+	require.Len(t, s.shutdownHooks, 1)
+	s.shutdownHooks[0]()
+	for {
+		_, err = body.ReadString('\n')
+		if err != nil {
+			break
+		}
+	}
+}
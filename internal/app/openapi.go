@@ -0,0 +1,177 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dyxj/bigbackend/internal/user/profile"
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/openapi"
+	"github.com/dyxj/bigbackend/pkg/sse"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+)
+
+const openAPIPath = "/openapi.json"
+const docsPath = "/docs"
+
+// buildOpenAPISpec describes routes of every API version, every route must be described.
+func (s *Server) buildOpenAPISpec() *openapi.Spec {
+	options := []openapi.Option{
+		openapi.WithErrorBody("application/json", httpx.ErrorResponse{}),
+		openapi.WithErrorBody("application/problem+json", httpx.ProblemDetails{}),
+	}
+	if s.authConfig.Enabled() {
+		options = append(options, openapi.WithBearerAuth("JWT"))
+	}
+	spec := openapi.NewSpec(openapi.Info{Title: "bigbackend API", Version: "v1"}, options...)
+
+	userIdParam := map[string]any{"id": uuid.UUID{}}
+	// Optional as the tenant is claimed by bearer tokens
+	tenantHeader := map[string]bool{tenant.HeaderKey: false}
+	v1Deprecated := s.apiV1().Deprecated()
+
+	spec.Route(http.MethodGet, apiV1Prefix+"/user/{id}/profile", openapi.Route{
+		Summary:    "Get user profile",
+		Tags:       []string{"user profile"},
+		PathParams: userIdParam,
+		Headers:    tenantHeader,
+		Responses:  map[int]any{http.StatusOK: profile.Response{}},
+		Deprecated: v1Deprecated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusUnauthorized,
+			http.StatusForbidden,
+			http.StatusNotFound,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusServiceUnavailable,
+		},
+	})
+	spec.Route(http.MethodPost, apiV1Prefix+"/user/{id}/profile", openapi.Route{
+		Summary:    "Create user profile",
+		Tags:       []string{"user profile"},
+		PathParams: userIdParam,
+		Headers:    map[string]bool{idempotency.DefaultHeaderKey: true, tenant.HeaderKey: false},
+		Request:    profile.CreateRequest{},
+		Responses:  map[int]any{http.StatusCreated: profile.Response{}},
+		Deprecated: v1Deprecated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusUnauthorized,
+			http.StatusForbidden,
+			http.StatusConflict,
+			http.StatusRequestEntityTooLarge,
+			http.StatusUnsupportedMediaType,
+			http.StatusUnprocessableEntity,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusServiceUnavailable,
+		},
+	})
+
+	spec.Route(http.MethodGet, apiV1Prefix+"/user/{id}/events", openapi.Route{
+		Summary: "Stream events of user as server-sent events, resumed with the Last-Event-ID header. " +
+			"A reset event signals events may have been missed",
+		Tags:                []string{"user"},
+		PathParams:          map[string]any{"id": uuid.UUID{}},
+		Headers:             map[string]bool{tenant.HeaderKey: false, sse.HeaderKeyLastEventID: false},
+		Responses:           map[int]any{http.StatusOK: ""},
+		ResponseContentType: "text/event-stream",
+		Deprecated:          v1Deprecated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusUnauthorized,
+			http.StatusForbidden,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusServiceUnavailable,
+		},
+	})
+
+	webhookIdParam := map[string]any{"id": uuid.UUID{}}
+	webhookReadErrors := []int{
+		http.StatusBadRequest,
+		http.StatusUnauthorized,
+		http.StatusForbidden,
+		http.StatusNotFound,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusServiceUnavailable,
+	}
+
+	spec.Route(http.MethodPost, apiV1Prefix+"/webhooks", openapi.Route{
+		Summary:    "Create webhook subscription, the signing secret is only returned once",
+		Tags:       []string{"webhook"},
+		Headers:    tenantHeader,
+		Request:    webhook.CreateRequest{},
+		Responses:  map[int]any{http.StatusCreated: webhook.SecretResponse{}},
+		Deprecated: v1Deprecated,
+		Errors: []int{
+			http.StatusBadRequest,
+			http.StatusUnauthorized,
+			http.StatusForbidden,
+			http.StatusRequestEntityTooLarge,
+			http.StatusUnsupportedMediaType,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusServiceUnavailable,
+		},
+	})
+	spec.Route(http.MethodGet, apiV1Prefix+"/webhooks", openapi.Route{
+		Summary:    "List webhook subscriptions",
+		Tags:       []string{"webhook"},
+		Headers:    tenantHeader,
+		Responses:  map[int]any{http.StatusOK: webhook.ListResponse{}},
+		Deprecated: v1Deprecated,
+		Errors:     webhookReadErrors,
+	})
+	spec.Route(http.MethodGet, apiV1Prefix+"/webhooks/{id}", openapi.Route{
+		Summary:    "Get webhook subscription",
+		Tags:       []string{"webhook"},
+		PathParams: webhookIdParam,
+		Headers:    tenantHeader,
+		Responses:  map[int]any{http.StatusOK: webhook.Response{}},
+		Deprecated: v1Deprecated,
+		Errors:     webhookReadErrors,
+	})
+	spec.Route(http.MethodDelete, apiV1Prefix+"/webhooks/{id}", openapi.Route{
+		Summary:    "Delete webhook subscription and its deliveries",
+		Tags:       []string{"webhook"},
+		PathParams: webhookIdParam,
+		Headers:    tenantHeader,
+		Responses:  map[int]any{http.StatusNoContent: nil},
+		Deprecated: v1Deprecated,
+		Errors:     webhookReadErrors,
+	})
+	spec.Route(http.MethodPost, apiV1Prefix+"/webhooks/{id}/enable", openapi.Route{
+		Summary:    "Enable webhook subscription disabled after repeated failures",
+		Tags:       []string{"webhook"},
+		PathParams: webhookIdParam,
+		Headers:    tenantHeader,
+		Responses:  map[int]any{http.StatusOK: webhook.Response{}},
+		Deprecated: v1Deprecated,
+		Errors:     webhookReadErrors,
+	})
+	spec.Route(http.MethodGet, apiV1Prefix+"/webhooks/{id}/deliveries", openapi.Route{
+		Summary:    "List latest deliveries of webhook subscription",
+		Tags:       []string{"webhook"},
+		PathParams: webhookIdParam,
+		Headers:    tenantHeader,
+		Responses:  map[int]any{http.StatusOK: webhook.DeliveryListResponse{}},
+		Deprecated: v1Deprecated,
+		Errors:     webhookReadErrors,
+	})
+	spec.Route(http.MethodPost, apiV1Prefix+"/webhooks/{id}/deliveries/{deliveryId}/redeliver", openapi.Route{
+		Summary:    "Redeliver event of webhook delivery",
+		Tags:       []string{"webhook"},
+		PathParams: map[string]any{"id": uuid.UUID{}, "deliveryId": uuid.UUID{}},
+		Headers:    tenantHeader,
+		Responses:  map[int]any{http.StatusAccepted: webhook.DeliveryResponse{}},
+		Deprecated: v1Deprecated,
+		Errors:     append(webhookReadErrors, http.StatusConflict),
+	})
+
+	return spec
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/pkg/openapi"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOpenAPISpec_AllRoutesDocumented(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+	router, ok := s.BuildRouter().(chi.Routes)
+	require.True(t, ok)
+
+	undocumented, err := s.buildOpenAPISpec().Undocumented(router, apiPrefix)
+
+	require.NoError(t, err)
+	assert.Empty(t, undocumented, "routes missing from openapi spec, describe them in buildOpenAPISpec")
+}
+
+func TestOpenAPISpec_Served(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+	router := s.BuildRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var doc openapi.Document
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+
+	err := chi.Walk(router.(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, apiPrefix) {
+			return nil
+		}
+		route = strings.TrimSuffix(strings.ReplaceAll(route, "/*/", "/"), "/")
+		assert.Contains(t, doc.Paths[route], strings.ToLower(method), "%s %s missing from served document", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestOpenAPIUI_Disabled(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+
+	w := httptest.NewRecorder()
+	s.BuildRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOpenAPIUI_Enabled(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{OpenAPIUIEnabledEV: true}, &config.AuthConfig{}, nil)
+
+	w := httptest.NewRecorder()
+	s.BuildRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), openAPIPath)
+	// Assets are served same-origin, as allowed by the content security policy
+	assert.NotContains(t, w.Body.String(), "https://")
+
+	assets := map[string]string{"docs.js": "text/javascript", "docs.css": "text/css"}
+	for asset, contentType := range assets {
+		w = httptest.NewRecorder()
+		s.BuildRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, docsPath+"/assets/"+asset, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, asset)
+		assert.Contains(t, w.Header().Get("Content-Type"), contentType, asset)
+	}
+}
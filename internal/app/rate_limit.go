@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/internal/ratelimit"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+)
+
+const (
+	rateLimitIP    = "ip"
+	rateLimitRead  = "read"
+	rateLimitWrite = "write"
+)
+
+// rateLimits middlewares of API routes.
+type rateLimits struct {
+	// ip limits every API request per client IP ahead of authentication, so that failed attempts are limited
+	ip    func(http.Handler) http.Handler
+	read  func(http.Handler) http.Handler
+	write func(http.Handler) http.Handler
+}
+
+// buildRateLimits returns middlewares limiting read and write API routes per principal, or client IP when anonymous,
+// and the worker of the store, nil when it needs none. When rate limiting is disabled requests pass through.
+func (s *Server) buildRateLimits(errRegistry *httpx.ErrorRegistry) (rateLimits, func(ctx context.Context)) {
+	if !s.httpConfig.RateLimitEnabled() {
+		passThrough := func(next http.Handler) http.Handler { return next }
+		return rateLimits{ip: passThrough, read: passThrough, write: passThrough}, nil
+	}
+
+	var worker func(ctx context.Context)
+
+	var store httpx.RateLimitStore = httpx.NewMemRateLimitStore()
+	if s.httpConfig.RateLimitStore() == config.RateLimitStorePostgres {
+		sqlStore := ratelimit.NewStoreSQLDB(s.logger, s.dbConn)
+		worker = sqlStore.Run
+		store = sqlStore
+	}
+
+	var options []httpx.RateLimiterOption
+	if s.metrics != nil {
+		options = append(options, httpx.WithRateLimitMetrics(s.metrics))
+	}
+	limiter := httpx.NewRateLimiter(s.logger, store, errRegistry.WriteError, options...)
+
+	clientIP := httpx.RateLimitByClientIP(s.httpConfig.TrustedProxies())
+	key := httpx.RateLimitBySubject(auth.PrincipalSubject, clientIP)
+
+	limits := rateLimits{
+		ip: limiter.Limit(rateLimitIP, httpx.RateLimit{
+			Requests: s.httpConfig.RateLimitIPRequests(),
+			Period:   s.httpConfig.RateLimitPeriod(),
+		}, clientIP),
+		read: limiter.Limit(rateLimitRead, httpx.RateLimit{
+			Requests: s.httpConfig.RateLimitRequests(),
+			Period:   s.httpConfig.RateLimitPeriod(),
+			Burst:    s.httpConfig.RateLimitBurst(),
+		}, key),
+		write: limiter.Limit(rateLimitWrite, httpx.RateLimit{
+			Requests: s.httpConfig.RateLimitWriteRequests(),
+			Period:   s.httpConfig.RateLimitPeriod(),
+			Burst:    s.httpConfig.RateLimitBurst(),
+		}, key),
+	}
+
+	return limits, worker
+}
@@ -1,70 +1,88 @@
 package app
 
 import (
-	"errors"
 	"net/http"
-	"time"
 
+	"github.com/dyxj/bigbackend/internal/authz"
 	"github.com/dyxj/bigbackend/pkg/httpx"
 	"github.com/dyxj/bigbackend/pkg/idempotency"
 	"github.com/dyxj/bigbackend/pkg/monitoring"
+	"github.com/dyxj/bigbackend/pkg/openapi"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 )
 
 func (s *Server) BuildRouter() http.Handler {
 	router := chi.NewRouter()
+	c := s.components()
+	errRegistry := c.errRegistry
+	enforcer := c.enforcer
+	readLimit, writeLimit := c.rateLimits.read, c.rateLimits.write
 
-	router.Use(middleware.Logger)
+	router.Use(httpx.RequestIDMiddleware)
+	router.Use(httpx.AccessLogMiddleware(s.logger,
+		httpx.WithSuccessSampleRate(s.httpConfig.AccessLogSampleRate()),
+		httpx.WithSlowThreshold(s.httpConfig.AccessLogSlowThreshold()),
+		httpx.WithTrustedProxies(s.httpConfig.TrustedProxies()),
+	))
 
 	if s.metrics != nil {
 		router.Use(s.metrics.HTTPMetricsMiddleware)
 	}
 
-	router.Get("/healthz", monitoring.HealthCheckHandler(func() bool {
-		return s.isShuttingDown.Load()
-	}))
-	router.Get("/readyz", monitoring.ReadinessCheckHandler(
-		func() bool { return s.isShuttingDown.Load() },
-		s.dbConn.Ping,
-	))
+	// Outside of idempotency, cached responses are stored uncompressed and compressed per request
+	if s.httpConfig.CompressionEnabled() {
+		router.Use(httpx.CompressMiddleware(httpx.WithCompressMinSize(s.httpConfig.CompressionMinSize())))
+	}
+
+	// Idempotency policies are applied per route, allowing keys to be scoped by route pattern
+	idemRequired := c.idemMiddleware.Policy(idempotency.PolicyRequired)
 
-	idemStore := idempotency.NewMemStore(idempotency.DefaultLockConfig)
-	idemMiddleware := idempotency.NewMiddleware(s.logger, idemStore,
-		idempotency.WithCacheExpiry(24*time.Hour),
-		idempotency.WithLockOptions(
-			idempotency.WithLockRetry(3, 100*time.Millisecond),
-			idempotency.WithLockExpiry(5*time.Second),
-		),
-		idempotency.WithErrorResponseWriter(s.idempotencyErrResponseWriter),
+	userProfileCreatorHandler, userProfileGetterHandler := s.buildUserProfileHandlers(
+		errRegistry, c.userProfile, c.notifier,
 	)
 
-	apiRouter := chi.NewRouter()
+	router.Mount(apiV1Prefix, s.buildAPIRouter(s.apiV1(), errRegistry, c.authenticate, func(r chi.Router) {
+		r.With(readLimit).Get("/user/{id}/profile", userProfileGetterHandler.ServeHTTP)
+		r.With(writeLimit, idemRequired).Post("/user/{id}/profile", userProfileCreatorHandler.ServeHTTP)
+		// Long-lived, excluded from the handler timeout. Reconnections are rate limited as reads
+		r.With(readLimit, httpx.NoTimeout).Get("/user/{id}/events", c.userEventStreamHandler.ServeHTTP)
 
-	apiRouter.Use(s.TimeoutHandler)
-	apiRouter.Use(idemMiddleware.Handler)
-	apiRouter.Use(middleware.Recoverer)
+		// Subscriptions send tenant data to the URLs of callers, never mounted unauthenticated
+		if s.authConfig.Enabled() {
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Use(enforcer.RequirePermission(authz.WebhooksManage))
+				r.With(writeLimit).Post("/", c.webhookHandler.Create)
+				r.With(readLimit).Get("/", c.webhookHandler.List)
+				r.With(readLimit).Get("/{id}", c.webhookHandler.Get)
+				r.With(writeLimit).Delete("/{id}", c.webhookHandler.Delete)
+				r.With(writeLimit).Post("/{id}/enable", c.webhookHandler.Enable)
+				r.With(readLimit).Get("/{id}/deliveries", c.webhookHandler.ListDeliveries)
+				r.With(writeLimit).Post("/{id}/deliveries/{deliveryId}/redeliver", c.webhookHandler.Redeliver)
+			})
+		} else {
+			s.logger.Warn("webhook routes not mounted, authentication disabled")
+		}
+	}))
 
-	userProfileCreatorHandler, userProfileGetterHandler := s.buildUserProfileHandlers()
-	apiRouter.Get("/user/{id}/profile", userProfileGetterHandler.ServeHTTP)
-	apiRouter.Post("/user/{id}/profile", userProfileCreatorHandler.ServeHTTP)
+	// Ops routes serve operators and tooling rather than API clients, with their own security headers
+	router.Group(func(ops chi.Router) {
+		ops.Use(s.buildOpsSecurity())
 
-	router.Mount("/api/v1", apiRouter)
+		ops.Get("/healthz", monitoring.HealthCheckHandler(func() bool {
+			return s.isShuttingDown.Load()
+		}))
+		ops.Get("/readyz", monitoring.ReadinessCheckHandler(
+			func() bool { return s.isShuttingDown.Load() },
+			c.readiness,
+		))
+		ops.Get("/startupz", monitoring.StartupCheckHandler(c.startup))
 
-	return router
-}
+		ops.Get(openAPIPath, openapi.NewHandler(s.logger, s.buildOpenAPISpec(), router).ServeHTTP)
+		if s.httpConfig.OpenAPIUIEnabled() {
+			ops.Mount(docsPath, openapi.UIHandler("bigbackend API", docsPath, openAPIPath))
+		}
 
-func (s *Server) idempotencyErrResponseWriter(err error, w http.ResponseWriter) {
-	if errors.Is(err, idempotency.ErrInProgress) {
-		httpx.JsonResponse(
-			http.StatusConflict,
-			httpx.ErrorResponse{
-				Code:    httpx.CodeIdempotencyError,
-				Message: "processing of idempotency key is in progress",
-			},
-			w,
-		)
-	}
+	})
 
-	httpx.InternalServerErrorResponse("", w)
+	return router
 }
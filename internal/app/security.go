@@ -0,0 +1,48 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/openapi"
+)
+
+// buildAPISecurity returns the CORS and security headers middleware of API routes.
+func (s *Server) buildAPISecurity() func(http.Handler) http.Handler {
+	headers := httpx.SecurityHeadersMiddleware(
+		httpx.APISecurityHeaders(s.httpConfig.HSTSMaxAge(), s.httpConfig.HSTSIncludeSubdomains()),
+	)
+	cors := httpx.CORSMiddleware(httpx.CORSPolicy{
+		AllowedOrigins:   s.httpConfig.CORSAllowedOrigins(),
+		AllowedMethods:   s.httpConfig.CORSAllowedMethods(),
+		AllowedHeaders:   s.httpConfig.CORSAllowedHeaders(),
+		ExposedHeaders:   s.httpConfig.CORSExposedHeaders(),
+		AllowCredentials: s.httpConfig.CORSAllowCredentials(),
+		MaxAge:           s.httpConfig.CORSMaxAge(),
+	})
+	return func(next http.Handler) http.Handler {
+		return headers(cors(next))
+	}
+}
+
+// buildOpsSecurity returns the security headers middleware of ops routes, e.g. health checks and docs.
+// Ops routes are same-origin only, the content security policy allows the docs page.
+func (s *Server) buildOpsSecurity() func(http.Handler) http.Handler {
+	return httpx.SecurityHeadersMiddleware(httpx.SecurityHeaders{
+		HSTSMaxAge:            s.httpConfig.HSTSMaxAge(),
+		HSTSIncludeSubdomains: s.httpConfig.HSTSIncludeSubdomains(),
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: openapi.UIContentSecurityPolicy,
+		FrameOptions:          "DENY",
+	})
+}
+
+// buildAdminSecurity returns the security headers middleware of the admin listener, served over plain HTTP
+// on a private port without HSTS. Pages, e.g. the pprof index, load no resources.
+func (s *Server) buildAdminSecurity() func(http.Handler) http.Handler {
+	return httpx.SecurityHeadersMiddleware(httpx.SecurityHeaders{
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'none'",
+		FrameOptions:          "DENY",
+	})
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBuildRouter_CORSPreflightUnauthenticated(t *testing.T) {
+	s, _ := newAuthEnabledServer(t)
+	s.httpConfig = &config.HTTPServerConfig{SecurityConfig: config.SecurityConfig{
+		CORSAllowedOriginsEV: []string{"https://app.example.com"},
+		CORSAllowedMethodsEV: []string{http.MethodGet},
+		CORSMaxAgeEV:         time.Minute,
+	}}
+	router := s.BuildRouter()
+
+	r := httptest.NewRequest(http.MethodOptions, apiV1Prefix+"/user/"+uuid.NewString()+"/profile", nil)
+	r.Header.Set(httpx.HeaderKeyOrigin, "https://app.example.com")
+	r.Header.Set(httpx.HeaderKeyAccessControlRequestMethod, http.MethodGet)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get(httpx.HeaderKeyAccessControlAllowOrigin))
+	assert.Equal(t, http.MethodGet, w.Header().Get(httpx.HeaderKeyAccessControlAllowMethods))
+}
+
+func TestBuildRouter_SecurityHeaders(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+	router := s.BuildRouter()
+
+	tests := []struct {
+		name        string
+		path        string
+		expectedCSP string
+	}{
+		{name: "api", path: apiV1Prefix + "/user/" + uuid.NewString() + "/profile", expectedCSP: "default-src 'none'; frame-ancestors 'none'"},
+		{name: "ops", path: "/healthz", expectedCSP: "default-src 'self'; frame-ancestors 'none'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedCSP, w.Header().Get(httpx.HeaderKeyContentSecurityPolicy))
+			assert.Equal(t, "nosniff", w.Header().Get(httpx.HeaderKeyContentTypeOptions))
+			assert.Equal(t, "DENY", w.Header().Get(httpx.HeaderKeyFrameOptions))
+		})
+	}
+}
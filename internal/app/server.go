@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/dyxj/bigbackend/pkg/monitoring"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 type Server struct {
@@ -20,39 +23,85 @@ type Server struct {
 	dbConn *sql.DB
 
 	httpConfig HttpConfig
+	authConfig AuthConfig
 
 	httpServer *http.Server
+	// grpcServer nil when gRPC is disabled
+	grpcServer *grpc.Server
+	grpcHealth *health.Server
+	// adminServer nil when the admin listener is disabled
+	adminServer *http.Server
 
 	// metrics enabled if not nil
 	metrics *monitoring.Metrics
+	// logLevel toggled on the admin listener if not nil
+	logLevel *zap.AtomicLevel
+	// effectiveConfig dumped on the admin listener if not nil, see WithEffectiveConfig
+	effectiveConfig map[string]string
+
+	// comps shared by BuildRouter and BuildGRPCServer, see components
+	componentsOnce sync.Once
+	comps          *components
+
+	// workersWg of the workers of components, run until ongoing requests are stopped
+	workersWg sync.WaitGroup
+	// shutdownHooks registered by components, called once shutdown starts, e.g. ending long-lived requests
+	shutdownHooks []func()
 
 	onGoingCtx            context.Context
 	stopOngoingGracefully context.CancelFunc
 
 	isShuttingDown atomic.Bool
 
-	errSig  chan struct{}
-	stopSig chan struct{}
-	runDone chan struct{}
-	done    chan struct{}
+	errSig       chan struct{}
+	stopSig      chan struct{}
+	runDone      chan struct{}
+	grpcRunDone  chan struct{}
+	adminRunDone chan struct{}
+	done         chan struct{}
+}
+
+type ServerOption func(*Server)
+
+// WithLogLevel toggles level on the admin listener, level being shared with the server logger.
+func WithLogLevel(level zap.AtomicLevel) ServerOption {
+	return func(s *Server) {
+		s.logLevel = &level
+	}
+}
+
+// WithEffectiveConfig dumped on the admin listener, values must already be redacted, e.g. by config.Redacted.
+func WithEffectiveConfig(values map[string]string) ServerOption {
+	return func(s *Server) {
+		s.effectiveConfig = values
+	}
 }
 
 func NewServer(
 	logger *zap.Logger,
 	dbConn *sql.DB,
 	httpConfig HttpConfig,
+	authConfig AuthConfig,
 	metrics *monitoring.Metrics,
+	options ...ServerOption,
 ) *Server {
-	return &Server{
-		logger:     logger,
-		dbConn:     dbConn,
-		httpConfig: httpConfig,
-		metrics:    metrics,
-		errSig:     make(chan struct{}),
-		stopSig:    make(chan struct{}),
-		runDone:    make(chan struct{}),
-		done:       make(chan struct{}),
+	s := &Server{
+		logger:       logger,
+		dbConn:       dbConn,
+		httpConfig:   httpConfig,
+		authConfig:   authConfig,
+		metrics:      metrics,
+		errSig:       make(chan struct{}, 1),
+		stopSig:      make(chan struct{}),
+		runDone:      make(chan struct{}),
+		grpcRunDone:  make(chan struct{}),
+		adminRunDone: make(chan struct{}),
+		done:         make(chan struct{}),
 	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Server) initServer() {
@@ -61,6 +110,24 @@ func (s *Server) initServer() {
 	s.onGoingCtx, s.stopOngoingGracefully = context.WithCancel(context.Background())
 
 	router := s.BuildRouter()
+	if s.httpConfig.GRPCEnabled() {
+		s.grpcServer, s.grpcHealth = s.BuildGRPCServer()
+	}
+	if s.httpConfig.AdminEnabled() {
+		s.adminServer = &http.Server{
+			Addr:              fmt.Sprintf("%v:%v", s.httpConfig.AdminHost(), s.httpConfig.AdminPort()),
+			ReadHeaderTimeout: s.httpConfig.ReadHeaderTimeout(),
+			ReadTimeout:       s.httpConfig.ReadTimeout(),
+			IdleTimeout:       s.httpConfig.IdleTimeout(),
+			Handler:           s.BuildAdminRouter(),
+		}
+	}
+
+	for _, worker := range s.components().workers {
+		s.workersWg.Go(func() {
+			worker(s.onGoingCtx)
+		})
+	}
 
 	s.httpServer = &http.Server{
 		Addr:              addr,
@@ -72,6 +139,9 @@ func (s *Server) initServer() {
 			return s.onGoingCtx
 		},
 	}
+	for _, hook := range s.shutdownHooks {
+		s.httpServer.RegisterOnShutdown(hook)
+	}
 }
 
 func (s *Server) Run() <-chan struct{} {
@@ -82,17 +152,80 @@ func (s *Server) Run() <-chan struct{} {
 		err := s.httpServer.ListenAndServe()
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.logger.Error("httpServer failed to listen and serve", zap.Error(err))
-			s.errSig <- struct{}{}
+			s.signalErr()
 		}
 		s.logger.Info("httpServer closed")
 		close(s.runDone)
 	}()
 
+	s.runGRPC()
+	s.runAdmin()
+
 	go s.listenForStopAndOrchestrateShutdown()
 
 	return s.errSig
 }
 
+// signalErr notifies the caller of Run that a listener failed. Only the first failure is received,
+// later ones are dropped rather than blocking their listener from closing, which shutdown waits for.
+func (s *Server) signalErr() {
+	select {
+	case s.errSig <- struct{}{}:
+	default:
+	}
+}
+
+// runGRPC serves gRPC on its own port, sharing the host of HTTP.
+func (s *Server) runGRPC() {
+	if s.grpcServer == nil {
+		close(s.grpcRunDone)
+		return
+	}
+
+	go func() {
+		defer close(s.grpcRunDone)
+
+		addr := fmt.Sprintf("%v:%v", s.httpConfig.Host(), s.httpConfig.GRPCPort())
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			s.logger.Error("grpcServer failed to listen", zap.Error(err), zap.String("address", addr))
+			s.signalErr()
+			return
+		}
+
+		s.logger.Info("starting grpcServer", zap.String("address", lis.Addr().String()))
+		err = s.grpcServer.Serve(lis)
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("grpcServer failed to serve", zap.Error(err))
+			s.signalErr()
+		}
+		s.logger.Info("grpcServer closed")
+	}()
+}
+
+// runAdmin serves the admin listener on its own host and port, see BuildAdminRouter.
+func (s *Server) runAdmin() {
+	if s.adminServer == nil {
+		close(s.adminRunDone)
+		return
+	}
+	if s.httpConfig.AdminToken() == "" {
+		s.logger.Warn("adminServer serves without authentication, ADMIN_TOKEN is not set")
+	}
+
+	go func() {
+		defer close(s.adminRunDone)
+
+		s.logger.Info("starting adminServer", zap.String("address", s.adminServer.Addr))
+		err := s.adminServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("adminServer failed to listen and serve", zap.Error(err))
+			s.signalErr()
+		}
+		s.logger.Info("adminServer closed")
+	}()
+}
+
 func (s *Server) listenForStopAndOrchestrateShutdown() {
 	<-s.stopSig
 
@@ -100,6 +233,9 @@ func (s *Server) listenForStopAndOrchestrateShutdown() {
 
 	// Set health check to unavailable, stops load balancers from sending new requests
 	s.isShuttingDown.Store(true)
+	if s.grpcHealth != nil {
+		s.grpcHealth.Shutdown()
+	}
 
 	// Allow time for readiness probe to pick up the change
 	time.Sleep(s.httpConfig.ShutDownReadyDelay())
@@ -110,26 +246,91 @@ func (s *Server) listenForStopAndOrchestrateShutdown() {
 	// Stop receiving new requests and wait for ongoing requests to finish
 	err := s.shutDown(shutDownCtx)
 	s.stopOngoingGracefully()
+
+	// Workers may flush work of the requests above
+	s.workersWg.Wait()
 	if err != nil {
 		// In the event of force shutdown we do not wait for runDone.
 		s.logger.Error("failed to wait for ongoing requests to finish, waiting for forced cancellation", zap.Error(err))
 		time.Sleep(s.httpConfig.ShutDownHardTimeout())
 		s.logger.Error("httpServer shut down ungracefully")
+		s.closeAdmin()
 		close(s.done)
 		return
 	}
 
 	s.logger.Info("httpServer shut down gracefully")
 	<-s.runDone
+	<-s.grpcRunDone
+	s.shutDownAdmin()
 	close(s.done)
 }
 
+// shutDownAdmin stops the admin listener last, metrics and profiles remain available while the server drains.
+// Ongoing requests, e.g. CPU profiles, are given ShutDownTimeout to finish.
+func (s *Server) shutDownAdmin() {
+	if s.adminServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.httpConfig.ShutDownTimeout())
+	defer cancel()
+
+	err := s.adminServer.Shutdown(ctx)
+	if err != nil {
+		s.logger.Error("failed to wait for ongoing admin requests to finish, closing them", zap.Error(err))
+		s.closeAdmin()
+		return
+	}
+	<-s.adminRunDone
+}
+
+// closeAdmin closes the admin listener and its connections without waiting for ongoing requests.
+func (s *Server) closeAdmin() {
+	if s.adminServer == nil {
+		return
+	}
+	err := s.adminServer.Close()
+	if err != nil {
+		s.logger.Error("failed to close adminServer", zap.Error(err))
+	}
+}
+
+// addShutdownHook registers hook called once the server stops receiving new requests, ending long-lived
+// requests such as event streams which would otherwise hold graceful shutdown until the timeout.
+func (s *Server) addShutdownHook(hook func()) {
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+}
+
 func (s *Server) Stop() <-chan struct{} {
 	close(s.stopSig)
 	return s.done
 }
 
+// shutDown stops the HTTP and gRPC servers concurrently, gRPC calls still ongoing once shutDownCtx is done
+// are cancelled.
 func (s *Server) shutDown(shutDownCtx context.Context) error {
 	s.logger.Info("initiate httpServer shutdown and wait for ongoing requests to finish")
-	return s.httpServer.Shutdown(shutDownCtx)
+	if s.grpcServer == nil {
+		return s.httpServer.Shutdown(shutDownCtx)
+	}
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	err := s.httpServer.Shutdown(shutDownCtx)
+
+	select {
+	case <-grpcStopped:
+	case <-shutDownCtx.Done():
+		s.logger.Error("failed to wait for ongoing grpc calls to finish, cancelling them")
+		s.grpcServer.Stop()
+		if err == nil {
+			err = shutDownCtx.Err()
+		}
+	}
+	return err
 }
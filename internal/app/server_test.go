@@ -0,0 +1,47 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServer_SignalErr_DoesNotBlock(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+
+	// Every listener failing, before and without the caller receiving
+	s.signalErr()
+	s.signalErr()
+	s.signalErr()
+
+	select {
+	case <-s.errSig:
+	default:
+		assert.Fail(t, "first failure not received")
+	}
+}
+
+func TestServer_WorkersRegisteredOnce(t *testing.T) {
+	httpConfig := &config.HTTPServerConfig{
+		RateLimitEnabledEV:       true,
+		RateLimitStoreEV:         config.RateLimitStorePostgres,
+		RateLimitRequestsEV:      10,
+		RateLimitWriteRequestsEV: 10,
+		RateLimitPeriodEV:        time.Minute,
+		WebhookConfig:            config.WebhookConfig{WebhookDispatchEnabledEV: true},
+	}
+	s := NewServer(zap.NewNop(), nil, httpConfig, &config.AuthConfig{}, nil)
+
+	// API key usage, rate limit store and webhook dispatcher
+	s.BuildRouter()
+	require.Len(t, s.components().workers, 3)
+
+	s.BuildRouter()
+	s.BuildGRPCServer()
+	s.BuildAdminRouter()
+	assert.Len(t, s.components().workers, 3)
+}
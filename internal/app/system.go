@@ -4,11 +4,24 @@ import (
 	"net/http"
 
 	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 )
 
-func (s *Server) TimeoutHandler(h http.Handler) http.Handler {
-	if s.httpConfig.HandlerTimeout() <= 0 {
-		return h
-	}
-	return httpx.TimeoutHandler(h, s.httpConfig.HandlerTimeout())
+// buildTimeout returns the middleware setting the HandlerTimeout deadline of API routes,
+// routes may override it with httpx.RouteTimeout. Handlers must observe the request context,
+// the timeout response is only written once they return.
+func (s *Server) buildTimeout(errRegistry *httpx.ErrorRegistry) func(http.Handler) http.Handler {
+	return httpx.TimeoutMiddleware(s.httpConfig.HandlerTimeout(), errRegistry.WriteError)
+}
+
+// LogContext attaches the tenant of the request to logx.FromContext loggers.
+// It must be applied after tenant.Middleware, only resolved tenants are logged.
+func (s *Server) LogContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenantID, ok := tenant.FromContext(r.Context()); ok {
+			r = r.WithContext(logx.WithFields(r.Context(), logx.TenantID(tenantID.String())))
+		}
+		next.ServeHTTP(w, r)
+	})
 }
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dyxj/bigbackend/internal/config"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestServer_LogContext(t *testing.T) {
+	s := NewServer(zap.NewNop(), nil, &config.HTTPServerConfig{}, &config.AuthConfig{}, nil)
+	tenantID := uuid.New()
+
+	tests := []struct {
+		name     string
+		resolved bool
+		expected []zap.Field
+	}{
+		{name: "resolved tenant", resolved: true, expected: []zap.Field{logx.TenantID(tenantID.String())}},
+		{name: "header only", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []zap.Field
+			handler := s.LogContext(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fields = logx.Fields(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			// Unverified, never logged
+			r.Header.Set(tenant.HeaderKey, uuid.NewString())
+			if tt.resolved {
+				r = r.WithContext(tenant.WithID(r.Context(), tenantID))
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			assert.Equal(t, tt.expected, fields)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+
+	"github.com/dyxj/bigbackend/internal/user/invitation"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+)
+
+// buildInvitationCreator returns the creator of user invitations, served by the gRPC API.
+// Status changes are notified to the streams of the inviting user.
+func (s *Server) buildInvitationCreator(notifier invitation.Notifier) invitation.Creator {
+	mapper := &invitation.UserInvitationMapper{}
+	// Invitations are scoped to the tenant of the request by row level security
+	tm := tenant.NewTxManager(s.dbConn)
+
+	expirer := invitation.NewExpirer(
+		invitation.NewGetterSQLDB(s.logger, s.dbConn),
+		invitation.NewUpdaterSQLDB(s.logger),
+	)
+
+	return invitation.NewCreator(s.logger, tm, invitation.NewCreatorSQLDB(s.logger), mapper,
+		noopInvitationPublisher{}, expirer, notifier)
+}
+
+// noopInvitationPublisher invitation emails are not sent yet, invitations are created for tokens to be
+// delivered out of band.
+type noopInvitationPublisher struct{}
+
+func (noopInvitationPublisher) Publish(context.Context, sqldb.Executable) error {
+	return nil
+}
@@ -1,21 +1,64 @@
 package app
 
 import (
+	"github.com/dyxj/bigbackend/internal/authz"
 	"github.com/dyxj/bigbackend/internal/user/profile"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/rbac"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 )
 
-func (s *Server) buildUserProfileHandlers() (
-	*profile.CreatorHandler,
-	*profile.GetterHandler,
-) {
+// userProfileComponents shared by the profile handlers and gRPC service.
+type userProfileComponents struct {
+	mapper            *profile.UserProfileMapper
+	tm                sqldb.TransactionManager
+	creator           profile.Creator
+	getter            profile.Getter
+	creatorAuthorizer auth.Authorizer
+	getterAuthorizer  auth.Authorizer
+}
+
+func (s *Server) buildUserProfile(
+	authorizer auth.Authorizer,
+	enforcer *rbac.Enforcer,
+	publisher profile.EventPublisher,
+) userProfileComponents {
 	mapper := &profile.UserProfileMapper{}
+	// Reads and writes are scoped to the tenant of the request by row level security
+	tm := tenant.NewTxManager(s.dbConn)
 
 	cRepo := profile.NewCreatorSQLDB(s.logger)
-	creator := profile.NewCreator(s.logger, cRepo, mapper)
+	gRepo := profile.NewGetterSQLDB(s.logger, tm)
 
-	gRepo := profile.NewGetterSQLDB(s.logger, s.dbConn)
-	getter := profile.NewGetter(s.logger, gRepo, mapper)
+	return userProfileComponents{
+		mapper:  mapper,
+		tm:      tm,
+		creator: profile.NewCreator(s.logger, cRepo, mapper, publisher),
+		getter:  profile.NewGetter(s.logger, gRepo, mapper),
+		// Staff holding the permissions access profiles of other users
+		creatorAuthorizer: rbac.SubjectOrPermission(authorizer, enforcer, authz.ProfilesWriteAny),
+		getterAuthorizer:  rbac.SubjectOrPermission(authorizer, enforcer, authz.ProfilesReadAny),
+	}
+}
+
+func (s *Server) buildUserProfileHandlers(
+	errRegistry *httpx.ErrorRegistry,
+	c userProfileComponents,
+	notifier profile.Notifier,
+) (
+	*profile.CreatorHandler[profile.Response],
+	*profile.GetterHandler[profile.Response],
+) {
+	return profile.NewCreatorHandler(
+			s.logger, errRegistry, c.creatorAuthorizer, c.tm, c.creator, notifier, c.mapper, c.mapper.ModelToResponse,
+		),
+		profile.NewGetterHandler(s.logger, errRegistry, c.getterAuthorizer, c.getter, c.mapper.ModelToResponse)
+}
 
-	return profile.NewCreatorHandler(s.logger, s.dbConn, creator, mapper),
-		profile.NewGetterHandler(s.logger, getter, mapper)
+func (s *Server) buildUserProfileGRPCService(c userProfileComponents, notifier profile.Notifier) *profile.GRPCService {
+	return profile.NewGRPCService(
+		s.logger, c.getterAuthorizer, c.creatorAuthorizer, c.tm, c.getter, c.creator, notifier, c.mapper,
+	)
 }
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/monitoring"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+)
+
+// buildWebhooks returns the publisher queueing webhook deliveries, the subscription handler and the worker
+// dispatching deliveries, nil when dispatch is disabled. Readiness is degraded once the dispatcher lags.
+func (s *Server) buildWebhooks(
+	errRegistry *httpx.ErrorRegistry, readiness *monitoring.HealthRegistry,
+) (*webhook.Publisher, *webhook.Handler, func(ctx context.Context)) {
+	mapper := &webhook.WebhookMapper{}
+	// Subscriptions and deliveries are scoped to the tenant of the request by row level security
+	tm := tenant.NewTxManager(s.dbConn)
+	subscriptionRepo := webhook.NewSubscriptionSQLDB(s.logger)
+	deliveryRepo := webhook.NewDeliverySQLDB(s.logger)
+
+	var dispatch func(ctx context.Context)
+	if s.httpConfig.WebhookDispatchEnabled() {
+		options := []webhook.DispatcherOption{
+			webhook.WithPollInterval(s.httpConfig.WebhookPollInterval()),
+			webhook.WithBatchSize(s.httpConfig.WebhookBatchSize()),
+			webhook.WithTimeout(s.httpConfig.WebhookTimeout()),
+			webhook.WithMaxAttempts(s.httpConfig.WebhookMaxAttempts()),
+			webhook.WithBackoff(s.httpConfig.WebhookBackoffBase(), s.httpConfig.WebhookBackoffMax()),
+			webhook.WithDisableAfter(s.httpConfig.WebhookDisableAfter()),
+			webhook.WithAllowedNetworks(s.httpConfig.WebhookAllowedNetworks()),
+		}
+		if s.metrics != nil {
+			options = append(options, webhook.WithDispatcherMetrics(s.metrics))
+		}
+		dispatcher := webhook.NewDispatcher(s.logger, tm, subscriptionRepo, deliveryRepo, options...)
+		dispatch = dispatcher.Run
+		readiness.Register("webhook_outbox_lag", lagCheck(dispatcher.Lag, s.httpConfig.WebhookMaxLag()),
+			monitoring.WithCritical(false))
+	}
+
+	manager := webhook.NewManager(s.logger, tm, subscriptionRepo, deliveryRepo, mapper)
+
+	return webhook.NewPublisher(deliveryRepo), webhook.NewHandler(s.logger, errRegistry, manager, mapper), dispatch
+}
@@ -0,0 +1,16 @@
+package authz
+
+import "github.com/dyxj/bigbackend/pkg/rbac"
+
+// Permissions granted to roles, see rbac.Policy.
+const (
+	InvitationsCreate rbac.Permission = "invitations:create"
+	ProfilesReadAny   rbac.Permission = "profiles:read_any"
+	ProfilesWriteAny  rbac.Permission = "profiles:write_any"
+	ProfilesErase     rbac.Permission = "profiles:erase"
+	IdempotencyAdmin  rbac.Permission = "idempotency:admin"
+	APIKeysManage     rbac.Permission = "api_keys:manage"
+	WebhooksManage    rbac.Permission = "webhooks:manage"
+	// TenantsAny allows naming the tenant by header rather than by the credentials, acting on behalf of any tenant
+	TenantsAny rbac.Permission = "tenants:any"
+)
@@ -0,0 +1,52 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/rbac"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/go-jet/jet/v2/postgres"
+	"go.uber.org/zap"
+)
+
+type PolicyGetterSQLDB struct {
+	logger *zap.Logger
+	sqlQ   sqldb.Queryable
+}
+
+func NewPolicyGetterSQLDB(logger *zap.Logger, sqlQ sqldb.Queryable) *PolicyGetterSQLDB {
+	return &PolicyGetterSQLDB{
+		logger: logger,
+		sqlQ:   sqlQ,
+	}
+}
+
+// LoadPolicy builds the policy from role permissions in the database, suitable as rbac.PolicyLoader.
+func (g *PolicyGetterSQLDB) LoadPolicy(ctx context.Context) (*rbac.Policy, error) {
+	stmt := g.buildStatement()
+
+	var rows []entity.RolePermission
+	err := stmt.QueryContext(ctx, g.sqlQ, &rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role permissions: %w", err)
+	}
+
+	roles := make(map[string][]rbac.Permission)
+	for _, row := range rows {
+		roles[row.Role] = append(roles[row.Role], rbac.Permission(row.Permission))
+	}
+
+	logx.FromContext(ctx, g.logger).Debug("loaded role permissions", zap.Int("roles", len(roles)))
+
+	return rbac.NewPolicy(roles), nil
+}
+
+func (g *PolicyGetterSQLDB) buildStatement() postgres.SelectStatement {
+	return table.RolePermission.
+		SELECT(table.RolePermission.AllColumns).
+		FROM(table.RolePermission)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+)
+
+// AdminConfig private listener serving metrics, profiling and diagnostics, embedded in HTTPServerConfig.
+type AdminConfig struct {
+	AdminEnabledEV bool   `env:"ADMIN_ENABLED" envDefault:"false"`
+	AdminHostEV    string `env:"ADMIN_HOST" envDefault:"127.0.0.1"`
+	AdminPortEV    int    `env:"ADMIN_PORT" envDefault:"9091"`
+	AdminTokenEV   string `env:"ADMIN_TOKEN" envDefault:"" redact:"true"`
+}
+
+// Validate requires a valid port when enabled.
+func (c *AdminConfig) Validate() error {
+	if !c.AdminEnabledEV {
+		return nil
+	}
+	if c.AdminPortEV < 0 || c.AdminPortEV > 65535 {
+		return errors.New("ADMIN_PORT must be between 0 and 65535")
+	}
+	return nil
+}
+
+func (c *AdminConfig) AdminEnabled() bool {
+	return c.AdminEnabledEV
+}
+
+func (c *AdminConfig) AdminHost() string {
+	return c.AdminHostEV
+}
+
+func (c *AdminConfig) AdminPort() int {
+	return c.AdminPortEV
+}
+
+func (c *AdminConfig) AdminToken() string {
+	return c.AdminTokenEV
+}
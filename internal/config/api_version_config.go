@@ -0,0 +1,35 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// APIVersionConfig deprecation of API versions, embedded in HTTPServerConfig.
+// Times are formatted as RFC 3339, zero when not planned.
+type APIVersionConfig struct {
+	APIV1DeprecationTimeEV time.Time `env:"API_V1_DEPRECATION_TIME" envDefault:""`
+	APIV1SunsetTimeEV      time.Time `env:"API_V1_SUNSET_TIME" envDefault:""`
+	APIV1DeprecationLinkEV string    `env:"API_V1_DEPRECATION_LINK" envDefault:""`
+}
+
+// Validate requires versions to be deprecated before they are sunset.
+func (c *APIVersionConfig) Validate() error {
+	if !c.APIV1SunsetTimeEV.IsZero() &&
+		(c.APIV1DeprecationTimeEV.IsZero() || c.APIV1SunsetTimeEV.Before(c.APIV1DeprecationTimeEV)) {
+		return errors.New("API_V1_SUNSET_TIME requires an earlier API_V1_DEPRECATION_TIME")
+	}
+	return nil
+}
+
+func (c *APIVersionConfig) APIV1DeprecationTime() time.Time {
+	return c.APIV1DeprecationTimeEV
+}
+
+func (c *APIVersionConfig) APIV1SunsetTime() time.Time {
+	return c.APIV1SunsetTimeEV
+}
+
+func (c *APIVersionConfig) APIV1DeprecationLink() string {
+	return c.APIV1DeprecationLinkEV
+}
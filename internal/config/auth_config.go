@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type AuthConfig struct {
+	EnabledEV      bool          `env:"AUTH_ENABLED" envDefault:"false"`
+	IssuerEV       string        `env:"AUTH_ISSUER" envDefault:""`
+	AudienceEV     string        `env:"AUTH_AUDIENCE" envDefault:""`
+	JWKSURLEV      string        `env:"AUTH_JWKS_URL" envDefault:""`
+	JWKSFileEV     string        `env:"AUTH_JWKS_FILE" envDefault:""`
+	JWKSCacheTTLEV time.Duration `env:"AUTH_JWKS_CACHE_TTL" envDefault:"15m"`
+	ClockSkewEV    time.Duration `env:"AUTH_CLOCK_SKEW" envDefault:"30s"`
+	AdminScopeEV   string        `env:"AUTH_ADMIN_SCOPE" envDefault:"admin"`
+
+	PolicyFileEV           string        `env:"AUTH_POLICY_FILE" envDefault:""`
+	PolicyReloadIntervalEV time.Duration `env:"AUTH_POLICY_RELOAD_INTERVAL" envDefault:"1m"`
+}
+
+// Validate requires issuer, audience and exactly one JWKS source when enabled.
+func (c *AuthConfig) Validate() error {
+	if !c.EnabledEV {
+		return nil
+	}
+	if c.IssuerEV == "" || c.AudienceEV == "" {
+		return errors.New("AUTH_ISSUER and AUTH_AUDIENCE are required when AUTH_ENABLED")
+	}
+	if (c.JWKSURLEV == "") == (c.JWKSFileEV == "") {
+		return errors.New("exactly one of AUTH_JWKS_URL or AUTH_JWKS_FILE is required when AUTH_ENABLED")
+	}
+	return nil
+}
+
+func (c *AuthConfig) Enabled() bool {
+	return c.EnabledEV
+}
+
+func (c *AuthConfig) Issuer() string {
+	return c.IssuerEV
+}
+
+func (c *AuthConfig) Audience() string {
+	return c.AudienceEV
+}
+
+func (c *AuthConfig) JWKSURL() string {
+	return c.JWKSURLEV
+}
+
+func (c *AuthConfig) JWKSFile() string {
+	return c.JWKSFileEV
+}
+
+func (c *AuthConfig) JWKSCacheTTL() time.Duration {
+	return c.JWKSCacheTTLEV
+}
+
+func (c *AuthConfig) ClockSkew() time.Duration {
+	return c.ClockSkewEV
+}
+
+func (c *AuthConfig) AdminScope() string {
+	return c.AdminScopeEV
+}
+
+func (c *AuthConfig) PolicyFile() string {
+	return c.PolicyFileEV
+}
+
+func (c *AuthConfig) PolicyReloadInterval() time.Duration {
+	return c.PolicyReloadIntervalEV
+}
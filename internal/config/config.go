@@ -7,6 +7,7 @@ import (
 type Config struct {
 	HTTPServerConfig *HTTPServerConfig `env:",init"`
 	DBConfig         *DBConfig         `env:",init"`
+	AuthConfig       *AuthConfig       `env:",init"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -17,6 +18,14 @@ func LoadConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = cfg.HTTPServerConfig.Validate()
+	if err != nil {
+		return nil, err
+	}
+	err = cfg.AuthConfig.Validate()
+	if err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -4,7 +4,7 @@ type DBConfig struct {
 	HostEV     string `env:"DB_HOST"`
 	PortEV     int    `env:"DB_PORT"`
 	UserEV     string `env:"DB_USER"`
-	PasswordEV string `env:"DB_PASSWORD"`
+	PasswordEV string `env:"DB_PASSWORD" redact:"true"`
 	DBNameEV   string `env:"DB_NAME"`
 }
 
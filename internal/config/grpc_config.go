@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+)
+
+// GRPCConfig gRPC API served alongside HTTP on its own port, embedded in HTTPServerConfig.
+type GRPCConfig struct {
+	GRPCEnabledEV bool `env:"GRPC_ENABLED" envDefault:"true"`
+	GRPCPortEV    int  `env:"GRPC_PORT" envDefault:"9090"`
+}
+
+// Validate requires a valid port when enabled.
+func (c *GRPCConfig) Validate() error {
+	if !c.GRPCEnabledEV {
+		return nil
+	}
+	if c.GRPCPortEV < 0 || c.GRPCPortEV > 65535 {
+		return errors.New("GRPC_PORT must be between 0 and 65535")
+	}
+	return nil
+}
+
+func (c *GRPCConfig) GRPCEnabled() bool {
+	return c.GRPCEnabledEV
+}
+
+func (c *GRPCConfig) GRPCPort() int {
+	return c.GRPCPortEV
+}
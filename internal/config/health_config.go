@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// HealthConfig checks of the readiness and startup probes, embedded in HTTPServerConfig.
+type HealthConfig struct {
+	HealthCheckTimeoutEV  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	HealthCheckCacheTTLEV time.Duration `env:"HEALTH_CHECK_CACHE_TTL" envDefault:"1s"`
+}
+
+// Validate requires a positive timeout and a non-negative cache TTL.
+func (c *HealthConfig) Validate() error {
+	if c.HealthCheckTimeoutEV <= 0 {
+		return errors.New("HEALTH_CHECK_TIMEOUT must be positive")
+	}
+	if c.HealthCheckCacheTTLEV < 0 {
+		return errors.New("HEALTH_CHECK_CACHE_TTL must not be negative")
+	}
+	return nil
+}
+
+func (c *HealthConfig) HealthCheckTimeout() time.Duration {
+	return c.HealthCheckTimeoutEV
+}
+
+func (c *HealthConfig) HealthCheckCacheTTL() time.Duration {
+	return c.HealthCheckCacheTTLEV
+}
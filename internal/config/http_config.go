@@ -1,6 +1,16 @@
 package config
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
 
 type HTTPServerConfig struct {
 	HostEV                string        `env:"HOST"`
@@ -12,6 +22,83 @@ type HTTPServerConfig struct {
 	ShutDownTimeoutEV     time.Duration `env:"SHUT_DOWN_TIMEOUT"`
 	ShutDownHardTimeoutEV time.Duration `env:"SHUT_DOWN_HARD_TIMEOUT"`
 	ShutDownReadyDelayEV  time.Duration `env:"SHUT_DOWN_READY_DELAY"`
+	OpenAPIUIEnabledEV    bool          `env:"OPENAPI_UI_ENABLED" envDefault:"false"`
+
+	AccessLogSampleRateEV    float64        `env:"ACCESS_LOG_SAMPLE_RATE" envDefault:"1"`
+	AccessLogSlowThresholdEV time.Duration  `env:"ACCESS_LOG_SLOW_THRESHOLD" envDefault:"1s"`
+	TrustedProxiesEV         []netip.Prefix `env:"TRUSTED_PROXIES" envDefault:""`
+
+	CompressionEnabledEV bool `env:"COMPRESSION_ENABLED" envDefault:"true"`
+	CompressionMinSizeEV int  `env:"COMPRESSION_MIN_SIZE" envDefault:"1024"`
+
+	RateLimitEnabledEV       bool          `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	RateLimitStoreEV         string        `env:"RATE_LIMIT_STORE" envDefault:"memory"`
+	RateLimitRequestsEV      int           `env:"RATE_LIMIT_REQUESTS" envDefault:"300"`
+	RateLimitWriteRequestsEV int           `env:"RATE_LIMIT_WRITE_REQUESTS" envDefault:"60"`
+	RateLimitIPRequestsEV    int           `env:"RATE_LIMIT_IP_REQUESTS" envDefault:"600"`
+	RateLimitPeriodEV        time.Duration `env:"RATE_LIMIT_PERIOD" envDefault:"1m"`
+	RateLimitBurstEV         int           `env:"RATE_LIMIT_BURST" envDefault:"0"`
+
+	SecurityConfig
+	APIVersionConfig
+	WebhookConfig
+	SSEConfig
+	GRPCConfig
+	HealthConfig
+	AdminConfig
+}
+
+// Validate requires a known rate limit store and positive limits when rate limiting is enabled,
+// and a valid SecurityConfig, APIVersionConfig, WebhookConfig, SSEConfig, GRPCConfig, HealthConfig and AdminConfig.
+// The gRPC and admin ports must differ from the HTTP port and from each other.
+func (c *HTTPServerConfig) Validate() error {
+	err := c.SecurityConfig.Validate()
+	if err != nil {
+		return err
+	}
+	err = c.APIVersionConfig.Validate()
+	if err != nil {
+		return err
+	}
+	err = c.WebhookConfig.Validate()
+	if err != nil {
+		return err
+	}
+	err = c.SSEConfig.Validate()
+	if err != nil {
+		return err
+	}
+	err = c.GRPCConfig.Validate()
+	if err != nil {
+		return err
+	}
+	err = c.HealthConfig.Validate()
+	if err != nil {
+		return err
+	}
+	err = c.AdminConfig.Validate()
+	if err != nil {
+		return err
+	}
+	if c.GRPCEnabledEV && c.GRPCPortEV != 0 && c.GRPCPortEV == c.PortEV {
+		return errors.New("GRPC_PORT must differ from PORT")
+	}
+	if c.AdminEnabledEV && c.AdminPortEV != 0 &&
+		(c.AdminPortEV == c.PortEV || (c.GRPCEnabledEV && c.AdminPortEV == c.GRPCPortEV)) {
+		return errors.New("ADMIN_PORT must differ from PORT and GRPC_PORT")
+	}
+	if !c.RateLimitEnabledEV {
+		return nil
+	}
+	if c.RateLimitStoreEV != RateLimitStoreMemory && c.RateLimitStoreEV != RateLimitStorePostgres {
+		return fmt.Errorf("RATE_LIMIT_STORE must be %s or %s", RateLimitStoreMemory, RateLimitStorePostgres)
+	}
+	if c.RateLimitRequestsEV <= 0 || c.RateLimitWriteRequestsEV <= 0 || c.RateLimitIPRequestsEV <= 0 ||
+		c.RateLimitPeriodEV <= 0 {
+		return errors.New("RATE_LIMIT_REQUESTS, RATE_LIMIT_WRITE_REQUESTS, RATE_LIMIT_IP_REQUESTS and " +
+			"RATE_LIMIT_PERIOD must be positive")
+	}
+	return nil
 }
 
 func (c *HTTPServerConfig) Host() string {
@@ -49,3 +136,55 @@ func (c *HTTPServerConfig) ShutDownHardTimeout() time.Duration {
 func (c *HTTPServerConfig) ShutDownReadyDelay() time.Duration {
 	return c.ShutDownReadyDelayEV
 }
+
+func (c *HTTPServerConfig) OpenAPIUIEnabled() bool {
+	return c.OpenAPIUIEnabledEV
+}
+
+func (c *HTTPServerConfig) AccessLogSampleRate() float64 {
+	return c.AccessLogSampleRateEV
+}
+
+func (c *HTTPServerConfig) AccessLogSlowThreshold() time.Duration {
+	return c.AccessLogSlowThresholdEV
+}
+
+func (c *HTTPServerConfig) TrustedProxies() []netip.Prefix {
+	return c.TrustedProxiesEV
+}
+
+func (c *HTTPServerConfig) CompressionEnabled() bool {
+	return c.CompressionEnabledEV
+}
+
+func (c *HTTPServerConfig) CompressionMinSize() int {
+	return c.CompressionMinSizeEV
+}
+
+func (c *HTTPServerConfig) RateLimitEnabled() bool {
+	return c.RateLimitEnabledEV
+}
+
+func (c *HTTPServerConfig) RateLimitStore() string {
+	return c.RateLimitStoreEV
+}
+
+func (c *HTTPServerConfig) RateLimitRequests() int {
+	return c.RateLimitRequestsEV
+}
+
+func (c *HTTPServerConfig) RateLimitWriteRequests() int {
+	return c.RateLimitWriteRequestsEV
+}
+
+func (c *HTTPServerConfig) RateLimitIPRequests() int {
+	return c.RateLimitIPRequestsEV
+}
+
+func (c *HTTPServerConfig) RateLimitPeriod() time.Duration {
+	return c.RateLimitPeriodEV
+}
+
+func (c *HTTPServerConfig) RateLimitBurst() int {
+	return c.RateLimitBurstEV
+}
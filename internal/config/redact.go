@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces set values of fields tagged redact:"true", unset values are left empty.
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns the effective configuration of cfg keyed by environment variable, e.g. to be dumped by operators.
+// Embedded and nested configs are flattened, values of fields tagged redact:"true" are replaced by RedactedValue.
+func Redacted(cfg any) map[string]string {
+	values := make(map[string]string)
+	redact(reflect.ValueOf(cfg), values)
+	return values
+}
+
+func redact(v reflect.Value, values map[string]string) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+		if name == "" {
+			redact(v.Field(i), values)
+			continue
+		}
+
+		value := formatValue(v.Field(i))
+		if field.Tag.Get("redact") == "true" && value != "" {
+			value = RedactedValue
+		}
+		values[name] = value
+	}
+}
+
+// formatValue as set in the environment, slices are comma separated.
+func formatValue(v reflect.Value) string {
+	if v.Kind() != reflect.Slice {
+		return fmt.Sprint(v.Interface())
+	}
+	items := make([]string, v.Len())
+	for i := range v.Len() {
+		items[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(items, ",")
+}
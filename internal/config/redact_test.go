@@ -0,0 +1,38 @@
+package config
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedacted(t *testing.T) {
+	cfg := &Config{
+		HTTPServerConfig: &HTTPServerConfig{
+			PortEV:           8080,
+			HandlerTimeoutEV: 5 * time.Second,
+			TrustedProxiesEV: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")},
+			AdminConfig:      AdminConfig{AdminEnabledEV: true, AdminTokenEV: "secret-token"},
+		},
+		DBConfig: &DBConfig{UserEV: "app", PasswordEV: "secret-password"},
+	}
+
+	values := Redacted(cfg)
+
+	assert.Equal(t, "8080", values["PORT"])
+	assert.Equal(t, "5s", values["HANDLER_TIMEOUT"])
+	assert.Equal(t, "10.0.0.0/8,::1/128", values["TRUSTED_PROXIES"])
+	assert.Equal(t, "true", values["ADMIN_ENABLED"])
+	assert.Equal(t, RedactedValue, values["ADMIN_TOKEN"])
+	assert.Equal(t, "app", values["DB_USER"])
+	assert.Equal(t, RedactedValue, values["DB_PASSWORD"])
+	// Unset secrets are reported empty, nil configs are skipped
+	assert.Empty(t, values["DB_NAME"])
+	assert.NotContains(t, values, "AUTH_ENABLED")
+
+	for _, value := range values {
+		assert.NotContains(t, value, "secret")
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SecurityConfig CORS and security headers of API routes, embedded in HTTPServerConfig.
+type SecurityConfig struct {
+	CORSAllowedOriginsEV   []string      `env:"CORS_ALLOWED_ORIGINS" envDefault:""`
+	CORSAllowedMethodsEV   []string      `env:"CORS_ALLOWED_METHODS" envDefault:"GET,POST,PUT,PATCH,DELETE"`
+	CORSAllowedHeadersEV   []string      `env:"CORS_ALLOWED_HEADERS" envDefault:"Authorization,Content-Type,Idempotency-Key,X-Request-Id,X-Tenant-Id,traceparent"`
+	CORSExposedHeadersEV   []string      `env:"CORS_EXPOSED_HEADERS" envDefault:"X-Request-Id,traceparent,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy,Retry-After,Deprecation,Sunset,Link"`
+	CORSAllowCredentialsEV bool          `env:"CORS_ALLOW_CREDENTIALS" envDefault:"false"`
+	CORSMaxAgeEV           time.Duration `env:"CORS_MAX_AGE" envDefault:"10m"`
+
+	HSTSMaxAgeEV            time.Duration `env:"HSTS_MAX_AGE" envDefault:"8760h"`
+	HSTSIncludeSubdomainsEV bool          `env:"HSTS_INCLUDE_SUBDOMAINS" envDefault:"true"`
+}
+
+// Validate requires origins formatted as scheme://host, and no wildcard origin with credentials.
+func (c *SecurityConfig) Validate() error {
+	for _, origin := range c.CORSAllowedOriginsEV {
+		if origin != "*" && !strings.Contains(origin, "://") {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS %q must be formatted as scheme://host", origin)
+		}
+	}
+	if c.CORSAllowCredentialsEV && slices.Contains(c.CORSAllowedOriginsEV, "*") {
+		return errors.New("CORS_ALLOWED_ORIGINS must not contain * when CORS_ALLOW_CREDENTIALS")
+	}
+	return nil
+}
+
+func (c *SecurityConfig) CORSAllowedOrigins() []string {
+	return c.CORSAllowedOriginsEV
+}
+
+func (c *SecurityConfig) CORSAllowedMethods() []string {
+	return c.CORSAllowedMethodsEV
+}
+
+func (c *SecurityConfig) CORSAllowedHeaders() []string {
+	return c.CORSAllowedHeadersEV
+}
+
+func (c *SecurityConfig) CORSExposedHeaders() []string {
+	return c.CORSExposedHeadersEV
+}
+
+func (c *SecurityConfig) CORSAllowCredentials() bool {
+	return c.CORSAllowCredentialsEV
+}
+
+func (c *SecurityConfig) CORSMaxAge() time.Duration {
+	return c.CORSMaxAgeEV
+}
+
+func (c *SecurityConfig) HSTSMaxAge() time.Duration {
+	return c.HSTSMaxAgeEV
+}
+
+func (c *SecurityConfig) HSTSIncludeSubdomains() bool {
+	return c.HSTSIncludeSubdomainsEV
+}
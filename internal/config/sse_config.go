@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// SSEConfig server-sent event streams, embedded in HTTPServerConfig.
+type SSEConfig struct {
+	SSEHeartbeatIntervalEV time.Duration `env:"SSE_HEARTBEAT_INTERVAL" envDefault:"15s"`
+	SSEWriteTimeoutEV      time.Duration `env:"SSE_WRITE_TIMEOUT" envDefault:"10s"`
+	SSEReplaySizeEV        int           `env:"SSE_REPLAY_SIZE" envDefault:"256"`
+	SSESubscriberBufferEV  int           `env:"SSE_SUBSCRIBER_BUFFER" envDefault:"32"`
+}
+
+// Validate requires positive settings, the replay buffer may be disabled with zero.
+func (c *SSEConfig) Validate() error {
+	if c.SSEHeartbeatIntervalEV <= 0 || c.SSEWriteTimeoutEV <= 0 || c.SSESubscriberBufferEV <= 0 {
+		return errors.New("SSE_HEARTBEAT_INTERVAL, SSE_WRITE_TIMEOUT and SSE_SUBSCRIBER_BUFFER must be positive")
+	}
+	if c.SSEReplaySizeEV < 0 {
+		return errors.New("SSE_REPLAY_SIZE must not be negative")
+	}
+	return nil
+}
+
+func (c *SSEConfig) SSEHeartbeatInterval() time.Duration {
+	return c.SSEHeartbeatIntervalEV
+}
+
+func (c *SSEConfig) SSEWriteTimeout() time.Duration {
+	return c.SSEWriteTimeoutEV
+}
+
+func (c *SSEConfig) SSEReplaySize() int {
+	return c.SSEReplaySizeEV
+}
+
+func (c *SSEConfig) SSESubscriberBuffer() int {
+	return c.SSESubscriberBufferEV
+}
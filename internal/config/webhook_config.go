@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"net/netip"
+	"time"
+)
+
+// WebhookConfig delivery of webhooks, embedded in HTTPServerConfig.
+type WebhookConfig struct {
+	WebhookDispatchEnabledEV bool          `env:"WEBHOOK_DISPATCH_ENABLED" envDefault:"true"`
+	WebhookPollIntervalEV    time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+	WebhookBatchSizeEV       int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"20"`
+	WebhookTimeoutEV         time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookMaxAttemptsEV     int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookBackoffBaseEV     time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"30s"`
+	WebhookBackoffMaxEV      time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"6h"`
+	WebhookDisableAfterEV    int           `env:"WEBHOOK_DISABLE_AFTER" envDefault:"20"`
+	WebhookMaxLagEV          time.Duration `env:"WEBHOOK_MAX_LAG" envDefault:"5m"`
+	// WebhookAllowedNetworksEV of internal receivers, deliveries to internal addresses are rejected otherwise
+	WebhookAllowedNetworksEV []netip.Prefix `env:"WEBHOOK_ALLOWED_NETWORKS" envDefault:""`
+}
+
+// Validate requires positive settings when dispatching is enabled.
+func (c *WebhookConfig) Validate() error {
+	if !c.WebhookDispatchEnabledEV {
+		return nil
+	}
+	if c.WebhookPollIntervalEV <= 0 || c.WebhookBatchSizeEV <= 0 || c.WebhookTimeoutEV <= 0 ||
+		c.WebhookMaxAttemptsEV <= 0 || c.WebhookDisableAfterEV <= 0 || c.WebhookMaxLagEV <= 0 {
+		return errors.New("WEBHOOK_POLL_INTERVAL, WEBHOOK_BATCH_SIZE, WEBHOOK_TIMEOUT, " +
+			"WEBHOOK_MAX_ATTEMPTS, WEBHOOK_DISABLE_AFTER and WEBHOOK_MAX_LAG must be positive")
+	}
+	if c.WebhookBackoffBaseEV <= 0 || c.WebhookBackoffMaxEV < c.WebhookBackoffBaseEV {
+		return errors.New("WEBHOOK_BACKOFF_BASE must be positive and not exceed WEBHOOK_BACKOFF_MAX")
+	}
+	return nil
+}
+
+func (c *WebhookConfig) WebhookDispatchEnabled() bool {
+	return c.WebhookDispatchEnabledEV
+}
+
+func (c *WebhookConfig) WebhookPollInterval() time.Duration {
+	return c.WebhookPollIntervalEV
+}
+
+func (c *WebhookConfig) WebhookBatchSize() int {
+	return c.WebhookBatchSizeEV
+}
+
+func (c *WebhookConfig) WebhookTimeout() time.Duration {
+	return c.WebhookTimeoutEV
+}
+
+func (c *WebhookConfig) WebhookMaxAttempts() int {
+	return c.WebhookMaxAttemptsEV
+}
+
+func (c *WebhookConfig) WebhookBackoffBase() time.Duration {
+	return c.WebhookBackoffBaseEV
+}
+
+func (c *WebhookConfig) WebhookBackoffMax() time.Duration {
+	return c.WebhookBackoffMaxEV
+}
+
+func (c *WebhookConfig) WebhookDisableAfter() int {
+	return c.WebhookDisableAfterEV
+}
+
+func (c *WebhookConfig) WebhookMaxLag() time.Duration {
+	return c.WebhookMaxLagEV
+}
+
+func (c *WebhookConfig) WebhookAllowedNetworks() []netip.Prefix {
+	return c.WebhookAllowedNetworksEV
+}
@@ -0,0 +1,173 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: bigbackend/user/v1/user_invitation.proto
+
+package userv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateUserInvitationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserInvitationRequest) Reset() {
+	*x = CreateUserInvitationRequest{}
+	mi := &file_bigbackend_user_v1_user_invitation_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserInvitationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserInvitationRequest) ProtoMessage() {}
+
+func (x *CreateUserInvitationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bigbackend_user_v1_user_invitation_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserInvitationRequest.ProtoReflect.Descriptor instead.
+func (*CreateUserInvitationRequest) Descriptor() ([]byte, []int) {
+	return file_bigbackend_user_v1_user_invitation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateUserInvitationRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type CreateUserInvitationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserInvitationResponse) Reset() {
+	*x = CreateUserInvitationResponse{}
+	mi := &file_bigbackend_user_v1_user_invitation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserInvitationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserInvitationResponse) ProtoMessage() {}
+
+func (x *CreateUserInvitationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bigbackend_user_v1_user_invitation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserInvitationResponse.ProtoReflect.Descriptor instead.
+func (*CreateUserInvitationResponse) Descriptor() ([]byte, []int) {
+	return file_bigbackend_user_v1_user_invitation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateUserInvitationResponse) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+var File_bigbackend_user_v1_user_invitation_proto protoreflect.FileDescriptor
+
+const file_bigbackend_user_v1_user_invitation_proto_rawDesc = "" +
+	"\n" +
+	"(bigbackend/user/v1/user_invitation.proto\x12\x12bigbackend.user.v1\"3\n" +
+	"\x1bCreateUserInvitationRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"4\n" +
+	"\x1cCreateUserInvitationResponse\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email2\x92\x01\n" +
+	"\x15UserInvitationService\x12y\n" +
+	"\x14CreateUserInvitation\x12/.bigbackend.user.v1.CreateUserInvitationRequest\x1a0.bigbackend.user.v1.CreateUserInvitationResponseBGZEgithub.com/dyxj/bigbackend/internal/grpcgen/bigbackend/user/v1;userv1b\x06proto3"
+
+var (
+	file_bigbackend_user_v1_user_invitation_proto_rawDescOnce sync.Once
+	file_bigbackend_user_v1_user_invitation_proto_rawDescData []byte
+)
+
+func file_bigbackend_user_v1_user_invitation_proto_rawDescGZIP() []byte {
+	file_bigbackend_user_v1_user_invitation_proto_rawDescOnce.Do(func() {
+		file_bigbackend_user_v1_user_invitation_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bigbackend_user_v1_user_invitation_proto_rawDesc), len(file_bigbackend_user_v1_user_invitation_proto_rawDesc)))
+	})
+	return file_bigbackend_user_v1_user_invitation_proto_rawDescData
+}
+
+var file_bigbackend_user_v1_user_invitation_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_bigbackend_user_v1_user_invitation_proto_goTypes = []any{
+	(*CreateUserInvitationRequest)(nil),  // 0: bigbackend.user.v1.CreateUserInvitationRequest
+	(*CreateUserInvitationResponse)(nil), // 1: bigbackend.user.v1.CreateUserInvitationResponse
+}
+var file_bigbackend_user_v1_user_invitation_proto_depIdxs = []int32{
+	0, // 0: bigbackend.user.v1.UserInvitationService.CreateUserInvitation:input_type -> bigbackend.user.v1.CreateUserInvitationRequest
+	1, // 1: bigbackend.user.v1.UserInvitationService.CreateUserInvitation:output_type -> bigbackend.user.v1.CreateUserInvitationResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_bigbackend_user_v1_user_invitation_proto_init() }
+func file_bigbackend_user_v1_user_invitation_proto_init() {
+	if File_bigbackend_user_v1_user_invitation_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bigbackend_user_v1_user_invitation_proto_rawDesc), len(file_bigbackend_user_v1_user_invitation_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bigbackend_user_v1_user_invitation_proto_goTypes,
+		DependencyIndexes: file_bigbackend_user_v1_user_invitation_proto_depIdxs,
+		MessageInfos:      file_bigbackend_user_v1_user_invitation_proto_msgTypes,
+	}.Build()
+	File_bigbackend_user_v1_user_invitation_proto = out.File
+	file_bigbackend_user_v1_user_invitation_proto_goTypes = nil
+	file_bigbackend_user_v1_user_invitation_proto_depIdxs = nil
+}
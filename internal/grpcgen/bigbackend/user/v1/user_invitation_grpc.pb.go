@@ -0,0 +1,131 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: bigbackend/user/v1/user_invitation.proto
+
+package userv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	UserInvitationService_CreateUserInvitation_FullMethodName = "/bigbackend.user.v1.UserInvitationService/CreateUserInvitation"
+)
+
+// UserInvitationServiceClient is the client API for UserInvitationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// UserInvitationService invites users by email, see UserProfileService for authentication and tenancy.
+type UserInvitationServiceClient interface {
+	// CreateUserInvitation invites email, requires the "invitations:create" permission.
+	// Responds with the email even when it already has a pending or accepted invitation, not disclosing it.
+	// Accepts the "idempotency-key" metadata.
+	CreateUserInvitation(ctx context.Context, in *CreateUserInvitationRequest, opts ...grpc.CallOption) (*CreateUserInvitationResponse, error)
+}
+
+type userInvitationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUserInvitationServiceClient(cc grpc.ClientConnInterface) UserInvitationServiceClient {
+	return &userInvitationServiceClient{cc}
+}
+
+func (c *userInvitationServiceClient) CreateUserInvitation(ctx context.Context, in *CreateUserInvitationRequest, opts ...grpc.CallOption) (*CreateUserInvitationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateUserInvitationResponse)
+	err := c.cc.Invoke(ctx, UserInvitationService_CreateUserInvitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UserInvitationServiceServer is the server API for UserInvitationService service.
+// All implementations must embed UnimplementedUserInvitationServiceServer
+// for forward compatibility.
+//
+// UserInvitationService invites users by email, see UserProfileService for authentication and tenancy.
+type UserInvitationServiceServer interface {
+	// CreateUserInvitation invites email, requires the "invitations:create" permission.
+	// Responds with the email even when it already has a pending or accepted invitation, not disclosing it.
+	// Accepts the "idempotency-key" metadata.
+	CreateUserInvitation(context.Context, *CreateUserInvitationRequest) (*CreateUserInvitationResponse, error)
+	mustEmbedUnimplementedUserInvitationServiceServer()
+}
+
+// UnimplementedUserInvitationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUserInvitationServiceServer struct{}
+
+func (UnimplementedUserInvitationServiceServer) CreateUserInvitation(context.Context, *CreateUserInvitationRequest) (*CreateUserInvitationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUserInvitation not implemented")
+}
+func (UnimplementedUserInvitationServiceServer) mustEmbedUnimplementedUserInvitationServiceServer() {}
+func (UnimplementedUserInvitationServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeUserInvitationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UserInvitationServiceServer will
+// result in compilation errors.
+type UnsafeUserInvitationServiceServer interface {
+	mustEmbedUnimplementedUserInvitationServiceServer()
+}
+
+func RegisterUserInvitationServiceServer(s grpc.ServiceRegistrar, srv UserInvitationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedUserInvitationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UserInvitationService_ServiceDesc, srv)
+}
+
+func _UserInvitationService_CreateUserInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserInvitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserInvitationServiceServer).CreateUserInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserInvitationService_CreateUserInvitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserInvitationServiceServer).CreateUserInvitation(ctx, req.(*CreateUserInvitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UserInvitationService_ServiceDesc is the grpc.ServiceDesc for UserInvitationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UserInvitationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bigbackend.user.v1.UserInvitationService",
+	HandlerType: (*UserInvitationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateUserInvitation",
+			Handler:    _UserInvitationService_CreateUserInvitation_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bigbackend/user/v1/user_invitation.proto",
+}
@@ -0,0 +1,14 @@
+package notification
+
+import (
+	"github.com/google/uuid"
+)
+
+// EventUserInvitationStatusChanged notified when an invitation is created or expires.
+// Notified to users only, not delivered to webhooks.
+const EventUserInvitationStatusChanged = "user_invitation.status_changed"
+
+// UserTopic of events notified to a user of a tenant, see Notifier and StreamHandler.
+func UserTopic(tenantID uuid.UUID, userID uuid.UUID) string {
+	return "user:" + tenantID.String() + ":" + userID.String()
+}
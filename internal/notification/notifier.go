@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sse"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Notifier pushes events to the streams of users connected to this instance, see StreamHandler.
+// Notifications are best effort, users reconnecting to another instance reload their state.
+type Notifier struct {
+	logger    *zap.Logger
+	publisher Publisher
+}
+
+func NewNotifier(logger *zap.Logger, publisher Publisher) *Notifier {
+	return &Notifier{logger: logger, publisher: publisher}
+}
+
+// NotifyUser publishes an event of eventType to the user of the tenant of ctx, data is marshalled as JSON.
+// Call once the change is committed, failures are logged rather than failing the change.
+func (n *Notifier) NotifyUser(ctx context.Context, userID uuid.UUID, eventType string, data any) {
+	logger := logx.FromContext(ctx, n.logger)
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		logger.Error("failed to notify user", zap.Error(err), zap.Stringer("userId", userID))
+		return
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("failed to marshal notification", zap.Error(err), zap.Stringer("userId", userID))
+		return
+	}
+
+	event := n.publisher.Publish(UserTopic(tenantID, userID), eventType, payload)
+
+	logger.Debug("notified user",
+		zap.Stringer("userId", userID), zap.String("eventType", eventType), zap.String("eventId", event.ID))
+}
+
+type Publisher interface {
+	Publish(topic string, eventType string, data []byte) sse.Event
+}
@@ -0,0 +1,78 @@
+package notification
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sse"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StreamHandler streams events notified to the user of URL parameter "id" as server-sent events.
+// Clients resume with the Last-Event-ID header, and reload their state on an sse.EventTypeReset event.
+type StreamHandler struct {
+	logger      *zap.Logger
+	errRegistry *httpx.ErrorRegistry
+	authorizer  auth.Authorizer
+	subscriber  Subscriber
+	options     []sse.StreamOption
+}
+
+func NewStreamHandler(
+	logger *zap.Logger,
+	errRegistry *httpx.ErrorRegistry,
+	authorizer auth.Authorizer,
+	subscriber Subscriber,
+	options ...sse.StreamOption,
+) *StreamHandler {
+	return &StreamHandler{
+		logger: logger, errRegistry: errRegistry, authorizer: authorizer, subscriber: subscriber, options: options,
+	}
+}
+
+func (s *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.errRegistry.Handle(s.stream)(w, r)
+}
+
+func (s *StreamHandler) stream(w http.ResponseWriter, r *http.Request) error {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return &errorx.BadRequestError{
+			Message:    "invalid id",
+			Properties: map[string]string{"error": err.Error()},
+		}
+	}
+
+	err = s.authorizer.AuthorizeSubject(r.Context(), userID.String())
+	if err != nil {
+		return err
+	}
+
+	tenantID, err := tenant.Require(r.Context())
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.subscriber.Subscribe(UserTopic(tenantID, userID), r.Header.Get(sse.HeaderKeyLastEventID))
+	if err != nil {
+		return err
+	}
+
+	// Errors can no longer be rendered once streaming
+	err = sse.Stream(w, r, sub, s.options...)
+	if err != nil && !errors.Is(err, sse.ErrClosed) {
+		logx.FromContext(r.Context(), s.logger).Info("event stream ended", zap.Error(err))
+	}
+	return nil
+}
+
+type Subscriber interface {
+	Subscribe(topic string, lastEventID string) (*sse.Subscription, error)
+}
@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"go.uber.org/zap"
+)
+
+// DefaultPurgeInterval interval of deleting replenished keys, see StoreSQLDB.Run.
+const DefaultPurgeInterval = 5 * time.Minute
+
+type queryExecutable interface {
+	sqldb.Queryable
+	sqldb.Executable
+}
+
+// StoreSQLDB implements httpx.RateLimitStore in PostgreSQL, sharing limits between instances.
+// Requests are evaluated in a single upsert, times are taken from the instance clock.
+type StoreSQLDB struct {
+	logger        *zap.Logger
+	db            queryExecutable
+	purgeInterval time.Duration
+	now           func() time.Time
+}
+
+type StoreOption func(*StoreSQLDB)
+
+// WithPurgeInterval overrides DefaultPurgeInterval.
+func WithPurgeInterval(d time.Duration) StoreOption {
+	return func(s *StoreSQLDB) {
+		s.purgeInterval = d
+	}
+}
+
+func NewStoreSQLDB(logger *zap.Logger, db queryExecutable, options ...StoreOption) *StoreSQLDB {
+	s := &StoreSQLDB{
+		logger:        logger,
+		db:            db,
+		purgeInterval: DefaultPurgeInterval,
+		now:           time.Now,
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+func (s *StoreSQLDB) Allow(ctx context.Context, key string, limit httpx.RateLimit) (httpx.RateLimitResult, error) {
+	now := s.now()
+	interval := limit.EmissionInterval()
+
+	// Unseen keys are always allowed, existing keys are evaluated as httpx.GCRA
+	newTat, _ := httpx.GCRA(now, time.Time{}, limit)
+
+	nowExp := postgres.TimestampzT(now)
+	updatedTat := postgres.TimestampzExp(postgres.GREATEST(table.RateLimit.Tat, nowExp)).
+		ADD(postgres.INTERVALd(interval))
+
+	stmt := table.RateLimit.
+		INSERT(table.RateLimit.Key, table.RateLimit.Tat).
+		VALUES(key, newTat).
+		ON_CONFLICT(table.RateLimit.Key).
+		DO_UPDATE(
+			postgres.SET(table.RateLimit.Tat.SET(updatedTat)).
+				WHERE(updatedTat.SUB(postgres.INTERVALd(limit.Tolerance())).LT_EQ(nowExp)),
+		).
+		RETURNING(table.RateLimit.Tat)
+
+	var stored entity.RateLimit
+	err := stmt.QueryContext(ctx, s.db, &stored)
+	if err == nil {
+		_, result := httpx.GCRA(now, stored.Tat.Add(-interval), limit)
+		return result, nil
+	}
+	if !errors.Is(err, qrm.ErrNoRows) {
+		return httpx.RateLimitResult{}, err
+	}
+
+	// The update was skipped as the request is throttled
+	current, err := s.findTat(ctx, key)
+	if err != nil {
+		return httpx.RateLimitResult{}, err
+	}
+	_, result := httpx.GCRA(now, current, limit)
+	result.Allowed = false
+	result.Remaining = 0
+	return result, nil
+}
+
+func (s *StoreSQLDB) findTat(ctx context.Context, key string) (time.Time, error) {
+	stmt := table.RateLimit.
+		SELECT(table.RateLimit.AllColumns).
+		FROM(table.RateLimit).
+		WHERE(table.RateLimit.Key.EQ(postgres.String(key)))
+
+	var result entity.RateLimit
+	err := stmt.QueryContext(ctx, s.db, &result)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return result.Tat, nil
+}
+
+// Purge deletes keys replenished before now, as they are equivalent to unseen keys.
+func (s *StoreSQLDB) Purge(ctx context.Context) (int64, error) {
+	stmt := table.RateLimit.
+		DELETE().
+		WHERE(table.RateLimit.Tat.LT(postgres.TimestampzT(s.now())))
+
+	result, err := stmt.ExecContext(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Run purges replenished keys every purge interval until ctx is done.
+func (s *StoreSQLDB) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.Purge(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Error("failed to purge rate limits", zap.Error(err))
+				}
+				continue
+			}
+			s.logger.Debug("purged rate limits", zap.Int64("keys", n))
+		}
+	}
+}
@@ -21,4 +21,6 @@ type UserInvitation struct {
 	CreateTime time.Time
 	UpdateTime time.Time
 	Version    int32
+	TenantID   uuid.UUID
+	Inviter    string
 }
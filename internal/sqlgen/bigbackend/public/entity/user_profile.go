@@ -22,4 +22,5 @@ type UserProfile struct {
 	CreateTime  time.Time
 	UpdateTime  time.Time
 	Version     int32
+	TenantID    uuid.UUID
 }
@@ -10,6 +10,11 @@ package table
 // UseSchema sets a new schema name for all generated table SQL builder types. It is recommended to invoke
 // this method only once at the beginning of the program.
 func UseSchema(schema string) {
+	APIKey = APIKey.FromSchema(schema)
+	RateLimit = RateLimit.FromSchema(schema)
+	RolePermission = RolePermission.FromSchema(schema)
 	UserInvitation = UserInvitation.FromSchema(schema)
 	UserProfile = UserProfile.FromSchema(schema)
+	WebhookDelivery = WebhookDelivery.FromSchema(schema)
+	WebhookSubscription = WebhookSubscription.FromSchema(schema)
 }
@@ -25,6 +25,8 @@ type userInvitationTable struct {
 	CreateTime postgres.ColumnTimestampz
 	UpdateTime postgres.ColumnTimestampz
 	Version    postgres.ColumnInteger
+	TenantID   postgres.ColumnString
+	Inviter    postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -74,8 +76,10 @@ func newUserInvitationTableImpl(schemaName, tableName, alias string) userInvitat
 		CreateTimeColumn = postgres.TimestampzColumn("create_time")
 		UpdateTimeColumn = postgres.TimestampzColumn("update_time")
 		VersionColumn    = postgres.IntegerColumn("version")
-		allColumns       = postgres.ColumnList{IDColumn, EmailColumn, StatusColumn, ExpiryTimeColumn, TokenColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn}
-		mutableColumns   = postgres.ColumnList{EmailColumn, StatusColumn, ExpiryTimeColumn, TokenColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn}
+		TenantIDColumn   = postgres.StringColumn("tenant_id")
+		InviterColumn    = postgres.StringColumn("inviter")
+		allColumns       = postgres.ColumnList{IDColumn, EmailColumn, StatusColumn, ExpiryTimeColumn, TokenColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn, TenantIDColumn, InviterColumn}
+		mutableColumns   = postgres.ColumnList{EmailColumn, StatusColumn, ExpiryTimeColumn, TokenColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn, TenantIDColumn, InviterColumn}
 		defaultColumns   = postgres.ColumnList{}
 	)
 
@@ -91,6 +95,8 @@ func newUserInvitationTableImpl(schemaName, tableName, alias string) userInvitat
 		CreateTime: CreateTimeColumn,
 		UpdateTime: UpdateTimeColumn,
 		Version:    VersionColumn,
+		TenantID:   TenantIDColumn,
+		Inviter:    InviterColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -25,6 +25,7 @@ type userProfileTable struct {
 	CreateTime  postgres.ColumnTimestampz
 	UpdateTime  postgres.ColumnTimestampz
 	Version     postgres.ColumnInteger
+	TenantID    postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -74,8 +75,9 @@ func newUserProfileTableImpl(schemaName, tableName, alias string) userProfileTab
 		CreateTimeColumn  = postgres.TimestampzColumn("create_time")
 		UpdateTimeColumn  = postgres.TimestampzColumn("update_time")
 		VersionColumn     = postgres.IntegerColumn("version")
-		allColumns        = postgres.ColumnList{IDColumn, UserIDColumn, FirstNameColumn, LastNameColumn, DateOfBirthColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn}
-		mutableColumns    = postgres.ColumnList{UserIDColumn, FirstNameColumn, LastNameColumn, DateOfBirthColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn}
+		TenantIDColumn    = postgres.StringColumn("tenant_id")
+		allColumns        = postgres.ColumnList{IDColumn, UserIDColumn, FirstNameColumn, LastNameColumn, DateOfBirthColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn, TenantIDColumn}
+		mutableColumns    = postgres.ColumnList{UserIDColumn, FirstNameColumn, LastNameColumn, DateOfBirthColumn, CreateTimeColumn, UpdateTimeColumn, VersionColumn, TenantIDColumn}
 		defaultColumns    = postgres.ColumnList{}
 	)
 
@@ -91,6 +93,7 @@ func newUserProfileTableImpl(schemaName, tableName, alias string) userProfileTab
 		CreateTime:  CreateTimeColumn,
 		UpdateTime:  UpdateTimeColumn,
 		Version:     VersionColumn,
+		TenantID:    TenantIDColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
@@ -23,13 +23,16 @@ var Statuses = []Status{
 
 type UserInvitation struct {
 	ID         uuid.UUID `json:"id"`
-	Email      string    `json:"email"`
+	Email      string    `json:"email" validate:"email"`
 	StatusRaw  Status    `json:"statusRaw"`
 	ExpiryTime time.Time `json:"expiryTime"`
 	Token      string    `json:"token"`
 	CreateTime time.Time `json:"createTime"`
 	UpdateTime time.Time `json:"updateTime"`
 	Version    int32     `json:"version"`
+	TenantID   uuid.UUID `json:"tenantId"`
+	// Inviter subject of the principal inviting, empty when unknown
+	Inviter string `json:"inviter"`
 }
 
 func (u *UserInvitation) Status() Status {
@@ -49,5 +52,5 @@ func (u *UserInvitation) IsValidForCreate() bool {
 }
 
 func (u *UserInvitation) IsValid() bool {
-	return validx.IsEmail(u.Email)
+	return validx.Struct(u) == nil
 }
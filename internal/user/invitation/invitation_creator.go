@@ -4,8 +4,11 @@ import (
 	"context"
 	"time"
 
+	"github.com/dyxj/bigbackend/internal/notification"
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/auth"
 	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -40,6 +43,7 @@ type creator struct {
 	mapper      Mapper
 	publisher   EventPublisher
 	expirer     Expirer
+	notifier    Notifier
 	cfg         createConfig
 }
 
@@ -50,6 +54,7 @@ func NewCreator(
 	mapper Mapper,
 	publisher EventPublisher,
 	expirer Expirer,
+	notifier Notifier,
 	option ...CreateOption,
 ) Creator {
 	cfg := createConfig{
@@ -63,7 +68,7 @@ func NewCreator(
 
 	return &creator{
 		logger: logger, tm: tm, creatorRepo: creatorRepo, mapper: mapper,
-		publisher: publisher, expirer: expirer, cfg: cfg,
+		publisher: publisher, expirer: expirer, notifier: notifier, cfg: cfg,
 	}
 }
 
@@ -79,15 +84,16 @@ func (c *creator) CreateUserInvitation(
 	input.ExpiryTime = time.Now().Add(c.cfg.defaultExpiryDuration)
 	input.StatusRaw = StatusPending
 	input.Token = uuid.New().String()
+	input.Inviter = auth.ContextSubject(ctx)
 
 	tx, err := c.tm.BeginTx(ctx, nil)
 	if err != nil {
-		c.logger.Error("failed to begin transaction", zap.Error(err))
+		logx.FromContext(ctx, c.logger).Error("failed to begin transaction", zap.Error(err))
 		return UserInvitation{}, err
 	}
 	defer sqldb.TxRollback(tx, c.logger)
 
-	err = c.expirer.ExpireInvitationsByEmailTx(ctx, tx, input.Email)
+	expired, err := c.expirer.ExpireInvitationsByEmailTx(ctx, tx, input.Email)
 	if err != nil {
 		return UserInvitation{}, err
 	}
@@ -105,11 +111,34 @@ func (c *creator) CreateUserInvitation(
 
 	err = tx.Commit()
 	if err != nil {
-		c.logger.Error("failed to commit transaction", zap.Error(err))
+		logx.FromContext(ctx, c.logger).Error("failed to commit transaction", zap.Error(err))
 		return UserInvitation{}, err
 	}
 
-	return c.mapper.EntityToModel(createdEntity), nil
+	created := c.mapper.EntityToModel(createdEntity)
+	c.notifyStatusChanged(ctx, expired, created)
+
+	return created, nil
+}
+
+// notifyStatusChanged notifies the principal inviting of the invitation created, and of the invitations expired
+// they sent. Expired invitations of other inviters are not notified to them.
+// Principals other than users, e.g. of API keys, are not notified.
+func (c *creator) notifyStatusChanged(ctx context.Context, expired []entity.UserInvitation, created UserInvitation) {
+	userID, err := uuid.Parse(created.Inviter)
+	if err != nil {
+		logx.FromContext(ctx, c.logger).Debug("invitation status not notified, principal is not a user")
+		return
+	}
+
+	for _, e := range expired {
+		if e.Inviter != created.Inviter {
+			continue
+		}
+		c.notifier.NotifyUser(ctx, userID, notification.EventUserInvitationStatusChanged,
+			newStatusEvent(c.mapper.EntityToModel(e)))
+	}
+	c.notifier.NotifyUser(ctx, userID, notification.EventUserInvitationStatusChanged, newStatusEvent(created))
 }
 
 type Creator interface {
@@ -127,5 +156,10 @@ type EventPublisher interface {
 }
 
 type Expirer interface {
-	ExpireInvitationsByEmailTx(ctx context.Context, tx sqldb.Executable, email string) error
+	ExpireInvitationsByEmailTx(ctx context.Context, tx sqldb.Queryable, email string) ([]entity.UserInvitation, error)
+}
+
+// Notifier notifies users of committed changes, e.g. notification.Notifier.
+type Notifier interface {
+	NotifyUser(ctx context.Context, userID uuid.UUID, eventType string, data any)
 }
@@ -0,0 +1,110 @@
+package invitation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/notification"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/user/invitation"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/test/faker"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type stubCreatorRepo struct{}
+
+func (stubCreatorRepo) InsertUserInvitation(
+	_ context.Context, _ sqldb.Executable, input entity.UserInvitation,
+) (entity.UserInvitation, error) {
+	input.ID = uuid.New()
+	return input, nil
+}
+
+type stubPublisher struct{}
+
+func (stubPublisher) Publish(context.Context, sqldb.Executable) error {
+	return nil
+}
+
+type stubExpirer struct {
+	expired []entity.UserInvitation
+}
+
+func (s stubExpirer) ExpireInvitationsByEmailTx(
+	context.Context, sqldb.Queryable, string,
+) ([]entity.UserInvitation, error) {
+	return s.expired, nil
+}
+
+func newCreatorTest(
+	t *testing.T, expired []entity.UserInvitation,
+) (invitation.Creator, *faker.TransactionManagerMock, *faker.UserNotifierMock) {
+	dbMock, err := faker.NewTransactionManagerMock()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = dbMock.Close() })
+
+	dbMock.SqlMock().ExpectBegin()
+	dbMock.SqlMock().ExpectCommit()
+	dbMock.On("BeginTx", mock.Anything, mock.Anything).
+		Run(dbMock.ReturnTx).
+		Once()
+
+	notifierMock := new(faker.UserNotifierMock)
+	creator := invitation.NewCreator(zap.NewNop(), dbMock, stubCreatorRepo{}, &invitation.UserInvitationMapper{},
+		stubPublisher{}, stubExpirer{expired: expired}, notifierMock)
+	return creator, dbMock, notifierMock
+}
+
+func expiredInvitation(email string, inviter string) entity.UserInvitation {
+	expired := faker.UserInvitationEntity()
+	expired.Email = email
+	expired.Status = string(invitation.StatusExpired)
+	expired.ExpiryTime = time.Now().Add(-time.Hour)
+	expired.Inviter = inviter
+	return expired
+}
+
+func TestCreator_CreateUserInvitation_NotifiesStatusAfterCommit(t *testing.T) {
+	inviterID := uuid.New()
+	expired := expiredInvitation("invitee@example.com", inviterID.String())
+	otherInviters := expiredInvitation(expired.Email, uuid.NewString())
+
+	creator, dbMock, notifierMock := newCreatorTest(t, []entity.UserInvitation{otherInviters, expired})
+	ctx := auth.WithPrincipal(t.Context(), auth.Principal{Subject: inviterID.String()})
+
+	notifierMock.On("NotifyUser", mock.Anything, inviterID, notification.EventUserInvitationStatusChanged, mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.NoError(t, dbMock.SqlMock().ExpectationsWereMet(), "notified before commit")
+		})
+
+	created, err := creator.CreateUserInvitation(ctx, invitation.UserInvitation{Email: expired.Email})
+	require.NoError(t, err)
+	assert.Equal(t, inviterID.String(), created.Inviter)
+
+	// Expired invitations of other inviters are not notified
+	notifierMock.AssertNumberOfCalls(t, "NotifyUser", 2)
+	expiredEvent := notifierMock.Calls[0].Arguments.Get(3).(invitation.StatusEvent)
+	assert.Equal(t, expired.ID, expiredEvent.ID)
+	assert.Equal(t, invitation.StatusExpired, expiredEvent.Status)
+	createdEvent := notifierMock.Calls[1].Arguments.Get(3).(invitation.StatusEvent)
+	assert.Equal(t, created.ID, createdEvent.ID)
+	assert.Equal(t, invitation.StatusPending, createdEvent.Status)
+}
+
+func TestCreator_CreateUserInvitation_PrincipalNotUser(t *testing.T) {
+	creator, _, notifierMock := newCreatorTest(t, nil)
+	ctx := auth.WithPrincipal(t.Context(), auth.Principal{Subject: "batch-jobs"})
+
+	created, err := creator.CreateUserInvitation(ctx, invitation.UserInvitation{Email: "invitee@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "batch-jobs", created.Inviter)
+
+	notifierMock.AssertNotCalled(t, "NotifyUser")
+}
@@ -1,71 +1,65 @@
 package invitation
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"go.uber.org/zap"
 )
 
 type CreatorHandler struct {
-	logger  *zap.Logger
-	creator Creator
-	mapper  Mapper
+	logger      *zap.Logger
+	errRegistry *httpx.ErrorRegistry
+	creator     Creator
+	mapper      Mapper
 }
 
 func NewCreatorHandler(
 	logger *zap.Logger,
+	errRegistry *httpx.ErrorRegistry,
 	creator Creator,
 	mapper Mapper,
 ) *CreatorHandler {
-	return &CreatorHandler{logger: logger, creator: creator, mapper: mapper}
+	return &CreatorHandler{logger: logger, errRegistry: errRegistry, creator: creator, mapper: mapper}
 }
 
 func (c *CreatorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.errRegistry.Handle(c.create)(w, r)
+}
+
+func (c *CreatorHandler) create(w http.ResponseWriter, r *http.Request) error {
 	defer func() { _ = r.Body.Close() }()
-	var cRequest CreateRequest
-	err := json.NewDecoder(r.Body).Decode(&cRequest)
+	cRequest, err := httpx.DecodeJSON[CreateRequest](w, r)
 	if err != nil {
-		c.logger.Warn("failed to decode create user invitation request", zap.Error(err))
-		httpx.BadRequestResponse("invalid request body",
-			map[string]string{"error": err.Error()},
-			w)
-		return
-	}
-
-	vErr := cRequest.Validate()
-	if vErr != nil {
-		c.logger.Warn("create user invitation request validation failed", zap.Any("email", cRequest.Email))
-		httpx.ValidationFailedResponse(vErr, w)
-		return
+		return err
 	}
 
 	input := c.mapper.CreateRequestToModel(cRequest)
 	_, err = c.creator.CreateUserInvitation(r.Context(), input)
-	if err != nil {
-		c.resolveError(err, w, cRequest)
-		return
+	if err != nil && !isConcealedError(r.Context(), c.logger, err) {
+		return fmt.Errorf("failed to insert user invitation: %w", err)
 	}
 
 	httpx.JsonResponse(http.StatusOK, CreateResponse{Email: cRequest.Email}, w)
+	return nil
 }
 
-func (c *CreatorHandler) resolveError(err error, w http.ResponseWriter, cr CreateRequest) {
+// isConcealedError errors responded as success to avoid disclosing existing invitations.
+func isConcealedError(ctx context.Context, logger *zap.Logger, err error) bool {
 	var uErr *errorx.UniqueViolationError
 	if errors.As(err, &uErr) {
-		c.logger.Warn("failed to create user invitation due to unique violation", zap.Error(uErr))
-		httpx.JsonResponse(http.StatusOK, CreateResponse{Email: cr.Email}, w)
-		return
+		logx.FromContext(ctx, logger).Warn("failed to create user invitation due to unique violation", zap.Error(uErr))
+		return true
 	}
 	var vErr *errorx.ValidationError
 	if errors.As(err, &vErr) {
-		c.logger.Warn("failed to create user invitation due to validation error", zap.Error(vErr))
-		httpx.JsonResponse(http.StatusOK, CreateResponse{Email: cr.Email}, w)
-		return
+		logx.FromContext(ctx, logger).Warn("failed to create user invitation due to validation error", zap.Error(vErr))
+		return true
 	}
-	c.logger.Error("failed to insert user invitation", zap.Error(err))
-	httpx.InternalServerErrorResponse("", w)
+	return false
 }
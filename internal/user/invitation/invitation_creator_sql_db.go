@@ -8,7 +8,9 @@ import (
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
 	"github.com/dyxj/bigbackend/pkg/audit"
 	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/go-jet/jet/v2/postgres"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
@@ -25,24 +27,31 @@ func NewCreatorSQLDB(logger *zap.Logger) *CreatorSQLDB {
 }
 
 // InsertUserInvitation inserts a new user invitation into the database.
+// Ignores and automatically sets TenantID from the tenant of ctx.
 func (c *CreatorSQLDB) InsertUserInvitation(
 	ctx context.Context,
 	tx sqldb.Executable,
 	input entity.UserInvitation,
 ) (entity.UserInvitation, error) {
-	c.logger.Debug("inserting user invitation", zap.Any("email", input.Email))
+	logx.FromContext(ctx, c.logger).Debug("inserting user invitation", zap.Any("email", input.Email))
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return entity.UserInvitation{}, err
+	}
+	input.TenantID = tenantID
 
 	inputAuditable := userInvitationAuditableEntity{E: &input}
 	audit.SetInsertFields(inputAuditable)
 
 	stmt := c.buildStatement(input)
 
-	_, err := stmt.ExecContext(ctx, tx)
+	_, err = stmt.ExecContext(ctx, tx)
 	if err != nil {
 		return entity.UserInvitation{}, c.resolveError(err)
 	}
 
-	c.logger.Debug("inserted user invitation", zap.Any("email", input.Email))
+	logx.FromContext(ctx, c.logger).Debug("inserted user invitation", zap.Any("email", input.Email))
 
 	return input, nil
 }
@@ -2,6 +2,7 @@ package invitation
 
 import (
 	"context"
+	"time"
 
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
@@ -12,9 +13,33 @@ type expirer struct {
 	updaterRepo UpdaterRepo
 }
 
-func (e *expirer) ExpireInvitationsByEmailTx(ctx context.Context, tx sqldb.Executable, email string) error {
-	//TODO implement me
-	panic("implement me")
+func NewExpirer(getterRepo GetterRepo, updaterRepo UpdaterRepo) Expirer {
+	return &expirer{getterRepo: getterRepo, updaterRepo: updaterRepo}
+}
+
+// ExpireInvitationsByEmailTx marks pending invitations of email past their expiry time as expired,
+// releasing the email for a new invitation, and returns them to be notified once committed.
+// Returns errorx.ErrConflict when one was updated concurrently.
+func (e *expirer) ExpireInvitationsByEmailTx(
+	ctx context.Context, tx sqldb.Queryable, email string,
+) ([]entity.UserInvitation, error) {
+	invitations, err := e.getterRepo.ListByEmailTx(ctx, tx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []entity.UserInvitation
+	for _, inv := range invitations {
+		if Status(inv.Status) == StatusPending && !inv.ExpiryTime.After(time.Now()) {
+			inv.Status = string(StatusExpired)
+			expired = append(expired, inv)
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	return e.updaterRepo.BatchUpdateInvitationTx(ctx, tx, expired)
 }
 
 type GetterRepo interface {
@@ -22,7 +47,7 @@ type GetterRepo interface {
 }
 
 type UpdaterRepo interface {
-	UpdateInvitationTx(
-		ctx context.Context, tx sqldb.Executable, invitation entity.UserInvitation,
-	) (entity.UserInvitation, error)
+	BatchUpdateInvitationTx(
+		ctx context.Context, tx sqldb.Queryable, inputs []entity.UserInvitation,
+	) ([]entity.UserInvitation, error)
 }
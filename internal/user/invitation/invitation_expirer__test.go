@@ -0,0 +1,74 @@
+package invitation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/user/invitation"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/test/faker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubGetterRepo struct {
+	invitations []entity.UserInvitation
+}
+
+func (s *stubGetterRepo) ListByEmailTx(context.Context, sqldb.Queryable, string) ([]entity.UserInvitation, error) {
+	return s.invitations, nil
+}
+
+type stubUpdaterRepo struct {
+	updated []entity.UserInvitation
+}
+
+func (s *stubUpdaterRepo) BatchUpdateInvitationTx(
+	_ context.Context, _ sqldb.Queryable, inputs []entity.UserInvitation,
+) ([]entity.UserInvitation, error) {
+	s.updated = inputs
+	return inputs, nil
+}
+
+func TestExpirer_ExpireInvitationsByEmailTx(t *testing.T) {
+	pendingExpired := faker.UserInvitationEntity()
+	pendingExpired.Status = string(invitation.StatusPending)
+	pendingExpired.ExpiryTime = time.Now().Add(-time.Hour)
+
+	pendingActive := faker.UserInvitationEntity()
+	pendingActive.Status = string(invitation.StatusPending)
+	pendingActive.ExpiryTime = time.Now().Add(time.Hour)
+
+	accepted := faker.UserInvitationEntity()
+	accepted.Status = string(invitation.StatusAccepted)
+	accepted.ExpiryTime = time.Now().Add(-time.Hour)
+
+	updaterRepo := &stubUpdaterRepo{}
+	expirer := invitation.NewExpirer(
+		&stubGetterRepo{invitations: []entity.UserInvitation{pendingExpired, pendingActive, accepted}},
+		updaterRepo,
+	)
+
+	expired, err := expirer.ExpireInvitationsByEmailTx(t.Context(), nil, pendingExpired.Email)
+	require.NoError(t, err)
+	assert.Equal(t, updaterRepo.updated, expired)
+
+	require.Len(t, updaterRepo.updated, 1)
+	assert.Equal(t, pendingExpired.ID, updaterRepo.updated[0].ID)
+	assert.Equal(t, string(invitation.StatusExpired), updaterRepo.updated[0].Status)
+}
+
+func TestExpirer_ExpireInvitationsByEmailTx_NoneExpired(t *testing.T) {
+	pending := faker.UserInvitationEntity()
+	pending.Status = string(invitation.StatusPending)
+
+	updaterRepo := &stubUpdaterRepo{}
+	expirer := invitation.NewExpirer(&stubGetterRepo{invitations: []entity.UserInvitation{pending}}, updaterRepo)
+
+	expired, err := expirer.ExpireInvitationsByEmailTx(t.Context(), nil, pending.Email)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	assert.Nil(t, updaterRepo.updated)
+}
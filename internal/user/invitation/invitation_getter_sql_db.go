@@ -5,6 +5,7 @@ import (
 
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
 	"github.com/go-jet/jet/v2/postgres"
 	"go.uber.org/zap"
@@ -23,7 +24,7 @@ func NewGetterSQLDB(logger *zap.Logger, sqlQ sqldb.Queryable) *GetterSQLDB {
 }
 
 func (g *GetterSQLDB) ListByEmailTx(ctx context.Context, tx sqldb.Queryable, email string) ([]entity.UserInvitation, error) {
-	g.logger.Debug("selecting invitations by email", zap.String("email", email))
+	logx.FromContext(ctx, g.logger).Debug("selecting invitations by email", zap.String("email", email))
 
 	stmt := table.UserInvitation.
 		SELECT(table.UserInvitation.AllColumns).
@@ -37,7 +38,7 @@ func (g *GetterSQLDB) ListByEmailTx(ctx context.Context, tx sqldb.Queryable, ema
 		return nil, err
 	}
 
-	g.logger.Debug("selected invitations by email", zap.String("email", email))
+	logx.FromContext(ctx, g.logger).Debug("selected invitations by email", zap.String("email", email))
 
 	return results, nil
 }
@@ -0,0 +1,39 @@
+package invitation
+
+import (
+	"context"
+	"fmt"
+
+	userv1 "github.com/dyxj/bigbackend/internal/grpcgen/bigbackend/user/v1"
+	"go.uber.org/zap"
+)
+
+// GRPCService serves invitations over gRPC with the same rules as CreatorHandler.
+// Errors are returned as is, to be mapped by grpcx.ErrorRegistry.
+type GRPCService struct {
+	userv1.UnimplementedUserInvitationServiceServer
+
+	logger  *zap.Logger
+	creator Creator
+	mapper  Mapper
+}
+
+func NewGRPCService(logger *zap.Logger, creator Creator, mapper Mapper) *GRPCService {
+	return &GRPCService{logger: logger, creator: creator, mapper: mapper}
+}
+
+func (s *GRPCService) CreateUserInvitation(
+	ctx context.Context, req *userv1.CreateUserInvitationRequest,
+) (*userv1.CreateUserInvitationResponse, error) {
+	cRequest := CreateRequest{Email: req.GetEmail()}
+	if vErr := cRequest.Validate(); vErr != nil {
+		return nil, vErr
+	}
+
+	_, err := s.creator.CreateUserInvitation(ctx, s.mapper.CreateRequestToModel(cRequest))
+	if err != nil && !isConcealedError(ctx, s.logger, err) {
+		return nil, fmt.Errorf("failed to insert user invitation: %w", err)
+	}
+
+	return &userv1.CreateUserInvitationResponse{Email: cRequest.Email}, nil
+}
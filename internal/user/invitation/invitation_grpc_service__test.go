@@ -0,0 +1,71 @@
+package invitation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	userv1 "github.com/dyxj/bigbackend/internal/grpcgen/bigbackend/user/v1"
+	"github.com/dyxj/bigbackend/internal/user/invitation"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type stubCreator struct {
+	err   error
+	calls int
+}
+
+func (s *stubCreator) CreateUserInvitation(
+	_ context.Context, input invitation.UserInvitation,
+) (invitation.UserInvitation, error) {
+	s.calls++
+	return input, s.err
+}
+
+func TestGRPCService_CreateUserInvitation(t *testing.T) {
+	errDB := errors.New("db down")
+
+	tests := []struct {
+		name    string
+		email   string
+		err     error
+		wantErr error
+		calls   int
+	}{
+		{name: "created", email: "a@b.co", calls: 1},
+		{name: "existing invitation concealed", email: "a@b.co", err: &errorx.UniqueViolationError{}, calls: 1},
+		{name: "unexpected error", email: "a@b.co", err: errDB, wantErr: errDB, calls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creator := &stubCreator{err: tt.err}
+			service := invitation.NewGRPCService(zap.NewNop(), creator, &invitation.UserInvitationMapper{})
+
+			resp, err := service.CreateUserInvitation(t.Context(), &userv1.CreateUserInvitationRequest{Email: tt.email})
+
+			assert.Equal(t, tt.calls, creator.calls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.email, resp.GetEmail())
+		})
+	}
+}
+
+func TestGRPCService_CreateUserInvitation_InvalidEmail(t *testing.T) {
+	creator := &stubCreator{}
+	service := invitation.NewGRPCService(zap.NewNop(), creator, &invitation.UserInvitationMapper{})
+
+	_, err := service.CreateUserInvitation(t.Context(), &userv1.CreateUserInvitationRequest{Email: "not-an-email"})
+
+	var vErr *errorx.ValidationError
+	require.ErrorAs(t, err, &vErr)
+	assert.Contains(t, vErr.Properties, "email")
+	assert.Zero(t, creator.calls)
+}
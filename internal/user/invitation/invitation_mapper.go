@@ -24,6 +24,8 @@ func (c *UserInvitationMapper) EntityToModel(source entity.UserInvitation) UserI
 	invitationUserInvitation.CreateTime = mapx.MapTime(source.CreateTime)
 	invitationUserInvitation.UpdateTime = mapx.MapTime(source.UpdateTime)
 	invitationUserInvitation.Version = source.Version
+	invitationUserInvitation.TenantID = mapx.MapUUID(source.TenantID)
+	invitationUserInvitation.Inviter = source.Inviter
 	return invitationUserInvitation
 }
 func (c *UserInvitationMapper) ModelToEntity(source UserInvitation) entity.UserInvitation {
@@ -36,5 +38,7 @@ func (c *UserInvitationMapper) ModelToEntity(source UserInvitation) entity.UserI
 	entityUserInvitation.CreateTime = mapx.MapTime(source.CreateTime)
 	entityUserInvitation.UpdateTime = mapx.MapTime(source.UpdateTime)
 	entityUserInvitation.Version = source.Version
+	entityUserInvitation.TenantID = mapx.MapUUID(source.TenantID)
+	entityUserInvitation.Inviter = source.Inviter
 	return entityUserInvitation
 }
@@ -1,28 +1,33 @@
 package invitation
 
 import (
+	"time"
+
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/validx"
+	"github.com/google/uuid"
 )
 
 type CreateRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"email"`
 }
 
 func (r CreateRequest) Validate() *errorx.ValidationError {
-	errors := make(map[string]string)
-
-	if !validx.IsEmail(r.Email) {
-		errors["email"] = "is not a valid email"
-	}
-
-	if len(errors) > 0 {
-		return &errorx.ValidationError{Properties: errors}
-	}
-
-	return nil
+	return validx.Struct(r)
 }
 
 type CreateResponse struct {
 	Email string `json:"email"`
 }
+
+// StatusEvent notified on status changes, without the token of the invitation.
+type StatusEvent struct {
+	ID         uuid.UUID `json:"id"`
+	Email      string    `json:"email"`
+	Status     Status    `json:"status"`
+	ExpiryTime time.Time `json:"expiryTime"`
+}
+
+func newStatusEvent(inv UserInvitation) StatusEvent {
+	return StatusEvent{ID: inv.ID, Email: inv.Email, Status: inv.Status(), ExpiryTime: inv.ExpiryTime}
+}
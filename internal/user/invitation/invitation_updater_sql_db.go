@@ -33,10 +33,11 @@ func (u *UpdaterSQLDB) UpdateInvitationTx(
 	iAuditable := userInvitationAuditableEntity{E: &input}
 	audit.SetUpdateFields(iAuditable)
 
+	// Invitations never move between tenants
 	stmt := table.UserInvitation.
 		UPDATE(
 			table.UserInvitation.AllColumns.
-				Except(table.UserInvitation.CreateTime),
+				Except(table.UserInvitation.CreateTime, table.UserInvitation.TenantID),
 		).
 		MODEL(input).
 		WHERE(postgres.AND(
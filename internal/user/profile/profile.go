@@ -6,18 +6,20 @@ import (
 
 	"cloud.google.com/go/civil"
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/validx"
 	"github.com/google/uuid"
 )
 
 type UserProfile struct {
 	ID          uuid.UUID  `json:"id"`
 	UserID      uuid.UUID  `json:"userId"`
-	FirstName   string     `json:"firstName"`
-	LastName    string     `json:"lastName"`
-	DateOfBirth civil.Date `json:"dateOfBirth"`
+	FirstName   string     `json:"firstName" validate:"required"`
+	LastName    string     `json:"lastName" validate:"required"`
+	DateOfBirth civil.Date `json:"dateOfBirth" validate:"past"`
 	CreateTime  time.Time  `json:"createTime"`
 	UpdateTime  time.Time  `json:"updateTime"`
 	Version     int32      `json:"version"`
+	TenantID    uuid.UUID  `json:"tenantId"`
 }
 
 func (u *UserProfile) Sanitize() {
@@ -35,11 +37,7 @@ func (u *UserProfile) IsValidForUpdate() bool {
 }
 
 func (u *UserProfile) isValid() bool {
-	return u.FirstName != "" &&
-		u.LastName != "" &&
-		u.DateOfBirth.IsValid() &&
-		!u.DateOfBirth.IsZero() &&
-		u.DateOfBirth.Before(civil.DateOf(time.Now()))
+	return validx.Struct(u) == nil
 }
 
 // userProfileAuditableEntity adapts entity.UserProfile to repo.Auditable.
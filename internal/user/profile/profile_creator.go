@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/webhook"
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
 	"go.uber.org/zap"
@@ -13,10 +14,11 @@ type creator struct {
 	logger      *zap.Logger
 	creatorRepo CreatorRepo
 	mapper      Mapper
+	publisher   EventPublisher
 }
 
-func NewCreator(logger *zap.Logger, creatorRepo CreatorRepo, mapper Mapper) Creator {
-	return &creator{logger: logger, creatorRepo: creatorRepo, mapper: mapper}
+func NewCreator(logger *zap.Logger, creatorRepo CreatorRepo, mapper Mapper, publisher EventPublisher) Creator {
+	return &creator{logger: logger, creatorRepo: creatorRepo, mapper: mapper, publisher: publisher}
 }
 
 func (c *creator) CreateUserProfileTx(ctx context.Context, tx sqldb.Executable, input UserProfile) (UserProfile, error) {
@@ -34,9 +36,21 @@ func (c *creator) CreateUserProfileTx(ctx context.Context, tx sqldb.Executable,
 		return UserProfile{}, err
 	}
 
-	return c.mapper.EntityToModel(createdEntity), nil
+	created := c.mapper.EntityToModel(createdEntity)
+
+	err = c.publisher.PublishTx(ctx, tx, webhook.EventUserProfileCreated, created)
+	if err != nil {
+		return UserProfile{}, err
+	}
+
+	return created, nil
 }
 
 type CreatorRepo interface {
 	InsertUserProfile(ctx context.Context, tx sqldb.Executable, input entity.UserProfile) (entity.UserProfile, error)
 }
+
+// EventPublisher publishes events in the transaction of the change, e.g. webhook.Publisher.
+type EventPublisher interface {
+	PublishTx(ctx context.Context, tx sqldb.Executable, eventType string, data any) error
+}
@@ -3,12 +3,14 @@ package profile_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/civil"
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
 	"github.com/dyxj/bigbackend/internal/user/profile"
+	"github.com/dyxj/bigbackend/internal/webhook"
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/test/faker"
@@ -37,12 +39,6 @@ func TestCreator_CreateUserProfileTx_Successfully(t *testing.T) {
 		}).
 		Once()
 
-	creator := profile.NewCreator(
-		logger,
-		mockRepo,
-		&profile.UserProfileMapper{},
-	)
-
 	input := faker.UserProfile()
 	input.FirstName = input.FirstName + "  "
 	input.LastName = "  " + input.LastName
@@ -50,9 +46,23 @@ func TestCreator_CreateUserProfileTx_Successfully(t *testing.T) {
 	inputSanitized := input
 	inputSanitized.Sanitize()
 
+	mockPublisher := new(faker.EventPublisherMock)
+	mockPublisher.
+		On("PublishTx", mock.Anything, mock.Anything, webhook.EventUserProfileCreated, inputSanitized).
+		Return(nil).
+		Once()
+
+	creator := profile.NewCreator(
+		logger,
+		mockRepo,
+		&profile.UserProfileMapper{},
+		mockPublisher,
+	)
+
 	result, err := creator.CreateUserProfileTx(context.Background(), &sql.Tx{}, input)
 
 	mockRepo.AssertNumberOfCalls(t, "InsertUserProfile", 1)
+	mockPublisher.AssertExpectations(t)
 	assert.NoError(t, err)
 	assert.EqualValues(t, inputSanitized, result)
 }
@@ -104,10 +114,13 @@ func TestCreator_CreateUserProfileTx_ValidationError(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo := new(faker.UserProfileCreatorRepoMock)
 
+			mockPublisher := new(faker.EventPublisherMock)
+
 			creator := profile.NewCreator(
 				logger,
 				mockRepo,
 				&profile.UserProfileMapper{},
+				mockPublisher,
 			)
 
 			input := faker.UserProfile()
@@ -116,6 +129,7 @@ func TestCreator_CreateUserProfileTx_ValidationError(t *testing.T) {
 			_, err = creator.CreateUserProfileTx(context.Background(), nil, input)
 
 			mockRepo.AssertNumberOfCalls(t, "InsertUserProfile", 0)
+			mockPublisher.AssertNumberOfCalls(t, "PublishTx", 0)
 			var expectedErr *errorx.ValidationError
 			assert.ErrorAs(t, err, &expectedErr)
 		})
@@ -133,11 +147,13 @@ func TestCreator_CreateUserProfileTx_InsertUserProfileError(t *testing.T) {
 		On("InsertUserProfile", mock.Anything, mock.Anything, mock.Anything).
 		Return(entity.UserProfile{}, &errorx.UniqueViolationError{}).
 		Once()
+	mockPublisher := new(faker.EventPublisherMock)
 
 	creator := profile.NewCreator(
 		logger,
 		mockRepo,
 		&profile.UserProfileMapper{},
+		mockPublisher,
 	)
 
 	input := faker.UserProfile()
@@ -145,7 +161,39 @@ func TestCreator_CreateUserProfileTx_InsertUserProfileError(t *testing.T) {
 	result, err := creator.CreateUserProfileTx(context.Background(), &sql.Tx{}, input)
 
 	mockRepo.AssertNumberOfCalls(t, "InsertUserProfile", 1)
+	mockPublisher.AssertNumberOfCalls(t, "PublishTx", 0)
 	var expectedErr *errorx.UniqueViolationError
 	assert.ErrorAs(t, err, &expectedErr)
 	assert.EqualValues(t, profile.UserProfile{}, result)
 }
+
+func TestCreator_CreateUserProfileTx_PublishError(t *testing.T) {
+	logger, err := logx.InitLogger()
+	if err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+
+	mockRepo := new(faker.UserProfileCreatorRepoMock)
+	mockRepo.
+		On("InsertUserProfile", mock.Anything, mock.Anything, mock.Anything).
+		Return(faker.UserProfileEntity(), nil).
+		Once()
+	publishErr := errors.New("fake publish error")
+	mockPublisher := new(faker.EventPublisherMock)
+	mockPublisher.
+		On("PublishTx", mock.Anything, mock.Anything, webhook.EventUserProfileCreated, mock.Anything).
+		Return(publishErr).
+		Once()
+
+	creator := profile.NewCreator(
+		logger,
+		mockRepo,
+		&profile.UserProfileMapper{},
+		mockPublisher,
+	)
+
+	result, err := creator.CreateUserProfileTx(context.Background(), &sql.Tx{}, faker.UserProfile())
+
+	assert.ErrorIs(t, err, publishErr)
+	assert.EqualValues(t, profile.UserProfile{}, result)
+}
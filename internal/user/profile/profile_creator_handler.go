@@ -2,14 +2,18 @@ package profile
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/auth"
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -17,55 +21,73 @@ type Creator interface {
 	CreateUserProfileTx(ctx context.Context, tx sqldb.Executable, input UserProfile) (UserProfile, error)
 }
 
-type CreatorHandler struct {
-	logger  *zap.Logger
-	tm      sqldb.TransactionManager
-	creator Creator
-	mapper  Mapper
+// Notifier notifies users of committed changes, e.g. notification.Notifier.
+type Notifier interface {
+	NotifyUser(ctx context.Context, userID uuid.UUID, eventType string, data any)
 }
 
-func NewCreatorHandler(
+// CreatorHandler responds with R, the response of an API version.
+type CreatorHandler[R any] struct {
+	logger      *zap.Logger
+	errRegistry *httpx.ErrorRegistry
+	authorizer  auth.Authorizer
+	tm          sqldb.TransactionManager
+	creator     Creator
+	notifier    Notifier
+	mapper      Mapper
+	toResponse  ResponseMapper[R]
+}
+
+func NewCreatorHandler[R any](
 	logger *zap.Logger,
+	errRegistry *httpx.ErrorRegistry,
+	authorizer auth.Authorizer,
 	tm sqldb.TransactionManager,
 	creator Creator,
+	notifier Notifier,
 	mapper Mapper,
-) *CreatorHandler {
-	return &CreatorHandler{logger: logger, tm: tm, creator: creator, mapper: mapper}
+	toResponse ResponseMapper[R],
+) *CreatorHandler[R] {
+	return &CreatorHandler[R]{
+		logger:      logger,
+		errRegistry: errRegistry,
+		authorizer:  authorizer,
+		tm:          tm,
+		creator:     creator,
+		notifier:    notifier,
+		mapper:      mapper,
+		toResponse:  toResponse,
+	}
+}
+
+func (c *CreatorHandler[R]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.errRegistry.Handle(c.create)(w, r)
 }
 
-func (c *CreatorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (c *CreatorHandler[R]) create(w http.ResponseWriter, r *http.Request) error {
 	defer func() { _ = r.Body.Close() }()
-	var cRequest CreateRequest
-	err := json.NewDecoder(r.Body).Decode(&cRequest)
+
+	userId := chi.URLParam(r, "id")
+	err := c.authorizer.AuthorizeSubject(r.Context(), userId)
 	if err != nil {
-		c.logger.Warn("failed to decode create user profile request", zap.Error(err))
-		httpx.BadRequestResponse("invalid request body",
-			map[string]string{"error": err.Error()},
-			w)
-		return
+		return err
 	}
 
-	vErr := cRequest.Validate()
-	if vErr != nil {
-		c.logger.Warn("create user profile request validation failed", zap.Any("userId", cRequest.UserID))
-		httpx.ValidationFailedResponse(vErr, w)
-		return
+	cRequest, err := httpx.DecodeJSON[CreateRequest](w, r)
+	if err != nil {
+		return err
 	}
 
-	userId := chi.URLParam(r, "id")
 	if userId != cRequest.UserID.String() {
-		c.logger.Warn("user ID in URL does not match user ID in request body",
+		logx.FromContext(r.Context(), c.logger).Warn("user ID in URL does not match user ID in request body",
 			zap.String("urlUserId", userId),
 			zap.String("bodyUserId", cRequest.UserID.String()))
-		httpx.BadRequestResponse("user ID in URL does not match user ID in request body", nil, w)
-		return
+		return &errorx.BadRequestError{Message: "user ID in URL does not match user ID in request body"}
 	}
 
 	tx, err := c.tm.BeginTx(r.Context(), nil)
 	if err != nil {
-		c.logger.Error("failed to begin transaction", zap.Error(err))
-		httpx.InternalServerErrorResponse("", w)
-		return
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer sqldb.TxRollback(tx, c.logger)
 
@@ -73,36 +95,22 @@ func (c *CreatorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	created, err := c.creator.CreateUserProfileTx(r.Context(), tx, input)
 	if err != nil {
-		c.resolveError(err, w)
-		return
+		var uErr *errorx.UniqueViolationError
+		if errors.As(err, &uErr) {
+			return httpx.WithMessage(err, "user profile already exists")
+		}
+		return fmt.Errorf("failed to insert user profile: %w", err)
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		c.logger.Error("failed to commit transaction", zap.Error(err))
-		httpx.InternalServerErrorResponse("", w)
-		return
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	response := c.mapper.ModelToResponse(created)
+	response := c.toResponse(created)
 
-	httpx.JsonResponse(http.StatusCreated, response, w)
-}
+	c.notifier.NotifyUser(r.Context(), created.UserID, webhook.EventUserProfileCreated, response)
 
-func (c *CreatorHandler) resolveError(err error, w http.ResponseWriter) {
-	var uErr *errorx.UniqueViolationError
-	if errors.As(err, &uErr) {
-		c.logger.Warn("failed to insert user profile due to unique key violation", zap.Error(uErr))
-		httpx.ConflictResponse("user profile already exists", nil, w)
-		return
-	}
-	var vErr *errorx.ValidationError
-	if errors.As(err, &vErr) {
-		c.logger.Warn("failed to insert user profile due to validation error", zap.Error(vErr))
-		httpx.ValidationFailedResponse(vErr, w)
-		return
-
-	}
-	c.logger.Error("failed to insert user profile due to internal server error", zap.Error(err))
-	httpx.InternalServerErrorResponse("", w)
+	httpx.JsonResponse(http.StatusCreated, response, w)
+	return nil
 }
@@ -13,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/dyxj/bigbackend/internal/user/profile"
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/auth"
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/httpx"
 	"github.com/dyxj/bigbackend/pkg/logx"
@@ -44,9 +46,13 @@ func TestCreatorHandler_BeginTxError(t *testing.T) {
 
 	handler := profile.NewCreatorHandler(
 		logger,
+		httpx.NewErrorRegistry(logger),
+		auth.AllowAll(),
 		dbMock,
 		creatorMock,
+		new(faker.UserNotifierMock),
 		mapper,
+		mapper.ModelToResponse,
 	)
 
 	payload := faker.UserProfileCreateRequest()
@@ -61,6 +67,7 @@ func TestCreatorHandler_BeginTxError(t *testing.T) {
 		"/user/{id}/profile",
 		&buf,
 	)
+	request.Header.Set("Content-Type", "application/json")
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add("id", payload.UserID.String())
 	request = request.WithContext(
@@ -123,9 +130,13 @@ func TestCreatorHandler_CreatorValidationError(t *testing.T) {
 
 	handler := profile.NewCreatorHandler(
 		logger,
+		httpx.NewErrorRegistry(logger),
+		auth.AllowAll(),
 		dbMock,
 		creatorMock,
+		new(faker.UserNotifierMock),
 		mapper,
+		mapper.ModelToResponse,
 	)
 
 	payload := faker.UserProfileCreateRequest()
@@ -140,6 +151,7 @@ func TestCreatorHandler_CreatorValidationError(t *testing.T) {
 		"/user/{id}/profile",
 		&buf,
 	)
+	request.Header.Set("Content-Type", "application/json")
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add("id", payload.UserID.String())
 	request = request.WithContext(
@@ -201,9 +213,13 @@ func TestCreatorHandler_UnexpectedCreatorError(t *testing.T) {
 
 	handler := profile.NewCreatorHandler(
 		logger,
+		httpx.NewErrorRegistry(logger),
+		auth.AllowAll(),
 		dbMock,
 		creatorMock,
+		new(faker.UserNotifierMock),
 		mapper,
+		mapper.ModelToResponse,
 	)
 
 	payload := faker.UserProfileCreateRequest()
@@ -218,6 +234,7 @@ func TestCreatorHandler_UnexpectedCreatorError(t *testing.T) {
 		"/user/{id}/profile",
 		&buf,
 	)
+	request.Header.Set("Content-Type", "application/json")
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add("id", payload.UserID.String())
 	request = request.WithContext(
@@ -277,13 +294,18 @@ func TestCreatorHandler_TxCommitError(t *testing.T) {
 	}(dbMock)
 
 	creatorMock := new(faker.UserProfileCreatorMock)
+	notifierMock := new(faker.UserNotifierMock)
 	mapper := new(profile.UserProfileMapper)
 
 	handler := profile.NewCreatorHandler(
 		logger,
+		httpx.NewErrorRegistry(logger),
+		auth.AllowAll(),
 		dbMock,
 		creatorMock,
+		notifierMock,
 		mapper,
+		mapper.ModelToResponse,
 	)
 
 	payload := faker.UserProfileCreateRequest()
@@ -298,6 +320,7 @@ func TestCreatorHandler_TxCommitError(t *testing.T) {
 		"/user/{id}/profile",
 		&buf,
 	)
+	request.Header.Set("Content-Type", "application/json")
 	rctx := chi.NewRouteContext()
 	rctx.URLParams.Add("id", payload.UserID.String())
 	request = request.WithContext(
@@ -344,4 +367,82 @@ func TestCreatorHandler_TxCommitError(t *testing.T) {
 	dbMock.AssertNumberOfCalls(t, "BeginTx", 1)
 	assert.NoError(t, dbMock.SqlMock().ExpectationsWereMet())
 	creatorMock.AssertNumberOfCalls(t, "CreateUserProfileTx", 1)
+	notifierMock.AssertNotCalled(t, "NotifyUser")
+}
+
+func TestCreatorHandler_NotifiesUserAfterCommit(t *testing.T) {
+	logger, err := logx.InitLogger()
+	if err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+
+	dbMock, err := faker.NewTransactionManagerMock()
+	if err != nil {
+		t.Fatalf("failed to create transaction manager mock: %v", err)
+	}
+	defer func(dbMock *faker.TransactionManagerMock) {
+		err := dbMock.Close()
+		if err != nil {
+			log.Printf("failed to close db mock: %v", err)
+		}
+	}(dbMock)
+
+	creatorMock := new(faker.UserProfileCreatorMock)
+	notifierMock := new(faker.UserNotifierMock)
+	mapper := new(profile.UserProfileMapper)
+
+	handler := profile.NewCreatorHandler(
+		logger,
+		httpx.NewErrorRegistry(logger),
+		auth.AllowAll(),
+		dbMock,
+		creatorMock,
+		notifierMock,
+		mapper,
+		mapper.ModelToResponse,
+	)
+
+	payload := faker.UserProfileCreateRequest()
+	var buf bytes.Buffer
+	err = json.NewEncoder(&buf).Encode(&payload)
+	if err != nil {
+		t.Fatalf("failed to encode payload: %v", err)
+	}
+
+	request := httptest.NewRequest(
+		"POST",
+		"/user/{id}/profile",
+		&buf,
+	)
+	request.Header.Set("Content-Type", "application/json")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", payload.UserID.String())
+	request = request.WithContext(
+		context.WithValue(request.Context(), chi.RouteCtxKey, rctx),
+	)
+
+	rr := httptest.NewRecorder()
+
+	dbMock.SqlMock().ExpectBegin()
+	dbMock.SqlMock().ExpectCommit()
+	dbMock.On("BeginTx", mock.Anything, mock.Anything).
+		Run(dbMock.ReturnTx).
+		Once()
+
+	creatorMock.On("CreateUserProfileTx", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			input := args.Get(2).(profile.UserProfile)
+			creatorMock.ExpectedCalls[0].ReturnArguments = mock.Arguments{input, nil}
+		})
+	notifierMock.On("NotifyUser", mock.Anything, payload.UserID, webhook.EventUserProfileCreated, mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.NoError(t, dbMock.SqlMock().ExpectationsWereMet(), "notified before commit")
+		})
+
+	handler.ServeHTTP(rr, request)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	notifierMock.AssertNumberOfCalls(t, "NotifyUser", 1)
+	response := notifierMock.Calls[0].Arguments.Get(3).(profile.Response)
+	assert.Equal(t, payload.UserID, response.UserID)
 }
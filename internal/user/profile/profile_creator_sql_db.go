@@ -8,7 +8,9 @@ import (
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
 	"github.com/dyxj/bigbackend/pkg/audit"
 	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/go-jet/jet/v2/postgres"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
@@ -25,25 +27,32 @@ func NewCreatorSQLDB(logger *zap.Logger) *CreatorSQLDB {
 }
 
 // InsertUserProfile inserts a new user profile into the database.
-// Ignores and automatically sets ID, CreateTime, UpdateTime, Version fields from input.
+// Ignores and automatically sets ID, CreateTime, UpdateTime, Version fields from input,
+// and TenantID from the tenant of ctx.
 func (c *CreatorSQLDB) InsertUserProfile(
 	ctx context.Context,
 	tx sqldb.Executable,
 	input entity.UserProfile,
 ) (entity.UserProfile, error) {
-	c.logger.Debug("inserting user profile", zap.Any("userId", input.UserID))
+	logx.FromContext(ctx, c.logger).Debug("inserting user profile", zap.Any("userId", input.UserID))
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return entity.UserProfile{}, err
+	}
+	input.TenantID = tenantID
 
 	inputAuditable := userProfileAuditableEntity{E: &input}
 	audit.SetInsertFields(inputAuditable)
 
 	stmt := c.buildStatement(input)
 
-	_, err := stmt.ExecContext(ctx, tx)
+	_, err = stmt.ExecContext(ctx, tx)
 	if err != nil {
 		return entity.UserProfile{}, c.resolveError(err, input)
 	}
 
-	c.logger.Debug("inserted user profile", zap.Any("userId", input.UserID))
+	logx.FromContext(ctx, c.logger).Debug("inserted user profile", zap.Any("userId", input.UserID))
 
 	return input, nil
 }
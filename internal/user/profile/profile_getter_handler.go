@@ -2,9 +2,9 @@ package profile
 
 import (
 	"context"
-	"errors"
 	"net/http"
 
+	"github.com/dyxj/bigbackend/pkg/auth"
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/httpx"
 	"github.com/go-chi/chi/v5"
@@ -12,45 +12,54 @@ import (
 	"go.uber.org/zap"
 )
 
-type GetterHandler struct {
-	logger *zap.Logger
-	getter Getter
-	mapper Mapper
+// GetterHandler responds with R, the response of an API version.
+type GetterHandler[R any] struct {
+	logger      *zap.Logger
+	errRegistry *httpx.ErrorRegistry
+	authorizer  auth.Authorizer
+	getter      Getter
+	toResponse  ResponseMapper[R]
 }
 
-func NewGetterHandler(logger *zap.Logger, getter Getter, mapper Mapper) *GetterHandler {
-	return &GetterHandler{logger: logger, getter: getter, mapper: mapper}
+func NewGetterHandler[R any](
+	logger *zap.Logger,
+	errRegistry *httpx.ErrorRegistry,
+	authorizer auth.Authorizer,
+	getter Getter,
+	toResponse ResponseMapper[R],
+) *GetterHandler[R] {
+	return &GetterHandler[R]{logger: logger, errRegistry: errRegistry, authorizer: authorizer, getter: getter, toResponse: toResponse}
 }
 
-func (g *GetterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (g *GetterHandler[R]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.errRegistry.Handle(g.get)(w, r)
+}
+
+func (g *GetterHandler[R]) get(w http.ResponseWriter, r *http.Request) error {
 	idStr := chi.URLParam(r, "id")
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		g.logger.Warn("failed to parse id", zap.String("id", idStr), zap.Error(err))
-		httpx.BadRequestResponse("invalid id",
-			map[string]string{"error": err.Error()},
-			w)
-		return
+		return &errorx.BadRequestError{
+			Message:    "invalid id",
+			Properties: map[string]string{"error": err.Error()},
+		}
+	}
+
+	err = g.authorizer.AuthorizeSubject(r.Context(), id.String())
+	if err != nil {
+		return err
 	}
 
 	profile, err := g.getter.GetUserProfileByUserID(r.Context(), id)
 	if err != nil {
-		g.resolveError(err, w)
-		return
+		return err
 	}
 
-	resp := g.mapper.ModelToResponse(profile)
+	resp := g.toResponse(profile)
 
 	httpx.JsonResponse(http.StatusOK, resp, w)
-}
-
-func (g *GetterHandler) resolveError(err error, w http.ResponseWriter) {
-	if errors.Is(err, errorx.ErrNotFound) {
-		httpx.NotFoundResponse(w)
-		return
-	}
-	httpx.InternalServerErrorResponse("", w)
+	return nil
 }
 
 type Getter interface {
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/dyxj/bigbackend/internal/user/profile"
+	"github.com/dyxj/bigbackend/pkg/auth"
 	"github.com/dyxj/bigbackend/pkg/httpx"
 	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/test/faker"
@@ -39,7 +40,7 @@ func TestGetterHandler_InternalServerError(t *testing.T) {
 
 	mapper := new(profile.UserProfileMapper)
 	getterMock := new(faker.UserProfileGetterMock)
-	getterHandler := profile.NewGetterHandler(logger, getterMock, mapper)
+	getterHandler := profile.NewGetterHandler(logger, httpx.NewErrorRegistry(logger), auth.AllowAll(), getterMock, mapper.ModelToResponse)
 
 	userId := uuid.New()
 
@@ -85,3 +86,55 @@ func TestGetterHandler_InternalServerError(t *testing.T) {
 	assert.Equal(t, expectedResultPayload, resultPayload)
 	getterMock.AssertNumberOfCalls(t, "GetUserProfileByUserID", 1)
 }
+
+func TestGetterHandler_Forbidden(t *testing.T) {
+	logger, err := logx.InitLogger()
+	if err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+
+	mapper := new(profile.UserProfileMapper)
+	getterMock := new(faker.UserProfileGetterMock)
+	getterHandler := profile.NewGetterHandler(
+		logger,
+		httpx.NewErrorRegistry(logger),
+		auth.NewSubjectAuthorizer(auth.DefaultAdminScope),
+		getterMock,
+		mapper.ModelToResponse,
+	)
+
+	userId := uuid.New()
+
+	request := httptest.NewRequest(
+		"GET",
+		"/user/{id}/profile",
+		nil,
+	)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", userId.String())
+	ctx := context.WithValue(request.Context(), chi.RouteCtxKey, rctx)
+	ctx = auth.WithPrincipal(ctx, auth.Principal{Subject: uuid.NewString()})
+	request = request.WithContext(ctx)
+
+	rr := httptest.NewRecorder()
+
+	getterHandler.ServeHTTP(rr, request)
+
+	result := rr.Result()
+
+	var resultPayload httpx.ErrorResponse
+	err = json.NewDecoder(result.Body).Decode(&resultPayload)
+	if err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("failed to close response body: %v", err)
+		}
+	}(result.Body)
+
+	assert.Equal(t, http.StatusForbidden, result.StatusCode)
+	assert.Equal(t, httpx.CodeForbidden, resultPayload.Code)
+	getterMock.AssertNotCalled(t, "GetUserProfileByUserID", mock.Anything, mock.Anything)
+}
@@ -2,11 +2,14 @@ package profile
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
 	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
 	"github.com/go-jet/jet/v2/postgres"
 	"github.com/go-jet/jet/v2/qrm"
@@ -16,13 +19,14 @@ import (
 
 type GetterSQLDB struct {
 	logger *zap.Logger
-	sqlQ   sqldb.Queryable
+	tm     sqldb.TransactionManager
 }
 
-func NewGetterSQLDB(logger *zap.Logger, sqlQ sqldb.Queryable) *GetterSQLDB {
+// NewGetterSQLDB queries within transactions of tm, e.g. tenant.TxManager scoping reads to the tenant of the request.
+func NewGetterSQLDB(logger *zap.Logger, tm sqldb.TransactionManager) *GetterSQLDB {
 	return &GetterSQLDB{
 		logger: logger,
-		sqlQ:   sqlQ,
+		tm:     tm,
 	}
 }
 
@@ -31,17 +35,24 @@ func (g *GetterSQLDB) FindUserProfileByUserID(
 	ctx context.Context,
 	userID uuid.UUID,
 ) (entity.UserProfile, error) {
-	g.logger.Debug("get user profile", zap.Any("userId", userID))
+	logx.FromContext(ctx, g.logger).Debug("get user profile", zap.Any("userId", userID))
 
 	stmt := g.buildStatement(userID)
 
+	tx, err := g.tm.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return entity.UserProfile{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Read only, rolled back rather than committed
+	defer sqldb.TxRollback(tx, g.logger)
+
 	var result entity.UserProfile
-	err := stmt.QueryContext(ctx, g.sqlQ, &result)
+	err = stmt.QueryContext(ctx, tx, &result)
 	if err != nil {
 		return entity.UserProfile{}, g.resolveError(err)
 	}
 
-	g.logger.Debug("found user profile", zap.Any("userId", userID))
+	logx.FromContext(ctx, g.logger).Debug("found user profile", zap.Any("userId", userID))
 
 	return result, nil
 }
@@ -0,0 +1,160 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/civil"
+	userv1 "github.com/dyxj/bigbackend/internal/grpcgen/bigbackend/user/v1"
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCService serves profiles over gRPC with the same rules as GetterHandler and CreatorHandler.
+// Errors are returned as is, to be mapped by grpcx.ErrorRegistry.
+type GRPCService struct {
+	userv1.UnimplementedUserProfileServiceServer
+
+	logger            *zap.Logger
+	getterAuthorizer  auth.Authorizer
+	creatorAuthorizer auth.Authorizer
+	tm                sqldb.TransactionManager
+	getter            Getter
+	creator           Creator
+	notifier          Notifier
+	mapper            Mapper
+}
+
+func NewGRPCService(
+	logger *zap.Logger,
+	getterAuthorizer auth.Authorizer,
+	creatorAuthorizer auth.Authorizer,
+	tm sqldb.TransactionManager,
+	getter Getter,
+	creator Creator,
+	notifier Notifier,
+	mapper Mapper,
+) *GRPCService {
+	return &GRPCService{
+		logger:            logger,
+		getterAuthorizer:  getterAuthorizer,
+		creatorAuthorizer: creatorAuthorizer,
+		tm:                tm,
+		getter:            getter,
+		creator:           creator,
+		notifier:          notifier,
+		mapper:            mapper,
+	}
+}
+
+func (s *GRPCService) GetUserProfile(
+	ctx context.Context, req *userv1.GetUserProfileRequest,
+) (*userv1.GetUserProfileResponse, error) {
+	id, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, &errorx.BadRequestError{
+			Message:    "invalid id",
+			Properties: map[string]string{"error": err.Error()},
+		}
+	}
+
+	err = s.getterAuthorizer.AuthorizeSubject(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.getter.GetUserProfileByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.GetUserProfileResponse{UserProfile: modelToProto(profile)}, nil
+}
+
+func (s *GRPCService) CreateUserProfile(
+	ctx context.Context, req *userv1.CreateUserProfileRequest,
+) (*userv1.CreateUserProfileResponse, error) {
+	err := s.creatorAuthorizer.AuthorizeSubject(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	cRequest, err := createRequestFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.tm.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqldb.TxRollback(tx, s.logger)
+
+	created, err := s.creator.CreateUserProfileTx(ctx, tx, s.mapper.CreateRequestToModel(cRequest))
+	if err != nil {
+		var uErr *errorx.UniqueViolationError
+		if errors.As(err, &uErr) {
+			return nil, grpcx.WithMessage(err, "user profile already exists")
+		}
+		return nil, fmt.Errorf("failed to insert user profile: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Same payload as the HTTP API, streamed clients do not depend on the API used to make the change
+	s.notifier.NotifyUser(ctx, created.UserID, webhook.EventUserProfileCreated, s.mapper.ModelToResponse(created))
+
+	return &userv1.CreateUserProfileResponse{UserProfile: modelToProto(created)}, nil
+}
+
+// createRequestFromProto parses and validates req, errors are keyed by the JSON field names of CreateRequest.
+func createRequestFromProto(req *userv1.CreateUserProfileRequest) (CreateRequest, error) {
+	cRequest := CreateRequest{
+		FirstName: req.GetFirstName(),
+		LastName:  req.GetLastName(),
+	}
+
+	// Empty values are left as zero values, reported by Validate
+	if req.GetUserId() != "" {
+		userID, err := uuid.Parse(req.GetUserId())
+		if err != nil {
+			return CreateRequest{}, &errorx.ValidationError{Properties: map[string]string{"userId": "is invalid"}}
+		}
+		cRequest.UserID = userID
+	}
+	if req.GetDateOfBirth() != "" {
+		dob, err := civil.ParseDate(req.GetDateOfBirth())
+		if err != nil {
+			return CreateRequest{}, &errorx.ValidationError{Properties: map[string]string{"dateOfBirth": "is invalid"}}
+		}
+		cRequest.DateOfBirth = dob
+	}
+
+	if vErr := cRequest.Validate(); vErr != nil {
+		return CreateRequest{}, vErr
+	}
+	return cRequest, nil
+}
+
+func modelToProto(source UserProfile) *userv1.UserProfile {
+	return &userv1.UserProfile{
+		Id:          source.ID.String(),
+		UserId:      source.UserID.String(),
+		FirstName:   source.FirstName,
+		LastName:    source.LastName,
+		DateOfBirth: source.DateOfBirth.String(),
+		CreateTime:  timestamppb.New(source.CreateTime),
+		UpdateTime:  timestamppb.New(source.UpdateTime),
+		Version:     source.Version,
+	}
+}
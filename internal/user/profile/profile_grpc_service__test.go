@@ -0,0 +1,175 @@
+package profile_test
+
+import (
+	"testing"
+
+	userv1 "github.com/dyxj/bigbackend/internal/grpcgen/bigbackend/user/v1"
+	"github.com/dyxj/bigbackend/internal/user/profile"
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"github.com/dyxj/bigbackend/test/faker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+func newGRPCService(
+	t *testing.T, getter profile.Getter, creator profile.Creator, notifier profile.Notifier,
+) (*profile.GRPCService, *faker.TransactionManagerMock) {
+	dbMock, err := faker.NewTransactionManagerMock()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = dbMock.Close() })
+
+	return profile.NewGRPCService(
+		zap.NewNop(),
+		auth.AllowAll(),
+		auth.AllowAll(),
+		dbMock,
+		getter,
+		creator,
+		notifier,
+		new(profile.UserProfileMapper),
+	), dbMock
+}
+
+func TestGRPCService_GetUserProfile(t *testing.T) {
+	expected := faker.UserProfile()
+
+	getterMock := new(faker.UserProfileGetterMock)
+	getterMock.On("GetUserProfileByUserID", mock.Anything, expected.UserID).Return(expected, nil)
+
+	service, _ := newGRPCService(t, getterMock, nil, nil)
+
+	resp, err := service.GetUserProfile(t.Context(), &userv1.GetUserProfileRequest{UserId: expected.UserID.String()})
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.ID.String(), resp.GetUserProfile().GetId())
+	assert.Equal(t, expected.FirstName, resp.GetUserProfile().GetFirstName())
+	assert.Equal(t, expected.DateOfBirth.String(), resp.GetUserProfile().GetDateOfBirth())
+	assert.True(t, expected.CreateTime.Equal(resp.GetUserProfile().GetCreateTime().AsTime()))
+}
+
+func TestGRPCService_GetUserProfile_InvalidID(t *testing.T) {
+	getterMock := new(faker.UserProfileGetterMock)
+	service, _ := newGRPCService(t, getterMock, nil, nil)
+
+	_, err := service.GetUserProfile(t.Context(), &userv1.GetUserProfileRequest{UserId: "not-a-uuid"})
+
+	var bErr *errorx.BadRequestError
+	assert.ErrorAs(t, err, &bErr)
+	getterMock.AssertNotCalled(t, "GetUserProfileByUserID")
+}
+
+func TestGRPCService_CreateUserProfile(t *testing.T) {
+	created := faker.UserProfile()
+
+	creatorMock := new(faker.UserProfileCreatorMock)
+	creatorMock.On("CreateUserProfileTx", mock.Anything, mock.Anything, mock.Anything).Return(created, nil)
+	notifierMock := new(faker.UserNotifierMock)
+	notifierMock.On("NotifyUser", mock.Anything, created.UserID, webhook.EventUserProfileCreated,
+		mock.AnythingOfType("profile.Response"))
+
+	service, dbMock := newGRPCService(t, nil, creatorMock, notifierMock)
+	dbMock.SqlMock().ExpectBegin()
+	dbMock.SqlMock().ExpectCommit()
+	dbMock.On("BeginTx", mock.Anything, mock.Anything).Run(dbMock.ReturnTx)
+
+	resp, err := service.CreateUserProfile(t.Context(), &userv1.CreateUserProfileRequest{
+		UserId:      created.UserID.String(),
+		FirstName:   created.FirstName,
+		LastName:    created.LastName,
+		DateOfBirth: created.DateOfBirth.String(),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, created.ID.String(), resp.GetUserProfile().GetId())
+	assert.NoError(t, dbMock.SqlMock().ExpectationsWereMet())
+	notifierMock.AssertExpectations(t)
+}
+
+func TestGRPCService_CreateUserProfile_ValidationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *userv1.CreateUserProfileRequest
+		property string
+	}{
+		{
+			name:     "invalid user id",
+			req:      &userv1.CreateUserProfileRequest{UserId: "not-a-uuid", FirstName: "a", LastName: "b"},
+			property: "userId",
+		},
+		{
+			name: "invalid date of birth",
+			req: &userv1.CreateUserProfileRequest{UserId: "8f1c7f36-1b7c-4a55-9b3a-2f0b8f0e6d11", FirstName: "a",
+				LastName: "b", DateOfBirth: "01/02/1990"},
+			property: "dateOfBirth",
+		},
+		{
+			name:     "missing last name",
+			req:      &userv1.CreateUserProfileRequest{UserId: "8f1c7f36-1b7c-4a55-9b3a-2f0b8f0e6d11", FirstName: "a"},
+			property: "lastName",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creatorMock := new(faker.UserProfileCreatorMock)
+			service, dbMock := newGRPCService(t, nil, creatorMock, nil)
+
+			_, err := service.CreateUserProfile(t.Context(), tt.req)
+
+			var vErr *errorx.ValidationError
+			require.ErrorAs(t, err, &vErr)
+			assert.Contains(t, vErr.Properties, tt.property)
+			dbMock.AssertNotCalled(t, "BeginTx")
+			creatorMock.AssertNotCalled(t, "CreateUserProfileTx")
+		})
+	}
+}
+
+func TestGRPCService_CreateUserProfile_AlreadyExists(t *testing.T) {
+	req := faker.UserProfileCreateRequest()
+
+	creatorMock := new(faker.UserProfileCreatorMock)
+	creatorMock.On("CreateUserProfileTx", mock.Anything, mock.Anything, mock.Anything).
+		Return(profile.UserProfile{}, &errorx.UniqueViolationError{})
+	notifierMock := new(faker.UserNotifierMock)
+
+	service, dbMock := newGRPCService(t, nil, creatorMock, notifierMock)
+	dbMock.SqlMock().ExpectBegin()
+	dbMock.SqlMock().ExpectRollback()
+	dbMock.On("BeginTx", mock.Anything, mock.Anything).Run(dbMock.ReturnTx)
+
+	_, err := service.CreateUserProfile(t.Context(), &userv1.CreateUserProfileRequest{
+		UserId:      req.UserID.String(),
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		DateOfBirth: req.DateOfBirth.String(),
+	})
+
+	mapping := grpcx.NewErrorRegistry(zap.NewNop()).Resolve(err)
+	assert.Equal(t, codes.AlreadyExists, mapping.Code)
+	assert.Equal(t, "user profile already exists", mapping.Message)
+	assert.NoError(t, dbMock.SqlMock().ExpectationsWereMet())
+	notifierMock.AssertNotCalled(t, "NotifyUser")
+}
+
+func TestGRPCService_CreateUserProfile_Forbidden(t *testing.T) {
+	creatorMock := new(faker.UserProfileCreatorMock)
+	dbMock, err := faker.NewTransactionManagerMock()
+	require.NoError(t, err)
+	defer func() { _ = dbMock.Close() }()
+
+	service := profile.NewGRPCService(zap.NewNop(), auth.AllowAll(), auth.NewSubjectAuthorizer(""),
+		dbMock, nil, creatorMock, nil, new(profile.UserProfileMapper))
+
+	ctx := auth.WithPrincipal(t.Context(), auth.Principal{Subject: "user-1"})
+	_, err = service.CreateUserProfile(ctx, &userv1.CreateUserProfileRequest{UserId: "user-2"})
+
+	assert.ErrorIs(t, err, errorx.ErrForbidden)
+	creatorMock.AssertNotCalled(t, "CreateUserProfileTx")
+}
@@ -27,6 +27,7 @@ func (c *UserProfileMapper) EntityToModel(source entity.UserProfile) UserProfile
 	profileUserProfile.CreateTime = mapx.MapTime(source.CreateTime)
 	profileUserProfile.UpdateTime = mapx.MapTime(source.UpdateTime)
 	profileUserProfile.Version = source.Version
+	profileUserProfile.TenantID = mapx.MapUUID(source.TenantID)
 	return profileUserProfile
 }
 func (c *UserProfileMapper) ModelToEntity(source UserProfile) entity.UserProfile {
@@ -39,6 +40,7 @@ func (c *UserProfileMapper) ModelToEntity(source UserProfile) entity.UserProfile
 	entityUserProfile.CreateTime = mapx.MapTime(source.CreateTime)
 	entityUserProfile.UpdateTime = mapx.MapTime(source.UpdateTime)
 	entityUserProfile.Version = source.Version
+	entityUserProfile.TenantID = mapx.MapUUID(source.TenantID)
 	return entityUserProfile
 }
 func (c *UserProfileMapper) ModelToResponse(source UserProfile) Response {
@@ -37,6 +37,7 @@ func TestUserProfileMapper_EntityToModel(t *testing.T) {
 	assert.Equal(t, entity.CreateTime, model.CreateTime)
 	assert.Equal(t, entity.UpdateTime, model.UpdateTime)
 	assert.Equal(t, entity.Version, model.Version)
+	assert.Equal(t, entity.TenantID, model.TenantID)
 }
 
 func TestUserProfileMapper_ModelToEntity(t *testing.T) {
@@ -55,6 +56,7 @@ func TestUserProfileMapper_ModelToEntity(t *testing.T) {
 	assert.Equal(t, userProfile.CreateTime, entityProfile.CreateTime)
 	assert.Equal(t, userProfile.UpdateTime, entityProfile.UpdateTime)
 	assert.Equal(t, userProfile.Version, entityProfile.Version)
+	assert.Equal(t, userProfile.TenantID, entityProfile.TenantID)
 }
 
 func TestUserProfileMapper_ModelToResponse(t *testing.T) {
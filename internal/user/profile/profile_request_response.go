@@ -5,37 +5,19 @@ import (
 
 	"cloud.google.com/go/civil"
 	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/validx"
 	"github.com/google/uuid"
 )
 
 type CreateRequest struct {
-	UserID      uuid.UUID  `json:"userId"`
-	FirstName   string     `json:"firstName"`
-	LastName    string     `json:"lastName"`
-	DateOfBirth civil.Date `json:"dateOfBirth"`
+	UserID      uuid.UUID  `json:"userId" validate:"required"`
+	FirstName   string     `json:"firstName" validate:"required"`
+	LastName    string     `json:"lastName" validate:"required"`
+	DateOfBirth civil.Date `json:"dateOfBirth" validate:"past"`
 }
 
 func (r *CreateRequest) Validate() *errorx.ValidationError {
-	errors := make(map[string]string)
-
-	if r.UserID == uuid.Nil {
-		errors["userId"] = "is required"
-	}
-	if r.FirstName == "" {
-		errors["firstName"] = "is required"
-	}
-	if r.LastName == "" {
-		errors["lastName"] = "is required"
-	}
-	if !r.DateOfBirth.IsValid() || r.DateOfBirth.IsZero() || !r.DateOfBirth.Before(civil.DateOf(time.Now())) {
-		errors["dateOfBirth"] = "is invalid or in the future"
-	}
-
-	if len(errors) > 0 {
-		return &errorx.ValidationError{Properties: errors}
-	}
-
-	return nil
+	return validx.Struct(r)
 }
 
 type UpdateRequest struct {
@@ -49,6 +31,11 @@ type UpdateRequest struct {
 	Version     int32      `json:"version"`
 }
 
+// ResponseMapper maps a profile to the response of an API version, e.g. Mapper.ModelToResponse for v1.
+// Breaking changes get a new response type and mapper, served by the handlers of a new version.
+type ResponseMapper[R any] func(source UserProfile) R
+
+// Response of API v1.
 type Response struct {
 	ID          uuid.UUID  `json:"id"`
 	UserID      uuid.UUID  `json:"userId"`
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types subscriptions may subscribe to, see EventTypes.
+const (
+	EventUserProfileCreated = "user_profile.created"
+)
+
+// EventTypes catalogue of published events, only events published by a change may be listed.
+var EventTypes = []string{
+	EventUserProfileCreated,
+}
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusEnabled SubscriptionStatus = "ENABLED"
+	// SubscriptionStatusDisabled after repeated delivery failures, re-enabled through the API.
+	SubscriptionStatusDisabled SubscriptionStatus = "DISABLED"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "PENDING"
+	DeliveryStatusSucceeded DeliveryStatus = "SUCCEEDED"
+	// DeliveryStatusFailed after the last attempt, or when the subscription was disabled.
+	DeliveryStatusFailed DeliveryStatus = "FAILED"
+)
+
+type Subscription struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenantId"`
+	URL      string    `json:"url"`
+	// Secret signing deliveries, only returned on create.
+	Secret     string             `json:"-"`
+	EventTypes []string           `json:"eventTypes"`
+	Status     SubscriptionStatus `json:"status"`
+	// FailureCount of consecutive failed attempts, reset by a successful attempt.
+	FailureCount int32      `json:"failureCount"`
+	DisableTime  *time.Time `json:"disableTime"`
+	CreateTime   time.Time  `json:"createTime"`
+	UpdateTime   time.Time  `json:"updateTime"`
+	Version      int32      `json:"version"`
+}
+
+// Delivery of an event to a subscription, retried until it succeeds or attempts are exhausted.
+type Delivery struct {
+	ID             uuid.UUID `json:"id"`
+	TenantID       uuid.UUID `json:"tenantId"`
+	SubscriptionID uuid.UUID `json:"subscriptionId"`
+	// EventID is shared by redeliveries, allowing receivers to deduplicate.
+	EventID   uuid.UUID `json:"eventId"`
+	EventType string    `json:"eventType"`
+	// Payload request body, the JSON Event.
+	Payload         string         `json:"payload"`
+	Status          DeliveryStatus `json:"status"`
+	AttemptCount    int32          `json:"attemptCount"`
+	NextAttemptTime time.Time      `json:"nextAttemptTime"`
+	LastAttemptTime *time.Time     `json:"lastAttemptTime"`
+	LastStatusCode  *int32         `json:"lastStatusCode"`
+	LastError       *string        `json:"lastError"`
+	CreateTime      time.Time      `json:"createTime"`
+	UpdateTime      time.Time      `json:"updateTime"`
+	Version         int32          `json:"version"`
+}
+
+// Event published to subscriptions of its type, delivered as the JSON request body.
+type Event struct {
+	ID   uuid.UUID `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"timestamp"`
+	Data any       `json:"data"`
+}
+
+// NewEvent returns an event of eventType occurring now, data is marshalled as JSON.
+func NewEvent(eventType string, data any) Event {
+	return Event{ID: uuid.New(), Type: eventType, Time: time.Now().UTC(), Data: data}
+}
+
+// IsEventType reports whether eventType is in the EventTypes catalogue.
+func IsEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// SplitEventTypes parses the space separated event types column.
+func SplitEventTypes(eventTypes string) []string {
+	return strings.Fields(eventTypes)
+}
+
+// JoinEventTypes formats event types for the space separated event types column.
+func JoinEventTypes(eventTypes []string) string {
+	return strings.Join(eventTypes, " ")
+}
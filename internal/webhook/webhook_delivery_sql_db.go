@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/audit"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultDeliveryListLimit of deliveries returned by ListDeliveriesTx.
+const DefaultDeliveryListLimit = 100
+
+// DeliverySQLDB is the persistent delivery queue, statements are expected to run in tenant.TxManager transactions.
+type DeliverySQLDB struct {
+	logger *zap.Logger
+}
+
+func NewDeliverySQLDB(logger *zap.Logger) *DeliverySQLDB {
+	return &DeliverySQLDB{logger: logger}
+}
+
+// InsertEventDeliveriesTx queues a delivery of event for every enabled subscription of the tenant of ctx
+// subscribing to its type, returns the number of queued deliveries.
+func (d *DeliverySQLDB) InsertEventDeliveriesTx(
+	ctx context.Context, tx sqldb.Executable, eventID uuid.UUID, eventType string, payload string,
+) (int64, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := postgres.TimestampzT(time.Now())
+	sub := table.WebhookSubscription
+	// Matches whole words of the space separated event types
+	subscribes := postgres.STRPOS(
+		postgres.String(" ").CONCAT(sub.EventTypes).CONCAT(postgres.String(" ")),
+		postgres.String(" "+eventType+" "),
+	).GT(postgres.Int(0))
+
+	stmt := table.WebhookDelivery.
+		INSERT(table.WebhookDelivery.AllColumns).
+		QUERY(
+			postgres.SELECT(
+				postgres.Func("gen_random_uuid"),
+				sub.TenantID,
+				sub.ID,
+				postgres.UUID(eventID),
+				postgres.String(eventType),
+				postgres.String(payload),
+				postgres.String(string(DeliveryStatusPending)),
+				postgres.Int32(0),
+				now,
+				postgres.NULL,
+				postgres.NULL,
+				postgres.NULL,
+				now,
+				now,
+				postgres.Int32(1),
+			).
+				FROM(sub).
+				WHERE(postgres.AND(
+					sub.TenantID.EQ(postgres.UUID(tenantID)),
+					sub.Status.EQ(postgres.String(string(SubscriptionStatusEnabled))),
+					subscribes,
+				)),
+		)
+
+	result, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	queued, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logx.FromContext(ctx, d.logger).Debug("queued webhook deliveries",
+		zap.Stringer("eventId", eventID), zap.String("eventType", eventType), zap.Int64("deliveries", queued))
+
+	return queued, nil
+}
+
+// InsertDeliveryTx queues a delivery, e.g. a redelivery of an event.
+// Ignores and automatically sets ID, CreateTime, UpdateTime, Version fields from input,
+// and TenantID from the tenant of ctx.
+func (d *DeliverySQLDB) InsertDeliveryTx(
+	ctx context.Context, tx sqldb.Executable, input entity.WebhookDelivery,
+) (entity.WebhookDelivery, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+	input.TenantID = tenantID
+
+	audit.SetInsertFields(deliveryAuditableEntity{E: &input})
+
+	stmt := table.WebhookDelivery.
+		INSERT(table.WebhookDelivery.AllColumns).
+		MODEL(input)
+
+	_, err = stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	logx.FromContext(ctx, d.logger).Debug("inserted webhook delivery",
+		zap.Stringer("id", input.ID), zap.Stringer("eventId", input.EventID))
+
+	return input, nil
+}
+
+// ListDeliveriesTx retrieves the latest DefaultDeliveryListLimit deliveries of a subscription, newest first.
+func (d *DeliverySQLDB) ListDeliveriesTx(
+	ctx context.Context, tx sqldb.Queryable, subscriptionID uuid.UUID,
+) ([]entity.WebhookDelivery, error) {
+	stmt := table.WebhookDelivery.
+		SELECT(table.WebhookDelivery.AllColumns).
+		FROM(table.WebhookDelivery).
+		WHERE(table.WebhookDelivery.SubscriptionID.EQ(postgres.UUID(subscriptionID))).
+		ORDER_BY(table.WebhookDelivery.CreateTime.DESC()).
+		LIMIT(DefaultDeliveryListLimit)
+
+	var results []entity.WebhookDelivery
+	err := stmt.QueryContext(ctx, tx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindDeliveryTx returns errorx.ErrNotFound when the delivery does not exist or belongs to another subscription.
+func (d *DeliverySQLDB) FindDeliveryTx(
+	ctx context.Context, tx sqldb.Queryable, subscriptionID uuid.UUID, id uuid.UUID,
+) (entity.WebhookDelivery, error) {
+	stmt := table.WebhookDelivery.
+		SELECT(table.WebhookDelivery.AllColumns).
+		FROM(table.WebhookDelivery).
+		WHERE(postgres.AND(
+			table.WebhookDelivery.ID.EQ(postgres.UUID(id)),
+			table.WebhookDelivery.SubscriptionID.EQ(postgres.UUID(subscriptionID)),
+		))
+
+	var result entity.WebhookDelivery
+	err := stmt.QueryContext(ctx, tx, &result)
+	if err != nil {
+		return entity.WebhookDelivery{}, resolveNoRows(err)
+	}
+
+	return result, nil
+}
+
+// ClaimDueDeliveriesTx claims up to limit pending deliveries due at now, oldest first.
+// Claimed deliveries are leased by moving their next attempt time to now plus lease,
+// they are attempted again by any dispatcher should the attempt not be recorded within the lease.
+// Rows locked by other dispatchers are skipped.
+func (d *DeliverySQLDB) ClaimDueDeliveriesTx(
+	ctx context.Context, tx sqldb.Queryable, now time.Time, lease time.Duration, limit int64,
+) ([]entity.WebhookDelivery, error) {
+	due := table.WebhookDelivery.
+		SELECT(table.WebhookDelivery.ID).
+		FROM(table.WebhookDelivery).
+		WHERE(postgres.AND(
+			table.WebhookDelivery.Status.EQ(postgres.String(string(DeliveryStatusPending))),
+			table.WebhookDelivery.NextAttemptTime.LT_EQ(postgres.TimestampzT(now)),
+		)).
+		ORDER_BY(table.WebhookDelivery.NextAttemptTime.ASC()).
+		LIMIT(limit).
+		FOR(postgres.UPDATE().SKIP_LOCKED())
+
+	stmt := table.WebhookDelivery.
+		UPDATE().
+		SET(table.WebhookDelivery.NextAttemptTime.SET(postgres.TimestampzT(now.Add(lease)))).
+		WHERE(table.WebhookDelivery.ID.IN(due)).
+		RETURNING(table.WebhookDelivery.AllColumns)
+
+	var results []entity.WebhookDelivery
+	err := stmt.QueryContext(ctx, tx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// OldestDueDeliveryTimeTx returns the next attempt time of the oldest pending delivery due at now, nil if none.
+// Claimed deliveries are not due until their lease expires.
+func (d *DeliverySQLDB) OldestDueDeliveryTimeTx(ctx context.Context, tx sqldb.Queryable, now time.Time) (*time.Time, error) {
+	stmt := table.WebhookDelivery.
+		SELECT(table.WebhookDelivery.ID, table.WebhookDelivery.NextAttemptTime).
+		FROM(table.WebhookDelivery).
+		WHERE(postgres.AND(
+			table.WebhookDelivery.Status.EQ(postgres.String(string(DeliveryStatusPending))),
+			table.WebhookDelivery.NextAttemptTime.LT_EQ(postgres.TimestampzT(now)),
+		)).
+		ORDER_BY(table.WebhookDelivery.NextAttemptTime.ASC()).
+		LIMIT(1)
+
+	var results []entity.WebhookDelivery
+	err := stmt.QueryContext(ctx, tx, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	return &results[0].NextAttemptTime, nil
+}
+
+// UpdateAttemptTx records the outcome of an attempt, updating status, attempt and next attempt fields of input.
+func (d *DeliverySQLDB) UpdateAttemptTx(ctx context.Context, tx sqldb.Executable, input entity.WebhookDelivery) error {
+	stmt := table.WebhookDelivery.
+		UPDATE().
+		SET(
+			table.WebhookDelivery.Status.SET(postgres.String(input.Status)),
+			table.WebhookDelivery.AttemptCount.SET(postgres.Int32(input.AttemptCount)),
+			table.WebhookDelivery.NextAttemptTime.SET(postgres.TimestampzT(input.NextAttemptTime)),
+			table.WebhookDelivery.LastAttemptTime.SET(timestampzOrNull(input.LastAttemptTime)),
+			table.WebhookDelivery.LastStatusCode.SET(int32OrNull(input.LastStatusCode)),
+			table.WebhookDelivery.LastError.SET(stringOrNull(input.LastError)),
+			table.WebhookDelivery.UpdateTime.SET(postgres.TimestampzT(time.Now())),
+			table.WebhookDelivery.Version.SET(table.WebhookDelivery.Version.ADD(postgres.Int32(1))),
+		).
+		WHERE(table.WebhookDelivery.ID.EQ(postgres.UUID(input.ID)))
+
+	_, err := stmt.ExecContext(ctx, tx)
+	return err
+}
+
+// FailPendingDeliveriesTx fails pending deliveries of a subscription, e.g. once it is disabled,
+// returns the number of failed deliveries.
+func (d *DeliverySQLDB) FailPendingDeliveriesTx(
+	ctx context.Context, tx sqldb.Executable, subscriptionID uuid.UUID, reason string,
+) (int64, error) {
+	stmt := table.WebhookDelivery.
+		UPDATE().
+		SET(
+			table.WebhookDelivery.Status.SET(postgres.String(string(DeliveryStatusFailed))),
+			table.WebhookDelivery.LastError.SET(postgres.String(reason)),
+			table.WebhookDelivery.UpdateTime.SET(postgres.TimestampzT(time.Now())),
+			table.WebhookDelivery.Version.SET(table.WebhookDelivery.Version.ADD(postgres.Int32(1))),
+		).
+		WHERE(postgres.AND(
+			table.WebhookDelivery.SubscriptionID.EQ(postgres.UUID(subscriptionID)),
+			table.WebhookDelivery.Status.EQ(postgres.String(string(DeliveryStatusPending))),
+		))
+
+	result, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func timestampzOrNull(t *time.Time) postgres.TimestampzExpression {
+	if t == nil {
+		return postgres.TimestampzExp(postgres.NULL)
+	}
+	return postgres.TimestampzT(*t)
+}
+
+func int32OrNull(i *int32) postgres.IntegerExpression {
+	if i == nil {
+		return postgres.IntExp(postgres.NULL)
+	}
+	return postgres.Int32(*i)
+}
+
+func stringOrNull(s *string) postgres.StringExpression {
+	if s == nil {
+		return postgres.StringExp(postgres.NULL)
+	}
+	return postgres.String(*s)
+}
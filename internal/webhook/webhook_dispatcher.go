@@ -0,0 +1,496 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	pkgwebhook "github.com/dyxj/bigbackend/pkg/webhook"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultPollInterval = 5 * time.Second
+	DefaultBatchSize    = 20
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxAttempts  = 8
+	DefaultBackoffBase  = 30 * time.Second
+	DefaultBackoffMax   = 6 * time.Hour
+	DefaultDisableAfter = 20
+
+	userAgent = "bigbackend-webhook"
+	// leaseMargin added to the timeout, claimed deliveries are not claimed again while attempted
+	leaseMargin = 30 * time.Second
+	// maxDrainedBodyBytes of responses read, allowing connections to be reused
+	maxDrainedBodyBytes = 64 << 10
+	// errDisabled recorded as last error of deliveries failed by a disabled subscription
+	errDisabled = "webhook subscription is disabled"
+)
+
+// Delivery attempt results recorded by DispatcherMetrics.
+const (
+	ResultSucceeded = "succeeded"
+	ResultRetrying  = "retrying"
+	ResultFailed    = "failed"
+)
+
+// DispatcherMetrics records delivery attempts.
+// Implemented by monitoring.Metrics.
+type DispatcherMetrics interface {
+	RecordWebhookDelivery(eventType string, result string, duration time.Duration)
+}
+
+type noopDispatcherMetrics struct{}
+
+func (noopDispatcherMetrics) RecordWebhookDelivery(string, string, time.Duration) {}
+
+// Dispatcher delivers queued deliveries of every tenant, see Run.
+//
+// Failed attempts are retried with exponential backoff and jitter until max attempts. A subscription is disabled
+// after consecutive failed attempts, see WithDisableAfter, failing its pending deliveries.
+type Dispatcher struct {
+	logger           *zap.Logger
+	tm               sqldb.TransactionManager
+	subscriptionRepo DispatcherSubscriptionRepo
+	deliveryRepo     DispatcherDeliveryRepo
+	client           *http.Client
+	allowedNetworks  []netip.Prefix
+	metrics          DispatcherMetrics
+
+	pollInterval time.Duration
+	batchSize    int64
+	timeout      time.Duration
+	maxAttempts  int32
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	disableAfter int32
+	now          func() time.Time
+}
+
+type DispatcherOption func(*Dispatcher)
+
+// WithPollInterval overrides DefaultPollInterval.
+func WithPollInterval(d time.Duration) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.pollInterval = d
+	}
+}
+
+// WithBatchSize overrides DefaultBatchSize, the number of deliveries attempted concurrently.
+func WithBatchSize(n int) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.batchSize = int64(n)
+	}
+}
+
+// WithTimeout overrides DefaultTimeout of an attempt.
+func WithTimeout(d time.Duration) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.timeout = d
+	}
+}
+
+// WithMaxAttempts overrides DefaultMaxAttempts of a delivery.
+func WithMaxAttempts(n int) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.maxAttempts = int32(n)
+	}
+}
+
+// WithBackoff overrides DefaultBackoffBase and DefaultBackoffMax, the delay before the first retry
+// and the limit of delays doubling with every attempt.
+func WithBackoff(base, maximum time.Duration) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.backoffBase = base
+		dp.backoffMax = maximum
+	}
+}
+
+// WithDisableAfter overrides DefaultDisableAfter consecutive failed attempts of a subscription.
+func WithDisableAfter(n int) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.disableAfter = int32(n)
+	}
+}
+
+// WithAllowedNetworks allows deliveries to internal addresses within allowed, e.g. receivers of a private
+// network or tests, which are rejected otherwise. Ignored given WithHTTPClient.
+func WithAllowedNetworks(allowed []netip.Prefix) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.allowedNetworks = allowed
+	}
+}
+
+// WithHTTPClient overrides the client sending deliveries, its timeout is superseded by WithTimeout.
+// Deliveries are not guarded against internal addresses unless its transport dials with pkgwebhook.DialControl.
+func WithHTTPClient(client *http.Client) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.client = client
+	}
+}
+
+func WithDispatcherMetrics(metrics DispatcherMetrics) DispatcherOption {
+	return func(dp *Dispatcher) {
+		dp.metrics = metrics
+	}
+}
+
+// NewDispatcher creates a dispatcher, tm must honour tenant.WithAll, e.g. tenant.TxManager.
+func NewDispatcher(
+	logger *zap.Logger,
+	tm sqldb.TransactionManager,
+	subscriptionRepo DispatcherSubscriptionRepo,
+	deliveryRepo DispatcherDeliveryRepo,
+	options ...DispatcherOption,
+) *Dispatcher {
+	d := &Dispatcher{
+		logger:           logger,
+		tm:               tm,
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		metrics:          noopDispatcherMetrics{},
+		pollInterval:     DefaultPollInterval,
+		batchSize:        DefaultBatchSize,
+		timeout:          DefaultTimeout,
+		maxAttempts:      DefaultMaxAttempts,
+		backoffBase:      DefaultBackoffBase,
+		backoffMax:       DefaultBackoffMax,
+		disableAfter:     DefaultDisableAfter,
+		now:              time.Now,
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	if d.client == nil {
+		d.client = newClient(d.allowedNetworks)
+	}
+	return d
+}
+
+// newClient returns the client sending deliveries, connections to internal addresses are rejected
+// unless within allowed, see pkgwebhook.DialControl.
+func newClient(allowed []netip.Prefix) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Dialing a proxy would bypass the guard of the receiver address
+	transport.Proxy = nil
+	transport.DialContext = pkgwebhook.NewDialer(allowed).DialContext
+	return &http.Client{
+		Transport: transport,
+		// Redirects are not followed, a redirected delivery is failed
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// Run dispatches due deliveries every poll interval until ctx is done, attempts in flight are completed.
+// Full batches are followed by the next batch without waiting, draining a backlog.
+// Dispatchers of several instances share the queue, deliveries are claimed by one of them.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				n, err := d.dispatchDue(ctx)
+				if err != nil {
+					d.logger.Error("failed to dispatch webhook deliveries", zap.Error(err))
+					break
+				}
+				if int64(n) < d.batchSize {
+					break
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Lag returns how long the oldest due delivery of every tenant has waited to be attempted, zero if none is due.
+// A growing lag means deliveries are queued faster than they are dispatched, or that no dispatcher is running.
+func (d *Dispatcher) Lag(ctx context.Context) (time.Duration, error) {
+	ctx = tenant.WithAll(ctx)
+
+	tx, err := d.tm.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqldb.TxRollback(tx, d.logger)
+
+	now := d.now()
+	oldest, err := d.deliveryRepo.OldestDueDeliveryTimeTx(ctx, tx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find oldest due delivery: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if oldest == nil {
+		return 0, nil
+	}
+	return now.Sub(*oldest), nil
+}
+
+// attempt outcome of a delivery.
+type attempt struct {
+	delivery     entity.WebhookDelivery
+	subscription *entity.WebhookSubscription
+	statusCode   *int32
+	err          error
+	duration     time.Duration
+}
+
+// dispatchDue attempts a batch of due deliveries, returns the number of attempted deliveries.
+func (d *Dispatcher) dispatchDue(ctx context.Context) (int, error) {
+	// Attempts in flight are completed and recorded on shutdown, bounded by the timeout
+	ctx = tenant.WithAll(context.WithoutCancel(ctx))
+
+	deliveries, subscriptions, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	attempts := make([]attempt, len(deliveries))
+	var wg sync.WaitGroup
+	for i, delivery := range deliveries {
+		attempts[i] = attempt{delivery: delivery}
+		if s, ok := subscriptions[delivery.SubscriptionID]; ok {
+			attempts[i].subscription = &s
+		}
+		wg.Go(func() {
+			d.send(ctx, &attempts[i])
+		})
+	}
+	wg.Wait()
+
+	for _, a := range attempts {
+		err = d.record(ctx, a)
+		if err != nil {
+			// The delivery is attempted again once its lease expires
+			d.logger.Error("failed to record webhook delivery attempt",
+				zap.Error(err), zap.Stringer("deliveryId", a.delivery.ID))
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) claim(
+	ctx context.Context,
+) ([]entity.WebhookDelivery, map[uuid.UUID]entity.WebhookSubscription, error) {
+	tx, err := d.tm.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqldb.TxRollback(tx, d.logger)
+
+	deliveries, err := d.deliveryRepo.ClaimDueDeliveriesTx(ctx, tx, d.now(), d.timeout+leaseMargin, d.batchSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.SubscriptionID)
+	}
+	found, err := d.subscriptionRepo.ListSubscriptionsByIDsTx(ctx, tx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	subscriptions := make(map[uuid.UUID]entity.WebhookSubscription, len(found))
+	for _, s := range found {
+		subscriptions[s.ID] = s
+	}
+	return deliveries, subscriptions, nil
+}
+
+// send posts the payload signed with the subscription secret, a 2xx response is a success.
+// The event ID identifies the message, it is shared by retries and redeliveries.
+func (d *Dispatcher) send(ctx context.Context, a *attempt) {
+	if a.subscription == nil || SubscriptionStatus(a.subscription.Status) != SubscriptionStatusEnabled {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	body := []byte(a.delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		a.err = err
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	err = pkgwebhook.SetHeaders(req.Header, a.subscription.Secret, a.delivery.EventID.String(), d.now(), body)
+	if err != nil {
+		a.err = err
+		return
+	}
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	a.duration = time.Since(start)
+	if err != nil {
+		a.err = err
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedBodyBytes))
+
+	statusCode := int32(resp.StatusCode)
+	a.statusCode = &statusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		a.err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+// record updates the delivery and the failure count of its subscription with the outcome of a.
+func (d *Dispatcher) record(ctx context.Context, a attempt) error {
+	tx, err := d.tm.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqldb.TxRollback(tx, d.logger)
+
+	result, err := d.recordTx(ctx, tx, a)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	d.metrics.RecordWebhookDelivery(a.delivery.EventType, result, a.duration)
+	return nil
+}
+
+func (d *Dispatcher) recordTx(ctx context.Context, tx *sql.Tx, a attempt) (string, error) {
+	delivery := a.delivery
+	logger := d.logger.With(
+		zap.Stringer("deliveryId", delivery.ID),
+		zap.Stringer("subscriptionId", delivery.SubscriptionID),
+		zap.String("eventType", delivery.EventType),
+	)
+
+	// Disabled or deleted while queued, the delivery is failed without an attempt
+	if a.subscription == nil || SubscriptionStatus(a.subscription.Status) != SubscriptionStatusEnabled {
+		delivery.Status = string(DeliveryStatusFailed)
+		reason := errDisabled
+		delivery.LastError = &reason
+		return ResultFailed, d.deliveryRepo.UpdateAttemptTx(ctx, tx, delivery)
+	}
+
+	now := d.now()
+	delivery.AttemptCount++
+	delivery.LastAttemptTime = &now
+	delivery.LastStatusCode = a.statusCode
+	delivery.LastError = nil
+
+	if a.err == nil {
+		delivery.Status = string(DeliveryStatusSucceeded)
+		err := d.deliveryRepo.UpdateAttemptTx(ctx, tx, delivery)
+		if err != nil {
+			return "", err
+		}
+		logger.Debug("delivered webhook", zap.Int32("attempt", delivery.AttemptCount))
+		return ResultSucceeded, d.subscriptionRepo.ResetFailuresTx(ctx, tx, delivery.SubscriptionID)
+	}
+
+	result := ResultRetrying
+	message := errorMessage(a.err)
+	delivery.LastError = &message
+	if delivery.AttemptCount >= d.maxAttempts {
+		result = ResultFailed
+		delivery.Status = string(DeliveryStatusFailed)
+	} else {
+		delivery.NextAttemptTime = now.Add(d.backoff(delivery.AttemptCount))
+	}
+	err := d.deliveryRepo.UpdateAttemptTx(ctx, tx, delivery)
+	if err != nil {
+		return "", err
+	}
+	logger.Warn("failed to deliver webhook", zap.Error(a.err),
+		zap.Int32("attempt", delivery.AttemptCount), zap.String("result", result))
+
+	subscription, err := d.subscriptionRepo.IncrementFailuresTx(ctx, tx, delivery.SubscriptionID, d.disableAfter)
+	if err != nil {
+		return "", err
+	}
+	if SubscriptionStatus(subscription.Status) == SubscriptionStatusDisabled {
+		failed, err := d.deliveryRepo.FailPendingDeliveriesTx(ctx, tx, delivery.SubscriptionID, errDisabled)
+		if err != nil {
+			return "", err
+		}
+		logger.Warn("disabled webhook subscription after repeated failures",
+			zap.Int32("failureCount", subscription.FailureCount), zap.Int64("failedDeliveries", failed))
+		if delivery.Status == string(DeliveryStatusPending) {
+			result = ResultFailed
+		}
+	}
+
+	return result, nil
+}
+
+// backoff returns the delay before the retry following attempt, doubling from the base delay
+// up to the maximum. Equal jitter, half fixed and half random, spreads retries of deliveries failing together.
+func (d *Dispatcher) backoff(attempt int32) time.Duration {
+	delay := d.backoffMax
+	if shift := attempt - 1; shift < 32 {
+		if b := d.backoffBase << shift; b > 0 && b < d.backoffMax {
+			delay = b
+		}
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// errorMessage of a failed attempt, without the request URL which may hold credentials.
+func errorMessage(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Op + ": " + urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+type DispatcherSubscriptionRepo interface {
+	ListSubscriptionsByIDsTx(ctx context.Context, tx sqldb.Queryable, ids []uuid.UUID) ([]entity.WebhookSubscription, error)
+	ResetFailuresTx(ctx context.Context, tx sqldb.Executable, id uuid.UUID) error
+	IncrementFailuresTx(
+		ctx context.Context, tx sqldb.Queryable, id uuid.UUID, disableAfter int32,
+	) (entity.WebhookSubscription, error)
+}
+
+type DispatcherDeliveryRepo interface {
+	ClaimDueDeliveriesTx(
+		ctx context.Context, tx sqldb.Queryable, now time.Time, lease time.Duration, limit int64,
+	) ([]entity.WebhookDelivery, error)
+	UpdateAttemptTx(ctx context.Context, tx sqldb.Executable, input entity.WebhookDelivery) error
+	FailPendingDeliveriesTx(ctx context.Context, tx sqldb.Executable, subscriptionID uuid.UUID, reason string) (int64, error)
+	OldestDueDeliveryTimeTx(ctx context.Context, tx sqldb.Queryable, now time.Time) (*time.Time, error)
+}
@@ -0,0 +1,352 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	pkgwebhook "github.com/dyxj/bigbackend/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type dispatcherRepoStub struct {
+	mu            sync.Mutex
+	due           []entity.WebhookDelivery
+	subscriptions []entity.WebhookSubscription
+	updated       []entity.WebhookDelivery
+	resets        []uuid.UUID
+	failures      map[uuid.UUID]int32
+	failedPending []uuid.UUID
+	oldestDue     *time.Time
+}
+
+func (s *dispatcherRepoStub) ClaimDueDeliveriesTx(
+	_ context.Context, _ sqldb.Queryable, _ time.Time, _ time.Duration, _ int64,
+) ([]entity.WebhookDelivery, error) {
+	return s.due, nil
+}
+
+func (s *dispatcherRepoStub) ListSubscriptionsByIDsTx(
+	_ context.Context, _ sqldb.Queryable, _ []uuid.UUID,
+) ([]entity.WebhookSubscription, error) {
+	return s.subscriptions, nil
+}
+
+func (s *dispatcherRepoStub) UpdateAttemptTx(_ context.Context, _ sqldb.Executable, input entity.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updated = append(s.updated, input)
+	return nil
+}
+
+func (s *dispatcherRepoStub) ResetFailuresTx(_ context.Context, _ sqldb.Executable, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resets = append(s.resets, id)
+	return nil
+}
+
+func (s *dispatcherRepoStub) IncrementFailuresTx(
+	_ context.Context, _ sqldb.Queryable, id uuid.UUID, disableAfter int32,
+) (entity.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures == nil {
+		s.failures = make(map[uuid.UUID]int32)
+	}
+	s.failures[id]++
+	status := SubscriptionStatusEnabled
+	if s.failures[id] >= disableAfter {
+		status = SubscriptionStatusDisabled
+	}
+	return entity.WebhookSubscription{ID: id, Status: string(status), FailureCount: s.failures[id]}, nil
+}
+
+func (s *dispatcherRepoStub) FailPendingDeliveriesTx(
+	_ context.Context, _ sqldb.Executable, subscriptionID uuid.UUID, _ string,
+) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failedPending = append(s.failedPending, subscriptionID)
+	return 0, nil
+}
+
+func (s *dispatcherRepoStub) OldestDueDeliveryTimeTx(_ context.Context, _ sqldb.Queryable, _ time.Time) (*time.Time, error) {
+	return s.oldestDue, nil
+}
+
+// newDispatcherTest expects one claim transaction and a record transaction per delivery.
+func newDispatcherTest(t *testing.T, repo *dispatcherRepoStub, options ...DispatcherOption) *Dispatcher {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		_ = db.Close()
+	})
+
+	for range len(repo.due) + 1 {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectCommit()
+	}
+
+	// Receivers of httptest listen on loopback
+	options = append([]DispatcherOption{WithAllowedNetworks([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})},
+		options...)
+	return NewDispatcher(zap.NewNop(), db, repo, repo, options...)
+}
+
+func newSubscription(url string) entity.WebhookSubscription {
+	return entity.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        url,
+		Secret:     pkgwebhook.GenerateSecret(),
+		EventTypes: EventUserProfileCreated,
+		Status:     string(SubscriptionStatusEnabled),
+	}
+}
+
+func newDelivery(subscriptionID uuid.UUID) entity.WebhookDelivery {
+	return entity.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: subscriptionID,
+		EventID:        uuid.New(),
+		EventType:      EventUserProfileCreated,
+		Payload:        `{"type":"user_profile.created"}`,
+		Status:         string(DeliveryStatusPending),
+	}
+}
+
+func TestDispatcher_DispatchDue_Succeeded(t *testing.T) {
+	var received http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription := newSubscription(server.URL)
+	delivery := newDelivery(subscription.ID)
+	repo := &dispatcherRepoStub{
+		due:           []entity.WebhookDelivery{delivery},
+		subscriptions: []entity.WebhookSubscription{subscription},
+	}
+
+	n, err := newDispatcherTest(t, repo).dispatchDue(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, delivery.Payload, string(body))
+	assert.Equal(t, delivery.EventID.String(), received.Get(pkgwebhook.HeaderID))
+	assert.NoError(t, pkgwebhook.Verify(subscription.Secret, received, body, pkgwebhook.DefaultTolerance, time.Now()))
+
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, string(DeliveryStatusSucceeded), repo.updated[0].Status)
+	assert.Equal(t, int32(1), repo.updated[0].AttemptCount)
+	assert.Equal(t, int32(http.StatusNoContent), *repo.updated[0].LastStatusCode)
+	assert.Nil(t, repo.updated[0].LastError)
+	assert.Equal(t, []uuid.UUID{subscription.ID}, repo.resets)
+}
+
+func TestDispatcher_DispatchDue_Retrying(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	subscription := newSubscription(server.URL)
+	delivery := newDelivery(subscription.ID)
+	repo := &dispatcherRepoStub{
+		due:           []entity.WebhookDelivery{delivery},
+		subscriptions: []entity.WebhookSubscription{subscription},
+	}
+
+	start := time.Now()
+	_, err := newDispatcherTest(t, repo, WithBackoff(time.Minute, time.Hour)).dispatchDue(t.Context())
+	require.NoError(t, err)
+
+	require.Len(t, repo.updated, 1)
+	updated := repo.updated[0]
+	assert.Equal(t, string(DeliveryStatusPending), updated.Status)
+	assert.Equal(t, int32(1), updated.AttemptCount)
+	assert.Equal(t, int32(http.StatusInternalServerError), *updated.LastStatusCode)
+	assert.Equal(t, "unexpected status 500", *updated.LastError)
+	assert.WithinRange(t, updated.NextAttemptTime, start.Add(30*time.Second), time.Now().Add(time.Minute))
+	assert.Equal(t, int32(1), repo.failures[subscription.ID])
+	assert.Empty(t, repo.resets)
+	assert.Empty(t, repo.failedPending)
+}
+
+func TestDispatcher_DispatchDue_FailedAtMaxAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	subscription := newSubscription(server.URL)
+	delivery := newDelivery(subscription.ID)
+	delivery.AttemptCount = 2
+	repo := &dispatcherRepoStub{
+		due:           []entity.WebhookDelivery{delivery},
+		subscriptions: []entity.WebhookSubscription{subscription},
+	}
+
+	_, err := newDispatcherTest(t, repo, WithMaxAttempts(3)).dispatchDue(t.Context())
+	require.NoError(t, err)
+
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, string(DeliveryStatusFailed), repo.updated[0].Status)
+	assert.Equal(t, int32(3), repo.updated[0].AttemptCount)
+}
+
+func TestDispatcher_DispatchDue_DisablesSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	subscription := newSubscription(server.URL)
+	repo := &dispatcherRepoStub{
+		due:           []entity.WebhookDelivery{newDelivery(subscription.ID), newDelivery(subscription.ID)},
+		subscriptions: []entity.WebhookSubscription{subscription},
+	}
+
+	_, err := newDispatcherTest(t, repo, WithDisableAfter(2)).dispatchDue(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), repo.failures[subscription.ID])
+	assert.Equal(t, []uuid.UUID{subscription.ID}, repo.failedPending)
+}
+
+func TestDispatcher_DispatchDue_SubscriptionDisabled(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	disabled := newSubscription(server.URL)
+	disabled.Status = string(SubscriptionStatusDisabled)
+	repo := &dispatcherRepoStub{
+		due:           []entity.WebhookDelivery{newDelivery(disabled.ID), newDelivery(uuid.New())},
+		subscriptions: []entity.WebhookSubscription{disabled},
+	}
+
+	_, err := newDispatcherTest(t, repo).dispatchDue(t.Context())
+	require.NoError(t, err)
+
+	assert.Zero(t, requests)
+	require.Len(t, repo.updated, 2)
+	for _, updated := range repo.updated {
+		assert.Equal(t, string(DeliveryStatusFailed), updated.Status)
+		assert.Equal(t, int32(0), updated.AttemptCount)
+		assert.Equal(t, errDisabled, *updated.LastError)
+	}
+	assert.Empty(t, repo.failures)
+}
+
+func TestDispatcher_DispatchDue_RedirectNotFollowed(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	subscription := newSubscription(server.URL)
+	repo := &dispatcherRepoStub{
+		due:           []entity.WebhookDelivery{newDelivery(subscription.ID)},
+		subscriptions: []entity.WebhookSubscription{subscription},
+	}
+
+	_, err := newDispatcherTest(t, repo).dispatchDue(t.Context())
+	require.NoError(t, err)
+
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, string(DeliveryStatusPending), repo.updated[0].Status)
+	assert.Equal(t, int32(http.StatusTemporaryRedirect), *repo.updated[0].LastStatusCode)
+}
+
+func TestDispatcher_DispatchDue_InternalAddressRejected(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	subscription := newSubscription(server.URL)
+	repo := &dispatcherRepoStub{
+		due:           []entity.WebhookDelivery{newDelivery(subscription.ID)},
+		subscriptions: []entity.WebhookSubscription{subscription},
+	}
+
+	_, err := newDispatcherTest(t, repo, WithAllowedNetworks(nil)).dispatchDue(t.Context())
+	require.NoError(t, err)
+
+	assert.False(t, called)
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, string(DeliveryStatusPending), repo.updated[0].Status)
+	assert.Contains(t, *repo.updated[0].LastError, pkgwebhook.ErrAddressNotAllowed.Error())
+}
+
+func TestDispatcher_Lag(t *testing.T) {
+	now := time.Now()
+	oldest := now.Add(-5 * time.Minute)
+
+	d := newDispatcherTest(t, &dispatcherRepoStub{oldestDue: &oldest})
+	d.now = func() time.Time { return now }
+	lag, err := d.Lag(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, lag)
+
+	lag, err = newDispatcherTest(t, &dispatcherRepoStub{}).Lag(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, lag)
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewDispatcher(zap.NewNop(), nil, nil, nil, WithBackoff(time.Second, time.Minute))
+
+	tests := []struct {
+		attempt int32
+		delay   time.Duration
+	}{
+		{attempt: 1, delay: time.Second},
+		{attempt: 2, delay: 2 * time.Second},
+		{attempt: 4, delay: 8 * time.Second},
+		{attempt: 7, delay: time.Minute},
+		{attempt: 100, delay: time.Minute},
+	}
+	for _, tt := range tests {
+		for range 10 {
+			backoff := d.backoff(tt.attempt)
+			assert.GreaterOrEqual(t, backoff, tt.delay/2)
+			assert.LessOrEqual(t, backoff, tt.delay)
+		}
+	}
+}
+
+func TestErrorMessage_OmitsURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	url := server.URL + "/hook?token=secret"
+	server.Close()
+
+	_, err := http.Post(url, "application/json", nil)
+	require.Error(t, err)
+
+	assert.NotContains(t, errorMessage(err), "secret")
+	assert.Contains(t, errorMessage(err), "Post")
+}
@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Handler manages webhook subscriptions of the tenant of the request and their delivery log.
+type Handler struct {
+	logger      *zap.Logger
+	errRegistry *httpx.ErrorRegistry
+	manager     SubscriptionManager
+	mapper      Mapper
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	errRegistry *httpx.ErrorRegistry,
+	manager SubscriptionManager,
+	mapper Mapper,
+) *Handler {
+	return &Handler{logger: logger, errRegistry: errRegistry, manager: manager, mapper: mapper}
+}
+
+// Create subscribes a URL to event types, the response holds the signing secret which is not shown again.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	h.errRegistry.Handle(h.create)(w, r)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	h.errRegistry.Handle(h.list)(w, r)
+}
+
+// Get responds with the subscription of URL parameter "id".
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	h.errRegistry.Handle(h.get)(w, r)
+}
+
+// Delete removes the subscription of URL parameter "id" with its delivery log.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	h.errRegistry.Handle(h.delete)(w, r)
+}
+
+// Enable re-enables the subscription of URL parameter "id", e.g. after it was disabled by failures.
+func (h *Handler) Enable(w http.ResponseWriter, r *http.Request) {
+	h.errRegistry.Handle(h.enable)(w, r)
+}
+
+// ListDeliveries responds with the latest deliveries of the subscription of URL parameter "id".
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	h.errRegistry.Handle(h.listDeliveries)(w, r)
+}
+
+// Redeliver queues the event of delivery of URL parameter "deliveryId" again,
+// for the subscription of URL parameter "id".
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	h.errRegistry.Handle(h.redeliver)(w, r)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) error {
+	defer func() { _ = r.Body.Close() }()
+	cRequest, err := httpx.DecodeJSON[CreateRequest](w, r)
+	if err != nil {
+		return err
+	}
+
+	created, secret, err := h.manager.Create(r.Context(), h.mapper.CreateRequestToModel(cRequest))
+	if err != nil {
+		return err
+	}
+
+	httpx.JsonResponse(http.StatusCreated, SecretResponse{Subscription: h.mapper.ModelToResponse(created), Secret: secret}, w)
+	return nil
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) error {
+	subscriptions, err := h.manager.List(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := ListResponse{Subscriptions: make([]Response, 0, len(subscriptions))}
+	for _, s := range subscriptions {
+		resp.Subscriptions = append(resp.Subscriptions, h.mapper.ModelToResponse(s))
+	}
+
+	httpx.JsonResponse(http.StatusOK, resp, w)
+	return nil
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) error {
+	id, err := urlID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	found, err := h.manager.Get(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	httpx.JsonResponse(http.StatusOK, h.mapper.ModelToResponse(found), w)
+	return nil
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) error {
+	id, err := urlID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	err = h.manager.Delete(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *Handler) enable(w http.ResponseWriter, r *http.Request) error {
+	id, err := urlID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	enabled, err := h.manager.Enable(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	httpx.JsonResponse(http.StatusOK, h.mapper.ModelToResponse(enabled), w)
+	return nil
+}
+
+func (h *Handler) listDeliveries(w http.ResponseWriter, r *http.Request) error {
+	id, err := urlID(r, "id")
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := h.manager.ListDeliveries(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	resp := DeliveryListResponse{Deliveries: make([]DeliveryResponse, 0, len(deliveries))}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, h.mapper.DeliveryModelToResponse(d))
+	}
+
+	httpx.JsonResponse(http.StatusOK, resp, w)
+	return nil
+}
+
+func (h *Handler) redeliver(w http.ResponseWriter, r *http.Request) error {
+	id, err := urlID(r, "id")
+	if err != nil {
+		return err
+	}
+	deliveryID, err := urlID(r, "deliveryId")
+	if err != nil {
+		return err
+	}
+
+	created, err := h.manager.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		if errors.Is(err, ErrSubscriptionDisabled) {
+			return httpx.WithMessage(err, "webhook subscription is disabled, enable it before redelivering")
+		}
+		return err
+	}
+
+	httpx.JsonResponse(http.StatusAccepted, h.mapper.DeliveryModelToResponse(created), w)
+	return nil
+}
+
+func urlID(r *http.Request, param string) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		return uuid.Nil, &errorx.BadRequestError{
+			Message:    "invalid " + param,
+			Properties: map[string]string{"error": err.Error()},
+		}
+	}
+	return id, nil
+}
+
+type SubscriptionManager interface {
+	Create(ctx context.Context, input Subscription) (Subscription, string, error)
+	List(ctx context.Context) ([]Subscription, error)
+	Get(ctx context.Context, id uuid.UUID) (Subscription, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Enable(ctx context.Context, id uuid.UUID) (Subscription, error)
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID) ([]Delivery, error)
+	Redeliver(ctx context.Context, subscriptionID uuid.UUID, deliveryID uuid.UUID) (Delivery, error)
+}
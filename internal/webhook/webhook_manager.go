@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	pkgwebhook "github.com/dyxj/bigbackend/pkg/webhook"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var ErrSubscriptionDisabled = fmt.Errorf("%w: webhook subscription is disabled", errorx.ErrConflict)
+
+// Manager manages subscriptions of the tenant of the context and their delivery log.
+type Manager struct {
+	logger           *zap.Logger
+	tm               sqldb.TransactionManager
+	subscriptionRepo SubscriptionRepo
+	deliveryRepo     DeliveryRepo
+	mapper           Mapper
+}
+
+func NewManager(
+	logger *zap.Logger,
+	tm sqldb.TransactionManager,
+	subscriptionRepo SubscriptionRepo,
+	deliveryRepo DeliveryRepo,
+	mapper Mapper,
+) *Manager {
+	return &Manager{
+		logger: logger, tm: tm, subscriptionRepo: subscriptionRepo, deliveryRepo: deliveryRepo, mapper: mapper,
+	}
+}
+
+// Create subscribes input.URL to input.EventTypes with a generated signing secret,
+// the returned secret is stored but not returned again.
+func (m *Manager) Create(ctx context.Context, input Subscription) (Subscription, string, error) {
+	input.Secret = pkgwebhook.GenerateSecret()
+	input.Status = SubscriptionStatusEnabled
+	input.FailureCount = 0
+	input.DisableTime = nil
+	input.EventTypes = slices.Compact(slices.Sorted(slices.Values(input.EventTypes)))
+
+	var created entity.WebhookSubscription
+	err := m.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		created, err = m.subscriptionRepo.InsertSubscriptionTx(ctx, tx, m.mapper.SubscriptionModelToEntity(input))
+		return err
+	})
+	if err != nil {
+		return Subscription{}, "", err
+	}
+
+	logx.FromContext(ctx, m.logger).Info("created webhook subscription",
+		zap.Stringer("id", created.ID), zap.String("url", created.URL), zap.String("eventTypes", created.EventTypes))
+
+	return m.mapper.SubscriptionEntityToModel(created), input.Secret, nil
+}
+
+func (m *Manager) List(ctx context.Context) ([]Subscription, error) {
+	var entities []entity.WebhookSubscription
+	err := m.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		entities, err = m.subscriptionRepo.ListSubscriptionsTx(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]Subscription, 0, len(entities))
+	for _, e := range entities {
+		subscriptions = append(subscriptions, m.mapper.SubscriptionEntityToModel(e))
+	}
+	return subscriptions, nil
+}
+
+// Get returns errorx.ErrNotFound when the subscription does not exist.
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (Subscription, error) {
+	var found entity.WebhookSubscription
+	err := m.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		found, err = m.subscriptionRepo.FindSubscriptionTx(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return Subscription{}, err
+	}
+	return m.mapper.SubscriptionEntityToModel(found), nil
+}
+
+// Delete removes a subscription and its delivery log, pending deliveries are not attempted.
+func (m *Manager) Delete(ctx context.Context, id uuid.UUID) error {
+	err := m.inTx(ctx, func(tx *sql.Tx) error {
+		return m.subscriptionRepo.DeleteSubscriptionTx(ctx, tx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	logx.FromContext(ctx, m.logger).Info("deleted webhook subscription", zap.Stringer("id", id))
+
+	return nil
+}
+
+// Enable re-enables a subscription disabled after repeated failures, resetting its failure count.
+// Deliveries failed while disabled are not retried, see Redeliver.
+func (m *Manager) Enable(ctx context.Context, id uuid.UUID) (Subscription, error) {
+	var updated entity.WebhookSubscription
+	err := m.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		updated, err = m.subscriptionRepo.EnableSubscriptionTx(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	logx.FromContext(ctx, m.logger).Info("enabled webhook subscription", zap.Stringer("id", id))
+
+	return m.mapper.SubscriptionEntityToModel(updated), nil
+}
+
+// ListDeliveries returns the latest deliveries of a subscription, newest first.
+// Returns errorx.ErrNotFound when the subscription does not exist.
+func (m *Manager) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID) ([]Delivery, error) {
+	var entities []entity.WebhookDelivery
+	err := m.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := m.subscriptionRepo.FindSubscriptionTx(ctx, tx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		entities, err = m.deliveryRepo.ListDeliveriesTx(ctx, tx, subscriptionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]Delivery, 0, len(entities))
+	for _, e := range entities {
+		deliveries = append(deliveries, m.mapper.DeliveryEntityToModel(e))
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues a new delivery of the event of a delivery, attempted immediately regardless of
+// the outcome of the original. The event ID is kept, allowing receivers to deduplicate.
+// Returns ErrSubscriptionDisabled when the subscription is disabled.
+func (m *Manager) Redeliver(ctx context.Context, subscriptionID uuid.UUID, deliveryID uuid.UUID) (Delivery, error) {
+	var created entity.WebhookDelivery
+	err := m.inTx(ctx, func(tx *sql.Tx) error {
+		subscription, err := m.subscriptionRepo.FindSubscriptionTx(ctx, tx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if SubscriptionStatus(subscription.Status) != SubscriptionStatusEnabled {
+			return ErrSubscriptionDisabled
+		}
+
+		original, err := m.deliveryRepo.FindDeliveryTx(ctx, tx, subscriptionID, deliveryID)
+		if err != nil {
+			return err
+		}
+
+		created, err = m.deliveryRepo.InsertDeliveryTx(ctx, tx, m.mapper.DeliveryModelToEntity(Delivery{
+			SubscriptionID:  subscriptionID,
+			EventID:         original.EventID,
+			EventType:       original.EventType,
+			Payload:         original.Payload,
+			Status:          DeliveryStatusPending,
+			NextAttemptTime: time.Now(),
+		}))
+		return err
+	})
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	logx.FromContext(ctx, m.logger).Info("queued webhook redelivery",
+		zap.Stringer("subscriptionId", subscriptionID), zap.Stringer("deliveryId", deliveryID),
+		zap.Stringer("redeliveryId", created.ID))
+
+	return m.mapper.DeliveryEntityToModel(created), nil
+}
+
+func (m *Manager) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.tm.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqldb.TxRollback(tx, m.logger)
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+type SubscriptionRepo interface {
+	InsertSubscriptionTx(
+		ctx context.Context, tx sqldb.Executable, input entity.WebhookSubscription,
+	) (entity.WebhookSubscription, error)
+	ListSubscriptionsTx(ctx context.Context, tx sqldb.Queryable) ([]entity.WebhookSubscription, error)
+	FindSubscriptionTx(ctx context.Context, tx sqldb.Queryable, id uuid.UUID) (entity.WebhookSubscription, error)
+	DeleteSubscriptionTx(ctx context.Context, tx sqldb.Executable, id uuid.UUID) error
+	EnableSubscriptionTx(ctx context.Context, tx sqldb.Queryable, id uuid.UUID) (entity.WebhookSubscription, error)
+}
+
+type DeliveryRepo interface {
+	InsertDeliveryTx(ctx context.Context, tx sqldb.Executable, input entity.WebhookDelivery) (entity.WebhookDelivery, error)
+	ListDeliveriesTx(ctx context.Context, tx sqldb.Queryable, subscriptionID uuid.UUID) ([]entity.WebhookDelivery, error)
+	FindDeliveryTx(
+		ctx context.Context, tx sqldb.Queryable, subscriptionID uuid.UUID, id uuid.UUID,
+	) (entity.WebhookDelivery, error)
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import "github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+
+// goverter:converter
+// goverter:output:file ./webhook_mapper.go
+// goverter:name WebhookMapper
+// goverter:extend github.com/dyxj/bigbackend/pkg/mapx:MapTime
+// goverter:extend github.com/dyxj/bigbackend/pkg/mapx:MapUUID
+// goverter:extend SplitEventTypes
+// goverter:extend JoinEventTypes
+type Mapper interface {
+	SubscriptionModelToEntity(source Subscription) entity.WebhookSubscription
+	SubscriptionEntityToModel(source entity.WebhookSubscription) Subscription
+	DeliveryModelToEntity(source Delivery) entity.WebhookDelivery
+	DeliveryEntityToModel(source entity.WebhookDelivery) Delivery
+	// goverter:ignoreMissing
+	CreateRequestToModel(source CreateRequest) Subscription
+	ModelToResponse(source Subscription) Response
+	DeliveryModelToResponse(source Delivery) DeliveryResponse
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/google/uuid"
+)
+
+// Publisher queues deliveries of events, see Dispatcher.
+type Publisher struct {
+	repo PublisherRepo
+}
+
+func NewPublisher(repo PublisherRepo) *Publisher {
+	return &Publisher{repo: repo}
+}
+
+// PublishTx queues a delivery of an event of eventType for every subscription of the tenant of ctx subscribing
+// to it, data is marshalled as JSON. Deliveries are queued in tx, they are only dispatched when the change
+// publishing the event is committed.
+func (p *Publisher) PublishTx(ctx context.Context, tx sqldb.Executable, eventType string, data any) error {
+	event := NewEvent(eventType, data)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	_, err = p.repo.InsertEventDeliveriesTx(ctx, tx, event.ID, event.Type, string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+type PublisherRepo interface {
+	InsertEventDeliveriesTx(
+		ctx context.Context, tx sqldb.Executable, eventID uuid.UUID, eventType string, payload string,
+	) (int64, error)
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/validx"
+	"github.com/google/uuid"
+)
+
+type CreateRequest struct {
+	URL        string   `json:"url" validate:"required,max=2000"`
+	EventTypes []string `json:"eventTypes" validate:"required,min=1,max=20"`
+}
+
+// Validate requires an absolute http or https URL and event types of the EventTypes catalogue.
+func (r *CreateRequest) Validate() *errorx.ValidationError {
+	vErr := validx.Struct(r)
+	addError := func(property, message string) {
+		if vErr == nil {
+			vErr = &errorx.ValidationError{Properties: map[string]string{}}
+		}
+		if _, ok := vErr.Properties[property]; !ok {
+			vErr.Properties[property] = message
+		}
+	}
+
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		addError("url", "must be an absolute http or https URL")
+	}
+	for _, t := range r.EventTypes {
+		if !IsEventType(t) {
+			addError("eventTypes", "unknown event type "+t)
+		}
+	}
+	return vErr
+}
+
+type Response struct {
+	ID           uuid.UUID  `json:"id"`
+	URL          string     `json:"url"`
+	EventTypes   []string   `json:"eventTypes"`
+	Status       string     `json:"status"`
+	FailureCount int32      `json:"failureCount"`
+	DisableTime  *time.Time `json:"disableTime"`
+	CreateTime   time.Time  `json:"createTime"`
+	UpdateTime   time.Time  `json:"updateTime"`
+	Version      int32      `json:"version"`
+}
+
+// SecretResponse returned on create, Secret is the only time the signing secret is shown.
+type SecretResponse struct {
+	Subscription Response `json:"subscription"`
+	Secret       string   `json:"secret"`
+}
+
+type ListResponse struct {
+	Subscriptions []Response `json:"subscriptions"`
+}
+
+type DeliveryResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	SubscriptionID  uuid.UUID  `json:"subscriptionId"`
+	EventID         uuid.UUID  `json:"eventId"`
+	EventType       string     `json:"eventType"`
+	Payload         string     `json:"payload"`
+	Status          string     `json:"status"`
+	AttemptCount    int32      `json:"attemptCount"`
+	NextAttemptTime time.Time  `json:"nextAttemptTime"`
+	LastAttemptTime *time.Time `json:"lastAttemptTime"`
+	LastStatusCode  *int32     `json:"lastStatusCode"`
+	LastError       *string    `json:"lastError"`
+	CreateTime      time.Time  `json:"createTime"`
+	UpdateTime      time.Time  `json:"updateTime"`
+	Version         int32      `json:"version"`
+}
+
+type DeliveryListResponse struct {
+	Deliveries []DeliveryResponse `json:"deliveries"`
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    CreateRequest
+		properties []string
+	}{
+		{
+			name:    "valid",
+			request: CreateRequest{URL: "https://example.com/hook", EventTypes: []string{EventUserProfileCreated}},
+		},
+		{
+			name:       "relative url",
+			request:    CreateRequest{URL: "/hook", EventTypes: []string{EventUserProfileCreated}},
+			properties: []string{"url"},
+		},
+		{
+			name:       "unsupported scheme",
+			request:    CreateRequest{URL: "ftp://example.com/hook", EventTypes: []string{EventUserProfileCreated}},
+			properties: []string{"url"},
+		},
+		{
+			name:       "unknown event type",
+			request:    CreateRequest{URL: "https://example.com/hook", EventTypes: []string{"user_profile.deleted"}},
+			properties: []string{"eventTypes"},
+		},
+		{
+			name:       "no event types",
+			request:    CreateRequest{URL: "https://example.com/hook", EventTypes: []string{}},
+			properties: []string{"eventTypes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vErr := tt.request.Validate()
+			if tt.properties == nil {
+				assert.Nil(t, vErr)
+				return
+			}
+			if assert.NotNil(t, vErr) {
+				for _, p := range tt.properties {
+					assert.Contains(t, vErr.Properties, p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/google/uuid"
+)
+
+type subscriptionAuditableEntity struct{ E *entity.WebhookSubscription }
+
+func (a subscriptionAuditableEntity) GetID() uuid.UUID          { return a.E.ID }
+func (a subscriptionAuditableEntity) SetID(id uuid.UUID)        { a.E.ID = id }
+func (a subscriptionAuditableEntity) SetCreateTime(t time.Time) { a.E.CreateTime = t }
+func (a subscriptionAuditableEntity) SetUpdateTime(t time.Time) { a.E.UpdateTime = t }
+func (a subscriptionAuditableEntity) GetVersion() int32         { return a.E.Version }
+func (a subscriptionAuditableEntity) SetVersion(v int32)        { a.E.Version = v }
+
+type deliveryAuditableEntity struct{ E *entity.WebhookDelivery }
+
+func (a deliveryAuditableEntity) GetID() uuid.UUID          { return a.E.ID }
+func (a deliveryAuditableEntity) SetID(id uuid.UUID)        { a.E.ID = id }
+func (a deliveryAuditableEntity) SetCreateTime(t time.Time) { a.E.CreateTime = t }
+func (a deliveryAuditableEntity) SetUpdateTime(t time.Time) { a.E.UpdateTime = t }
+func (a deliveryAuditableEntity) GetVersion() int32         { return a.E.Version }
+func (a deliveryAuditableEntity) SetVersion(v int32)        { a.E.Version = v }
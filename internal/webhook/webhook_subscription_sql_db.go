@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
+	"github.com/dyxj/bigbackend/pkg/audit"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SubscriptionSQLDB statements are expected to run in tenant.TxManager transactions,
+// rows of other tenants are hidden by row level security.
+type SubscriptionSQLDB struct {
+	logger *zap.Logger
+}
+
+func NewSubscriptionSQLDB(logger *zap.Logger) *SubscriptionSQLDB {
+	return &SubscriptionSQLDB{logger: logger}
+}
+
+// InsertSubscriptionTx inserts a new subscription into the database.
+// Ignores and automatically sets ID, CreateTime, UpdateTime, Version fields from input,
+// and TenantID from the tenant of ctx.
+func (s *SubscriptionSQLDB) InsertSubscriptionTx(
+	ctx context.Context, tx sqldb.Executable, input entity.WebhookSubscription,
+) (entity.WebhookSubscription, error) {
+	logx.FromContext(ctx, s.logger).Debug("inserting webhook subscription", zap.String("url", input.URL))
+
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+	input.TenantID = tenantID
+
+	audit.SetInsertFields(subscriptionAuditableEntity{E: &input})
+
+	stmt := table.WebhookSubscription.
+		INSERT(table.WebhookSubscription.AllColumns).
+		MODEL(input)
+
+	_, err = stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+
+	logx.FromContext(ctx, s.logger).Debug("inserted webhook subscription", zap.Stringer("id", input.ID))
+
+	return input, nil
+}
+
+// ListSubscriptionsTx retrieves all subscriptions, newest first.
+func (s *SubscriptionSQLDB) ListSubscriptionsTx(
+	ctx context.Context, tx sqldb.Queryable,
+) ([]entity.WebhookSubscription, error) {
+	stmt := table.WebhookSubscription.
+		SELECT(table.WebhookSubscription.AllColumns).
+		FROM(table.WebhookSubscription).
+		ORDER_BY(table.WebhookSubscription.CreateTime.DESC())
+
+	var results []entity.WebhookSubscription
+	err := stmt.QueryContext(ctx, tx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindSubscriptionTx returns errorx.ErrNotFound when the subscription does not exist.
+func (s *SubscriptionSQLDB) FindSubscriptionTx(
+	ctx context.Context, tx sqldb.Queryable, id uuid.UUID,
+) (entity.WebhookSubscription, error) {
+	stmt := table.WebhookSubscription.
+		SELECT(table.WebhookSubscription.AllColumns).
+		FROM(table.WebhookSubscription).
+		WHERE(table.WebhookSubscription.ID.EQ(postgres.UUID(id)))
+
+	var result entity.WebhookSubscription
+	err := stmt.QueryContext(ctx, tx, &result)
+	if err != nil {
+		return entity.WebhookSubscription{}, resolveNoRows(err)
+	}
+
+	return result, nil
+}
+
+// ListSubscriptionsByIDsTx retrieves the subscriptions of ids, ids that do not exist are omitted.
+func (s *SubscriptionSQLDB) ListSubscriptionsByIDsTx(
+	ctx context.Context, tx sqldb.Queryable, ids []uuid.UUID,
+) ([]entity.WebhookSubscription, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	idExps := make([]postgres.Expression, 0, len(ids))
+	for _, id := range ids {
+		idExps = append(idExps, postgres.UUID(id))
+	}
+
+	stmt := table.WebhookSubscription.
+		SELECT(table.WebhookSubscription.AllColumns).
+		FROM(table.WebhookSubscription).
+		WHERE(table.WebhookSubscription.ID.IN(idExps...))
+
+	var results []entity.WebhookSubscription
+	err := stmt.QueryContext(ctx, tx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// DeleteSubscriptionTx deletes a subscription with its deliveries,
+// returns errorx.ErrNotFound when the subscription does not exist.
+func (s *SubscriptionSQLDB) DeleteSubscriptionTx(ctx context.Context, tx sqldb.Executable, id uuid.UUID) error {
+	logx.FromContext(ctx, s.logger).Debug("deleting webhook subscription", zap.Stringer("id", id))
+
+	stmt := table.WebhookSubscription.
+		DELETE().
+		WHERE(table.WebhookSubscription.ID.EQ(postgres.UUID(id)))
+
+	result, err := stmt.ExecContext(ctx, tx)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errorx.ErrNotFound
+	}
+
+	return nil
+}
+
+// EnableSubscriptionTx enables a subscription and resets its failure count,
+// returns errorx.ErrNotFound when the subscription does not exist.
+func (s *SubscriptionSQLDB) EnableSubscriptionTx(
+	ctx context.Context, tx sqldb.Queryable, id uuid.UUID,
+) (entity.WebhookSubscription, error) {
+	logx.FromContext(ctx, s.logger).Debug("enabling webhook subscription", zap.Stringer("id", id))
+
+	stmt := table.WebhookSubscription.
+		UPDATE().
+		SET(
+			table.WebhookSubscription.Status.SET(postgres.String(string(SubscriptionStatusEnabled))),
+			table.WebhookSubscription.FailureCount.SET(postgres.Int32(0)),
+			table.WebhookSubscription.DisableTime.SET(postgres.TimestampzExp(postgres.NULL)),
+			table.WebhookSubscription.UpdateTime.SET(postgres.TimestampzT(time.Now())),
+			table.WebhookSubscription.Version.SET(table.WebhookSubscription.Version.ADD(postgres.Int32(1))),
+		).
+		WHERE(table.WebhookSubscription.ID.EQ(postgres.UUID(id))).
+		RETURNING(table.WebhookSubscription.AllColumns)
+
+	var updated entity.WebhookSubscription
+	err := stmt.QueryContext(ctx, tx, &updated)
+	if err != nil {
+		return entity.WebhookSubscription{}, resolveNoRows(err)
+	}
+
+	return updated, nil
+}
+
+// ResetFailuresTx resets the failure count after a successful attempt, a no-op when already zero.
+func (s *SubscriptionSQLDB) ResetFailuresTx(ctx context.Context, tx sqldb.Executable, id uuid.UUID) error {
+	stmt := table.WebhookSubscription.
+		UPDATE().
+		SET(
+			table.WebhookSubscription.FailureCount.SET(postgres.Int32(0)),
+			table.WebhookSubscription.UpdateTime.SET(postgres.TimestampzT(time.Now())),
+			table.WebhookSubscription.Version.SET(table.WebhookSubscription.Version.ADD(postgres.Int32(1))),
+		).
+		WHERE(postgres.AND(
+			table.WebhookSubscription.ID.EQ(postgres.UUID(id)),
+			table.WebhookSubscription.FailureCount.GT(postgres.Int32(0)),
+		))
+
+	_, err := stmt.ExecContext(ctx, tx)
+	return err
+}
+
+// IncrementFailuresTx increments the failure count after a failed attempt, disabling the subscription
+// once it reaches disableAfter. Evaluated in a single statement, concurrent failures are all counted.
+func (s *SubscriptionSQLDB) IncrementFailuresTx(
+	ctx context.Context, tx sqldb.Queryable, id uuid.UUID, disableAfter int32,
+) (entity.WebhookSubscription, error) {
+	now := postgres.TimestampzT(time.Now())
+	failureCount := table.WebhookSubscription.FailureCount.ADD(postgres.Int32(1))
+	reachedLimit := failureCount.GT_EQ(postgres.Int32(disableAfter))
+
+	stmt := table.WebhookSubscription.
+		UPDATE().
+		SET(
+			table.WebhookSubscription.FailureCount.SET(failureCount),
+			table.WebhookSubscription.Status.SET(postgres.StringExp(
+				postgres.CASE().
+					WHEN(reachedLimit).THEN(postgres.String(string(SubscriptionStatusDisabled))).
+					ELSE(table.WebhookSubscription.Status),
+			)),
+			table.WebhookSubscription.DisableTime.SET(postgres.TimestampzExp(
+				postgres.CASE().
+					WHEN(reachedLimit).THEN(postgres.COALESCE(table.WebhookSubscription.DisableTime, now)).
+					ELSE(table.WebhookSubscription.DisableTime),
+			)),
+			table.WebhookSubscription.UpdateTime.SET(now),
+			table.WebhookSubscription.Version.SET(table.WebhookSubscription.Version.ADD(postgres.Int32(1))),
+		).
+		WHERE(table.WebhookSubscription.ID.EQ(postgres.UUID(id))).
+		RETURNING(table.WebhookSubscription.AllColumns)
+
+	var updated entity.WebhookSubscription
+	err := stmt.QueryContext(ctx, tx, &updated)
+	if err != nil {
+		return entity.WebhookSubscription{}, resolveNoRows(err)
+	}
+
+	return updated, nil
+}
+
+func resolveNoRows(err error) error {
+	if errors.Is(err, qrm.ErrNoRows) {
+		return errorx.ErrNotFound
+	}
+	return err
+}
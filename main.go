@@ -12,12 +12,15 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/dyxj/bigbackend/internal/app"
 	"github.com/dyxj/bigbackend/internal/config"
 	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/monitoring"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
 	"go.uber.org/automaxprocs/maxprocs"
+	"go.uber.org/zap"
 )
 
 func init() {
@@ -42,8 +45,9 @@ func main() {
 		log.Panicf("failed to load config: %v", err)
 	}
 
-	// Initialize logger
-	logger, err := logx.InitLogger()
+	// Initialize logger, its level is shared with the admin listener toggling it
+	logLevel := zap.NewAtomicLevelAt(zap.InfoLevel)
+	logger, err := logx.InitLogger(logx.WithLevel(logLevel))
 	if err != nil {
 		log.Panicf("failed to init logger: %v", err)
 	}
@@ -76,11 +80,24 @@ func main() {
 		log.Panicf("failed to run database migrations: %v", err)
 	}
 
+	// Metrics are scraped from the admin listener, not collected without it
+	var metrics *monitoring.Metrics
+	if cfg.HTTPServerConfig.AdminEnabled() {
+		metrics = monitoring.NewMetrics("bigbackend")
+		metrics.RecordAppInfo(monitoring.ReadBuildInfo())
+		collector := monitoring.NewCollector(metrics, dbConn, 15*time.Second)
+		collector.Start()
+		defer collector.Stop()
+	}
+
 	server := app.NewServer(
 		logger,
 		dbConn,
 		cfg.HTTPServerConfig,
-		nil,
+		cfg.AuthConfig,
+		metrics,
+		app.WithLogLevel(logLevel),
+		app.WithEffectiveConfig(config.Redacted(cfg)),
 	)
 
 	errSig := server.Run()
@@ -0,0 +1,3 @@
+BEGIN;
+DROP TABLE IF EXISTS role_permission;
+COMMIT;
\ No newline at end of file
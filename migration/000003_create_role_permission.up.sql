@@ -0,0 +1,9 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS role_permission
+(
+    role        TEXT        NOT NULL,
+    permission  TEXT        NOT NULL,
+    create_time TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT role_permission_pk PRIMARY KEY (role, permission)
+);
+COMMIT;
\ No newline at end of file
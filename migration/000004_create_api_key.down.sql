@@ -0,0 +1,3 @@
+BEGIN;
+DROP TABLE IF EXISTS api_key;
+COMMIT;
\ No newline at end of file
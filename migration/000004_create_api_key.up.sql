@@ -0,0 +1,19 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS api_key
+(
+    id             UUID        NOT NULL,
+    name           TEXT        NOT NULL,
+    owner          TEXT        NOT NULL,
+    prefix         TEXT        NOT NULL,
+    secret_hash    TEXT        NOT NULL,
+    scope          TEXT        NOT NULL,
+    expiry_time    TIMESTAMPTZ,
+    last_used_time TIMESTAMPTZ,
+    revoke_time    TIMESTAMPTZ,
+    create_time    TIMESTAMPTZ NOT NULL,
+    update_time    TIMESTAMPTZ NOT NULL,
+    version        INTEGER     NOT NULL,
+    CONSTRAINT api_key_pk PRIMARY KEY (id),
+    CONSTRAINT api_key_prefix_uk UNIQUE (prefix)
+);
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,9 @@
+BEGIN;
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit
+(
+    key TEXT        NOT NULL,
+    tat TIMESTAMPTZ NOT NULL,
+    CONSTRAINT rate_limit_pk PRIMARY KEY (key)
+);
+CREATE INDEX IF NOT EXISTS rate_limit_tat_idx ON rate_limit (tat);
+COMMIT;
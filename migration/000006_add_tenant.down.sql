@@ -0,0 +1,24 @@
+BEGIN;
+DROP POLICY IF EXISTS user_invitation_tenant_isolation ON user_invitation;
+ALTER TABLE user_invitation NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE user_invitation DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS user_profile_tenant_isolation ON user_profile;
+ALTER TABLE user_profile NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE user_profile DISABLE ROW LEVEL SECURITY;
+
+-- Fails when an email or user has rows in several tenants, which cannot be merged back.
+DROP INDEX user_invitation_accepted_pending_email_uk;
+CREATE UNIQUE INDEX user_invitation_accepted_pending_email_uk
+    ON user_invitation (email)
+    WHERE status IN ('ACCEPTED', 'PENDING');
+ALTER TABLE user_invitation
+    DROP COLUMN tenant_id;
+
+ALTER TABLE user_profile
+    DROP CONSTRAINT user_profile_tenant_id_user_id_uk;
+ALTER TABLE user_profile
+    ADD CONSTRAINT user_profile_user_id_uk UNIQUE (user_id);
+ALTER TABLE user_profile
+    DROP COLUMN tenant_id;
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+-- Rows created before tenancy are assigned to the nil tenant, the default only backfills existing rows.
+ALTER TABLE user_profile
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE user_profile
+    ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE user_profile
+    DROP CONSTRAINT user_profile_user_id_uk;
+ALTER TABLE user_profile
+    ADD CONSTRAINT user_profile_tenant_id_user_id_uk UNIQUE (tenant_id, user_id);
+
+ALTER TABLE user_invitation
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE user_invitation
+    ALTER COLUMN tenant_id DROP DEFAULT;
+DROP INDEX user_invitation_accepted_pending_email_uk;
+CREATE UNIQUE INDEX user_invitation_accepted_pending_email_uk
+    ON user_invitation (tenant_id, email)
+    WHERE status IN ('ACCEPTED', 'PENDING');
+
+-- Rows are visible to, and writable by, transactions of their tenant only, see tenant.TxManager.
+-- Without app.tenant_id the setting is NULL and no row matches.
+-- FORCE applies the policies to the table owner, which the application connects as.
+ALTER TABLE user_profile ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_profile FORCE ROW LEVEL SECURITY;
+CREATE POLICY user_profile_tenant_isolation ON user_profile
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::UUID);
+
+ALTER TABLE user_invitation ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_invitation FORCE ROW LEVEL SECURITY;
+CREATE POLICY user_invitation_tenant_isolation ON user_invitation
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::UUID);
+COMMIT;
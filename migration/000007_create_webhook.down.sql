@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook_subscription;
+COMMIT;
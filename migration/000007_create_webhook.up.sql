@@ -0,0 +1,59 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS webhook_subscription
+(
+    id            UUID        NOT NULL,
+    tenant_id     UUID        NOT NULL,
+    url           TEXT        NOT NULL,
+    secret        TEXT        NOT NULL,
+    event_types   TEXT        NOT NULL,
+    status        TEXT        NOT NULL,
+    failure_count INTEGER     NOT NULL,
+    disable_time  TIMESTAMPTZ,
+    create_time   TIMESTAMPTZ NOT NULL,
+    update_time   TIMESTAMPTZ NOT NULL,
+    version       INTEGER     NOT NULL,
+    CONSTRAINT webhook_subscription_pk PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery
+(
+    id                UUID        NOT NULL,
+    tenant_id         UUID        NOT NULL,
+    subscription_id   UUID        NOT NULL,
+    event_id          UUID        NOT NULL,
+    event_type        TEXT        NOT NULL,
+    payload           TEXT        NOT NULL,
+    status            TEXT        NOT NULL,
+    attempt_count     INTEGER     NOT NULL,
+    next_attempt_time TIMESTAMPTZ NOT NULL,
+    last_attempt_time TIMESTAMPTZ,
+    last_status_code  INTEGER,
+    last_error        TEXT,
+    create_time       TIMESTAMPTZ NOT NULL,
+    update_time       TIMESTAMPTZ NOT NULL,
+    version           INTEGER     NOT NULL,
+    CONSTRAINT webhook_delivery_pk PRIMARY KEY (id),
+    CONSTRAINT webhook_delivery_subscription_fk FOREIGN KEY (subscription_id)
+        REFERENCES webhook_subscription (id) ON DELETE CASCADE
+);
+-- Queue of deliveries due, polled by the dispatcher
+CREATE INDEX webhook_delivery_pending_idx
+    ON webhook_delivery (next_attempt_time)
+    WHERE status = 'PENDING';
+CREATE INDEX webhook_delivery_subscription_id_idx
+    ON webhook_delivery (subscription_id, create_time);
+
+-- As tenant scoped tables, see 000006_add_tenant. The dispatcher delivers for every tenant
+-- with app.tenant_all, see tenant.WithAll.
+ALTER TABLE webhook_subscription ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_subscription FORCE ROW LEVEL SECURITY;
+CREATE POLICY webhook_subscription_tenant_isolation ON webhook_subscription
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::UUID
+        OR current_setting('app.tenant_all', true) = 'on');
+
+ALTER TABLE webhook_delivery ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_delivery FORCE ROW LEVEL SECURITY;
+CREATE POLICY webhook_delivery_tenant_isolation ON webhook_delivery
+    USING (tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::UUID
+        OR current_setting('app.tenant_all', true) = 'on');
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE api_key
+    DROP COLUMN tenant_id;
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+-- Keys created before tenancy are assigned to the nil tenant, which claims no tenant, they must be reissued
+-- to access tenants unless granted tenants:any.
+ALTER TABLE api_key
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE api_key
+    ALTER COLUMN tenant_id DROP DEFAULT;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE user_invitation
+    DROP COLUMN inviter;
+COMMIT;
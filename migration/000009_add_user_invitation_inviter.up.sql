@@ -0,0 +1,8 @@
+BEGIN;
+-- Subject of the principal inviting, status changes are only notified to them.
+-- Invitations created before are of an unknown inviter.
+ALTER TABLE user_invitation
+    ADD COLUMN inviter TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_invitation
+    ALTER COLUMN inviter DROP DEFAULT;
+COMMIT;
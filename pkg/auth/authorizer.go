@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+)
+
+const DefaultAdminScope = "admin"
+
+// Authorizer decides whether the caller may access resources owned by subject.
+type Authorizer interface {
+	AuthorizeSubject(ctx context.Context, subject string) error
+}
+
+// SubjectAuthorizer allows principals to access their own resources, or any with the admin scope.
+type SubjectAuthorizer struct {
+	adminScope string
+}
+
+func NewSubjectAuthorizer(adminScope string) *SubjectAuthorizer {
+	return &SubjectAuthorizer{adminScope: adminScope}
+}
+
+// AuthorizeSubject returns errorx.ErrUnauthorized without a principal and errorx.ErrForbidden when denied.
+func (a *SubjectAuthorizer) AuthorizeSubject(ctx context.Context, subject string) error {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrMissingToken
+	}
+	if p.Subject == subject || (a.adminScope != "" && p.HasScope(a.adminScope)) {
+		return nil
+	}
+	return fmt.Errorf("%w: subject %q cannot access resources of %q", errorx.ErrForbidden, p.Subject, subject)
+}
+
+type allowAll struct{}
+
+func (allowAll) AuthorizeSubject(context.Context, string) error {
+	return nil
+}
+
+// AllowAll authorizes every request, used when authentication is disabled.
+func AllowAll() Authorizer {
+	return allowAll{}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubjectAuthorizer_AuthorizeSubject(t *testing.T) {
+	tests := []struct {
+		name        string
+		principal   *Principal
+		subject     string
+		expectedErr error
+	}{
+		{name: "anonymous", subject: "user-1", expectedErr: errorx.ErrUnauthorized},
+		{name: "owner", principal: &Principal{Subject: "user-1"}, subject: "user-1"},
+		{name: "other", principal: &Principal{Subject: "user-2"}, subject: "user-1", expectedErr: errorx.ErrForbidden},
+		{name: "admin", principal: &Principal{Subject: "user-2", Scopes: []string{DefaultAdminScope}}, subject: "user-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = WithPrincipal(ctx, *tt.principal)
+			}
+
+			err := NewSubjectAuthorizer(DefaultAdminScope).AuthorizeSubject(ctx, tt.subject)
+
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestAllowAll(t *testing.T) {
+	assert.NoError(t, AllowAll().AuthorizeSubject(context.Background(), "user-1"))
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"google.golang.org/grpc"
+)
+
+// MetadataKeyAuthorization carries the credentials of gRPC calls, formatted as the Authorization header.
+const MetadataKeyAuthorization = "authorization"
+
+// UnaryServerInterceptor authenticates gRPC calls by the authorization metadata, as Authenticate does requests.
+// Rejections are returned as errors wrapping errorx.ErrUnauthorized, e.g. mapped by grpcx.ErrorRegistry.
+func (m *Middleware) UnaryServerInterceptor(
+	ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	ctx, _, err := m.authenticate(ctx, grpcx.MetadataValue(ctx, MetadataKeyAuthorization))
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
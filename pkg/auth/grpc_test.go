@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestMiddleware_UnaryServerInterceptor(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgES256)
+	verifier := newTestVerifier(t, key)
+	token := key.sign(t, validClaims())
+	m := NewMiddleware(zap.NewNop(), verifier, nil)
+
+	t.Run("valid", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(MetadataKeyAuthorization, "Bearer "+token))
+
+		var principal Principal
+		_, err := m.UnaryServerInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+			principal, _ = PrincipalFromContext(ctx)
+			return nil, nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", principal.Subject)
+	})
+
+	for name, md := range map[string]metadata.MD{
+		"missing": {},
+		"invalid": metadata.Pairs(MetadataKeyAuthorization, "Bearer "+token+"x"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(t.Context(), md)
+
+			_, err := m.UnaryServerInterceptor(ctx, nil, nil, func(context.Context, any) (any, error) {
+				t.Fatal("handler must not be called")
+				return nil, nil
+			})
+
+			assert.ErrorIs(t, err, errorx.ErrUnauthorized)
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+const DefaultKeySetCacheTTL = 15 * time.Minute
+const DefaultKeySetRefreshTimeout = 10 * time.Second
+const defaultMinRefreshInterval = 30 * time.Second
+const maxJWKSBytes = 1 << 20 // 1 MiB
+
+var ErrKeyNotFound = errors.New("key not found")
+var ErrMalformedKey = errors.New("malformed key")
+
+// KeySet resolves the public key identified by kid, kid may be empty when the token does not specify one.
+type KeySet interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// KeySource returns a JSON Web Key Set document, see https://www.rfc-editor.org/rfc/rfc7517#section-5.
+type KeySource func(ctx context.Context) ([]byte, error)
+
+// FileSource reads the key set from path on every refresh.
+func FileSource(path string) KeySource {
+	return func(_ context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
+
+// URLSource fetches the key set from url, e.g. an identity provider's jwks_uri.
+func URLSource(client *http.Client, url string) KeySource {
+	return func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	}
+}
+
+// CachedKeySet caches keys of a KeySource, refreshing them once expired.
+//
+// Keys are rotated by refreshing early when a token references an unknown kid.
+// Refreshes are attempted at most once per minimum refresh interval, so that neither unknown kids
+// nor an unavailable source once expired flood the source. Stale keys are kept when a refresh fails.
+//
+// Cached keys are read concurrently, refreshes are shared by the callers triggering them and not cancelled
+// with their requests, bounded by the refresh timeout instead.
+type CachedKeySet struct {
+	logger *zap.Logger
+	source KeySource
+
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+	refreshTimeout     time.Duration
+	now                func() time.Time
+
+	refreshGroup singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	refreshedAt time.Time
+	attemptedAt time.Time
+}
+
+type KeySetOption func(*CachedKeySet)
+
+// WithCacheTTL overrides DefaultKeySetCacheTTL.
+func WithCacheTTL(ttl time.Duration) KeySetOption {
+	return func(ks *CachedKeySet) {
+		ks.ttl = ttl
+	}
+}
+
+// WithMinRefreshInterval limits refreshes triggered by unknown kids or retried once expired.
+func WithMinRefreshInterval(interval time.Duration) KeySetOption {
+	return func(ks *CachedKeySet) {
+		ks.minRefreshInterval = interval
+	}
+}
+
+// WithRefreshTimeout overrides DefaultKeySetRefreshTimeout.
+func WithRefreshTimeout(timeout time.Duration) KeySetOption {
+	return func(ks *CachedKeySet) {
+		ks.refreshTimeout = timeout
+	}
+}
+
+func NewCachedKeySet(logger *zap.Logger, source KeySource, options ...KeySetOption) *CachedKeySet {
+	ks := &CachedKeySet{
+		logger:             logger,
+		source:             source,
+		ttl:                DefaultKeySetCacheTTL,
+		minRefreshInterval: defaultMinRefreshInterval,
+		refreshTimeout:     DefaultKeySetRefreshTimeout,
+		now:                time.Now,
+	}
+	for _, opt := range options {
+		opt(ks)
+	}
+	return ks
+}
+
+// Key returns the key identified by kid. When kid is empty the only key of the set is returned.
+func (ks *CachedKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok, expired := ks.cached(kid)
+	if expired {
+		ks.refresh(ctx)
+		key, ok, _ = ks.cached(kid)
+	}
+	if !ok {
+		ks.refresh(ctx)
+		key, ok, _ = ks.cached(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+	}
+	return key, nil
+}
+
+// Refresh reloads the keys, e.g. at startup to fail fast on misconfiguration.
+func (ks *CachedKeySet) Refresh(ctx context.Context) error {
+	keys, err := ks.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = keys
+	ks.refreshedAt = ks.now()
+	ks.attemptedAt = ks.refreshedAt
+	return nil
+}
+
+// cached looks kid up in the cached keys, expired when they should be refreshed.
+func (ks *CachedKeySet) cached(kid string) (crypto.PublicKey, bool, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.lookup(kid)
+	expired := ks.keys == nil || ks.now().Sub(ks.refreshedAt) >= ks.ttl
+	return key, ok, expired
+}
+
+// refresh reloads the keys unless attempted within the minimum refresh interval, concurrent callers share the
+// refresh in flight. Callers stop waiting once ctx is done, the refresh goes on for the callers after them.
+func (ks *CachedKeySet) refresh(ctx context.Context) {
+	done := ks.refreshGroup.DoChan("refresh", func() (any, error) {
+		ks.mu.Lock()
+		now := ks.now()
+		if now.Sub(ks.attemptedAt) < ks.minRefreshInterval {
+			ks.mu.Unlock()
+			return nil, nil
+		}
+		ks.attemptedAt = now
+		ks.mu.Unlock()
+
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ks.refreshTimeout)
+		defer cancel()
+		keys, err := ks.load(loadCtx)
+
+		ks.mu.Lock()
+		defer ks.mu.Unlock()
+		if err != nil {
+			ks.logger.Error("failed to refresh key set, using cached keys",
+				zap.Error(err), zap.Int("cachedKeys", len(ks.keys)))
+			return nil, nil
+		}
+		ks.keys = keys
+		ks.refreshedAt = now
+		return nil, nil
+	})
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+func (ks *CachedKeySet) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := ks.source(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key set: %w", err)
+	}
+	keys, err := ParseKeySet(data)
+	if len(keys) == 0 && err != nil {
+		return nil, err
+	}
+	if err != nil {
+		ks.logger.Warn("skipped malformed keys of key set", zap.Error(err), zap.Int("keys", len(keys)))
+	}
+	return keys, nil
+}
+
+func (ks *CachedKeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(ks.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseKeySet parses RSA, EC P-256 and Ed25519 signing keys of a JSON Web Key Set by kid.
+// Keys of other types or uses are skipped, allowing providers to publish them alongside.
+//
+// Malformed keys are skipped as well, so that one of them does not reject the whole set. Their errors are
+// returned wrapping ErrMalformedKey along with the keys parsed, nil only when the set itself is invalid.
+func ParseKeySet(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse key set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	var errs []error
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w %q: %w", ErrMalformedKey, k.Kid, err))
+			continue
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, errors.Join(errs...)
+}
+
+// publicKey returns nil for unsupported key types.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 coordinates")
+		}
+		// Uncompressed point encoding, validated to be on the curve
+		point := append([]byte{4}, append(x, y...)...)
+		key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
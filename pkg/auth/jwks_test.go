@@ -0,0 +1,325 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseKeySet(t *testing.T) {
+	rsaKey := newTestKey(t, "rsa", AlgRS256)
+	ecKey := newTestKey(t, "ec", AlgES256)
+	edKey := newTestKey(t, "ed", AlgEdDSA)
+
+	rsaJWK, err := json.Marshal(rsaKey.jwk())
+	require.NoError(t, err)
+	data := []byte(`{"keys":[
+		` + string(rsaJWK) + `,
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"},
+		{"kty":"oct","kid":"symmetric","k":"c2VjcmV0"},
+		{"kty":"EC","kid":"p384","crv":"P-384","x":"AA","y":"AA"}
+	]}`)
+
+	keys, err := ParseKeySet(data)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Contains(t, keys, "rsa")
+
+	keys, err = ParseKeySet(jwksOf(t, rsaKey, ecKey, edKey))
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+}
+
+func TestParseKeySet_Invalid(t *testing.T) {
+	keys, err := ParseKeySet([]byte(`keys`))
+	assert.Error(t, err)
+	assert.Nil(t, keys)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid rsa exponent", data: `{"keys":[{"kty":"RSA","kid":"1","n":"AQAB","e":"AQ"}]}`},
+		{name: "invalid ec point", data: `{"keys":[{"kty":"EC","kid":"1","crv":"P-256",` +
+			`"x":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA","y":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}]}`},
+		{name: "invalid ed25519 size", data: `{"keys":[{"kty":"OKP","kid":"1","crv":"Ed25519","x":"AA"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseKeySet([]byte(tt.data))
+			assert.ErrorIs(t, err, ErrMalformedKey)
+			assert.Empty(t, keys)
+		})
+	}
+}
+
+func TestParseKeySet_SkipsMalformed(t *testing.T) {
+	key := newTestKey(t, "valid", AlgEdDSA)
+	keyJWK, err := json.Marshal(key.jwk())
+	require.NoError(t, err)
+
+	keys, err := ParseKeySet([]byte(`{"keys":[
+		{"kty":"OKP","kid":"malformed","crv":"Ed25519","x":"AA"},
+		` + string(keyJWK) + `
+	]}`))
+	assert.ErrorIs(t, err, ErrMalformedKey)
+	assert.ErrorContains(t, err, `"malformed"`)
+	assert.Len(t, keys, 1)
+	assert.Contains(t, keys, "valid")
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newCountingKeySet(source *atomic.Value, calls *atomic.Int32, clock *fakeClock, options ...KeySetOption) *CachedKeySet {
+	ks := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		data, _ := source.Load().([]byte)
+		if data == nil {
+			return nil, errors.New("source unavailable")
+		}
+		return data, nil
+	}, options...)
+	ks.now = clock.Now
+	return ks
+}
+
+func TestCachedKeySet_Caching(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	var source atomic.Value
+	source.Store(jwksOf(t, key))
+	var calls atomic.Int32
+	clock := &fakeClock{now: time.Now()}
+
+	ks := newCountingKeySet(&source, &calls, clock, WithCacheTTL(time.Minute))
+
+	_, err := ks.Key(t.Context(), "kid-1")
+	require.NoError(t, err)
+	_, err = ks.Key(t.Context(), "kid-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	clock.now = clock.now.Add(time.Minute)
+	_, err = ks.Key(t.Context(), "kid-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCachedKeySet_Rotation(t *testing.T) {
+	oldKey := newTestKey(t, "old", AlgEdDSA)
+	newKey := newTestKey(t, "new", AlgEdDSA)
+	var source atomic.Value
+	source.Store(jwksOf(t, oldKey))
+	var calls atomic.Int32
+	clock := &fakeClock{now: time.Now()}
+
+	ks := newCountingKeySet(&source, &calls, clock, WithMinRefreshInterval(10*time.Second))
+
+	_, err := ks.Key(t.Context(), "old")
+	require.NoError(t, err)
+
+	source.Store(jwksOf(t, newKey))
+
+	// Refresh on unknown kid is rate limited
+	_, err = ks.Key(t.Context(), "new")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Equal(t, int32(1), calls.Load())
+
+	clock.now = clock.now.Add(10 * time.Second)
+	_, err = ks.Key(t.Context(), "new")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+
+	_, err = ks.Key(t.Context(), "old")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestCachedKeySet_StaleOnRefreshFailure(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	var source atomic.Value
+	source.Store(jwksOf(t, key))
+	var calls atomic.Int32
+	clock := &fakeClock{now: time.Now()}
+
+	ks := newCountingKeySet(&source, &calls, clock, WithCacheTTL(time.Minute))
+	_, err := ks.Key(t.Context(), "kid-1")
+	require.NoError(t, err)
+
+	source.Store([]byte(nil))
+	clock.now = clock.now.Add(time.Minute)
+
+	_, err = ks.Key(t.Context(), "kid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCachedKeySet_RefreshFailureRateLimited(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	var source atomic.Value
+	source.Store(jwksOf(t, key))
+	var calls atomic.Int32
+	clock := &fakeClock{now: time.Now()}
+
+	ks := newCountingKeySet(&source, &calls, clock, WithCacheTTL(time.Minute), WithMinRefreshInterval(10*time.Second))
+	_, err := ks.Key(t.Context(), "kid-1")
+	require.NoError(t, err)
+
+	source.Store([]byte(nil))
+	clock.now = clock.now.Add(time.Minute)
+
+	// Expired keys are refreshed once per interval while the source is unavailable
+	for range 3 {
+		_, err = ks.Key(t.Context(), "kid-1")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	clock.now = clock.now.Add(10 * time.Second)
+	_, err = ks.Key(t.Context(), "kid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCachedKeySet_LookupNotBlockedByRefresh(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	ks := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) {
+		if calls.Add(1) > 1 {
+			<-release
+		}
+		return jwksOf(t, key), nil
+	}, WithMinRefreshInterval(10*time.Second))
+	clock := &fakeClock{now: time.Now()}
+	ks.now = clock.Now
+	_, err := ks.Key(t.Context(), "kid-1")
+	require.NoError(t, err)
+	clock.now = clock.now.Add(10 * time.Second)
+
+	// Unknown kids of concurrent callers share one refresh
+	refreshed := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := ks.Key(t.Context(), "unknown")
+			refreshed <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+
+	_, err = ks.Key(t.Context(), "kid-1")
+	assert.NoError(t, err, "cached keys read while refreshing")
+
+	close(release)
+	for range 3 {
+		assert.ErrorIs(t, <-refreshed, ErrKeyNotFound)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCachedKeySet_RefreshNotCancelledWithCaller(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	type loadState struct {
+		err         error
+		hasDeadline bool
+	}
+	loaded := make(chan loadState, 1)
+
+	ks := NewCachedKeySet(zap.NewNop(), func(ctx context.Context) ([]byte, error) {
+		_, hasDeadline := ctx.Deadline()
+		loaded <- loadState{err: ctx.Err(), hasDeadline: hasDeadline}
+		return jwksOf(t, key), nil
+	}, WithRefreshTimeout(time.Minute))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, _ = ks.Key(ctx, "kid-1")
+
+	state := <-loaded
+	assert.NoError(t, state.err)
+	assert.True(t, state.hasDeadline, "refresh without a timeout")
+
+	// Keys refreshed for the cancelled caller are cached
+	assert.Eventually(t, func() bool {
+		_, err := ks.Key(t.Context(), "kid-1")
+		return err == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestCachedKeySet_SkipsMalformed(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	keyJWK, err := json.Marshal(key.jwk())
+	require.NoError(t, err)
+	data := []byte(`{"keys":[{"kty":"RSA","kid":"malformed","n":"AQAB","e":"AQ"},` + string(keyJWK) + `]}`)
+
+	ks := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) { return data, nil })
+	_, err = ks.Key(t.Context(), "kid-1")
+	assert.NoError(t, err)
+}
+
+func TestCachedKeySet_EmptyKid(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	other := newTestKey(t, "kid-2", AlgEdDSA)
+
+	single := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) { return jwksOf(t, key), nil })
+	_, err := single.Key(t.Context(), "")
+	assert.NoError(t, err)
+
+	multiple := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) { return jwksOf(t, key, other), nil })
+	_, err = multiple.Key(t.Context(), "")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestCachedKeySet_Refresh(t *testing.T) {
+	ks := NewCachedKeySet(zap.NewNop(), FileSource(filepath.Join(t.TempDir(), "missing.json")))
+	assert.Error(t, ks.Refresh(t.Context()))
+}
+
+func TestFileSource(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgRS256)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksOf(t, key), 0o600))
+
+	ks := NewCachedKeySet(zap.NewNop(), FileSource(path))
+	require.NoError(t, ks.Refresh(t.Context()))
+
+	_, err := ks.Key(t.Context(), "kid-1")
+	assert.NoError(t, err)
+}
+
+func TestURLSource(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgES256)
+	data := jwksOf(t, key)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jwks.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	got, err := URLSource(srv.Client(), srv.URL+"/jwks.json")(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	_, err = URLSource(srv.Client(), srv.URL+"/missing")(t.Context())
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+const DefaultClockSkew = 30 * time.Second
+
+// ErrInvalidToken wraps errorx.ErrUnauthorized, the reason is appended for logging only.
+var ErrInvalidToken = fmt.Errorf("%w: invalid token", errorx.ErrUnauthorized)
+
+// Claims registered claims of a JWT, see https://www.rfc-editor.org/rfc/rfc7519#section-4.1,
+// scopes granted by "scope" or "scp", roles granted by "roles", and the tenant of the subject by "tenant_id".
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	Scopes    []string
+	Roles     []string
+	Tenant    string
+}
+
+// Verifier verifies signed JWTs against a KeySet.
+type Verifier struct {
+	keys      KeySet
+	issuer    string
+	audience  string
+	clockSkew time.Duration
+	algs      []string
+	now       func() time.Time
+}
+
+type VerifierOption func(*Verifier)
+
+// WithClockSkew overrides DefaultClockSkew tolerated on exp and nbf.
+func WithClockSkew(skew time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.clockSkew = skew
+	}
+}
+
+// WithAlgorithms restricts accepted algorithms, defaults to RS256, ES256 and EdDSA.
+func WithAlgorithms(algs ...string) VerifierOption {
+	return func(v *Verifier) {
+		v.algs = algs
+	}
+}
+
+// NewVerifier creates a Verifier accepting tokens issued by issuer for audience.
+func NewVerifier(keys KeySet, issuer, audience string, options ...VerifierOption) *Verifier {
+	v := &Verifier{
+		keys:      keys,
+		issuer:    issuer,
+		audience:  audience,
+		clockSkew: DefaultClockSkew,
+		algs:      []string{AlgRS256, AlgES256, AlgEdDSA},
+		now:       time.Now,
+	}
+	for _, opt := range options {
+		opt(v)
+	}
+	return v
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Verify checks the signature, issuer, audience, expiry and not before of token.
+// Errors wrap ErrInvalidToken, or the KeySet error when keys cannot be resolved.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed header: %w", ErrInvalidToken, err)
+	}
+	// alg is attacker controlled, only configured asymmetric algorithms are accepted
+	if !slices.Contains(v.algs, h.Alg) {
+		return Claims{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, h.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	key, err := v.keys.Key(ctx, h.Kid)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+		}
+		return Claims{}, err
+	}
+
+	signed := parts[0] + "." + parts[1]
+	if err := verifySignature(h.Alg, key, []byte(signed), signature); err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	var raw rawClaims
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed claims: %w", ErrInvalidToken, err)
+	}
+	claims := raw.claims()
+
+	if err := v.validate(claims); err != nil {
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validate(c Claims) error {
+	now := v.now()
+	if c.Issuer != v.issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if !slices.Contains(c.Audience, v.audience) {
+		return fmt.Errorf("unexpected audience %q", c.Audience)
+	}
+	if c.Subject == "" {
+		return errors.New("missing subject")
+	}
+	if c.ExpiresAt.IsZero() {
+		return errors.New("missing expiry")
+	}
+	if !now.Before(c.ExpiresAt.Add(v.clockSkew)) {
+		return errors.New("token expired")
+	}
+	if !c.NotBefore.IsZero() && now.Add(v.clockSkew).Before(c.NotBefore) {
+		return errors.New("token not yet valid")
+	}
+	return nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	errSignature := errors.New("invalid signature")
+	errKeyType := fmt.Errorf("key type %T does not match algorithm %s", key, alg)
+
+	switch alg {
+	case AlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errKeyType
+		}
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return errSignature
+		}
+	case AlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			return errKeyType
+		}
+		// JWS ECDSA signatures are the fixed size concatenation of r and s
+		if len(signature) != 64 {
+			return errSignature
+		}
+		digest := sha256.Sum256(signed)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errSignature
+		}
+	case AlgEdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return errKeyType
+		}
+		if !ed25519.Verify(pub, signed, signature) {
+			return errSignature
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+type rawClaims struct {
+	Issuer    string      `json:"iss"`
+	Subject   string      `json:"sub"`
+	Audience  stringList  `json:"aud"`
+	ExpiresAt json.Number `json:"exp"`
+	NotBefore json.Number `json:"nbf"`
+	IssuedAt  json.Number `json:"iat"`
+	Scope     string      `json:"scope"`
+	Scp       stringList  `json:"scp"`
+	Roles     stringList  `json:"roles"`
+	TenantID  string      `json:"tenant_id"`
+}
+
+func (c rawClaims) claims() Claims {
+	scopes := strings.Fields(c.Scope)
+	for _, scp := range c.Scp {
+		scopes = append(scopes, strings.Fields(scp)...)
+	}
+	return Claims{
+		Issuer:    c.Issuer,
+		Subject:   c.Subject,
+		Audience:  c.Audience,
+		ExpiresAt: numericDate(c.ExpiresAt),
+		NotBefore: numericDate(c.NotBefore),
+		IssuedAt:  numericDate(c.IssuedAt),
+		Scopes:    scopes,
+		Roles:     c.Roles,
+		Tenant:    c.TenantID,
+	}
+}
+
+// numericDate converts seconds since epoch, possibly fractional, zero when absent or invalid.
+func numericDate(n json.Number) time.Time {
+	if n == "" {
+		return time.Time{}
+	}
+	seconds, err := n.Float64()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(seconds * 1000))
+}
+
+// stringList decodes a string or an array of strings, as used by "aud" and "scp".
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = []string{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testIssuer = "https://issuer.test"
+const testAudience = "bigbackend"
+
+type testKey struct {
+	kid  string
+	alg  string
+	priv crypto.Signer
+}
+
+func newTestKey(t *testing.T, kid, alg string) testKey {
+	t.Helper()
+	var priv crypto.Signer
+	var err error
+	switch alg {
+	case AlgRS256:
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	}
+	require.NoError(t, err)
+	return testKey{kid: kid, alg: alg, priv: priv}
+}
+
+func (k testKey) jwk() map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := k.priv.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": k.kid, "use": "sig",
+			"n": b64(pub.N.Bytes()), "e": b64(big.NewInt(int64(pub.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		point, _ := pub.Bytes()
+		return map[string]string{"kty": "EC", "kid": k.kid, "crv": "P-256",
+			"x": b64(point[1:33]), "y": b64(point[33:])}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": k.kid, "crv": "Ed25519", "x": b64(pub)}
+	}
+	return nil
+}
+
+func jwksOf(t *testing.T, keys ...testKey) []byte {
+	t.Helper()
+	set := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	return data
+}
+
+func (k testKey) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	return k.signWithHeader(t, map[string]any{"alg": k.alg, "kid": k.kid, "typ": "JWT"}, claims)
+}
+
+func (k testKey) signWithHeader(t *testing.T, header, claims map[string]any) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+
+	var signature []byte
+	switch priv := k.priv.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, priv, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(priv, []byte(signed))
+	}
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":       testIssuer,
+		"sub":       "user-1",
+		"aud":       testAudience,
+		"exp":       now.Add(time.Hour).Unix(),
+		"nbf":       now.Add(-time.Minute).Unix(),
+		"iat":       now.Unix(),
+		"scope":     "profile:read profile:write",
+		"roles":     []string{"support"},
+		"tenant_id": "6f1c1b52-4bb4-4a8e-9d9e-0b6a3cf1d9f1",
+	}
+}
+
+func newTestVerifier(t *testing.T, keys ...testKey) *Verifier {
+	t.Helper()
+	data := jwksOf(t, keys...)
+	keySet := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) { return data, nil })
+	return NewVerifier(keySet, testIssuer, testAudience, WithClockSkew(0))
+}
+
+func TestVerifier_Verify_Algorithms(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			key := newTestKey(t, "kid-"+alg, alg)
+			verifier := newTestVerifier(t, key)
+
+			claims, err := verifier.Verify(t.Context(), key.sign(t, validClaims()))
+
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Subject)
+			assert.Equal(t, testIssuer, claims.Issuer)
+			assert.Equal(t, []string{testAudience}, claims.Audience)
+			assert.Equal(t, []string{"profile:read", "profile:write"}, claims.Scopes)
+			assert.Equal(t, []string{"support"}, claims.Roles)
+			assert.Equal(t, "6f1c1b52-4bb4-4a8e-9d9e-0b6a3cf1d9f1", claims.Tenant)
+			assert.False(t, claims.ExpiresAt.IsZero())
+		})
+	}
+}
+
+func TestVerifier_Verify_Claims(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgES256)
+	verifier := newTestVerifier(t, key)
+
+	tests := []struct {
+		name   string
+		modify func(c map[string]any)
+		valid  bool
+	}{
+		{name: "audience list", modify: func(c map[string]any) { c["aud"] = []string{"other", testAudience} }, valid: true},
+		{name: "scp list", modify: func(c map[string]any) { delete(c, "scope"); c["scp"] = []string{"admin"} }, valid: true},
+		{name: "roles", modify: func(c map[string]any) { c["roles"] = []string{"support"} }, valid: true},
+		{name: "wrong issuer", modify: func(c map[string]any) { c["iss"] = "https://evil.test" }},
+		{name: "wrong audience", modify: func(c map[string]any) { c["aud"] = "other" }},
+		{name: "missing subject", modify: func(c map[string]any) { delete(c, "sub") }},
+		{name: "missing expiry", modify: func(c map[string]any) { delete(c, "exp") }},
+		{name: "expired", modify: func(c map[string]any) { c["exp"] = time.Now().Add(-time.Second).Unix() }},
+		{name: "not yet valid", modify: func(c map[string]any) { c["nbf"] = time.Now().Add(time.Minute).Unix() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(claims)
+
+			_, err := verifier.Verify(t.Context(), key.sign(t, claims))
+
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			assert.ErrorIs(t, err, errorx.ErrUnauthorized)
+		})
+	}
+}
+
+func TestVerifier_Verify_ClockSkew(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgEdDSA)
+	data := jwksOf(t, key)
+	keySet := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) { return data, nil })
+	verifier := NewVerifier(keySet, testIssuer, testAudience, WithClockSkew(time.Minute))
+
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+
+	_, err := verifier.Verify(t.Context(), key.sign(t, claims))
+	assert.NoError(t, err)
+}
+
+func TestVerifier_Verify_Rejected(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgRS256)
+	other := newTestKey(t, "kid-1", AlgRS256)
+	verifier := newTestVerifier(t, key)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed", token: "not-a-token"},
+		{name: "signed by other key", token: other.sign(t, validClaims())},
+		{name: "none algorithm", token: key.signWithHeader(t, map[string]any{"alg": "none", "kid": "kid-1"}, validClaims())},
+		{name: "symmetric algorithm", token: key.signWithHeader(t, map[string]any{"alg": "HS256", "kid": "kid-1"}, validClaims())},
+		{name: "algorithm not matching key", token: key.signWithHeader(t, map[string]any{"alg": AlgES256, "kid": "kid-1"}, validClaims())},
+		{name: "unknown kid", token: key.signWithHeader(t, map[string]any{"alg": AlgRS256, "kid": "kid-2"}, validClaims())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(t.Context(), tt.token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerifier_Verify_TamperedClaims(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgES256)
+	verifier := newTestVerifier(t, key)
+
+	token := key.sign(t, validClaims())
+	tampered := validClaims()
+	tampered["sub"] = "admin"
+	payload, err := json.Marshal(tampered)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+	_, err = verifier.Verify(t.Context(), parts[0]+"."+parts[1]+"."+parts[2])
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_Verify_AlgorithmsRestricted(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgRS256)
+	data := jwksOf(t, key)
+	keySet := NewCachedKeySet(zap.NewNop(), func(context.Context) ([]byte, error) { return data, nil })
+	verifier := NewVerifier(keySet, testIssuer, testAudience, WithAlgorithms(AlgEdDSA))
+
+	_, err := verifier.Verify(t.Context(), key.sign(t, validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"go.uber.org/zap"
+)
+
+const HeaderKeyAuthorization = "Authorization"
+const headerKeyWWWAuthenticate = "WWW-Authenticate"
+const bearerPrefix = "Bearer "
+const apiKeyPrefix = "ApiKey "
+
+// ErrMissingToken wraps errorx.ErrUnauthorized.
+var ErrMissingToken = fmt.Errorf("%w: missing bearer token", errorx.ErrUnauthorized)
+
+// ErrInvalidAPIKey wraps errorx.ErrUnauthorized, returned by APIKeyVerifier implementations.
+var ErrInvalidAPIKey = fmt.Errorf("%w: invalid api key", errorx.ErrUnauthorized)
+
+// ErrorResponseWriter writes the response of a failed authentication, e.g. httpx.ErrorRegistry.WriteError.
+type ErrorResponseWriter func(err error, w http.ResponseWriter, r *http.Request)
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (Claims, error)
+}
+
+// APIKeyVerifier resolves the principal of an API key, errors wrap ErrInvalidAPIKey when rejected.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (Principal, error)
+}
+
+// Middleware authenticates requests by bearer token, see https://www.rfc-editor.org/rfc/rfc6750,
+// or by "Authorization: ApiKey <key>" when an APIKeyVerifier is configured.
+type Middleware struct {
+	logger    *zap.Logger
+	verifier  TokenVerifier
+	apiKeys   APIKeyVerifier
+	errWriter ErrorResponseWriter
+}
+
+type MiddlewareOption func(*Middleware)
+
+// WithAPIKeyVerifier accepts API keys in addition to bearer tokens, e.g. for service callers.
+func WithAPIKeyVerifier(verifier APIKeyVerifier) MiddlewareOption {
+	return func(m *Middleware) {
+		m.apiKeys = verifier
+	}
+}
+
+func NewMiddleware(
+	logger *zap.Logger,
+	verifier TokenVerifier,
+	errWriter ErrorResponseWriter,
+	options ...MiddlewareOption,
+) *Middleware {
+	m := &Middleware{logger: logger, verifier: verifier, errWriter: errWriter}
+	for _, opt := range options {
+		opt(m)
+	}
+	return m
+}
+
+// Authenticate rejects requests without valid credentials,
+// otherwise the Principal is placed in the context and attached to logx.FromContext loggers.
+func (m *Middleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, challenge, err := m.authenticate(r.Context(), r.Header.Get(HeaderKeyAuthorization))
+		if err != nil {
+			w.Header().Set(headerKeyWWWAuthenticate, challenge)
+			m.errWriter(err, w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticate verifies the credentials of an Authorization value, returning ctx carrying the Principal,
+// or the WWW-Authenticate challenge of the rejection.
+func (m *Middleware) authenticate(ctx context.Context, authorization string) (context.Context, string, error) {
+	var p Principal
+	var err error
+
+	if key, ok := credentials(authorization, apiKeyPrefix); ok && m.apiKeys != nil {
+		p, err = m.apiKeys.VerifyAPIKey(ctx, key)
+		if err != nil {
+			return ctx, "ApiKey", err
+		}
+	} else if token, ok := credentials(authorization, bearerPrefix); ok {
+		p, err = m.verifyToken(ctx, token)
+		if err != nil {
+			return ctx, `Bearer error="invalid_token"`, err
+		}
+	} else {
+		return ctx, "Bearer", ErrMissingToken
+	}
+
+	ctx = WithPrincipal(ctx, p)
+	ctx = logx.WithFields(ctx, logx.UserID(p.Subject))
+	return ctx, "", nil
+}
+
+func (m *Middleware) verifyToken(ctx context.Context, token string) (Principal, error) {
+	claims, err := m.verifier.Verify(ctx, token)
+	if err != nil {
+		return Principal{}, err
+	}
+	return Principal{
+		Subject: claims.Subject,
+		Scopes:  claims.Scopes,
+		Roles:   claims.Roles,
+		Claims:  claims,
+	}, nil
+}
+
+// PrincipalSubject returns the subject of the authenticated principal, empty if anonymous.
+// Suitable as idempotency.PrincipalFunc.
+func PrincipalSubject(r *http.Request) string {
+	return ContextSubject(r.Context())
+}
+
+// ContextSubject returns the subject of the authenticated principal of ctx, empty if anonymous.
+func ContextSubject(ctx context.Context) string {
+	p, _ := PrincipalFromContext(ctx)
+	return p.Subject
+}
+
+// PrincipalTenant returns the tenant claimed by the authenticated principal, empty if anonymous or unclaimed.
+func PrincipalTenant(ctx context.Context) string {
+	p, _ := PrincipalFromContext(ctx)
+	return p.Claims.Tenant
+}
+
+// credentials returns the credentials of an Authorization value with scheme prefix, e.g. "Bearer ".
+func credentials(value string, prefix string) (string, bool) {
+	// Scheme is case-insensitive
+	if len(value) < len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+		return "", false
+	}
+	credentials := strings.TrimSpace(value[len(prefix):])
+	return credentials, credentials != ""
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMiddleware_Authenticate(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgES256)
+	verifier := newTestVerifier(t, key)
+	token := key.sign(t, validClaims())
+
+	tests := []struct {
+		name                 string
+		authorization        string
+		expectedStatus       int
+		expectedAuthenticate string
+	}{
+		{name: "valid", authorization: "Bearer " + token, expectedStatus: http.StatusOK},
+		{name: "case insensitive scheme", authorization: "bearer " + token, expectedStatus: http.StatusOK},
+		{name: "missing", expectedStatus: http.StatusUnauthorized, expectedAuthenticate: "Bearer"},
+		{name: "other scheme", authorization: "Basic dXNlcjpwYXNz", expectedStatus: http.StatusUnauthorized, expectedAuthenticate: "Bearer"},
+		{name: "empty token", authorization: "Bearer  ", expectedStatus: http.StatusUnauthorized, expectedAuthenticate: "Bearer"},
+		{
+			name:                 "invalid",
+			authorization:        "Bearer " + token + "x",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer error="invalid_token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writtenErr error
+			errWriter := func(err error, w http.ResponseWriter, r *http.Request) {
+				writtenErr = err
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+
+			var principal Principal
+			var logFields []zap.Field
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ = PrincipalFromContext(r.Context())
+				logFields = logx.Fields(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				r.Header.Set(HeaderKeyAuthorization, tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			NewMiddleware(zap.NewNop(), verifier, errWriter).Authenticate(next).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedAuthenticate, w.Header().Get(headerKeyWWWAuthenticate))
+			if tt.expectedStatus != http.StatusOK {
+				assert.ErrorIs(t, writtenErr, errorx.ErrUnauthorized)
+				return
+			}
+			assert.Equal(t, "user-1", principal.Subject)
+			assert.True(t, principal.HasScope("profile:read"))
+			assert.Equal(t, []zap.Field{logx.UserID("user-1")}, logFields)
+		})
+	}
+}
+
+type errVerifier struct {
+	err error
+}
+
+func (v errVerifier) Verify(context.Context, string) (Claims, error) {
+	return Claims{}, v.err
+}
+
+func TestMiddleware_Authenticate_KeySetUnavailable(t *testing.T) {
+	keySetErr := assert.AnError
+	var writtenErr error
+	errWriter := func(err error, w http.ResponseWriter, r *http.Request) {
+		writtenErr = err
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderKeyAuthorization, "Bearer token")
+
+	NewMiddleware(zap.NewNop(), errVerifier{err: keySetErr}, errWriter).
+		Authenticate(http.NotFoundHandler()).
+		ServeHTTP(httptest.NewRecorder(), r)
+
+	// Rendered by the error writer as a server error rather than an authentication failure
+	assert.ErrorIs(t, writtenErr, keySetErr)
+	assert.NotErrorIs(t, writtenErr, errorx.ErrUnauthorized)
+}
+
+type staticAPIKeyVerifier struct {
+	key string
+}
+
+func (v staticAPIKeyVerifier) VerifyAPIKey(_ context.Context, key string) (Principal, error) {
+	if key != v.key {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	return Principal{Subject: "apikey:1", Scopes: []string{"batch"}}, nil
+}
+
+func TestMiddleware_Authenticate_APIKey(t *testing.T) {
+	key := newTestKey(t, "kid-1", AlgES256)
+	verifier := newTestVerifier(t, key)
+
+	tests := []struct {
+		name                 string
+		authorization        string
+		apiKeys              APIKeyVerifier
+		expectedStatus       int
+		expectedAuthenticate string
+	}{
+		{name: "valid", authorization: "ApiKey secret", apiKeys: staticAPIKeyVerifier{key: "secret"}, expectedStatus: http.StatusOK},
+		{name: "case insensitive scheme", authorization: "apikey secret", apiKeys: staticAPIKeyVerifier{key: "secret"}, expectedStatus: http.StatusOK},
+		{
+			name:                 "invalid",
+			authorization:        "ApiKey other",
+			apiKeys:              staticAPIKeyVerifier{key: "secret"},
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: "ApiKey",
+		},
+		{
+			name:                 "not accepted without verifier",
+			authorization:        "ApiKey secret",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: "Bearer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writtenErr error
+			errWriter := func(err error, w http.ResponseWriter, r *http.Request) {
+				writtenErr = err
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+
+			var principal Principal
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ = PrincipalFromContext(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(HeaderKeyAuthorization, tt.authorization)
+			w := httptest.NewRecorder()
+
+			var options []MiddlewareOption
+			if tt.apiKeys != nil {
+				options = append(options, WithAPIKeyVerifier(tt.apiKeys))
+			}
+			NewMiddleware(zap.NewNop(), verifier, errWriter, options...).Authenticate(next).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedAuthenticate, w.Header().Get(headerKeyWWWAuthenticate))
+			if tt.expectedStatus != http.StatusOK {
+				assert.ErrorIs(t, writtenErr, errorx.ErrUnauthorized)
+				return
+			}
+			assert.Equal(t, "apikey:1", principal.Subject)
+			assert.True(t, principal.HasScope("batch"))
+		})
+	}
+}
+
+func TestPrincipalSubject(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Empty(t, PrincipalSubject(r))
+
+	r = r.WithContext(WithPrincipal(r.Context(), Principal{Subject: "user-1"}))
+	assert.Equal(t, "user-1", PrincipalSubject(r))
+}
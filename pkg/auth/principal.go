@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Scopes  []string
+	Roles   []string
+	// Permissions granted directly rather than through Roles, e.g. the scopes of API keys
+	Permissions []string
+	Claims      Claims
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalCtxKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, p)
+}
+
+// PrincipalFromContext returns the principal set by Middleware, false if anonymous.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+)
+
+// ErrInvalidStaticToken wraps errorx.ErrUnauthorized.
+var ErrInvalidStaticToken = fmt.Errorf("%w: invalid token", errorx.ErrUnauthorized)
+
+// StaticTokenMiddleware authenticates requests bearing token, e.g. operators and scrapers of private listeners
+// which do not hold API credentials. Rejections are written by errWriter.
+func StaticTokenMiddleware(token string, errWriter ErrorResponseWriter) func(http.Handler) http.Handler {
+	// Compared as digests, the comparison does not leak the length of token
+	expected := sha256.Sum256([]byte(token))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bearer, ok := credentials(r.Header.Get(HeaderKeyAuthorization), bearerPrefix)
+			if !ok {
+				w.Header().Set(headerKeyWWWAuthenticate, "Bearer")
+				errWriter(ErrMissingToken, w, r)
+				return
+			}
+
+			actual := sha256.Sum256([]byte(bearer))
+			if subtle.ConstantTimeCompare(actual[:], expected[:]) != 1 {
+				w.Header().Set(headerKeyWWWAuthenticate, `Bearer error="invalid_token"`)
+				errWriter(ErrInvalidStaticToken, w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
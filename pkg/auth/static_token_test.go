@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticTokenMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		expectedErr   error
+	}{
+		{name: "valid", authorization: "Bearer admin-token"},
+		{name: "case insensitive scheme", authorization: "bearer admin-token"},
+		{name: "missing", expectedErr: ErrMissingToken},
+		{name: "other scheme", authorization: "ApiKey admin-token", expectedErr: ErrMissingToken},
+		{name: "invalid", authorization: "Bearer admin-token-x", expectedErr: ErrInvalidStaticToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writtenErr error
+			errWriter := func(err error, w http.ResponseWriter, r *http.Request) {
+				writtenErr = err
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			called := false
+			next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true })
+
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				r.Header.Set(HeaderKeyAuthorization, tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			StaticTokenMiddleware("admin-token", errWriter)(next).ServeHTTP(w, r)
+
+			if tt.expectedErr == nil {
+				assert.True(t, called)
+				assert.Equal(t, http.StatusOK, w.Code)
+				return
+			}
+			assert.False(t, called)
+			assert.ErrorIs(t, writtenErr, tt.expectedErr)
+			assert.NotEmpty(t, w.Header().Get(headerKeyWWWAuthenticate))
+		})
+	}
+}
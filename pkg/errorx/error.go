@@ -7,9 +7,12 @@ import (
 
 var ErrNotFound = errors.New("not found")
 var ErrConflict = errors.New("conflict")
+var ErrUnauthorized = errors.New("unauthorized")
+var ErrForbidden = errors.New("forbidden")
 
 const validationErrorPrefix = "validation error"
 const uniqueViolationErrorPrefix = "unique violation error"
+const badRequestErrorPrefix = "bad request error"
 const errSeparator = " | "
 const keyValueSeparator = ":"
 
@@ -29,6 +32,21 @@ func (e *UniqueViolationError) Error() string {
 	return writeErrorWithProperties(uniqueViolationErrorPrefix, e.Properties)
 }
 
+// BadRequestError malformed request, e.g. undecodable body or invalid URL parameters.
+// Message and Properties are returned to the client.
+type BadRequestError struct {
+	Message    string
+	Properties map[string]string
+}
+
+func (e *BadRequestError) Error() string {
+	prefix := badRequestErrorPrefix
+	if e.Message != "" {
+		prefix += keyValueSeparator + e.Message
+	}
+	return writeErrorWithProperties(prefix, e.Properties)
+}
+
 func writeErrorWithProperties(prefix string, properties map[string]string) string {
 	if properties == nil || len(properties) == 0 {
 		return prefix
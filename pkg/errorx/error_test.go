@@ -52,3 +52,25 @@ func TestUniqueViolationError(t *testing.T) {
 		assert.Equal(t, expectedMessage, err.Error())
 	})
 }
+
+func TestBadRequestError(t *testing.T) {
+	t.Run("with message and properties", func(t *testing.T) {
+		err := &BadRequestError{
+			Message: "invalid id",
+			Properties: map[string]string{
+				"error": "invalid UUID length: 3",
+			},
+		}
+
+		errMessages := strings.Split(err.Error(), " | ")
+		assert.Contains(t, errMessages, "bad request error:invalid id")
+		assert.Contains(t, errMessages, "error:invalid UUID length: 3")
+	})
+
+	t.Run("without message", func(t *testing.T) {
+		err := &BadRequestError{}
+
+		expectedMessage := "bad request error"
+		assert.Equal(t, expectedMessage, err.Error())
+	})
+}
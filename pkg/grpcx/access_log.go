@@ -0,0 +1,140 @@
+package grpcx
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const DefaultSlowCallThreshold = time.Second
+
+const metadataKeyUserAgent = "user-agent"
+
+var defaultAccessLogSkipMethods = []string{grpc_health_v1.Health_Check_FullMethodName}
+
+type accessLogConfig struct {
+	skipMethods       map[string]struct{}
+	successSampleRate float64
+	slowThreshold     time.Duration
+	random            func() float64
+}
+
+type AccessLogOption func(*accessLogConfig)
+
+// WithAccessLogSkipMethods replaces the full methods not logged, defaults to the health check.
+func WithAccessLogSkipMethods(methods ...string) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.skipMethods = toSet(methods)
+	}
+}
+
+// WithSuccessSampleRate logs the given fraction of fast successful calls, between 0 and 1.
+// Defaults to 1, failed and slow calls are always logged.
+func WithSuccessSampleRate(rate float64) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.successSampleRate = min(max(rate, 0), 1)
+	}
+}
+
+// WithSlowThreshold overrides DefaultSlowCallThreshold, calls taking longer are logged as warnings.
+// Non positive values disable slow call detection.
+func WithSlowThreshold(threshold time.Duration) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.slowThreshold = threshold
+	}
+}
+
+// AccessLogInterceptor logs a structured entry per call once the handler returns, the gRPC counterpart of
+// httpx.AccessLogMiddleware. It must run before ErrorRegistry.UnaryServerInterceptor to log the returned code.
+//
+// Entries are logged at error for codes returned as 5xx by HTTP, see serverErrorCodes, warn for other
+// failures and slow calls, and info otherwise.
+func AccessLogInterceptor(logger *zap.Logger, options ...AccessLogOption) grpc.UnaryServerInterceptor {
+	config := accessLogConfig{
+		skipMethods:       toSet(defaultAccessLogSkipMethods),
+		successSampleRate: 1,
+		slowThreshold:     DefaultSlowCallThreshold,
+		random:            rand.Float64,
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := config.skipMethods[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		duration := time.Since(start)
+
+		code := status.Code(err)
+		level := config.level(code, duration)
+		if level == zapcore.InfoLevel && !config.sampled() {
+			return resp, err
+		}
+
+		logx.FromContext(ctx, logger).Log(level, "grpc call",
+			zap.String("method", info.FullMethod),
+			zap.Stringer("code", code),
+			zap.Duration("latency", duration),
+			zap.String("peer", peerAddr(ctx)),
+			zap.String("userAgent", MetadataValue(ctx, metadataKeyUserAgent)),
+		)
+		return resp, err
+	}
+}
+
+// serverErrorCodes returned as 5xx by HTTP, see https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md.
+var serverErrorCodes = map[codes.Code]struct{}{
+	codes.Unknown:          {},
+	codes.DeadlineExceeded: {},
+	codes.Unimplemented:    {},
+	codes.Internal:         {},
+	codes.Unavailable:      {},
+	codes.DataLoss:         {},
+}
+
+func (c *accessLogConfig) level(code codes.Code, duration time.Duration) zapcore.Level {
+	_, isServerError := serverErrorCodes[code]
+	switch {
+	case isServerError:
+		return zapcore.ErrorLevel
+	case code != codes.OK:
+		return zapcore.WarnLevel
+	case c.slowThreshold > 0 && duration > c.slowThreshold:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+func (c *accessLogConfig) sampled() bool {
+	return c.successSampleRate >= 1 || c.random() < c.successSampleRate
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,92 @@
+package grpcx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func callAccessLog(
+	t *testing.T, method string, handler grpc.UnaryHandler, options ...AccessLogOption,
+) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(metadataKeyUserAgent, "test-agent"))
+	_, _ = AccessLogInterceptor(zap.New(core), options...)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return logs
+}
+
+func TestAccessLogInterceptor_Fields(t *testing.T) {
+	logs := callAccessLog(t, "/svc/Method", func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.InfoLevel, entry.Level)
+
+	fields := entry.ContextMap()
+	assert.Equal(t, "/svc/Method", fields["method"])
+	assert.Equal(t, codes.OK.String(), fields["code"])
+	assert.Contains(t, fields, "latency")
+	assert.Equal(t, "test-agent", fields["userAgent"])
+}
+
+func TestAccessLogInterceptor_Level(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		delay         time.Duration
+		expectedLevel zapcore.Level
+	}{
+		{name: "success", expectedLevel: zapcore.InfoLevel},
+		{name: "client error", err: status.Error(codes.NotFound, ""), expectedLevel: zapcore.WarnLevel},
+		{name: "server error", err: status.Error(codes.Internal, ""), expectedLevel: zapcore.ErrorLevel},
+		{name: "unavailable", err: status.Error(codes.Unavailable, ""), expectedLevel: zapcore.ErrorLevel},
+		{name: "slow", delay: 20 * time.Millisecond, expectedLevel: zapcore.WarnLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := callAccessLog(t, "/svc/Method", func(context.Context, any) (any, error) {
+				time.Sleep(tt.delay)
+				return nil, tt.err
+			}, WithSlowThreshold(10*time.Millisecond))
+
+			require.Equal(t, 1, logs.Len())
+			assert.Equal(t, tt.expectedLevel, logs.All()[0].Level)
+		})
+	}
+}
+
+func TestAccessLogInterceptor_SkipMethods(t *testing.T) {
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+
+	assert.Zero(t, callAccessLog(t, grpc_health_v1.Health_Check_FullMethodName, handler).Len())
+	assert.Equal(t, 1, callAccessLog(t, grpc_health_v1.Health_Check_FullMethodName, handler,
+		WithAccessLogSkipMethods()).Len())
+}
+
+func TestAccessLogInterceptor_SuccessSampleRate(t *testing.T) {
+	logs := callAccessLog(t, "/svc/Method", func(context.Context, any) (any, error) {
+		return nil, nil
+	}, WithSuccessSampleRate(0))
+	assert.Zero(t, logs.Len())
+
+	logs = callAccessLog(t, "/svc/Method", func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.InvalidArgument, "")
+	}, WithSuccessSampleRate(0))
+	assert.Equal(t, 1, logs.Len())
+}
@@ -0,0 +1,209 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const notFoundDefaultMessage = "entity not found"
+const unauthorizedDefaultMessage = "unauthorized"
+const forbiddenDefaultMessage = "forbidden"
+const conflictDefaultMessage = "entity already exists"
+const validationFailedDefaultMessage = "validation failed"
+const internalDefaultMessage = "internal server error"
+
+// ErrorMapping describes how a matched error is returned and logged.
+type ErrorMapping struct {
+	Code    codes.Code
+	Message string
+	// Details returned as field violations of errdetails.BadRequest.
+	Details  map[string]string
+	LogLevel zapcore.Level
+}
+
+type errorMatcher func(err error) (ErrorMapping, bool)
+
+// ErrorRegistry maps errors to gRPC statuses, the first registered match wins.
+// Unmatched errors are returned as codes.Internal, errors already carrying a status are returned as is.
+type ErrorRegistry struct {
+	logger   *zap.Logger
+	matchers []errorMatcher
+}
+
+// NewErrorRegistry creates a registry with mappings for errorx errors.
+func NewErrorRegistry(logger *zap.Logger) *ErrorRegistry {
+	reg := &ErrorRegistry{logger: logger}
+
+	reg.RegisterIs(errorx.ErrNotFound, ErrorMapping{
+		Code:     codes.NotFound,
+		Message:  notFoundDefaultMessage,
+		LogLevel: zapcore.InfoLevel,
+	})
+	reg.RegisterIs(errorx.ErrConflict, ErrorMapping{
+		Code:     codes.AlreadyExists,
+		Message:  conflictDefaultMessage,
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(errorx.ErrUnauthorized, ErrorMapping{
+		Code:     codes.Unauthenticated,
+		Message:  unauthorizedDefaultMessage,
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(errorx.ErrForbidden, ErrorMapping{
+		Code:     codes.PermissionDenied,
+		Message:  forbiddenDefaultMessage,
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(context.DeadlineExceeded, ErrorMapping{
+		Code:     codes.DeadlineExceeded,
+		Message:  context.DeadlineExceeded.Error(),
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(context.Canceled, ErrorMapping{
+		Code:     codes.Canceled,
+		Message:  context.Canceled.Error(),
+		LogLevel: zapcore.InfoLevel,
+	})
+	RegisterAs(reg, func(err *errorx.ValidationError) ErrorMapping {
+		return ErrorMapping{
+			Code:     codes.InvalidArgument,
+			Message:  validationFailedDefaultMessage,
+			Details:  err.Properties,
+			LogLevel: zapcore.WarnLevel,
+		}
+	})
+	RegisterAs(reg, func(err *errorx.BadRequestError) ErrorMapping {
+		return ErrorMapping{
+			Code:     codes.InvalidArgument,
+			Message:  err.Message,
+			Details:  err.Properties,
+			LogLevel: zapcore.WarnLevel,
+		}
+	})
+	// Properties are not returned as they may hold values of entities the client cannot access
+	RegisterAs(reg, func(err *errorx.UniqueViolationError) ErrorMapping {
+		return ErrorMapping{
+			Code:     codes.AlreadyExists,
+			Message:  conflictDefaultMessage,
+			LogLevel: zapcore.WarnLevel,
+		}
+	})
+
+	return reg
+}
+
+// RegisterIs maps errors matching target with errors.Is.
+func (reg *ErrorRegistry) RegisterIs(target error, mapping ErrorMapping) {
+	reg.matchers = append(reg.matchers, func(err error) (ErrorMapping, bool) {
+		return mapping, errors.Is(err, target)
+	})
+}
+
+// RegisterAs maps errors matching T with errors.As.
+func RegisterAs[T error](reg *ErrorRegistry, fn func(err T) ErrorMapping) {
+	reg.matchers = append(reg.matchers, func(err error) (ErrorMapping, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return ErrorMapping{}, false
+		}
+		return fn(target), true
+	})
+}
+
+// Resolve returns the mapping of err, with message overridden by WithMessage.
+func (reg *ErrorRegistry) Resolve(err error) ErrorMapping {
+	mapping := ErrorMapping{
+		Code:     codes.Internal,
+		Message:  internalDefaultMessage,
+		LogLevel: zapcore.ErrorLevel,
+	}
+	for _, match := range reg.matchers {
+		if m, ok := match(err); ok {
+			mapping = m
+			break
+		}
+	}
+
+	var mErr *messageError
+	if mapping.Code != codes.Internal && errors.As(err, &mErr) {
+		mapping.Message = mErr.message
+	}
+	return mapping
+}
+
+// Status logs err and returns it as a status error, see Resolve.
+func (reg *ErrorRegistry) Status(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	mapping := reg.Resolve(err)
+
+	logx.FromContext(ctx, reg.logger).Log(mapping.LogLevel, "rpc failed",
+		zap.Error(err),
+		zap.Stringer("code", mapping.Code),
+		zap.String("method", method),
+	)
+
+	st := status.New(mapping.Code, mapping.Message)
+	if len(mapping.Details) == 0 {
+		return st.Err()
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	// Sorted for stable responses
+	for _, field := range slices.Sorted(maps.Keys(mapping.Details)) {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: mapping.Details[field],
+		})
+	}
+	withDetails, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// UnaryServerInterceptor returns errors of handlers and inner interceptors as status errors, see Status.
+func (reg *ErrorRegistry) UnaryServerInterceptor(
+	ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, reg.Status(ctx, info.FullMethod, err)
+	}
+	return resp, nil
+}
+
+type messageError struct {
+	err     error
+	message string
+}
+
+func (e *messageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *messageError) Unwrap() error {
+	return e.err
+}
+
+// WithMessage overrides the client facing message of an error not returned as codes.Internal.
+func WithMessage(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &messageError{err: err, message: message}
+}
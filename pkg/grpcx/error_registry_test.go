@@ -0,0 +1,137 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorRegistry_Resolve(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	reg := NewErrorRegistry(zap.NewNop())
+	reg.RegisterIs(errCustom, ErrorMapping{
+		Code:     codes.FailedPrecondition,
+		Message:  "custom",
+		LogLevel: zapcore.WarnLevel,
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorMapping
+	}{
+		{
+			name: "not found",
+			err:  fmt.Errorf("wrapped: %w", errorx.ErrNotFound),
+			want: ErrorMapping{Code: codes.NotFound, Message: notFoundDefaultMessage, LogLevel: zapcore.InfoLevel},
+		},
+		{
+			name: "unauthorized",
+			err:  errorx.ErrUnauthorized,
+			want: ErrorMapping{Code: codes.Unauthenticated, Message: unauthorizedDefaultMessage,
+				LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "forbidden",
+			err:  errorx.ErrForbidden,
+			want: ErrorMapping{Code: codes.PermissionDenied, Message: forbiddenDefaultMessage,
+				LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "validation",
+			err:  &errorx.ValidationError{Properties: map[string]string{"email": "is required"}},
+			want: ErrorMapping{Code: codes.InvalidArgument, Message: validationFailedDefaultMessage,
+				Details: map[string]string{"email": "is required"}, LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "unique violation does not expose properties",
+			err:  &errorx.UniqueViolationError{Properties: map[string]string{"email": "a@b.c"}},
+			want: ErrorMapping{Code: codes.AlreadyExists, Message: conflictDefaultMessage, LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "deadline exceeded",
+			err:  fmt.Errorf("query: %w", context.DeadlineExceeded),
+			want: ErrorMapping{Code: codes.DeadlineExceeded, Message: context.DeadlineExceeded.Error(),
+				LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "custom registration",
+			err:  errCustom,
+			want: ErrorMapping{Code: codes.FailedPrecondition, Message: "custom", LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "message override",
+			err:  WithMessage(&errorx.UniqueViolationError{}, "user profile already exists"),
+			want: ErrorMapping{Code: codes.AlreadyExists, Message: "user profile already exists",
+				LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "message override ignored for internal errors",
+			err:  WithMessage(errors.New("db down"), "leaked"),
+			want: ErrorMapping{Code: codes.Internal, Message: internalDefaultMessage, LogLevel: zapcore.ErrorLevel},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reg.Resolve(tt.err))
+		})
+	}
+}
+
+func TestErrorRegistry_Status(t *testing.T) {
+	reg := NewErrorRegistry(zap.NewNop())
+
+	t.Run("details", func(t *testing.T) {
+		err := reg.Status(t.Context(), "/svc/Method", &errorx.ValidationError{
+			Properties: map[string]string{"lastName": "is required", "firstName": "is required"},
+		})
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, validationFailedDefaultMessage, st.Message())
+		require.Len(t, st.Details(), 1)
+		badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Len(t, badRequest.FieldViolations, 2)
+		assert.Equal(t, "firstName", badRequest.FieldViolations[0].Field)
+		assert.Equal(t, "lastName", badRequest.FieldViolations[1].Field)
+	})
+
+	t.Run("status errors returned as is", func(t *testing.T) {
+		original := status.Error(codes.Unavailable, "draining")
+		assert.Equal(t, original, reg.Status(t.Context(), "/svc/Method", original))
+	})
+}
+
+func TestErrorRegistry_UnaryServerInterceptor(t *testing.T) {
+	reg := NewErrorRegistry(zap.NewNop())
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+
+	_, err := reg.UnaryServerInterceptor(t.Context(), nil, info, func(context.Context, any) (any, error) {
+		return nil, errorx.ErrNotFound
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err := reg.UnaryServerInterceptor(t.Context(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestWithMessage_Nil(t *testing.T) {
+	assert.Nil(t, WithMessage(nil, "message"))
+}
@@ -0,0 +1,48 @@
+package grpcx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// RecoveryInterceptor returns panics of handlers as errors, returned as codes.Internal by ErrorRegistry,
+// rather than crashing the server. It must run after ErrorRegistry.UnaryServerInterceptor.
+func RecoveryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logx.FromContext(ctx, logger).Error("panic handling rpc",
+					zap.Any("panic", r),
+					zap.String("method", info.FullMethod),
+					zap.ByteString("stack", debug.Stack()),
+				)
+				err = fmt.Errorf("panic handling %s: %v", info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// ForServices applies interceptor to methods of the given services only, e.g. names of grpc.ServiceDesc,
+// other methods such as health checks are handled without it.
+func ForServices(interceptor grpc.UnaryServerInterceptor, services ...string) grpc.UnaryServerInterceptor {
+	set := toSet(services)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := set[serviceName(info.FullMethod)]; !ok {
+			return handler(ctx, req)
+		}
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+// serviceName of fullMethod, formatted as "/package.Service/Method".
+func serviceName(fullMethod string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service
+}
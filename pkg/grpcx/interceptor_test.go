@@ -0,0 +1,46 @@
+package grpcx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryInterceptor(t *testing.T) {
+	reg := NewErrorRegistry(zap.NewNop())
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+
+	_, err := reg.UnaryServerInterceptor(t.Context(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return RecoveryInterceptor(zap.NewNop())(ctx, req, info, func(context.Context, any) (any, error) {
+			panic("boom")
+		})
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "boom")
+}
+
+func TestForServices(t *testing.T) {
+	var intercepted []string
+	interceptor := ForServices(func(
+		ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (any, error) {
+		intercepted = append(intercepted, info.FullMethod)
+		return handler(ctx, req)
+	}, "pkg.v1.Service")
+
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+	for _, method := range []string{"/pkg.v1.Service/Get", "/grpc.health.v1.Health/Check", "/pkg.v1.ServiceOther/Get"} {
+		_, err := interceptor(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"/pkg.v1.Service/Get"}, intercepted)
+}
@@ -0,0 +1,41 @@
+package grpcx
+
+import (
+	"context"
+
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys are the lowercase HTTP headers of httpx.
+const (
+	MetadataKeyRequestID   = "x-request-id"
+	MetadataKeyTraceparent = "traceparent"
+)
+
+// RequestIDInterceptor accepts or generates the x-request-id and traceparent metadata, see httpx.WithRequestID,
+// and returns them in the response header. Handlers retrieve them with httpx.RequestIDFromContext.
+func RequestIDInterceptor(
+	ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	ctx, requestID, tc := httpx.WithRequestID(ctx,
+		MetadataValue(ctx, MetadataKeyRequestID), MetadataValue(ctx, MetadataKeyTraceparent))
+
+	// Fails only once headers are sent, which no handler has done yet
+	_ = grpc.SetHeader(ctx, metadata.Pairs(
+		MetadataKeyRequestID, requestID,
+		MetadataKeyTraceparent, tc.Traceparent(),
+	))
+
+	return handler(ctx, req)
+}
+
+// MetadataValue returns the first value of key in the incoming metadata of ctx, empty if not set.
+func MetadataValue(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,40 @@
+package grpcx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		expected string
+	}{
+		{name: "accepted", md: metadata.Pairs(MetadataKeyRequestID, "req-123"), expected: "req-123"},
+		{name: "generated", md: metadata.MD{}},
+		{name: "invalid replaced", md: metadata.Pairs(MetadataKeyRequestID, "bad id")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(t.Context(), tt.md)
+
+			var requestID string
+			_, _ = RequestIDInterceptor(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+				requestID = httpx.RequestIDFromContext(ctx)
+				return nil, nil
+			})
+
+			assert.NotEmpty(t, requestID)
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, requestID)
+			}
+			assert.NotContains(t, requestID, " ")
+		})
+	}
+}
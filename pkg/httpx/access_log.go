@@ -0,0 +1,141 @@
+package httpx
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const DefaultSlowRequestThreshold = time.Second
+
+var defaultAccessLogSkipPaths = []string{"/healthz", "/readyz", "/startupz"}
+
+type accessLogConfig struct {
+	skipPaths         map[string]struct{}
+	successSampleRate float64
+	slowThreshold     time.Duration
+	trustedProxies    []netip.Prefix
+	random            func() float64
+}
+
+type AccessLogOption func(*accessLogConfig)
+
+// WithAccessLogSkipPaths replaces the paths not logged, defaults to /healthz, /readyz and /startupz.
+func WithAccessLogSkipPaths(paths ...string) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.skipPaths = toSet(paths)
+	}
+}
+
+// WithSuccessSampleRate logs the given fraction of fast 2xx and 3xx responses, between 0 and 1.
+// Defaults to 1, failed and slow requests are always logged.
+func WithSuccessSampleRate(rate float64) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.successSampleRate = min(max(rate, 0), 1)
+	}
+}
+
+// WithSlowThreshold overrides DefaultSlowRequestThreshold, requests taking longer are logged as warnings.
+// Non positive values disable slow request detection.
+func WithSlowThreshold(threshold time.Duration) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.slowThreshold = threshold
+	}
+}
+
+// WithTrustedProxies sets the proxies allowed to report the client IP, see ClientIP.
+func WithTrustedProxies(prefixes []netip.Prefix) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.trustedProxies = prefixes
+	}
+}
+
+// AccessLogMiddleware logs a structured entry per request once the response is written.
+//
+// Entries are logged at error for 5xx, warn for 4xx and slow requests, and info otherwise,
+// event streams are long-lived and never slow.
+// with the request ID and trace ID when RequestIDMiddleware runs before it.
+func AccessLogMiddleware(logger *zap.Logger, options ...AccessLogOption) func(http.Handler) http.Handler {
+	config := accessLogConfig{
+		skipPaths:         toSet(defaultAccessLogSkipPaths),
+		successSampleRate: 1,
+		slowThreshold:     DefaultSlowRequestThreshold,
+		random:            rand.Float64,
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := config.skipPaths[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			interceptor := NewInterceptor(w, InterceptorHooks{})
+
+			next.ServeHTTP(interceptor.Wrap(), r)
+
+			duration := interceptor.Duration()
+			streaming := strings.HasPrefix(interceptor.Header().Get(headerKeyContentType), contentTypeEventStream)
+			level := config.level(interceptor.Status(), duration, streaming)
+			if level == zapcore.InfoLevel && !config.sampled() {
+				return
+			}
+
+			logx.FromContext(r.Context(), logger).Log(level, "http request",
+				zap.String("method", r.Method),
+				zap.String("route", routePattern(r)),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", interceptor.Status()),
+				zap.Int64("bytes", interceptor.BytesWritten()),
+				zap.Duration("latency", duration),
+				zap.String("clientIp", ClientIP(r, config.trustedProxies)),
+				zap.String("userAgent", r.UserAgent()),
+				zap.String("proto", r.Proto),
+			)
+		})
+	}
+}
+
+func (c *accessLogConfig) level(status int, duration time.Duration, streaming bool) zapcore.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return zapcore.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return zapcore.WarnLevel
+	case c.slowThreshold > 0 && duration > c.slowThreshold && !streaming:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+func (c *accessLogConfig) sampled() bool {
+	return c.successSampleRate >= 1 || c.random() < c.successSampleRate
+}
+
+// routePattern returns the matched chi route, or empty when unmatched or not routed by chi.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
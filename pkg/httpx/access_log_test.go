@@ -0,0 +1,132 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func serveAccessLog(t *testing.T, path string, handler http.HandlerFunc, options ...AccessLogOption) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	router := chi.NewRouter()
+	router.Use(RequestIDMiddleware)
+	router.Use(AccessLogMiddleware(zap.New(core), options...))
+	router.Get("/user/{id}", handler)
+	router.Get("/healthz", handler)
+
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	r.Header.Set("User-Agent", "test-agent")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	return logs
+}
+
+func TestAccessLogMiddleware_Fields(t *testing.T) {
+	logs := serveAccessLog(t, "/user/123", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.InfoLevel, entry.Level)
+
+	fields := entry.ContextMap()
+	assert.Equal(t, http.MethodGet, fields["method"])
+	assert.Equal(t, "/user/{id}", fields["route"])
+	assert.Equal(t, "/user/123", fields["path"])
+	assert.Equal(t, int64(http.StatusOK), fields["status"])
+	assert.Equal(t, int64(5), fields["bytes"])
+	assert.Contains(t, fields, "latency")
+	assert.Equal(t, "192.0.2.1", fields["clientIp"])
+	assert.Equal(t, "test-agent", fields["userAgent"])
+	assert.NotEmpty(t, fields[logx.FieldKeyRequestID])
+	assert.NotEmpty(t, fields[logx.FieldKeyTraceID])
+}
+
+func TestAccessLogMiddleware_Level(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		delay         time.Duration
+		contentType   string
+		expectedLevel zapcore.Level
+	}{
+		{name: "success", status: http.StatusOK, expectedLevel: zapcore.InfoLevel},
+		{name: "client error", status: http.StatusNotFound, expectedLevel: zapcore.WarnLevel},
+		{name: "server error", status: http.StatusServiceUnavailable, expectedLevel: zapcore.ErrorLevel},
+		{name: "slow", status: http.StatusOK, delay: 20 * time.Millisecond, expectedLevel: zapcore.WarnLevel},
+		{
+			name:          "slow event stream",
+			status:        http.StatusOK,
+			delay:         20 * time.Millisecond,
+			contentType:   contentTypeEventStream,
+			expectedLevel: zapcore.InfoLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := serveAccessLog(t, "/user/123", func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tt.delay)
+				if tt.contentType != "" {
+					w.Header().Set(headerKeyContentType, tt.contentType)
+				}
+				w.WriteHeader(tt.status)
+			}, WithSlowThreshold(10*time.Millisecond))
+
+			require.Equal(t, 1, logs.Len())
+			assert.Equal(t, tt.expectedLevel, logs.All()[0].Level)
+		})
+	}
+}
+
+func TestAccessLogMiddleware_SkipPaths(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	logs := serveAccessLog(t, "/healthz", noop)
+	assert.Equal(t, 0, logs.Len())
+
+	logs = serveAccessLog(t, "/healthz", noop, WithAccessLogSkipPaths())
+	assert.Equal(t, 1, logs.Len())
+}
+
+func TestAccessLogMiddleware_Sampling(t *testing.T) {
+	tests := []struct {
+		name          string
+		rate          float64
+		status        int
+		expectedCount int
+	}{
+		{name: "success dropped", rate: 0, status: http.StatusOK, expectedCount: 0},
+		{name: "success kept", rate: 1, status: http.StatusOK, expectedCount: 1},
+		{name: "failure never dropped", rate: 0, status: http.StatusBadRequest, expectedCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := serveAccessLog(t, "/user/123", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}, WithSuccessSampleRate(tt.rate))
+
+			assert.Equal(t, tt.expectedCount, logs.Len())
+		})
+	}
+}
+
+func TestAccessLogConfig_Sampled(t *testing.T) {
+	config := accessLogConfig{successSampleRate: 0.25, random: func() float64 { return 0.2 }}
+	assert.True(t, config.sampled())
+
+	config.random = func() float64 { return 0.3 }
+	assert.False(t, config.sampled())
+}
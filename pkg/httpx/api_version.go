@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	HeaderKeyDeprecation = "Deprecation"
+	HeaderKeySunset      = "Sunset"
+	HeaderKeyLink        = "Link"
+)
+
+// unmatchedRoute labels metrics of requests without a route pattern, keeping label cardinality bounded.
+const unmatchedRoute = "unmatched"
+
+type apiVersionCtxKey struct{}
+
+// APIVersion of routes mounted under a version prefix, e.g. "v1" for /api/v1.
+type APIVersion struct {
+	Name string
+	// DeprecationTime when the version was or will be deprecated, zero when not deprecated.
+	DeprecationTime time.Time
+	// SunsetTime when the version stops responding, zero when not planned.
+	SunsetTime time.Time
+	// Link to migration documentation, advertised to clients of a deprecated version.
+	Link string
+}
+
+// Deprecated reports whether the version has a deprecation time, including one in the future.
+func (v APIVersion) Deprecated() bool {
+	return !v.DeprecationTime.IsZero()
+}
+
+// APIVersionMetricsRecorder records requests per version, e.g. to decide when a version can be turned off.
+// Implemented by monitoring.Metrics.
+type APIVersionMetricsRecorder interface {
+	RecordAPIVersionRequest(version, route string, deprecated bool)
+}
+
+type noopAPIVersionMetrics struct{}
+
+func (noopAPIVersionMetrics) RecordAPIVersionRequest(string, string, bool) {}
+
+type apiVersionConfig struct {
+	metrics APIVersionMetricsRecorder
+}
+
+type APIVersionOption func(*apiVersionConfig)
+
+func WithAPIVersionMetrics(metrics APIVersionMetricsRecorder) APIVersionOption {
+	return func(c *apiVersionConfig) {
+		c.metrics = metrics
+	}
+}
+
+// APIVersionMiddleware stores version in the request context and signals deprecation to clients.
+//
+// Deprecated versions respond with the Deprecation header (RFC 9745), and Link to the migration
+// documentation with rel="deprecation". A planned sunset is announced with the Sunset header (RFC 8594).
+func APIVersionMiddleware(version APIVersion, options ...APIVersionOption) func(http.Handler) http.Handler {
+	config := apiVersionConfig{metrics: noopAPIVersionMetrics{}}
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	deprecation, sunset, link := "", "", ""
+	if version.Deprecated() {
+		deprecation = "@" + strconv.FormatInt(version.DeprecationTime.Unix(), 10)
+		if version.Link != "" {
+			link = "<" + version.Link + `>; rel="deprecation"; type="text/html"`
+		}
+	}
+	if !version.SunsetTime.IsZero() {
+		sunset = version.SunsetTime.UTC().Format(http.TimeFormat)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			setIfNotEmpty(h, HeaderKeyDeprecation, deprecation)
+			setIfNotEmpty(h, HeaderKeySunset, sunset)
+			if link != "" {
+				h.Add(HeaderKeyLink, link)
+			}
+
+			ctx := context.WithValue(r.Context(), apiVersionCtxKey{}, version.Name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			config.metrics.RecordAPIVersionRequest(version.Name, route, version.Deprecated())
+		})
+	}
+}
+
+// APIVersionFromContext returns the version set by APIVersionMiddleware, empty if not set.
+func APIVersionFromContext(ctx context.Context) string {
+	version, _ := ctx.Value(apiVersionCtxKey{}).(string)
+	return version
+}
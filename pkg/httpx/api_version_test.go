@@ -0,0 +1,99 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAPIVersionMetrics struct {
+	mock.Mock
+}
+
+func (m *mockAPIVersionMetrics) RecordAPIVersionRequest(version, route string, deprecated bool) {
+	m.Called(version, route, deprecated)
+}
+
+func TestAPIVersionMiddleware(t *testing.T) {
+	deprecation := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.FixedZone("UTC+8", 8*60*60))
+
+	tests := []struct {
+		name                string
+		version             APIVersion
+		expectedDeprecation string
+		expectedSunset      string
+		expectedLink        string
+	}{
+		{name: "current", version: APIVersion{Name: "v2"}},
+		{
+			name:                "deprecated",
+			version:             APIVersion{Name: "v1", DeprecationTime: deprecation, Link: "https://docs.test/migrate"},
+			expectedDeprecation: "@1767225600",
+			expectedLink:        `<https://docs.test/migrate>; rel="deprecation"; type="text/html"`,
+		},
+		{
+			name:                "sunset",
+			version:             APIVersion{Name: "v1", DeprecationTime: deprecation, SunsetTime: sunset},
+			expectedDeprecation: "@1767225600",
+			expectedSunset:      "Tue, 30 Jun 2026 16:00:00 GMT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &mockAPIVersionMetrics{}
+			metrics.On("RecordAPIVersionRequest", mock.Anything, mock.Anything, mock.Anything).Return()
+			var version string
+
+			router := chi.NewRouter()
+			router.Route("/api/"+tt.version.Name, func(r chi.Router) {
+				r.Use(APIVersionMiddleware(tt.version, WithAPIVersionMetrics(metrics)))
+				r.Get("/item/{id}", func(w http.ResponseWriter, r *http.Request) {
+					version = APIVersionFromContext(r.Context())
+				})
+			})
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/"+tt.version.Name+"/item/1", nil))
+
+			assert.Equal(t, tt.version.Name, version)
+			assert.Equal(t, tt.expectedDeprecation, w.Header().Get(HeaderKeyDeprecation))
+			assert.Equal(t, tt.expectedSunset, w.Header().Get(HeaderKeySunset))
+			assert.Equal(t, tt.expectedLink, w.Header().Get(HeaderKeyLink))
+			metrics.AssertCalled(t, "RecordAPIVersionRequest",
+				tt.version.Name, "/api/"+tt.version.Name+"/item/{id}", tt.version.Deprecated())
+		})
+	}
+}
+
+func TestAPIVersionMiddleware_UnmatchedRoute(t *testing.T) {
+	metrics := &mockAPIVersionMetrics{}
+	metrics.On("RecordAPIVersionRequest", mock.Anything, mock.Anything, mock.Anything).Return()
+
+	router := chi.NewRouter()
+	router.Route("/api/v1", func(r chi.Router) {
+		r.Use(APIVersionMiddleware(APIVersion{Name: "v1"}, WithAPIVersionMetrics(metrics)))
+		r.Get("/item", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/unknown/1", nil))
+
+	// Recorded by the mount pattern, keeping label cardinality bounded
+	metrics.AssertCalled(t, "RecordAPIVersionRequest", "v1", "/api/v1/*", false)
+}
+
+func TestAPIVersionMiddleware_WithoutRouter(t *testing.T) {
+	metrics := &mockAPIVersionMetrics{}
+	metrics.On("RecordAPIVersionRequest", mock.Anything, mock.Anything, mock.Anything).Return()
+
+	APIVersionMiddleware(APIVersion{Name: "v1"}, WithAPIVersionMetrics(metrics))(http.NotFoundHandler()).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/item", nil))
+
+	metrics.AssertCalled(t, "RecordAPIVersionRequest", "v1", unmatchedRoute, false)
+}
@@ -0,0 +1,70 @@
+package httpx
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const HeaderKeyForwardedFor = "X-Forwarded-For"
+
+// ClientIP returns the IP of the client that sent r.
+//
+// X-Forwarded-For is only honoured when the connection comes from one of trustedProxies,
+// in which case the header is walked right to left and the first untrusted address is returned.
+// Entries left of it are set by the client and cannot be trusted.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote, ok := remoteAddr(r)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !isTrusted(remote, trustedProxies) {
+		return remote.String()
+	}
+
+	hops := forwardedFor(r)
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Malformed entries cannot be attributed, the closest valid hop is returned
+			break
+		}
+		client = addr.Unmap()
+		if !isTrusted(client, trustedProxies) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// forwardedFor returns the entries of all X-Forwarded-For headers in order.
+func forwardedFor(r *http.Request) []string {
+	var hops []string
+	for _, value := range r.Header.Values(HeaderKeyForwardedFor) {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	return hops
+}
+
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
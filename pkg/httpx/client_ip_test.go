@@ -0,0 +1,112 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		trusted      []netip.Prefix
+		expectedIP   string
+	}{
+		{
+			name:       "no proxy",
+			remoteAddr: "203.0.113.7:5123",
+			trusted:    trusted,
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:         "untrusted remote ignores header",
+			remoteAddr:   "203.0.113.7:5123",
+			forwardedFor: []string{"198.51.100.1"},
+			trusted:      trusted,
+			expectedIP:   "203.0.113.7",
+		},
+		{
+			name:         "no trusted proxies ignores header",
+			remoteAddr:   "10.0.0.2:5123",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "10.0.0.2",
+		},
+		{
+			name:         "trusted proxy",
+			remoteAddr:   "10.0.0.2:5123",
+			forwardedFor: []string{"198.51.100.1"},
+			trusted:      trusted,
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			name:         "spoofed entries left of client",
+			remoteAddr:   "10.0.0.2:5123",
+			forwardedFor: []string{"1.1.1.1, 198.51.100.1, 10.0.0.3"},
+			trusted:      trusted,
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			name:         "multiple headers",
+			remoteAddr:   "10.0.0.2:5123",
+			forwardedFor: []string{"1.1.1.1", "198.51.100.1,10.0.0.3"},
+			trusted:      trusted,
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			name:         "all trusted returns leftmost",
+			remoteAddr:   "10.0.0.2:5123",
+			forwardedFor: []string{"10.0.0.4, 10.0.0.3"},
+			trusted:      trusted,
+			expectedIP:   "10.0.0.4",
+		},
+		{
+			name:         "malformed entry returns closest valid hop",
+			remoteAddr:   "10.0.0.2:5123",
+			forwardedFor: []string{"198.51.100.1, unknown, 10.0.0.3"},
+			trusted:      trusted,
+			expectedIP:   "10.0.0.3",
+		},
+		{
+			name:         "ipv6",
+			remoteAddr:   "[fd00::1]:5123",
+			forwardedFor: []string{"2001:db8::1"},
+			trusted:      trusted,
+			expectedIP:   "2001:db8::1",
+		},
+		{
+			name:         "ipv4 mapped ipv6 remote",
+			remoteAddr:   "[::ffff:10.0.0.2]:5123",
+			forwardedFor: []string{"198.51.100.1"},
+			trusted:      trusted,
+			expectedIP:   "198.51.100.1",
+		},
+		{
+			name:       "unparsable remote",
+			remoteAddr: "pipe",
+			trusted:    trusted,
+			expectedIP: "pipe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwardedFor {
+				r.Header.Add(HeaderKeyForwardedFor, v)
+			}
+
+			assert.Equal(t, tt.expectedIP, ClientIP(r, tt.trusted))
+		})
+	}
+}
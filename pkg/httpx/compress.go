@@ -0,0 +1,310 @@
+package httpx
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	HeaderKeyAcceptEncoding  = "Accept-Encoding"
+	HeaderKeyContentEncoding = "Content-Encoding"
+	HeaderKeyContentLength   = "Content-Length"
+)
+
+const (
+	EncodingZstd = "zstd"
+	EncodingGzip = "gzip"
+)
+
+// DefaultCompressMinSize responses smaller than this are not worth the encoding overhead.
+const DefaultCompressMinSize = 1024
+
+var defaultCompressEncodings = []string{EncodingZstd, EncodingGzip}
+
+var defaultCompressContentTypes = []string{
+	contentTypeJSON,
+	contentTypeProblemJSON,
+	"text/html",
+	"text/plain",
+	"text/css",
+	"text/javascript",
+}
+
+// encoder compresses a response, reset and pooled between responses.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	EncodingZstd: {New: func() any {
+		// Concurrency of 1 as each response is encoded by its own goroutine,
+		// the window is bounded as browsers reject windows larger than 8 MiB.
+		e, _ := zstd.NewWriter(nil,
+			zstd.WithEncoderConcurrency(1),
+			zstd.WithWindowSize(1<<20),
+		)
+		return e
+	}},
+	EncodingGzip: {New: func() any {
+		return gzip.NewWriter(nil)
+	}},
+}
+
+type compressConfig struct {
+	minSize      int
+	encodings    []string
+	contentTypes map[string]struct{}
+}
+
+type CompressOption func(*compressConfig)
+
+// WithCompressMinSize overrides DefaultCompressMinSize.
+func WithCompressMinSize(n int) CompressOption {
+	return func(c *compressConfig) {
+		c.minSize = n
+	}
+}
+
+// WithCompressEncodings replaces the supported encodings, in order of preference
+// when the client accepts several with equal quality. Unknown encodings are ignored.
+// Defaults to zstd then gzip.
+func WithCompressEncodings(encodings ...string) CompressOption {
+	return func(c *compressConfig) {
+		c.encodings = slices.DeleteFunc(slices.Clone(encodings), func(e string) bool {
+			return encoderPools[e] == nil
+		})
+	}
+}
+
+// WithCompressContentTypes replaces the media types compressed, defaults to JSON, problem JSON and text.
+func WithCompressContentTypes(mediaTypes ...string) CompressOption {
+	return func(c *compressConfig) {
+		c.contentTypes = toSet(mediaTypes)
+	}
+}
+
+// CompressMiddleware compresses responses with the encoding negotiated from Accept-Encoding.
+//
+// Responses are compressed when their Content-Type is allowed, they are not already encoded,
+// and their body reaches the minimum size, which is buffered before deciding.
+// Handlers inside the middleware write uncompressed bodies, e.g. the idempotency recorder
+// stores uncompressed responses which are compressed per request when replayed.
+func CompressMiddleware(options ...CompressOption) func(http.Handler) http.Handler {
+	config := compressConfig{
+		minSize:      DefaultCompressMinSize,
+		encodings:    defaultCompressEncodings,
+		contentTypes: toSet(defaultCompressContentTypes),
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				config:         &config,
+				encoding:       negotiateEncoding(r.Header.Get(HeaderKeyAcceptEncoding), config.encodings),
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the supported encoding with the highest quality, empty when none is acceptable.
+// Ties are broken by the order of supported.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range supported {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter defers the status until the body reaches the minimum size or the handler returns,
+// then writes the response either compressed or as is.
+type compressWriter struct {
+	http.ResponseWriter
+	config *compressConfig
+	// encoding negotiated, empty when the client accepts none
+	encoding string
+
+	status      int
+	wroteHeader bool
+	committed   bool
+	buf         []byte
+	encoder     encoder
+}
+
+func (cw *compressWriter) WriteHeader(statusCode int) {
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		cw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = statusCode
+
+	if !cw.isCompressible() {
+		cw.commit(false)
+		return
+	}
+	if length, err := strconv.Atoi(cw.Header().Get(HeaderKeyContentLength)); err == nil && length < cw.config.minSize {
+		cw.commit(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.committed {
+		return cw.write(b)
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.config.minSize {
+		cw.commit(true)
+		if _, err := cw.writeBuffered(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush commits the response compressed when compressible, as the full size is unknown when streaming.
+func (cw *compressWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.committed {
+		cw.commit(true)
+		_, _ = cw.writeBuffered()
+	}
+	if cw.encoder != nil {
+		_ = cw.encoder.Flush()
+	}
+	// Errors are likely due to client disconnect, or the underlying writer not supporting flush.
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// isCompressible checks the response headers set by the handler, and that the client accepts an encoding.
+func (cw *compressWriter) isCompressible() bool {
+	if cw.encoding == "" ||
+		cw.status < http.StatusOK ||
+		cw.status == http.StatusNoContent ||
+		cw.status == http.StatusNotModified ||
+		cw.Header().Get(HeaderKeyContentEncoding) != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(cw.Header().Get(headerKeyContentType))
+	if err != nil {
+		return false
+	}
+	_, ok := cw.config.contentTypes[mediaType]
+	return ok
+}
+
+// commit writes the deferred status, compressed when compress and the response is compressible.
+func (cw *compressWriter) commit(compress bool) {
+	cw.committed = true
+
+	h := cw.Header()
+	// Vary is set whether compressed or not, as the decision depends on Accept-Encoding.
+	// Set here rather than before the handler runs, as handlers may replace headers, e.g. idempotent replays.
+	if !slices.Contains(h.Values(HeaderKeyVary), HeaderKeyAcceptEncoding) {
+		h.Add(HeaderKeyVary, HeaderKeyAcceptEncoding)
+	}
+
+	if compress && cw.isCompressible() {
+		h.Set(HeaderKeyContentEncoding, cw.encoding)
+		h.Del(HeaderKeyContentLength)
+		cw.encoder = encoderPools[cw.encoding].Get().(encoder)
+		cw.encoder.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+func (cw *compressWriter) write(b []byte) (int, error) {
+	if cw.encoder != nil {
+		return cw.encoder.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *compressWriter) writeBuffered() (int, error) {
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	return cw.write(buf)
+}
+
+// close writes a response smaller than the minimum size as is, and returns the encoder to its pool.
+func (cw *compressWriter) close() {
+	if cw.wroteHeader && !cw.committed {
+		cw.commit(false)
+		// Errors are likely due to client disconnect.
+		_, _ = cw.writeBuffered()
+	}
+	if cw.encoder != nil {
+		_ = cw.encoder.Close()
+		// Released writer is not retained by the pooled encoder
+		cw.encoder.Reset(nil)
+		encoderPools[cw.encoding].Put(cw.encoder)
+		cw.encoder = nil
+	}
+}
@@ -0,0 +1,243 @@
+package httpx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{EncodingZstd, EncodingGzip}
+
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{acceptEncoding: "", expected: ""},
+		{acceptEncoding: "identity", expected: ""},
+		{acceptEncoding: "gzip", expected: EncodingGzip},
+		{acceptEncoding: "gzip, deflate, br, zstd", expected: EncodingZstd},
+		{acceptEncoding: "GZIP", expected: EncodingGzip},
+		{acceptEncoding: "zstd;q=0.5, gzip;q=0.8", expected: EncodingGzip},
+		{acceptEncoding: "zstd;q=0, gzip", expected: EncodingGzip},
+		{acceptEncoding: "*", expected: EncodingZstd},
+		{acceptEncoding: "*;q=0.1, zstd;q=0", expected: EncodingGzip},
+		{acceptEncoding: "gzip;q=invalid", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			assert.Equal(t, tt.expected, negotiateEncoding(tt.acceptEncoding, supported))
+		})
+	}
+}
+
+func decompress(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+	var r io.Reader
+	switch encoding {
+	case EncodingGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		r = gr
+	case EncodingZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		defer zr.Close()
+		r = zr
+	default:
+		return body
+	}
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return b
+}
+
+func serveCompressed(handler http.HandlerFunc, method, acceptEncoding string, options ...CompressOption) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/", nil)
+	r.Header.Set(HeaderKeyAcceptEncoding, acceptEncoding)
+	w := httptest.NewRecorder()
+	CompressMiddleware(options...)(handler).ServeHTTP(w, r)
+	return w
+}
+
+func jsonHandler(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerKeyContentType, contentTypeJSON)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	large := `{"data":"` + strings.Repeat("a", 2*DefaultCompressMinSize) + `"}`
+	small := `{"data":"a"}`
+
+	tests := []struct {
+		name             string
+		handler          http.HandlerFunc
+		method           string
+		acceptEncoding   string
+		expectedEncoding string
+		expectedStatus   int
+		expectedBody     string
+	}{
+		{
+			name:             "gzip",
+			handler:          jsonHandler(http.StatusCreated, large),
+			acceptEncoding:   "gzip",
+			expectedEncoding: EncodingGzip,
+			expectedStatus:   http.StatusCreated,
+			expectedBody:     large,
+		},
+		{
+			name:             "zstd",
+			handler:          jsonHandler(http.StatusOK, large),
+			acceptEncoding:   "gzip, zstd",
+			expectedEncoding: EncodingZstd,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     large,
+		},
+		{
+			name:           "not accepted",
+			handler:        jsonHandler(http.StatusOK, large),
+			expectedStatus: http.StatusOK,
+			expectedBody:   large,
+		},
+		{
+			name:           "below min size",
+			handler:        jsonHandler(http.StatusOK, small),
+			acceptEncoding: "gzip",
+			expectedStatus: http.StatusOK,
+			expectedBody:   small,
+		},
+		{
+			name: "content type not allowed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(headerKeyContentType, "image/png")
+				_, _ = w.Write([]byte(large))
+			},
+			acceptEncoding: "gzip",
+			expectedStatus: http.StatusOK,
+			expectedBody:   large,
+		},
+		{
+			name: "already encoded",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(headerKeyContentType, contentTypeJSON)
+				w.Header().Set(HeaderKeyContentEncoding, "br")
+				_, _ = w.Write([]byte(large))
+			},
+			acceptEncoding:   "gzip",
+			expectedEncoding: "br",
+			expectedStatus:   http.StatusOK,
+			expectedBody:     large,
+		},
+		{
+			name: "written in chunks",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(headerKeyContentType, contentTypeJSON+"; charset=utf-8")
+				for _, chunk := range strings.SplitAfter(large, "a") {
+					_, _ = w.Write([]byte(chunk))
+				}
+			},
+			acceptEncoding:   "gzip",
+			expectedEncoding: EncodingGzip,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     large,
+		},
+		{
+			name: "no content",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(headerKeyContentType, contentTypeJSON)
+				w.WriteHeader(http.StatusNoContent)
+			},
+			acceptEncoding: "gzip",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "head",
+			handler:        jsonHandler(http.StatusOK, ""),
+			method:         http.MethodHead,
+			acceptEncoding: "gzip",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			w := serveCompressed(tt.handler, method, tt.acceptEncoding)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedEncoding, w.Header().Get(HeaderKeyContentEncoding))
+			assert.Equal(t, tt.expectedBody, string(decompress(t, w.Header().Get(HeaderKeyContentEncoding), w.Body.Bytes())))
+			if method != http.MethodHead {
+				assert.Equal(t, []string{HeaderKeyAcceptEncoding}, w.Header().Values(HeaderKeyVary))
+			}
+		})
+	}
+}
+
+func TestCompressMiddleware_ContentLength(t *testing.T) {
+	body := strings.Repeat("a", 2*DefaultCompressMinSize)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerKeyContentType, "text/plain")
+		w.Header().Set(HeaderKeyContentLength, strconv.Itoa(len(body)))
+		_, _ = w.Write([]byte(body))
+	}
+
+	w := serveCompressed(handler, http.MethodGet, "gzip")
+
+	assert.Equal(t, EncodingGzip, w.Header().Get(HeaderKeyContentEncoding))
+	assert.Empty(t, w.Header().Get(HeaderKeyContentLength))
+	assert.Equal(t, body, string(decompress(t, EncodingGzip, w.Body.Bytes())))
+}
+
+func TestCompressMiddleware_Flush(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerKeyContentType, "text/plain")
+		_, _ = w.Write([]byte("first"))
+		require.NoError(t, http.NewResponseController(w).Flush())
+		_, _ = w.Write([]byte("second"))
+	}
+
+	w := serveCompressed(handler, http.MethodGet, "zstd")
+
+	assert.True(t, w.Flushed)
+	assert.Equal(t, EncodingZstd, w.Header().Get(HeaderKeyContentEncoding))
+	assert.Equal(t, "firstsecond", string(decompress(t, EncodingZstd, w.Body.Bytes())))
+}
+
+func TestCompressMiddleware_Options(t *testing.T) {
+	body := `{"data":"` + strings.Repeat("a", 64) + `"}`
+
+	w := serveCompressed(jsonHandler(http.StatusOK, body), http.MethodGet, "zstd, gzip",
+		WithCompressMinSize(16),
+		WithCompressEncodings(EncodingGzip, "unknown"),
+	)
+
+	assert.Equal(t, EncodingGzip, w.Header().Get(HeaderKeyContentEncoding))
+	assert.Equal(t, body, string(decompress(t, EncodingGzip, w.Body.Bytes())))
+
+	w = serveCompressed(jsonHandler(http.StatusOK, body), http.MethodGet, "gzip",
+		WithCompressMinSize(16),
+		WithCompressContentTypes("text/plain"),
+	)
+
+	assert.Empty(t, w.Header().Get(HeaderKeyContentEncoding))
+	assert.Equal(t, body, w.Body.String())
+}
@@ -0,0 +1,152 @@
+package httpx
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderKeyOrigin                        = "Origin"
+	HeaderKeyVary                          = "Vary"
+	HeaderKeyAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderKeyAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderKeyAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderKeyAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderKeyAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderKeyAccessControlMaxAge           = "Access-Control-Max-Age"
+	HeaderKeyAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderKeyAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+)
+
+// CORSPolicy cross-origin requests allowed by CORSMiddleware, see https://fetch.spec.whatwg.org/#http-cors-protocol.
+type CORSPolicy struct {
+	// AllowedOrigins as scheme://host[:port], "https://*.example.com" matches subdomains at any depth
+	// but not example.com itself. "*" matches any origin, without credentials.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge preflight responses are cached by browsers, not sent when zero.
+	MaxAge time.Duration
+}
+
+// CORSMiddleware sets CORS headers for origins allowed by policy and answers preflight requests,
+// requests of other origins are served without CORS headers and are blocked by browsers.
+// Nothing is set when no origins are allowed.
+func CORSMiddleware(policy CORSPolicy) func(http.Handler) http.Handler {
+	allowedMethods := strings.Join(policy.AllowedMethods, ", ")
+	exposedHeaders := strings.Join(policy.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(policy.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		if len(policy.AllowedOrigins) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			origin := r.Header.Get(HeaderKeyOrigin)
+			isPreflight := r.Method == http.MethodOptions && r.Header.Get(HeaderKeyAccessControlRequestMethod) != ""
+
+			// Responses vary by origin, caches must not serve them to other origins
+			h.Add(HeaderKeyVary, HeaderKeyOrigin)
+			if isPreflight {
+				h.Add(HeaderKeyVary, HeaderKeyAccessControlRequestMethod)
+				h.Add(HeaderKeyVary, HeaderKeyAccessControlRequestHeaders)
+			}
+
+			pattern, ok := policy.matchOrigin(origin)
+			if !ok {
+				if isPreflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if pattern == "*" {
+				h.Set(HeaderKeyAccessControlAllowOrigin, "*")
+			} else {
+				h.Set(HeaderKeyAccessControlAllowOrigin, origin)
+				if policy.AllowCredentials {
+					h.Set(HeaderKeyAccessControlAllowCredentials, "true")
+				}
+			}
+
+			if !isPreflight {
+				if exposedHeaders != "" {
+					h.Set(HeaderKeyAccessControlExposeHeaders, exposedHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !policy.allowsMethod(r.Header.Get(HeaderKeyAccessControlRequestMethod)) {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set(HeaderKeyAccessControlAllowMethods, allowedMethods)
+			if headers := policy.allowedRequestHeaders(r.Header.Get(HeaderKeyAccessControlRequestHeaders)); headers != "" {
+				h.Set(HeaderKeyAccessControlAllowHeaders, headers)
+			}
+			if policy.MaxAge > 0 {
+				h.Set(HeaderKeyAccessControlMaxAge, maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// matchOrigin returns the allowed origin pattern matching origin.
+func (p CORSPolicy) matchOrigin(origin string) (string, bool) {
+	if origin == "" {
+		return "", false
+	}
+	origin = strings.ToLower(origin)
+	for _, pattern := range p.AllowedOrigins {
+		if matchOriginPattern(strings.ToLower(pattern), origin) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func matchOriginPattern(pattern, origin string) bool {
+	if pattern == "*" || pattern == origin {
+		return true
+	}
+	scheme, host, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	// The suffix includes the separating dot, excluding the apex domain and look-alikes such as evilexample.com
+	return strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+host) &&
+		len(origin) > len(scheme+"://")+len("."+host)
+}
+
+func (p CORSPolicy) allowsMethod(method string) bool {
+	return slices.ContainsFunc(p.AllowedMethods, func(m string) bool {
+		return strings.EqualFold(m, method)
+	})
+}
+
+// allowedRequestHeaders filters requested headers by AllowedHeaders, as browsers reject the preflight
+// when a requested header is missing.
+func (p CORSPolicy) allowedRequestHeaders(requested string) string {
+	var allowed []string
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if slices.ContainsFunc(p.AllowedHeaders, func(h string) bool { return strings.EqualFold(h, header) }) {
+			allowed = append(allowed, header)
+		}
+	}
+	return strings.Join(allowed, ", ")
+}
@@ -0,0 +1,151 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchOriginPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		origin   string
+		expected bool
+	}{
+		{pattern: "*", origin: "https://any.io", expected: true},
+		{pattern: "https://app.example.com", origin: "https://app.example.com", expected: true},
+		{pattern: "https://app.example.com", origin: "http://app.example.com", expected: false},
+		{pattern: "https://app.example.com", origin: "https://app.example.com:8443", expected: false},
+		{pattern: "https://*.example.com", origin: "https://app.example.com", expected: true},
+		{pattern: "https://*.example.com", origin: "https://a.b.example.com", expected: true},
+		{pattern: "https://*.example.com", origin: "https://example.com", expected: false},
+		{pattern: "https://*.example.com", origin: "https://.example.com", expected: false},
+		{pattern: "https://*.example.com", origin: "https://evilexample.com", expected: false},
+		{pattern: "https://*.example.com", origin: "http://app.example.com", expected: false},
+		{pattern: "https://*.example.com", origin: "https://app.example.com.evil.io", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.origin, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchOriginPattern(tt.pattern, tt.origin))
+		})
+	}
+}
+
+var testCORSPolicy = CORSPolicy{
+	AllowedOrigins:   []string{"https://*.example.com"},
+	AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+	AllowedHeaders:   []string{"Authorization", "Content-Type"},
+	ExposedHeaders:   []string{HeaderKeyRequestID},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func serveCORS(policy CORSPolicy, r *http.Request) (*httptest.ResponseRecorder, bool) {
+	served := false
+	w := httptest.NewRecorder()
+	CORSMiddleware(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})).ServeHTTP(w, r)
+	return w, served
+}
+
+func TestCORSMiddleware_Request(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderKeyOrigin, "https://App.example.com")
+
+	w, served := serveCORS(testCORSPolicy, r)
+
+	assert.True(t, served)
+	assert.Equal(t, "https://App.example.com", w.Header().Get(HeaderKeyAccessControlAllowOrigin))
+	assert.Equal(t, "true", w.Header().Get(HeaderKeyAccessControlAllowCredentials))
+	assert.Equal(t, HeaderKeyRequestID, w.Header().Get(HeaderKeyAccessControlExposeHeaders))
+	assert.Equal(t, []string{HeaderKeyOrigin}, w.Header().Values(HeaderKeyVary))
+}
+
+func TestCORSMiddleware_DisallowedOrigin(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderKeyOrigin, "https://evil.io")
+
+	w, served := serveCORS(testCORSPolicy, r)
+
+	assert.True(t, served)
+	assert.Empty(t, w.Header().Get(HeaderKeyAccessControlAllowOrigin))
+	assert.Empty(t, w.Header().Get(HeaderKeyAccessControlAllowCredentials))
+	assert.Equal(t, []string{HeaderKeyOrigin}, w.Header().Values(HeaderKeyVary))
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	tests := []struct {
+		name                 string
+		origin               string
+		method               string
+		headers              string
+		expectedAllowOrigin  string
+		expectedAllowHeaders string
+	}{
+		{
+			name:                 "allowed",
+			origin:               "https://app.example.com",
+			method:               http.MethodPost,
+			headers:              "content-type, authorization, x-unknown",
+			expectedAllowOrigin:  "https://app.example.com",
+			expectedAllowHeaders: "content-type, authorization",
+		},
+		{name: "disallowed origin", origin: "https://evil.io", method: http.MethodPost},
+		{name: "disallowed method", origin: "https://app.example.com", method: http.MethodDelete, expectedAllowOrigin: "https://app.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodOptions, "/", nil)
+			r.Header.Set(HeaderKeyOrigin, tt.origin)
+			r.Header.Set(HeaderKeyAccessControlRequestMethod, tt.method)
+			r.Header.Set(HeaderKeyAccessControlRequestHeaders, tt.headers)
+
+			w, served := serveCORS(testCORSPolicy, r)
+
+			assert.False(t, served)
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tt.expectedAllowOrigin, w.Header().Get(HeaderKeyAccessControlAllowOrigin))
+			assert.Equal(t, tt.expectedAllowHeaders, w.Header().Get(HeaderKeyAccessControlAllowHeaders))
+			assert.Equal(t,
+				[]string{HeaderKeyOrigin, HeaderKeyAccessControlRequestMethod, HeaderKeyAccessControlRequestHeaders},
+				w.Header().Values(HeaderKeyVary),
+			)
+			if tt.expectedAllowHeaders == "" {
+				assert.Empty(t, w.Header().Get(HeaderKeyAccessControlAllowMethods))
+				return
+			}
+			assert.Equal(t, "GET, POST", w.Header().Get(HeaderKeyAccessControlAllowMethods))
+			assert.Equal(t, "600", w.Header().Get(HeaderKeyAccessControlMaxAge))
+		})
+	}
+}
+
+func TestCORSMiddleware_WildcardOrigin(t *testing.T) {
+	policy := testCORSPolicy
+	policy.AllowedOrigins = []string{"*"}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderKeyOrigin, "https://any.io")
+
+	w, _ := serveCORS(policy, r)
+
+	assert.Equal(t, "*", w.Header().Get(HeaderKeyAccessControlAllowOrigin))
+	// Browsers reject credentials with a wildcard origin
+	assert.Empty(t, w.Header().Get(HeaderKeyAccessControlAllowCredentials))
+}
+
+func TestCORSMiddleware_Disabled(t *testing.T) {
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set(HeaderKeyOrigin, "https://app.example.com")
+	r.Header.Set(HeaderKeyAccessControlRequestMethod, http.MethodGet)
+
+	w, served := serveCORS(CORSPolicy{}, r)
+
+	assert.True(t, served)
+	assert.Empty(t, w.Header())
+}
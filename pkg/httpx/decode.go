@@ -0,0 +1,193 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+)
+
+// DefaultMaxBodyBytes request body limit applied by DecodeJSON.
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1 MiB
+
+// bodyProperty addresses errors that do not belong to a field.
+const bodyProperty = "body"
+
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+var ErrRequestBodyTooLarge = errors.New("request body too large")
+
+// Validator is called by DecodeJSON after decoding.
+type Validator interface {
+	Validate() *errorx.ValidationError
+}
+
+type decodeConfig struct {
+	maxBodyBytes int64
+}
+
+type DecodeOption func(*decodeConfig)
+
+// WithMaxBodyBytes overrides DefaultMaxBodyBytes.
+func WithMaxBodyBytes(n int64) DecodeOption {
+	return func(c *decodeConfig) {
+		c.maxBodyBytes = n
+	}
+}
+
+// DecodeJSON strictly decodes the request body into T.
+//
+// Requests must have a JSON Content-Type, a body within the size limit, a single JSON value
+// and no unknown fields. Decoding failures are returned as *errorx.ValidationError addressed
+// by JSON field name, or ErrUnsupportedMediaType and ErrRequestBodyTooLarge.
+// When *T implements Validator, its validation error is returned.
+func DecodeJSON[T any](w http.ResponseWriter, r *http.Request, options ...DecodeOption) (T, error) {
+	config := decodeConfig{maxBodyBytes: DefaultMaxBodyBytes}
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	var v T
+
+	if !isJSONContentType(r.Header.Get(headerKeyContentType)) {
+		return v, ErrUnsupportedMediaType
+	}
+
+	// Buffered to locate invalid fields on failure, bounded by the size limit
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, config.maxBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return v, ErrRequestBodyTooLarge
+		}
+		return v, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(&v)
+	if err != nil {
+		return v, translateDecodeError[T](err, body)
+	}
+
+	// Anything other than EOF after the first value is trailing data
+	_, err = decoder.Token()
+	if !errors.Is(err, io.EOF) {
+		return v, bodyValidationError("must contain a single JSON value")
+	}
+
+	if validator, ok := any(&v).(Validator); ok {
+		if vErr := validator.Validate(); vErr != nil {
+			return v, vErr
+		}
+	}
+
+	return v, nil
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == contentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+func translateDecodeError[T any](err error, body []byte) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return bodyValidationError("is required")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return bodyValidationError("is malformed JSON")
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return bodyValidationError("must be " + jsonTypeName(typeErr.Type))
+		}
+		return fieldValidationError(typeErr.Field, "must be "+jsonTypeName(typeErr.Type))
+	}
+
+	// As of encoding/json v1 there is no exported type for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fieldValidationError(strings.Trim(field, `"`), "is not allowed")
+	}
+
+	// Errors returned by UnmarshalJSON implementations, e.g. time.Time, are not addressed by field
+	if field, ok := locateInvalidField[T](body); ok {
+		return fieldValidationError(field, "is invalid")
+	}
+	return bodyValidationError("is invalid")
+}
+
+// locateInvalidField decodes top level fields of T one by one to find the one failing.
+func locateInvalidField[T any](body []byte) (string, bool) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(body, &raw) != nil {
+		return "", false
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := jsonFieldName(f)
+		value, ok := raw[name]
+		if !ok {
+			continue
+		}
+		if json.Unmarshal(value, reflect.New(f.Type).Interface()) != nil {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func jsonTypeName(t reflect.Type) string {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return "a string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+func bodyValidationError(message string) *errorx.ValidationError {
+	return fieldValidationError(bodyProperty, message)
+}
+
+func fieldValidationError(field, message string) *errorx.ValidationError {
+	return &errorx.ValidationError{Properties: map[string]string{field: message}}
+}
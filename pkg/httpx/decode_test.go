@@ -0,0 +1,154 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeAddress struct {
+	City string `json:"city"`
+}
+
+type decodeRequest struct {
+	Name      string        `json:"name"`
+	Age       int           `json:"age"`
+	BirthTime time.Time     `json:"birthTime"`
+	Address   decodeAddress `json:"address"`
+}
+
+func (d decodeRequest) Validate() *errorx.ValidationError {
+	if d.Name == "" {
+		return &errorx.ValidationError{Properties: map[string]string{"name": "is required"}}
+	}
+	return nil
+}
+
+func newDecodeRequest(contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set(headerKeyContentType, contentType)
+	}
+	return r
+}
+
+func TestDecodeJSON(t *testing.T) {
+	r := newDecodeRequest("application/json; charset=utf-8",
+		`{"name":"john","age":30,"address":{"city":"kl"}}`)
+
+	got, err := DecodeJSON[decodeRequest](httptest.NewRecorder(), r)
+
+	require.NoError(t, err)
+	assert.Equal(t, decodeRequest{Name: "john", Age: 30, Address: decodeAddress{City: "kl"}}, got)
+}
+
+func TestDecodeJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		options     []DecodeOption
+		wantErr     error
+		wantProps   map[string]string
+	}{
+		{
+			name:    "missing content type",
+			body:    `{"name":"john"}`,
+			wantErr: ErrUnsupportedMediaType,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "text/plain",
+			body:        `{"name":"john"}`,
+			wantErr:     ErrUnsupportedMediaType,
+		},
+		{
+			name:        "body too large",
+			contentType: contentTypeJSON,
+			body:        `{"name":"john"}`,
+			options:     []DecodeOption{WithMaxBodyBytes(5)},
+			wantErr:     ErrRequestBodyTooLarge,
+		},
+		{
+			name:        "empty body",
+			contentType: contentTypeJSON,
+			wantProps:   map[string]string{"body": "is required"},
+		},
+		{
+			name:        "malformed",
+			contentType: contentTypeJSON,
+			body:        `{"name":`,
+			wantProps:   map[string]string{"body": "is malformed JSON"},
+		},
+		{
+			name:        "syntax error",
+			contentType: contentTypeJSON,
+			body:        `{"name" "john"}`,
+			wantProps:   map[string]string{"body": "is malformed JSON"},
+		},
+		{
+			name:        "trailing data",
+			contentType: contentTypeJSON,
+			body:        `{"name":"john"}{}`,
+			wantProps:   map[string]string{"body": "must contain a single JSON value"},
+		},
+		{
+			name:        "unknown field",
+			contentType: contentTypeJSON,
+			body:        `{"name":"john","role":"admin"}`,
+			wantProps:   map[string]string{"role": "is not allowed"},
+		},
+		{
+			name:        "wrong type",
+			contentType: contentTypeJSON,
+			body:        `{"name":"john","age":"thirty"}`,
+			wantProps:   map[string]string{"age": "must be a number"},
+		},
+		{
+			name:        "wrong nested type",
+			contentType: contentTypeJSON,
+			body:        `{"name":"john","address":{"city":1}}`,
+			wantProps:   map[string]string{"address.city": "must be a string"},
+		},
+		{
+			name:        "wrong root type",
+			contentType: contentTypeJSON,
+			body:        `[]`,
+			wantProps:   map[string]string{"body": "must be an object"},
+		},
+		{
+			name:        "invalid value of unmarshaler",
+			contentType: contentTypeJSON,
+			body:        `{"name":"john","birthTime":"yesterday"}`,
+			wantProps:   map[string]string{"birthTime": "is invalid"},
+		},
+		{
+			name:        "validate hook",
+			contentType: contentTypeJSON,
+			body:        `{"age":30}`,
+			wantProps:   map[string]string{"name": "is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newDecodeRequest(tt.contentType, tt.body)
+
+			_, err := DecodeJSON[decodeRequest](httptest.NewRecorder(), r, tt.options...)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			var vErr *errorx.ValidationError
+			require.ErrorAs(t, err, &vErr)
+			assert.Equal(t, tt.wantProps, vErr.Properties)
+		})
+	}
+}
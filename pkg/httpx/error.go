@@ -1,9 +1,12 @@
 package httpx
 
+// ErrorResponse legacy error response, served unless client accepts application/problem+json.
 type ErrorResponse struct {
 	Code    errorCode         `json:"code"`
 	Message string            `json:"message"`
 	Details map[string]string `json:"details,omitempty"`
+	// RequestID set from the request context, see RequestIDMiddleware.
+	RequestID string `json:"requestId,omitempty"`
 }
 
 type errorCode string
@@ -14,6 +17,14 @@ const (
 	CodeEntityNotFound   errorCode = "entity_not_found"
 	CodeDuplicateEntity  errorCode = "duplicate_entity"
 	CodeIdempotencyError errorCode = "idempotency_error"
+	CodeUnauthorized     errorCode = "unauthorized"
+	CodeForbidden        errorCode = "forbidden"
+	CodePayloadTooLarge  errorCode = "payload_too_large"
+	CodeUnsupportedMedia errorCode = "unsupported_media_type"
+	CodeTooManyRequests  errorCode = "too_many_requests"
+	CodeTimeout          errorCode = "timeout"
+	CodeUnavailable      errorCode = "unavailable"
+	CodeClientClosed     errorCode = "client_closed_request"
 )
 
 func (e errorCode) String() string {
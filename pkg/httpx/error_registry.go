@@ -0,0 +1,228 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const unauthorizedDefaultMessage = "unauthorized"
+const forbiddenDefaultMessage = "forbidden"
+const conflictDefaultMessage = "entity already exists"
+
+// StatusClientClosedRequest of requests the client aborted before a response, following nginx.
+// Not a server error, the response is not received.
+const StatusClientClosedRequest = 499
+
+// ErrorMapping describes how a matched error is rendered and logged.
+type ErrorMapping struct {
+	Status   int
+	Code     errorCode
+	Message  string
+	Details  map[string]string
+	LogLevel zapcore.Level
+}
+
+type errorMatcher func(err error) (ErrorMapping, bool)
+
+// ErrorRegistry maps errors to responses, the first registered match wins.
+// Unmatched errors are rendered as internal server errors.
+type ErrorRegistry struct {
+	logger   *zap.Logger
+	matchers []errorMatcher
+}
+
+// NewErrorRegistry creates a registry with mappings for errorx errors.
+func NewErrorRegistry(logger *zap.Logger) *ErrorRegistry {
+	reg := &ErrorRegistry{logger: logger}
+
+	reg.RegisterIs(errorx.ErrNotFound, ErrorMapping{
+		Status:   http.StatusNotFound,
+		Code:     CodeEntityNotFound,
+		Message:  notFoundDefaultMessage,
+		LogLevel: zapcore.InfoLevel,
+	})
+	reg.RegisterIs(errorx.ErrConflict, ErrorMapping{
+		Status:   http.StatusConflict,
+		Code:     CodeDuplicateEntity,
+		Message:  conflictDefaultMessage,
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(errorx.ErrUnauthorized, ErrorMapping{
+		Status:   http.StatusUnauthorized,
+		Code:     CodeUnauthorized,
+		Message:  unauthorizedDefaultMessage,
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(errorx.ErrForbidden, ErrorMapping{
+		Status:   http.StatusForbidden,
+		Code:     CodeForbidden,
+		Message:  forbiddenDefaultMessage,
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(ErrRequestBodyTooLarge, ErrorMapping{
+		Status:   http.StatusRequestEntityTooLarge,
+		Code:     CodePayloadTooLarge,
+		Message:  ErrRequestBodyTooLarge.Error(),
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(ErrUnsupportedMediaType, ErrorMapping{
+		Status:   http.StatusUnsupportedMediaType,
+		Code:     CodeUnsupportedMedia,
+		Message:  "content type must be application/json",
+		LogLevel: zapcore.WarnLevel,
+	})
+	reg.RegisterIs(ErrTooManyRequests, ErrorMapping{
+		Status:   http.StatusTooManyRequests,
+		Code:     CodeTooManyRequests,
+		Message:  ErrTooManyRequests.Error(),
+		LogLevel: zapcore.InfoLevel,
+	})
+	// Deadlines of downstream calls are set by TimeoutMiddleware, rendered as the handler timing out
+	for _, target := range []error{ErrHandlerTimeout, context.DeadlineExceeded} {
+		reg.RegisterIs(target, ErrorMapping{
+			Status:   http.StatusServiceUnavailable,
+			Code:     CodeTimeout,
+			Message:  ErrHandlerTimeout.Error(),
+			LogLevel: zapcore.WarnLevel,
+		})
+	}
+	// Requests cancelled by the client disconnecting, after deadlines as both may be joined
+	reg.RegisterIs(context.Canceled, ErrorMapping{
+		Status:   StatusClientClosedRequest,
+		Code:     CodeClientClosed,
+		Message:  "client closed request",
+		LogLevel: zapcore.DebugLevel,
+	})
+	RegisterAs(reg, func(err *errorx.ValidationError) ErrorMapping {
+		return ErrorMapping{
+			Status:   http.StatusBadRequest,
+			Code:     CodeBadRequest,
+			Message:  validationFailedDefaultMessage,
+			Details:  err.Properties,
+			LogLevel: zapcore.WarnLevel,
+		}
+	})
+	RegisterAs(reg, func(err *errorx.BadRequestError) ErrorMapping {
+		return ErrorMapping{
+			Status:   http.StatusBadRequest,
+			Code:     CodeBadRequest,
+			Message:  err.Message,
+			Details:  err.Properties,
+			LogLevel: zapcore.WarnLevel,
+		}
+	})
+	// Properties are not returned as they may hold values of entities the client cannot access
+	RegisterAs(reg, func(err *errorx.UniqueViolationError) ErrorMapping {
+		return ErrorMapping{
+			Status:   http.StatusConflict,
+			Code:     CodeDuplicateEntity,
+			Message:  conflictDefaultMessage,
+			LogLevel: zapcore.WarnLevel,
+		}
+	})
+
+	return reg
+}
+
+// RegisterIs maps errors matching target with errors.Is.
+func (reg *ErrorRegistry) RegisterIs(target error, mapping ErrorMapping) {
+	reg.matchers = append(reg.matchers, func(err error) (ErrorMapping, bool) {
+		return mapping, errors.Is(err, target)
+	})
+}
+
+// RegisterAs maps errors matching T with errors.As.
+func RegisterAs[T error](reg *ErrorRegistry, fn func(err T) ErrorMapping) {
+	reg.matchers = append(reg.matchers, func(err error) (ErrorMapping, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return ErrorMapping{}, false
+		}
+		return fn(target), true
+	})
+}
+
+// Resolve returns the mapping of err, with message overridden by WithMessage.
+func (reg *ErrorRegistry) Resolve(err error) ErrorMapping {
+	mapping := ErrorMapping{
+		Status:   http.StatusInternalServerError,
+		Code:     CodeServerError,
+		Message:  internalServerErrorDefaultMessage,
+		LogLevel: zapcore.ErrorLevel,
+	}
+	for _, match := range reg.matchers {
+		if m, ok := match(err); ok {
+			mapping = m
+			break
+		}
+	}
+
+	var mErr *messageError
+	if mapping.Status < http.StatusInternalServerError && errors.As(err, &mErr) {
+		mapping.Message = mErr.message
+	}
+	return mapping
+}
+
+// WriteError logs and renders err, see ErrorJsonResponse.
+func (reg *ErrorRegistry) WriteError(err error, w http.ResponseWriter, r *http.Request) {
+	mapping := reg.Resolve(err)
+
+	logx.FromContext(r.Context(), reg.logger).Log(mapping.LogLevel, "request failed",
+		zap.Error(err),
+		zap.Int("status", mapping.Status),
+		zap.Stringer("code", mapping.Code),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+	)
+
+	ErrorJsonResponse(
+		mapping.Status,
+		ErrorResponse{
+			Code:    mapping.Code,
+			Message: mapping.Message,
+			Details: mapping.Details,
+		},
+		w, r)
+}
+
+// ErrorHandlerFunc handler that returns errors to be rendered by ErrorRegistry.
+// A response must not be written when an error is returned.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handle adapts h to a http.HandlerFunc, rendering returned errors.
+func (reg *ErrorRegistry) Handle(h ErrorHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h(w, r)
+		if err != nil {
+			reg.WriteError(err, w, r)
+		}
+	}
+}
+
+type messageError struct {
+	err     error
+	message string
+}
+
+func (e *messageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *messageError) Unwrap() error {
+	return e.err
+}
+
+// WithMessage overrides the client facing message of a non server error.
+func WithMessage(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &messageError{err: err, message: message}
+}
@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestErrorRegistry_Resolve(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	reg := NewErrorRegistry(zap.NewNop())
+	reg.RegisterIs(errCustom, ErrorMapping{
+		Status:   http.StatusTeapot,
+		Code:     CodeBadRequest,
+		Message:  "custom",
+		LogLevel: zapcore.WarnLevel,
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorMapping
+	}{
+		{
+			name: "not found",
+			err:  fmt.Errorf("wrapped: %w", errorx.ErrNotFound),
+			want: ErrorMapping{Status: http.StatusNotFound, Code: CodeEntityNotFound,
+				Message: notFoundDefaultMessage, LogLevel: zapcore.InfoLevel},
+		},
+		{
+			name: "conflict",
+			err:  errorx.ErrConflict,
+			want: ErrorMapping{Status: http.StatusConflict, Code: CodeDuplicateEntity,
+				Message: conflictDefaultMessage, LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "unauthorized",
+			err:  errorx.ErrUnauthorized,
+			want: ErrorMapping{Status: http.StatusUnauthorized, Code: CodeUnauthorized,
+				Message: unauthorizedDefaultMessage, LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "forbidden",
+			err:  errorx.ErrForbidden,
+			want: ErrorMapping{Status: http.StatusForbidden, Code: CodeForbidden,
+				Message: forbiddenDefaultMessage, LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "validation",
+			err:  &errorx.ValidationError{Properties: map[string]string{"email": "is required"}},
+			want: ErrorMapping{Status: http.StatusBadRequest, Code: CodeBadRequest,
+				Message: validationFailedDefaultMessage, Details: map[string]string{"email": "is required"},
+				LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "bad request",
+			err:  &errorx.BadRequestError{Message: "invalid id"},
+			want: ErrorMapping{Status: http.StatusBadRequest, Code: CodeBadRequest,
+				Message: "invalid id", LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "unique violation does not expose properties",
+			err:  &errorx.UniqueViolationError{Properties: map[string]string{"email": "a@b.c"}},
+			want: ErrorMapping{Status: http.StatusConflict, Code: CodeDuplicateEntity,
+				Message: conflictDefaultMessage, LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "custom registration",
+			err:  errCustom,
+			want: ErrorMapping{Status: http.StatusTeapot, Code: CodeBadRequest,
+				Message: "custom", LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "message override",
+			err:  WithMessage(&errorx.UniqueViolationError{}, "user profile already exists"),
+			want: ErrorMapping{Status: http.StatusConflict, Code: CodeDuplicateEntity,
+				Message: "user profile already exists", LogLevel: zapcore.WarnLevel},
+		},
+		{
+			name: "message override ignored for server errors",
+			err:  WithMessage(errors.New("db down"), "leaked"),
+			want: ErrorMapping{Status: http.StatusInternalServerError, Code: CodeServerError,
+				Message: internalServerErrorDefaultMessage, LogLevel: zapcore.ErrorLevel},
+		},
+		{
+			name: "client closed request",
+			err:  fmt.Errorf("query failed: %w", context.Canceled),
+			want: ErrorMapping{Status: StatusClientClosedRequest, Code: CodeClientClosed,
+				Message: "client closed request", LogLevel: zapcore.DebugLevel},
+		},
+		{
+			name: "unknown",
+			err:  errors.New("unknown"),
+			want: ErrorMapping{Status: http.StatusInternalServerError, Code: CodeServerError,
+				Message: internalServerErrorDefaultMessage, LogLevel: zapcore.ErrorLevel},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reg.Resolve(tt.err))
+		})
+	}
+}
+
+func TestErrorRegistry_Handle(t *testing.T) {
+	reg := NewErrorRegistry(zap.NewNop())
+
+	t.Run("error rendered", func(t *testing.T) {
+		h := reg.Handle(func(w http.ResponseWriter, r *http.Request) error {
+			return errorx.ErrNotFound
+		})
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var errResp ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+		assert.Equal(t, CodeEntityNotFound, errResp.Code)
+	})
+
+	t.Run("no error", func(t *testing.T) {
+		h := reg.Handle(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		})
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestWithMessage_Nil(t *testing.T) {
+	assert.Nil(t, WithMessage(nil, "message"))
+}
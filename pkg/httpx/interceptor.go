@@ -0,0 +1,224 @@
+package httpx
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// InterceptorHooks observe a response as it is written, nil hooks are skipped.
+type InterceptorHooks struct {
+	// OnWriteHeader called once with the final status, including the implicit 200 of a first Write.
+	OnWriteHeader func(statusCode int)
+	// OnWrite called with the bytes accepted by the underlying writer.
+	// When set, io.ReaderFrom is served through Write so that every byte is observed.
+	OnWrite func(b []byte)
+	OnFlush func()
+}
+
+// Interceptor records status, bytes written and timing of a response.
+// Use Wrap to pass it to handlers, as it exposes the optional
+// http.Flusher, http.Hijacker and io.ReaderFrom only when the underlying writer supports them.
+type Interceptor struct {
+	w     http.ResponseWriter
+	hooks InterceptorHooks
+
+	start        time.Time
+	status       int
+	wroteHeader  bool
+	bytesWritten int64
+	writeErr     error
+	flushed      bool
+	hijacked     bool
+}
+
+func NewInterceptor(w http.ResponseWriter, hooks InterceptorHooks) *Interceptor {
+	return &Interceptor{w: w, hooks: hooks, start: time.Now()}
+}
+
+// Wrap returns the interceptor as a http.ResponseWriter implementing
+// the same optional interfaces as the underlying writer.
+func (i *Interceptor) Wrap() http.ResponseWriter {
+	_, isFlusher := i.w.(http.Flusher)
+	_, isHijacker := i.w.(http.Hijacker)
+	_, isReaderFrom := i.w.(io.ReaderFrom)
+
+	switch {
+	case isFlusher && isHijacker && isReaderFrom:
+		return struct {
+			*Interceptor
+			flusher
+			hijacker
+			readerFrom
+		}{i, flusher{i}, hijacker{i}, readerFrom{i}}
+	case isFlusher && isHijacker:
+		return struct {
+			*Interceptor
+			flusher
+			hijacker
+		}{i, flusher{i}, hijacker{i}}
+	case isFlusher && isReaderFrom:
+		return struct {
+			*Interceptor
+			flusher
+			readerFrom
+		}{i, flusher{i}, readerFrom{i}}
+	case isHijacker && isReaderFrom:
+		return struct {
+			*Interceptor
+			hijacker
+			readerFrom
+		}{i, hijacker{i}, readerFrom{i}}
+	case isFlusher:
+		return struct {
+			*Interceptor
+			flusher
+		}{i, flusher{i}}
+	case isHijacker:
+		return struct {
+			*Interceptor
+			hijacker
+		}{i, hijacker{i}}
+	case isReaderFrom:
+		return struct {
+			*Interceptor
+			readerFrom
+		}{i, readerFrom{i}}
+	default:
+		return i
+	}
+}
+
+func (i *Interceptor) Header() http.Header {
+	return i.w.Header()
+}
+
+func (i *Interceptor) WriteHeader(statusCode int) {
+	// Informational responses may be written multiple times before the final status,
+	// except 101 which hands over the connection.
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		i.w.WriteHeader(statusCode)
+		return
+	}
+	i.recordHeader(statusCode)
+	i.w.WriteHeader(statusCode)
+}
+
+func (i *Interceptor) Write(b []byte) (int, error) {
+	i.recordHeader(http.StatusOK)
+	n, err := i.w.Write(b)
+	i.recordWrite(b[:n], err)
+	return n, err
+}
+
+// Unwrap allows http.ResponseController to reach the underlying writer.
+func (i *Interceptor) Unwrap() http.ResponseWriter {
+	return i.w
+}
+
+// recordHeader keeps the first status, as per net/http superfluous calls are ignored.
+func (i *Interceptor) recordHeader(statusCode int) {
+	if i.wroteHeader {
+		return
+	}
+	i.wroteHeader = true
+	i.status = statusCode
+	if i.hooks.OnWriteHeader != nil {
+		i.hooks.OnWriteHeader(statusCode)
+	}
+}
+
+func (i *Interceptor) recordWrite(b []byte, err error) {
+	i.bytesWritten += int64(len(b))
+	if err != nil && i.writeErr == nil {
+		i.writeErr = err
+	}
+	if i.hooks.OnWrite != nil && len(b) > 0 {
+		i.hooks.OnWrite(b)
+	}
+}
+
+// Status returns the written status, defaults to 200 as per net/http when not explicitly set.
+func (i *Interceptor) Status() int {
+	if i.status == 0 {
+		return http.StatusOK
+	}
+	return i.status
+}
+
+func (i *Interceptor) WroteHeader() bool {
+	return i.wroteHeader
+}
+
+func (i *Interceptor) BytesWritten() int64 {
+	return i.bytesWritten
+}
+
+// Duration since the interceptor was created.
+func (i *Interceptor) Duration() time.Duration {
+	return time.Since(i.start)
+}
+
+// WriteErr returns the first error returned by the underlying writer,
+// e.g. client disconnected or http.ErrHandlerTimeout.
+func (i *Interceptor) WriteErr() error {
+	return i.writeErr
+}
+
+func (i *Interceptor) Flushed() bool {
+	return i.flushed
+}
+
+func (i *Interceptor) Hijacked() bool {
+	return i.hijacked
+}
+
+type flusher struct {
+	i *Interceptor
+}
+
+func (f flusher) Flush() {
+	f.i.recordHeader(http.StatusOK)
+	f.i.flushed = true
+	if f.i.hooks.OnFlush != nil {
+		f.i.hooks.OnFlush()
+	}
+	f.i.w.(http.Flusher).Flush()
+}
+
+type hijacker struct {
+	i *Interceptor
+}
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := h.i.w.(http.Hijacker).Hijack()
+	if err == nil {
+		h.i.hijacked = true
+		h.i.recordHeader(http.StatusSwitchingProtocols)
+	}
+	return conn, rw, err
+}
+
+type readerFrom struct {
+	i *Interceptor
+}
+
+func (rf readerFrom) ReadFrom(src io.Reader) (int64, error) {
+	if rf.i.hooks.OnWrite != nil {
+		// writerOnly hides ReadFrom, preventing io.Copy from calling back into this method
+		return io.Copy(writerOnly{rf.i}, src)
+	}
+	rf.i.recordHeader(http.StatusOK)
+	n, err := rf.i.w.(io.ReaderFrom).ReadFrom(src)
+	rf.i.bytesWritten += n
+	if err != nil && rf.i.writeErr == nil {
+		rf.i.writeErr = err
+	}
+	return n, err
+}
+
+type writerOnly struct {
+	io.Writer
+}
@@ -0,0 +1,201 @@
+package httpx
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+type hijackWriter struct {
+	http.ResponseWriter
+	hijacked bool
+}
+
+func (h *hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+type readerFromWriter struct {
+	http.ResponseWriter
+	readFrom bool
+}
+
+func (rf *readerFromWriter) ReadFrom(src io.Reader) (int64, error) {
+	rf.readFrom = true
+	return io.Copy(rf.ResponseWriter, src)
+}
+
+type errWriter struct {
+	http.ResponseWriter
+}
+
+func (e errWriter) Write(b []byte) (int, error) {
+	return 0, http.ErrHandlerTimeout
+}
+
+func TestInterceptor_Wrap_OptionalInterfaces(t *testing.T) {
+	tests := []struct {
+		name         string
+		w            http.ResponseWriter
+		isFlusher    bool
+		isHijacker   bool
+		isReaderFrom bool
+	}{
+		{name: "plain", w: plainWriter{httptest.NewRecorder()}},
+		{name: "flusher", w: httptest.NewRecorder(), isFlusher: true},
+		{name: "hijacker", w: &hijackWriter{ResponseWriter: plainWriter{httptest.NewRecorder()}}, isHijacker: true},
+		{name: "reader from", w: &readerFromWriter{ResponseWriter: plainWriter{httptest.NewRecorder()}}, isReaderFrom: true},
+		{
+			name: "flusher and hijacker",
+			w: struct {
+				*httptest.ResponseRecorder
+				*hijackWriter
+			}{httptest.NewRecorder(), &hijackWriter{}},
+			isFlusher:  true,
+			isHijacker: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewInterceptor(tt.w, InterceptorHooks{}).Wrap()
+
+			_, isFlusher := w.(http.Flusher)
+			_, isHijacker := w.(http.Hijacker)
+			_, isReaderFrom := w.(io.ReaderFrom)
+			assert.Equal(t, tt.isFlusher, isFlusher)
+			assert.Equal(t, tt.isHijacker, isHijacker)
+			assert.Equal(t, tt.isReaderFrom, isReaderFrom)
+		})
+	}
+}
+
+func TestInterceptor_WriteHeader(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var hookStatuses []int
+	interceptor := NewInterceptor(recorder, InterceptorHooks{
+		OnWriteHeader: func(statusCode int) { hookStatuses = append(hookStatuses, statusCode) },
+	})
+	w := interceptor.Wrap()
+
+	w.WriteHeader(http.StatusEarlyHints)
+	assert.False(t, interceptor.WroteHeader())
+
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusOK) // superfluous, should not overwrite the first status
+
+	assert.True(t, interceptor.WroteHeader())
+	assert.Equal(t, http.StatusCreated, interceptor.Status())
+	assert.Equal(t, []int{http.StatusCreated}, hookStatuses)
+}
+
+func TestInterceptor_Write(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	var written []byte
+	interceptor := NewInterceptor(recorder, InterceptorHooks{
+		OnWrite: func(b []byte) { written = append(written, b...) },
+	})
+	w := interceptor.Wrap()
+
+	assert.Equal(t, http.StatusOK, interceptor.Status())
+	assert.False(t, interceptor.WroteHeader())
+
+	_, err := w.Write([]byte("hello "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("world"))
+	require.NoError(t, err)
+
+	assert.True(t, interceptor.WroteHeader())
+	assert.Equal(t, http.StatusOK, interceptor.Status())
+	assert.Equal(t, int64(11), interceptor.BytesWritten())
+	assert.Equal(t, "hello world", string(written))
+	assert.Equal(t, "hello world", recorder.Body.String())
+	assert.NoError(t, interceptor.WriteErr())
+	assert.Positive(t, interceptor.Duration())
+}
+
+func TestInterceptor_Write_Error(t *testing.T) {
+	interceptor := NewInterceptor(errWriter{httptest.NewRecorder()}, InterceptorHooks{})
+
+	_, err := interceptor.Wrap().Write([]byte("abc"))
+
+	assert.ErrorIs(t, err, http.ErrHandlerTimeout)
+	assert.ErrorIs(t, interceptor.WriteErr(), http.ErrHandlerTimeout)
+	assert.Equal(t, int64(0), interceptor.BytesWritten())
+}
+
+func TestInterceptor_Flush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	hookCalled := false
+	interceptor := NewInterceptor(recorder, InterceptorHooks{
+		OnFlush: func() { hookCalled = true },
+	})
+
+	interceptor.Wrap().(http.Flusher).Flush()
+
+	assert.True(t, hookCalled)
+	assert.True(t, interceptor.Flushed())
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, http.StatusOK, interceptor.Status())
+}
+
+func TestInterceptor_Hijack(t *testing.T) {
+	underlying := &hijackWriter{ResponseWriter: httptest.NewRecorder()}
+	interceptor := NewInterceptor(underlying, InterceptorHooks{})
+
+	_, _, err := interceptor.Wrap().(http.Hijacker).Hijack()
+
+	require.NoError(t, err)
+	assert.True(t, underlying.hijacked)
+	assert.True(t, interceptor.Hijacked())
+	assert.Equal(t, http.StatusSwitchingProtocols, interceptor.Status())
+}
+
+func TestInterceptor_ReadFrom(t *testing.T) {
+	t.Run("forwarded without write hook", func(t *testing.T) {
+		underlying := &readerFromWriter{ResponseWriter: httptest.NewRecorder()}
+		interceptor := NewInterceptor(underlying, InterceptorHooks{})
+
+		n, err := interceptor.Wrap().(io.ReaderFrom).ReadFrom(strings.NewReader("abc"))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.True(t, underlying.readFrom)
+		assert.Equal(t, int64(3), interceptor.BytesWritten())
+	})
+
+	t.Run("served through write with write hook", func(t *testing.T) {
+		underlying := &readerFromWriter{ResponseWriter: httptest.NewRecorder()}
+		var written []byte
+		interceptor := NewInterceptor(underlying, InterceptorHooks{
+			OnWrite: func(b []byte) { written = append(written, b...) },
+		})
+
+		n, err := interceptor.Wrap().(io.ReaderFrom).ReadFrom(strings.NewReader("abc"))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.False(t, underlying.readFrom)
+		assert.Equal(t, "abc", string(written))
+		assert.Equal(t, int64(3), interceptor.BytesWritten())
+	})
+}
+
+func TestInterceptor_Unwrap(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	interceptor := NewInterceptor(recorder, InterceptorHooks{})
+
+	assert.Equal(t, recorder, interceptor.Unwrap())
+}
@@ -9,6 +9,7 @@ import (
 )
 
 const contentTypeJSON = "application/json"
+const contentTypeEventStream = "text/event-stream"
 const headerKeyContentType = "Content-Type"
 
 const internalServerErrorDefaultMessage = "internal server error"
@@ -16,6 +17,10 @@ const validationFailedDefaultMessage = "validation failed"
 const notFoundDefaultMessage = "entity not found"
 
 func JsonResponse(statusCode int, resp any, w http.ResponseWriter) {
+	writeJson(statusCode, contentTypeJSON, resp, w)
+}
+
+func writeJson(statusCode int, contentType string, resp any, w http.ResponseWriter) {
 	// Why not directly in the response writer?
 	// In the event encoding fails, we would be able to
 	// change the status code accordingly.
@@ -28,65 +33,65 @@ func JsonResponse(statusCode int, resp any, w http.ResponseWriter) {
 		return
 	}
 
-	w.Header().Set(headerKeyContentType, contentTypeJSON)
+	w.Header().Set(headerKeyContentType, contentType)
 	w.WriteHeader(statusCode)
 
 	// Errors are likely due to client disconnect.
 	_, _ = w.Write(buf.Bytes())
 }
 
-func BadRequestResponse(message string, details map[string]string, w http.ResponseWriter) {
-	JsonResponse(
+func BadRequestResponse(message string, details map[string]string, w http.ResponseWriter, r *http.Request) {
+	ErrorJsonResponse(
 		http.StatusBadRequest,
 		ErrorResponse{
 			Code:    CodeBadRequest,
 			Message: message,
 			Details: details,
 		},
-		w)
+		w, r)
 }
 
-func ValidationFailedResponse(validationFailure *errorx.ValidationError, w http.ResponseWriter) {
-	JsonResponse(
+func ValidationFailedResponse(validationFailure *errorx.ValidationError, w http.ResponseWriter, r *http.Request) {
+	ErrorJsonResponse(
 		http.StatusBadRequest,
 		ErrorResponse{
 			Code:    CodeBadRequest,
 			Message: validationFailedDefaultMessage,
 			Details: validationFailure.Properties,
 		},
-		w)
+		w, r)
 }
 
-func NotFoundResponse(w http.ResponseWriter) {
-	JsonResponse(
+func NotFoundResponse(w http.ResponseWriter, r *http.Request) {
+	ErrorJsonResponse(
 		http.StatusNotFound,
 		ErrorResponse{
 			Code:    CodeEntityNotFound,
 			Message: notFoundDefaultMessage,
 		},
-		w)
+		w, r)
 }
 
-func ConflictResponse(message string, details map[string]string, w http.ResponseWriter) {
-	JsonResponse(
+func ConflictResponse(message string, details map[string]string, w http.ResponseWriter, r *http.Request) {
+	ErrorJsonResponse(
 		http.StatusConflict,
 		ErrorResponse{
 			Code:    CodeDuplicateEntity,
 			Message: message,
 			Details: details,
 		},
-		w)
+		w, r)
 }
 
-func InternalServerErrorResponse(message string, w http.ResponseWriter) {
+func InternalServerErrorResponse(message string, w http.ResponseWriter, r *http.Request) {
 	if message == "" {
 		message = internalServerErrorDefaultMessage
 	}
-	JsonResponse(
+	ErrorJsonResponse(
 		http.StatusInternalServerError,
 		ErrorResponse{
 			Code:    CodeServerError,
 			Message: message,
 		},
-		w)
+		w, r)
 }
@@ -0,0 +1,92 @@
+package httpx
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const contentTypeProblemJSON = "application/problem+json"
+const headerKeyAccept = "Accept"
+const headerKeyVary = "Vary"
+
+// ProblemTypePrefix prefixes errorCode to form ProblemDetails.Type.
+const ProblemTypePrefix = "urn:bigbackend:problem:"
+
+// ProblemDetails RFC 9457 problem details, errorCode and errors are extension members.
+type ProblemDetails struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	ErrorCode errorCode         `json:"errorCode"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	RequestID string            `json:"requestId,omitempty"`
+}
+
+func newProblemDetails(statusCode int, errResp ErrorResponse, r *http.Request) ProblemDetails {
+	problem := ProblemDetails{
+		Type:      ProblemTypePrefix + errResp.Code.String(),
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    errResp.Message,
+		ErrorCode: errResp.Code,
+		Errors:    errResp.Details,
+		RequestID: errResp.RequestID,
+	}
+	if r != nil {
+		problem.Instance = r.URL.Path
+	}
+	return problem
+}
+
+// ErrorJsonResponse writes errResp as application/problem+json when accepted by the client,
+// else as the legacy ErrorResponse.
+func ErrorJsonResponse(statusCode int, errResp ErrorResponse, w http.ResponseWriter, r *http.Request) {
+	w.Header().Add(headerKeyVary, headerKeyAccept)
+	if r != nil && errResp.RequestID == "" {
+		errResp.RequestID = RequestIDFromContext(r.Context())
+	}
+
+	if !AcceptsProblemJSON(r) {
+		JsonResponse(statusCode, errResp, w)
+		return
+	}
+
+	writeJson(statusCode, contentTypeProblemJSON, newProblemDetails(statusCode, errResp, r), w)
+}
+
+// AcceptsProblemJSON reports whether application/problem+json is preferred over application/json.
+// Wildcards are ignored, clients have to explicitly opt in.
+func AcceptsProblemJSON(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+
+	problemQ, jsonQ := 0.0, 0.0
+	for _, accept := range r.Header.Values(headerKeyAccept) {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if qStr, ok := params["q"]; ok {
+				q, err = strconv.ParseFloat(qStr, 64)
+				if err != nil {
+					continue
+				}
+			}
+			switch mediaType {
+			case contentTypeProblemJSON:
+				problemQ = max(problemQ, q)
+			case contentTypeJSON:
+				jsonQ = max(jsonQ, q)
+			}
+		}
+	}
+
+	return problemQ > 0 && problemQ >= jsonQ
+}
@@ -0,0 +1,82 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsProblemJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept []string
+		want   bool
+	}{
+		{name: "no accept header", accept: nil, want: false},
+		{name: "wildcard", accept: []string{"*/*"}, want: false},
+		{name: "json", accept: []string{"application/json"}, want: false},
+		{name: "problem json", accept: []string{"application/problem+json"}, want: true},
+		{name: "problem json with params", accept: []string{"application/problem+json; charset=utf-8"}, want: true},
+		{name: "problem json preferred", accept: []string{"application/json;q=0.5, application/problem+json"}, want: true},
+		{name: "json preferred", accept: []string{"application/json, application/problem+json;q=0.9"}, want: false},
+		{name: "equal preference", accept: []string{"application/json, application/problem+json"}, want: true},
+		{name: "problem json rejected", accept: []string{"application/problem+json;q=0"}, want: false},
+		{name: "multiple headers", accept: []string{"text/html", "application/problem+json"}, want: true},
+		{name: "invalid q ignored", accept: []string{"application/problem+json;q=abc"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, v := range tt.accept {
+				r.Header.Add(headerKeyAccept, v)
+			}
+			assert.Equal(t, tt.want, AcceptsProblemJSON(r))
+		})
+	}
+}
+
+func TestErrorJsonResponse_Problem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/user/1/profile", nil)
+	r.Header.Set(headerKeyAccept, contentTypeProblemJSON)
+	w := httptest.NewRecorder()
+
+	BadRequestResponse("validation failed", map[string]string{"email": "is required"}, w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, contentTypeProblemJSON, w.Header().Get(headerKeyContentType))
+	assert.Equal(t, headerKeyAccept, w.Header().Get(headerKeyVary))
+
+	var problem ProblemDetails
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, ProblemDetails{
+		Type:      "urn:bigbackend:problem:bad_request",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Detail:    "validation failed",
+		Instance:  "/api/v1/user/1/profile",
+		ErrorCode: CodeBadRequest,
+		Errors:    map[string]string{"email": "is required"},
+	}, problem)
+}
+
+func TestErrorJsonResponse_Legacy(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/user/1/profile", nil)
+	w := httptest.NewRecorder()
+
+	NotFoundResponse(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, contentTypeJSON, w.Header().Get(headerKeyContentType))
+
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, ErrorResponse{
+		Code:    CodeEntityNotFound,
+		Message: notFoundDefaultMessage,
+	}, errResp)
+}
@@ -0,0 +1,211 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderKeyRateLimitLimit     = "RateLimit-Limit"
+	HeaderKeyRateLimitRemaining = "RateLimit-Remaining"
+	HeaderKeyRateLimitReset     = "RateLimit-Reset"
+	HeaderKeyRateLimitPolicy    = "RateLimit-Policy"
+	HeaderKeyRetryAfter         = "Retry-After"
+)
+
+var ErrTooManyRequests = errors.New("too many requests")
+
+// RateLimit allows Requests per Period, with bursts of up to Burst requests.
+// Burst defaults to Requests when zero.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l RateLimit) burst() int {
+	if l.Burst <= 0 {
+		return l.Requests
+	}
+	return l.Burst
+}
+
+// EmissionInterval time for a single request to be replenished.
+func (l RateLimit) EmissionInterval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Tolerance how far the theoretical arrival time may run ahead of now, the time to replenish a burst.
+func (l RateLimit) Tolerance() time.Duration {
+	return l.EmissionInterval() * time.Duration(l.burst())
+}
+
+// RateLimitResult outcome of a request against a RateLimit.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter until the request would be allowed, zero when allowed.
+	RetryAfter time.Duration
+	// ResetAfter until the limit is fully replenished.
+	ResetAfter time.Duration
+}
+
+// GCRA evaluates a request with the generic cell rate algorithm, a token bucket tracked by a single timestamp.
+// tat is the theoretical arrival time stored for the key, zero for unseen keys.
+// When allowed, the returned time is the new tat to store, otherwise tat is returned unchanged.
+func GCRA(now, tat time.Time, limit RateLimit) (time.Time, RateLimitResult) {
+	interval := limit.EmissionInterval()
+	tolerance := limit.Tolerance()
+
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(interval)
+	allowAt := newTat.Add(-tolerance)
+
+	result := RateLimitResult{Limit: limit.burst()}
+	if now.Before(allowAt) {
+		result.RetryAfter = allowAt.Sub(now)
+		result.ResetAfter = tat.Sub(now)
+		return tat, result
+	}
+
+	result.Allowed = true
+	result.Remaining = int(now.Sub(allowAt) / interval)
+	result.ResetAfter = newTat.Sub(now)
+	return newTat, result
+}
+
+// RateLimitStore tracks requests per key, implementations must evaluate and record atomically.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimitKeyFunc identifies the client of a request, an empty key skips limiting.
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimitByClientIP keys requests by ClientIP.
+func RateLimitByClientIP(trustedProxies []netip.Prefix) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		return "ip:" + ClientIP(r, trustedProxies)
+	}
+}
+
+// RateLimitByHeader keys requests by header value, e.g. a tenant ID.
+func RateLimitByHeader(header string) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		value := r.Header.Get(header)
+		if value == "" {
+			return ""
+		}
+		return "header:" + header + ":" + value
+	}
+}
+
+// RateLimitBySubject keys authenticated requests by subject, e.g. a user or API key,
+// falling back to fallback for anonymous requests.
+func RateLimitBySubject(subject func(r *http.Request) string, fallback RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		if s := subject(r); s != "" {
+			return "subject:" + s
+		}
+		return fallback(r)
+	}
+}
+
+// RateLimitMetricsRecorder records rate limiter metrics.
+// Implemented by monitoring.Metrics.
+type RateLimitMetricsRecorder interface {
+	RecordRateLimited(name string)
+	RecordRateLimitStoreError(name string)
+}
+
+type noopRateLimitMetrics struct{}
+
+func (noopRateLimitMetrics) RecordRateLimited(string)         {}
+func (noopRateLimitMetrics) RecordRateLimitStoreError(string) {}
+
+// RateLimiter creates per-route rate limiting middlewares sharing a store.
+type RateLimiter struct {
+	logger    *zap.Logger
+	store     RateLimitStore
+	errWriter func(err error, w http.ResponseWriter, r *http.Request)
+	metrics   RateLimitMetricsRecorder
+}
+
+type RateLimiterOption func(*RateLimiter)
+
+func WithRateLimitMetrics(metrics RateLimitMetricsRecorder) RateLimiterOption {
+	return func(rl *RateLimiter) {
+		rl.metrics = metrics
+	}
+}
+
+// NewRateLimiter renders throttled requests with errWriter, e.g. ErrorRegistry.WriteError.
+func NewRateLimiter(
+	logger *zap.Logger,
+	store RateLimitStore,
+	errWriter func(err error, w http.ResponseWriter, r *http.Request),
+	options ...RateLimiterOption,
+) *RateLimiter {
+	rl := &RateLimiter{logger: logger, store: store, errWriter: errWriter, metrics: noopRateLimitMetrics{}}
+	for _, opt := range options {
+		opt(rl)
+	}
+	return rl
+}
+
+// Limit returns a middleware limiting requests of each key to limit, name scopes keys so that routes are limited independently.
+// RateLimit-* headers are set on every limited response, throttled requests are rejected with
+// ErrTooManyRequests and Retry-After. Requests are allowed when the store fails, preferring availability.
+func (rl *RateLimiter) Limit(name string, limit RateLimit, keyFn RateLimitKeyFunc) func(http.Handler) http.Handler {
+	policy := strconv.Itoa(limit.burst()) + ";w=" + strconv.Itoa(ceilSeconds(limit.Period))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := keyFn(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := rl.store.Allow(r.Context(), name+":"+key, limit)
+			if err != nil {
+				rl.metrics.RecordRateLimitStoreError(name)
+				logx.FromContext(r.Context(), rl.logger).Error("failed to evaluate rate limit, allowing request",
+					zap.Error(err), zap.String("limit", name))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set(HeaderKeyRateLimitPolicy, policy)
+			h.Set(HeaderKeyRateLimitLimit, strconv.Itoa(result.Limit))
+			h.Set(HeaderKeyRateLimitRemaining, strconv.Itoa(result.Remaining))
+			h.Set(HeaderKeyRateLimitReset, strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+			if !result.Allowed {
+				rl.metrics.RecordRateLimited(name)
+				h.Set(HeaderKeyRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				rl.errWriter(ErrTooManyRequests, w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds rounds up, so that clients retrying after the returned seconds are not throttled.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memRateLimitSweepInterval = time.Minute
+
+// MemRateLimitStore keeps limits in memory, each instance limits independently.
+// Use a shared store such as PostgreSQL when running multiple instances.
+type MemRateLimitStore struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemRateLimitStore() *MemRateLimitStore {
+	return &MemRateLimitStore{tats: make(map[string]time.Time), now: time.Now}
+}
+
+func (s *MemRateLimitStore) Allow(_ context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	tat, result := GCRA(now, s.tats[key], limit)
+	s.tats[key] = tat
+	return result, nil
+}
+
+// sweep removes fully replenished keys, as they are equivalent to unseen keys.
+func (s *MemRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memRateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, tat := range s.tats {
+		if !tat.After(now) {
+			delete(s.tats, key)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGCRA(t *testing.T) {
+	limit := RateLimit{Requests: 2, Period: time.Second}
+	now := time.Now()
+
+	tat, result := GCRA(now, time.Time{}, limit)
+	assert.Equal(t, RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: 500 * time.Millisecond}, result)
+
+	tat, result = GCRA(now, tat, limit)
+	assert.Equal(t, RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: time.Second}, result)
+
+	denied, result := GCRA(now, tat, limit)
+	assert.Equal(t, tat, denied)
+	assert.Equal(t, RateLimitResult{Limit: 2, RetryAfter: 500 * time.Millisecond, ResetAfter: time.Second}, result)
+
+	// A single request is replenished per emission interval
+	_, result = GCRA(now.Add(500*time.Millisecond), tat, limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestGCRA_Burst(t *testing.T) {
+	limit := RateLimit{Requests: 60, Period: time.Minute, Burst: 3}
+	now := time.Now()
+
+	var tat time.Time
+	var result RateLimitResult
+	for range 3 {
+		tat, result = GCRA(now, tat, limit)
+		require.True(t, result.Allowed)
+	}
+	_, result = GCRA(now, tat, limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+}
+
+func TestMemRateLimitStore_Sweep(t *testing.T) {
+	store := NewMemRateLimitStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	_, err := store.Allow(t.Context(), "a", RateLimit{Requests: 1, Period: time.Second})
+	require.NoError(t, err)
+	assert.Len(t, store.tats, 1)
+
+	now = now.Add(memRateLimitSweepInterval)
+	_, err = store.Allow(t.Context(), "b", RateLimit{Requests: 1, Period: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, keysOf(store.tats))
+}
+
+func keysOf(m map[string]time.Time) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type rateLimitMetricsStub struct {
+	limited     []string
+	storeErrors []string
+}
+
+func (m *rateLimitMetricsStub) RecordRateLimited(name string) { m.limited = append(m.limited, name) }
+func (m *rateLimitMetricsStub) RecordRateLimitStoreError(name string) {
+	m.storeErrors = append(m.storeErrors, name)
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	metrics := &rateLimitMetricsStub{}
+	limiter := NewRateLimiter(zap.NewNop(), NewMemRateLimitStore(), NewErrorRegistry(zap.NewNop()).WriteError,
+		WithRateLimitMetrics(metrics))
+	handler := limiter.Limit("read", RateLimit{Requests: 2, Period: time.Minute}, RateLimitByClientIP(nil))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2;w=60", w.Header().Get(HeaderKeyRateLimitPolicy))
+	assert.Equal(t, "2", w.Header().Get(HeaderKeyRateLimitLimit))
+	assert.Equal(t, "1", w.Header().Get(HeaderKeyRateLimitRemaining))
+	assert.Equal(t, "30", w.Header().Get(HeaderKeyRateLimitReset))
+	assert.Empty(t, w.Header().Get(HeaderKeyRetryAfter))
+
+	assert.Equal(t, http.StatusOK, serve("192.0.2.1:1234").Code)
+
+	w = serve("192.0.2.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get(HeaderKeyRateLimitRemaining))
+	assert.Equal(t, "30", w.Header().Get(HeaderKeyRetryAfter))
+
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, CodeTooManyRequests, resp.Code)
+	assert.Equal(t, []string{"read"}, metrics.limited)
+
+	// Limited independently per client
+	assert.Equal(t, http.StatusOK, serve("192.0.2.2:1234").Code)
+}
+
+type errRateLimitStore struct{}
+
+func (errRateLimitStore) Allow(context.Context, string, RateLimit) (RateLimitResult, error) {
+	return RateLimitResult{}, assert.AnError
+}
+
+func TestRateLimiter_Limit_StoreErrorAllows(t *testing.T) {
+	metrics := &rateLimitMetricsStub{}
+	limiter := NewRateLimiter(zap.NewNop(), errRateLimitStore{}, NewErrorRegistry(zap.NewNop()).WriteError,
+		WithRateLimitMetrics(metrics))
+
+	w := httptest.NewRecorder()
+	limiter.Limit("read", RateLimit{Requests: 1, Period: time.Second}, RateLimitByClientIP(nil))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }),
+	).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderKeyRateLimitLimit))
+	assert.Equal(t, []string{"read"}, metrics.storeErrors)
+}
+
+func TestRateLimitKeyFuncs(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set(HeaderKeyForwardedFor, "198.51.100.7")
+	r.Header.Set("X-Tenant-Id", "tenant-1")
+
+	byIP := RateLimitByClientIP([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	assert.Equal(t, "ip:198.51.100.7", byIP(r))
+	assert.Equal(t, "header:X-Tenant-Id:tenant-1", RateLimitByHeader("X-Tenant-Id")(r))
+	assert.Empty(t, RateLimitByHeader("X-Other")(r))
+
+	subject := ""
+	bySubject := RateLimitBySubject(func(*http.Request) string { return subject }, byIP)
+	assert.Equal(t, "ip:198.51.100.7", bySubject(r))
+	subject = "user-1"
+	assert.Equal(t, "subject:user-1", bySubject(r))
+}
@@ -0,0 +1,137 @@
+package httpx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/google/uuid"
+)
+
+const HeaderKeyRequestID = "X-Request-Id"
+const HeaderKeyTraceparent = "traceparent"
+
+const maxRequestIDLength = 128
+const traceparentVersion = "00"
+
+type requestIDCtxKey struct{}
+type traceContextCtxKey struct{}
+
+// TraceContext W3C trace context of a request, see https://www.w3.org/TR/trace-context/.
+type TraceContext struct {
+	TraceID string
+	// SpanID identifies this server's handling of the request, sent downstream as parent-id.
+	SpanID string
+	// ParentID span ID of the caller, empty when the trace started here.
+	ParentID string
+	Flags    string
+}
+
+// Traceparent formats tc as a traceparent header value.
+func (tc TraceContext) Traceparent() string {
+	return traceparentVersion + "-" + tc.TraceID + "-" + tc.SpanID + "-" + tc.Flags
+}
+
+// RequestIDMiddleware accepts or generates X-Request-Id and traceparent, stores them in the context,
+// attaches them to logx.FromContext loggers and echoes them in the response.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, requestID, tc := WithRequestID(r.Context(),
+			r.Header.Get(HeaderKeyRequestID), r.Header.Get(HeaderKeyTraceparent))
+
+		w.Header().Set(HeaderKeyRequestID, requestID)
+		w.Header().Set(HeaderKeyTraceparent, tc.Traceparent())
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WithRequestID accepts or generates requestID and traceparent as RequestIDMiddleware does, for transports
+// other than HTTP, e.g. gRPC metadata. Returns ctx carrying them and the values to echo to the caller.
+func WithRequestID(ctx context.Context, requestID, traceparent string) (context.Context, string, TraceContext) {
+	if !isValidRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+
+	tc, ok := parseTraceparent(traceparent)
+	if !ok {
+		tc = TraceContext{TraceID: randomHex(16), Flags: "00"}
+	}
+	tc.SpanID = randomHex(8)
+
+	ctx = context.WithValue(ctx, requestIDCtxKey{}, requestID)
+	ctx = context.WithValue(ctx, traceContextCtxKey{}, tc)
+	ctx = logx.WithFields(ctx, logx.RequestID(requestID), logx.TraceID(tc.TraceID))
+
+	return ctx, requestID, tc
+}
+
+// RequestIDFromContext returns the request ID set by RequestIDMiddleware, empty if not set.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// TraceContextFromContext returns the trace context set by RequestIDMiddleware.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextCtxKey{}).(TraceContext)
+	return tc, ok
+}
+
+// isValidRequestID restricts client IDs to visible ASCII, as they are echoed and logged.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTraceparent parses version 00 headers, future versions are parsed by their version 00 prefix.
+func parseTraceparent(header string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == traceparentVersion && len(parts) != 4) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(traceID, 32) || isAllZero(traceID) ||
+		!isLowerHex(parentID, 16) || isAllZero(parentID) ||
+		!isLowerHex(flags, 2) {
+		return TraceContext{}, false
+	}
+	return TraceContext{TraceID: traceID, ParentID: parentID, Flags: flags}, true
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAllZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,140 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var traceparentRegexp = regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+func serveRequestID(r *http.Request, next http.HandlerFunc) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	RequestIDMiddleware(next).ServeHTTP(w, r)
+	return w
+}
+
+func TestRequestIDMiddleware_Generated(t *testing.T) {
+	var ctxRequestID string
+	var ctxTrace TraceContext
+	var fields []zap.Field
+
+	w := serveRequestID(httptest.NewRequest(http.MethodGet, "/", nil), func(w http.ResponseWriter, r *http.Request) {
+		ctxRequestID = RequestIDFromContext(r.Context())
+		ctxTrace, _ = TraceContextFromContext(r.Context())
+		fields = logx.Fields(r.Context())
+	})
+
+	assert.NotEmpty(t, ctxRequestID)
+	assert.Equal(t, ctxRequestID, w.Header().Get(HeaderKeyRequestID))
+
+	assert.Empty(t, ctxTrace.ParentID)
+	assert.Equal(t, "00", ctxTrace.Flags)
+	assert.Regexp(t, traceparentRegexp, w.Header().Get(HeaderKeyTraceparent))
+	assert.Equal(t, ctxTrace.Traceparent(), w.Header().Get(HeaderKeyTraceparent))
+
+	assert.Equal(t, []zap.Field{logx.RequestID(ctxRequestID), logx.TraceID(ctxTrace.TraceID)}, fields)
+}
+
+func TestRequestIDMiddleware_Accepted(t *testing.T) {
+	incoming := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderKeyRequestID, "client-request-1")
+	r.Header.Set(HeaderKeyTraceparent, incoming)
+
+	var ctxTrace TraceContext
+	w := serveRequestID(r, func(w http.ResponseWriter, r *http.Request) {
+		ctxTrace, _ = TraceContextFromContext(r.Context())
+	})
+
+	assert.Equal(t, "client-request-1", w.Header().Get(HeaderKeyRequestID))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ctxTrace.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", ctxTrace.ParentID)
+	assert.Equal(t, "01", ctxTrace.Flags)
+	assert.NotEqual(t, ctxTrace.ParentID, ctxTrace.SpanID)
+
+	outgoing := w.Header().Get(HeaderKeyTraceparent)
+	assert.True(t, strings.HasPrefix(outgoing, "00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+	assert.NotEqual(t, incoming, outgoing)
+}
+
+func TestRequestIDMiddleware_InvalidRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "too long", id: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "space", id: "request id"},
+		{name: "non ascii", id: "réquest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(HeaderKeyRequestID, tt.id)
+
+			w := serveRequestID(r, func(w http.ResponseWriter, r *http.Request) {})
+
+			assert.NotEqual(t, tt.id, w.Header().Get(HeaderKeyRequestID))
+			assert.NotEmpty(t, w.Header().Get(HeaderKeyRequestID))
+		})
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{name: "valid", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: true},
+		{name: "future version with extra fields", header: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", ok: true},
+		{name: "version 00 with extra fields", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", ok: false},
+		{name: "invalid version", header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: false},
+		{name: "zero trace id", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ok: false},
+		{name: "zero parent id", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", ok: false},
+		{name: "upper case", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ok: false},
+		{name: "short trace id", header: "00-4bf92f35-00f067aa0ba902b7-01", ok: false},
+		{name: "empty", header: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := parseTraceparent(tt.header)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestErrorJsonResponse_RequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+	}{
+		{name: "legacy"},
+		{name: "problem", accept: contentTypeProblemJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(headerKeyAccept, tt.accept)
+
+			w := serveRequestID(r, func(w http.ResponseWriter, r *http.Request) {
+				NotFoundResponse(w, r)
+			})
+
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, w.Header().Get(HeaderKeyRequestID), body["requestId"])
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderKeyStrictTransportSecurity = "Strict-Transport-Security"
+	HeaderKeyContentTypeOptions      = "X-Content-Type-Options"
+	HeaderKeyReferrerPolicy          = "Referrer-Policy"
+	HeaderKeyContentSecurityPolicy   = "Content-Security-Policy"
+	HeaderKeyFrameOptions            = "X-Frame-Options"
+)
+
+// SecurityHeaders set on every response by SecurityHeadersMiddleware, empty values are not set.
+// X-Content-Type-Options is always set to nosniff.
+type SecurityHeaders struct {
+	// HSTSMaxAge Strict-Transport-Security max-age, not set when zero.
+	// Browsers ignore it over plain HTTP, it is set regardless as TLS is usually terminated by a proxy.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+	FrameOptions          string
+}
+
+// APISecurityHeaders headers of JSON APIs, which never render in or embed documents.
+func APISecurityHeaders(hstsMaxAge time.Duration, hstsIncludeSubdomains bool) SecurityHeaders {
+	return SecurityHeaders{
+		HSTSMaxAge:            hstsMaxAge,
+		HSTSIncludeSubdomains: hstsIncludeSubdomains,
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+		FrameOptions:          "DENY",
+	}
+}
+
+func SecurityHeadersMiddleware(headers SecurityHeaders) func(http.Handler) http.Handler {
+	hsts := ""
+	if headers.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(headers.HSTSMaxAge.Seconds()))
+		if headers.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set(HeaderKeyContentTypeOptions, "nosniff")
+			setIfNotEmpty(h, HeaderKeyStrictTransportSecurity, hsts)
+			setIfNotEmpty(h, HeaderKeyReferrerPolicy, headers.ReferrerPolicy)
+			setIfNotEmpty(h, HeaderKeyContentSecurityPolicy, headers.ContentSecurityPolicy)
+			setIfNotEmpty(h, HeaderKeyFrameOptions, headers.FrameOptions)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setIfNotEmpty(h http.Header, key, value string) {
+	if value != "" {
+		h.Set(key, value)
+	}
+}
@@ -0,0 +1,38 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	w := httptest.NewRecorder()
+	SecurityHeadersMiddleware(APISecurityHeaders(365*24*time.Hour, true))(http.NotFoundHandler()).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get(HeaderKeyStrictTransportSecurity))
+	assert.Equal(t, "nosniff", w.Header().Get(HeaderKeyContentTypeOptions))
+	assert.Equal(t, "no-referrer", w.Header().Get(HeaderKeyReferrerPolicy))
+	assert.Equal(t, "default-src 'none'; frame-ancestors 'none'", w.Header().Get(HeaderKeyContentSecurityPolicy))
+	assert.Equal(t, "DENY", w.Header().Get(HeaderKeyFrameOptions))
+}
+
+func TestSecurityHeadersMiddleware_Empty(t *testing.T) {
+	w := httptest.NewRecorder()
+	SecurityHeadersMiddleware(SecurityHeaders{})(http.NotFoundHandler()).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, "nosniff", w.Header().Get(HeaderKeyContentTypeOptions))
+	for _, key := range []string{
+		HeaderKeyStrictTransportSecurity,
+		HeaderKeyReferrerPolicy,
+		HeaderKeyContentSecurityPolicy,
+		HeaderKeyFrameOptions,
+	} {
+		assert.Empty(t, w.Header().Get(key), key)
+	}
+}
@@ -1,12 +1,174 @@
 package httpx
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 )
 
-const timeoutResponseBody = `{message: "request timeout"}`
+// HeaderKeyRequestTimeout remaining budget of a request in milliseconds, set by callers so that
+// downstream services give up once the caller has, see TimeoutMiddleware and PropagateTimeout.
+const HeaderKeyRequestTimeout = "X-Request-Timeout"
 
-func TimeoutHandler(h http.Handler, timeout time.Duration) http.Handler {
-	return http.TimeoutHandler(h, timeout, timeoutResponseBody)
+var ErrHandlerTimeout = errors.New("request timeout")
+
+type timeoutBudgetCtxKey struct{}
+
+// timeoutBudget shared by TimeoutMiddleware and RouteTimeout of a request.
+type timeoutBudget struct {
+	start time.Time
+	// base context of the request without a deadline, cancelled when the client disconnects
+	base context.Context
+	// callerTimeout budget of the caller from HeaderKeyRequestTimeout, zero when not set
+	callerTimeout time.Duration
+	// ctx with the effective deadline, replaced by RouteTimeout
+	ctx context.Context
+}
+
+func (b *timeoutBudget) effective(timeout time.Duration) time.Duration {
+	if b.callerTimeout > 0 && (timeout <= 0 || b.callerTimeout < timeout) {
+		return b.callerTimeout
+	}
+	return timeout
+}
+
+// TimeoutMiddleware sets a deadline of timeout on the request context, observed by handlers and
+// downstream calls such as database queries. Non positive timeouts set no deadline.
+// Callers may shorten the deadline with HeaderKeyRequestTimeout, never extend it.
+//
+// Handlers are not interrupted, unlike http.TimeoutHandler responses are neither buffered nor
+// hidden from http.Flusher. When the deadline passes before the handler writes a response,
+// ErrHandlerTimeout is rendered with errWriter, e.g. ErrorRegistry.WriteError, once the handler returns.
+// Handlers must therefore observe the request context, passing it to blocking calls or selecting on its Done,
+// a handler ignoring it holds the timeout response back until it returns.
+// Requests cancelled by the client are not rendered as timeouts, see StatusClientClosedRequest.
+func TimeoutMiddleware(
+	timeout time.Duration,
+	errWriter func(err error, w http.ResponseWriter, r *http.Request),
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			budget := &timeoutBudget{
+				start:         time.Now(),
+				base:          r.Context(),
+				callerTimeout: parseRequestTimeout(r.Header.Get(HeaderKeyRequestTimeout)),
+			}
+
+			ctx := context.WithValue(r.Context(), timeoutBudgetCtxKey{}, budget)
+			if effective := budget.effective(timeout); effective > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, budget.start.Add(effective))
+				defer cancel()
+			}
+			budget.ctx = ctx
+
+			interceptor := NewInterceptor(w, InterceptorHooks{})
+			next.ServeHTTP(interceptor.Wrap(), r.WithContext(ctx))
+
+			if !interceptor.WroteHeader() && errors.Is(budget.ctx.Err(), context.DeadlineExceeded) {
+				errWriter(ErrHandlerTimeout, w, r)
+			}
+		})
+	}
+}
+
+// RouteTimeout overrides the timeout of TimeoutMiddleware for a route, measured from the start of the request.
+// Unlike a nested context deadline, the override may extend the default timeout.
+// Without TimeoutMiddleware the deadline is set from now, and no timeout response is written.
+func RouteTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			budget, ok := r.Context().Value(timeoutBudgetCtxKey{}).(*timeoutBudget)
+			if !ok {
+				ctx, cancel := context.WithTimeout(r.Context(), timeout)
+				defer cancel()
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			// Values of the request are kept while the default deadline is dropped,
+			// cancellation by the client is propagated from the base context.
+			ctx, cancel := context.WithDeadline(
+				context.WithoutCancel(r.Context()),
+				budget.start.Add(budget.effective(timeout)),
+			)
+			defer cancel()
+			stop := context.AfterFunc(budget.base, cancel)
+			defer stop()
+
+			budget.ctx = ctx
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// NoTimeout removes the deadline of TimeoutMiddleware for a route, e.g. long-lived event streams,
+// including the caller budget of HeaderKeyRequestTimeout. Cancellation by the client is kept.
+func NoTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget, ok := r.Context().Value(timeoutBudgetCtxKey{}).(*timeoutBudget)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+		defer cancel()
+		stop := context.AfterFunc(budget.base, cancel)
+		defer stop()
+
+		budget.ctx = ctx
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RemainingBudget returns the time left before the deadline of ctx, false when ctx has no deadline.
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// PropagateTimeout sets HeaderKeyRequestTimeout on outgoing requests from the deadline of their context.
+// A nil base defaults to http.DefaultTransport.
+func PropagateTimeout(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		remaining, ok := RemainingBudget(r.Context())
+		if !ok {
+			return base.RoundTrip(r)
+		}
+		if remaining <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+		// RoundTrippers must not modify the request
+		r = r.Clone(r.Context())
+		// At least a millisecond, as zero is not a valid budget
+		r.Header.Set(HeaderKeyRequestTimeout, strconv.FormatInt(max(remaining.Milliseconds(), 1), 10))
+		return base.RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// parseRequestTimeout returns zero for missing or invalid values.
+func parseRequestTimeout(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
 }
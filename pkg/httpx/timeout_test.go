@@ -0,0 +1,231 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func serveWithTimeout(timeout time.Duration, handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	TimeoutMiddleware(timeout, NewErrorRegistry(zap.NewNop()).WriteError)(handler).ServeHTTP(w, r)
+	return w
+}
+
+func waitDeadline(w http.ResponseWriter, r *http.Request) {
+	<-r.Context().Done()
+}
+
+func TestTimeoutMiddleware_DeadlineExceeded(t *testing.T) {
+	w := serveWithTimeout(10*time.Millisecond, http.HandlerFunc(waitDeadline), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, contentTypeJSON, w.Header().Get(headerKeyContentType))
+	assert.JSONEq(t, `{"code":"timeout","message":"request timeout"}`, w.Body.String())
+}
+
+func TestTimeoutMiddleware_HandlerReturnsDeadlineError(t *testing.T) {
+	errRegistry := NewErrorRegistry(zap.NewNop())
+	handler := errRegistry.Handle(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		// e.g. database query cancelled by the deadline
+		return errors.Join(errors.New("query failed"), r.Context().Err())
+	})
+
+	w := serveWithTimeout(10*time.Millisecond, handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"code":"timeout","message":"request timeout"}`, w.Body.String())
+}
+
+func TestTimeoutMiddleware_ClientCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errRegistry := NewErrorRegistry(zap.NewNop())
+	handler := errRegistry.Handle(func(w http.ResponseWriter, r *http.Request) error {
+		cancel()
+		<-r.Context().Done()
+		return errors.Join(errors.New("query failed"), r.Context().Err())
+	})
+
+	w := serveWithTimeout(time.Minute, handler, httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+	assert.Equal(t, StatusClientClosedRequest, w.Code)
+	assert.JSONEq(t, `{"code":"client_closed_request","message":"client closed request"}`, w.Body.String())
+}
+
+func TestTimeoutMiddleware_WrittenBeforeDeadline(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		<-r.Context().Done()
+	})
+
+	w := serveWithTimeout(10*time.Millisecond, handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestTimeoutMiddleware_Flusher(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+		assert.True(t, ok)
+	})
+
+	serveWithTimeout(time.Second, handler, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestTimeoutMiddleware_Deadline(t *testing.T) {
+	tests := []struct {
+		name             string
+		timeout          time.Duration
+		requestTimeout   string
+		expectedDeadline bool
+		expectedBudget   time.Duration
+	}{
+		{name: "timeout", timeout: time.Second, expectedDeadline: true, expectedBudget: time.Second},
+		{name: "disabled", timeout: 0},
+		{name: "caller shortens", timeout: time.Second, requestTimeout: "200", expectedDeadline: true, expectedBudget: 200 * time.Millisecond},
+		{name: "caller cannot extend", timeout: time.Second, requestTimeout: "5000", expectedDeadline: true, expectedBudget: time.Second},
+		{name: "caller when disabled", timeout: 0, requestTimeout: "200", expectedDeadline: true, expectedBudget: 200 * time.Millisecond},
+		{name: "caller invalid", timeout: time.Second, requestTimeout: "-1", expectedDeadline: true, expectedBudget: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.requestTimeout != "" {
+				r.Header.Set(HeaderKeyRequestTimeout, tt.requestTimeout)
+			}
+			var remaining time.Duration
+			var ok bool
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				remaining, ok = RemainingBudget(r.Context())
+			})
+
+			serveWithTimeout(tt.timeout, handler, r)
+
+			assert.Equal(t, tt.expectedDeadline, ok)
+			if tt.expectedDeadline {
+				assert.InDelta(t, tt.expectedBudget, remaining, float64(50*time.Millisecond))
+			}
+		})
+	}
+}
+
+func TestRouteTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		timeout        time.Duration
+		routeTimeout   time.Duration
+		requestTimeout string
+		expectedBudget time.Duration
+	}{
+		{name: "extends", timeout: 100 * time.Millisecond, routeTimeout: time.Second, expectedBudget: time.Second},
+		{name: "shortens", timeout: time.Second, routeTimeout: 100 * time.Millisecond, expectedBudget: 100 * time.Millisecond},
+		{name: "bounded by caller", timeout: 100 * time.Millisecond, routeTimeout: time.Second, requestTimeout: "300", expectedBudget: 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.requestTimeout != "" {
+				r.Header.Set(HeaderKeyRequestTimeout, tt.requestTimeout)
+			}
+			var remaining time.Duration
+			handler := RouteTimeout(tt.routeTimeout)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				remaining, _ = RemainingBudget(r.Context())
+			}))
+
+			serveWithTimeout(tt.timeout, handler, r)
+
+			assert.InDelta(t, tt.expectedBudget, remaining, float64(50*time.Millisecond))
+		})
+	}
+}
+
+func TestRouteTimeout_DeadlineExceeded(t *testing.T) {
+	handler := RouteTimeout(10 * time.Millisecond)(http.HandlerFunc(waitDeadline))
+
+	w := serveWithTimeout(time.Minute, handler, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestRouteTimeout_ClientCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := RouteTimeout(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+		assert.ErrorIs(t, r.Context().Err(), context.Canceled)
+	}))
+
+	w := serveWithTimeout(time.Minute, handler, httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+	// Not a timeout, the client is gone
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestPropagateTimeout(t *testing.T) {
+	var received string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(HeaderKeyRequestTimeout)
+	}))
+	defer downstream.Close()
+	client := &http.Client{Transport: PropagateTimeout(nil)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(r)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.InDelta(t, time.Minute.Milliseconds(), int64(parseRequestTimeout(received)/time.Millisecond), 1000)
+	assert.Empty(t, r.Header.Get(HeaderKeyRequestTimeout))
+
+	r, err = http.NewRequest(http.MethodGet, downstream.URL, nil)
+	require.NoError(t, err)
+	resp, err = client.Do(r)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Empty(t, received)
+}
+
+func TestNoTimeout(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(HeaderKeyRequestTimeout, "10")
+	handler := NoTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := RemainingBudget(r.Context())
+		assert.False(t, ok)
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, r.Context().Err())
+	}))
+
+	w := serveWithTimeout(10*time.Millisecond, handler, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNoTimeout_ClientCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NoTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+		assert.ErrorIs(t, r.Context().Err(), context.Canceled)
+	}))
+
+	w := serveWithTimeout(time.Minute, handler, httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,106 @@
+package idempotency
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// AdminHandler inspects and purges stored idempotency records.
+// Records are scoped by tenant, keyFn must mirror how the middleware builds store keys.
+type AdminHandler struct {
+	logger *zap.Logger
+	store  Store
+	keyFn  AdminKeyFunc
+}
+
+func NewAdminHandler(logger *zap.Logger, store Store, keyFn AdminKeyFunc) *AdminHandler {
+	return &AdminHandler{logger: logger, store: store, keyFn: keyFn}
+}
+
+// AdminKeyFunc builds the store key of an admin request for key.
+type AdminKeyFunc func(r *http.Request, key string) string
+
+// TenantAdminKey mirrors TenantAndIdempotency.
+func TenantAdminKey(r *http.Request, key string) string {
+	return TenantKey(r.Header.Get(TenantIdHeaderKey), key)
+}
+
+// ScopeAdminKey mirrors PrincipalRouteScope, principal, method, route and path are read from query parameters.
+func ScopeAdminKey(r *http.Request, key string) string {
+	q := r.URL.Query()
+	return Scope{
+		Tenant:    r.Header.Get(TenantIdHeaderKey),
+		Principal: q.Get("principal"),
+		Method:    strings.ToUpper(q.Get("method")),
+		Route:     q.Get("route"),
+		Path:      q.Get("path"),
+	}.Key(key)
+}
+
+type AdminRecordResponse struct {
+	Key    string      `json:"key"`
+	State  string      `json:"state"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Get looks up a stored record by URL parameter "key" and tenant header.
+func (a *AdminHandler) Get(w http.ResponseWriter, r *http.Request) {
+	key, ok := a.storeKey(w, r)
+	if !ok {
+		return
+	}
+
+	cached, err := a.store.Get(r.Context(), key)
+	if err != nil {
+		logx.FromContext(r.Context(), a.logger).Error("failed to retrieve idempotency record", zap.Error(err), zap.String("key", key))
+		httpx.InternalServerErrorResponse("", w, r)
+		return
+	}
+	if cached == nil {
+		httpx.NotFoundResponse(w, r)
+		return
+	}
+
+	httpx.JsonResponse(http.StatusOK, AdminRecordResponse{
+		Key:    key,
+		State:  cached.State.String(),
+		Status: cached.Status,
+		Header: cached.Header,
+		Body:   string(cached.Body),
+	}, w)
+}
+
+// Delete purges a stored record by URL parameter "key" and tenant header,
+// subsequent requests with the same key are re-executed.
+func (a *AdminHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	key, ok := a.storeKey(w, r)
+	if !ok {
+		return
+	}
+
+	err := a.store.Delete(r.Context(), key)
+	if err != nil {
+		logx.FromContext(r.Context(), a.logger).Error("failed to delete idempotency record", zap.Error(err), zap.String("key", key))
+		httpx.InternalServerErrorResponse("", w, r)
+		return
+	}
+
+	logx.FromContext(r.Context(), a.logger).Info("deleted idempotency record", zap.String("key", key))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *AdminHandler) storeKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	key := chi.URLParam(r, "key")
+	if key == "" {
+		httpx.BadRequestResponse("missing idempotency key", nil, w, r)
+		return "", false
+	}
+	return a.keyFn(r, key), true
+}
@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newAdminTestRouter(store Store) http.Handler {
+	handler := NewAdminHandler(zap.NewNop(), store, TenantAdminKey)
+	router := chi.NewRouter()
+	router.Get("/{key}", handler.Get)
+	router.Delete("/{key}", handler.Delete)
+	return router
+}
+
+func TestAdminHandler_Get(t *testing.T) {
+	store := NewMemStore(DefaultLockConfig)
+	err := store.Set(context.Background(), "tenant-123::test-key", &Response{
+		State:  StateCompleted,
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   []byte(`{"message":"cached response"}`),
+	}, 0)
+	assert.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/test-key", nil)
+	request.Header.Set("X-Tenant-Id", "tenant-123")
+	recorder := httptest.NewRecorder()
+
+	newAdminTestRouter(store).ServeHTTP(recorder, request)
+
+	var result AdminRecordResponse
+	err = json.NewDecoder(recorder.Body).Decode(&result)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "tenant-123::test-key", result.Key)
+	assert.Equal(t, "completed", result.State)
+	assert.Equal(t, http.StatusCreated, result.Status)
+	assert.Equal(t, `{"message":"cached response"}`, result.Body)
+}
+
+func TestAdminHandler_Get_OtherTenant(t *testing.T) {
+	store := NewMemStore(DefaultLockConfig)
+	err := store.Set(context.Background(), "tenant-123::test-key", &Response{}, 0)
+	assert.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/test-key", nil)
+	request.Header.Set("X-Tenant-Id", "tenant-456")
+	recorder := httptest.NewRecorder()
+
+	newAdminTestRouter(store).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestAdminHandler_Delete(t *testing.T) {
+	store := NewMemStore(DefaultLockConfig)
+	err := store.Set(context.Background(), "tenant-123::test-key", &Response{}, 0)
+	assert.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodDelete, "/test-key", nil)
+	request.Header.Set("X-Tenant-Id", "tenant-123")
+	recorder := httptest.NewRecorder()
+
+	newAdminTestRouter(store).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	result, err := store.Get(context.Background(), "tenant-123::test-key")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestScopeAdminKey(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet,
+		"/test-key?principal=user-1&method=post&route=/user/{id}/profile&path=/user/123/profile", nil)
+	request.Header.Set("X-Tenant-Id", "tenant-123")
+
+	key := ScopeAdminKey(request, "test-key")
+
+	assert.Equal(t, "tenant-123::user-1::POST::/user/{id}/profile::/user/123/profile::test-key", key)
+}
@@ -3,3 +3,8 @@ package idempotency
 import "errors"
 
 var ErrInProgress = errors.New("key in progress")
+var ErrKeyRequired = errors.New("idempotency key is required")
+var ErrInvalidKey = errors.New("idempotency key is invalid")
+
+// ErrKeyMismatch returned when a key is reused with another request body, the stored response is not replayed.
+var ErrKeyMismatch = errors.New("idempotency key was used with another request")
@@ -0,0 +1,200 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Metadata keys are the lowercase HTTP headers.
+const (
+	MetadataKey         = "idempotency-key"
+	MetadataKeyReplayed = "idempotent-replayed"
+)
+
+// scopeMethodGRPC is the Scope.Method of gRPC calls, the route being the full method.
+const scopeMethodGRPC = "GRPC"
+
+// GRPCPrincipalFunc returns the authenticated principal of a gRPC call, empty if anonymous.
+type GRPCPrincipalFunc func(ctx context.Context) string
+
+// UnaryServerInterceptor applies the middleware to gRPC calls, with the policy of their full method in policies.
+// Methods not in policies are passed through. Keys are read from MetadataKey and scoped by the tenant resolved
+// into the context, see tenant.UnaryServerInterceptor, principal and full method.
+//
+// Responses are stored as serialized anypb.Any and replayed with the MetadataKeyReplayed header,
+// to calls of the same request message only, others are rejected with ErrKeyMismatch. Failed calls are stored
+// as StateFailed and re-executed on retry. Errors are returned as is, e.g. ErrKeyRequired or ErrInProgress,
+// to be mapped by grpcx.ErrorRegistry.
+func (m *Middleware) UnaryServerInterceptor(
+	policies map[string]Policy, principal GRPCPrincipalFunc,
+) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		policy, ok := policies[info.FullMethod]
+		if !ok || policy == PolicyDisabled {
+			return handler(ctx, req)
+		}
+
+		logger := logx.FromContext(ctx, m.logger)
+
+		rawKey := grpcx.MetadataValue(ctx, MetadataKey)
+		if rawKey == "" {
+			if policy == PolicyRequired {
+				logger.Warn("idempotency key is required", zap.String("method", info.FullMethod))
+				return nil, ErrKeyRequired
+			}
+			return handler(ctx, req)
+		}
+
+		err := m.config.keyValidator(rawKey)
+		if err != nil {
+			logger.Warn("invalid idempotency key", zap.Error(err))
+			return nil, err
+		}
+
+		scope := Scope{
+			Method: scopeMethodGRPC,
+			Route:  info.FullMethod,
+		}
+		if id, ok := tenant.FromContext(ctx); ok {
+			scope.Tenant = id.String()
+		}
+		if principal != nil {
+			scope.Principal = principal(ctx)
+		}
+		key := scope.Key(rawKey)
+		fingerprint := fingerprintMessage(req)
+
+		lockStart := time.Now()
+		err = m.store.Lock(ctx, key, m.config.lockOptions...)
+		m.config.metrics.RecordIdempotencyLockWait(time.Since(lockStart))
+		if err != nil {
+			if errors.Is(err, ErrInProgress) {
+				logger.Warn("idempotent request in progress", zap.String("key", key))
+				m.config.metrics.RecordIdempotencyLockConflict()
+				return nil, err
+			}
+			logger.Error("idempotent lock error", zap.Error(err), zap.String("key", key))
+			m.config.metrics.RecordIdempotencyStoreError(opLock)
+			return nil, err
+		}
+
+		defer func() {
+			unlockCtx, cancel := storeWriteContext(ctx)
+			defer cancel()
+			err := m.store.Unlock(unlockCtx, key)
+			if err != nil {
+				logger.Error("failed to unlock idempotent key", zap.Error(err), zap.String("key", key))
+				m.config.metrics.RecordIdempotencyStoreError(opUnlock)
+			}
+		}()
+
+		cached, err := m.store.Get(ctx, key)
+		if err != nil {
+			logger.Error("failed to retrieve cache response", zap.Error(err), zap.String("key", key))
+			m.config.metrics.RecordIdempotencyStoreError(opGet)
+			return nil, err
+		}
+		if cached != nil && cached.State == StateCompleted {
+			if cached.Fingerprint != "" && cached.Fingerprint != fingerprint {
+				logger.Warn("idempotency key reused with another request", zap.String("key", key))
+				return nil, ErrKeyMismatch
+			}
+			resp, err := unmarshalResponse(cached.Body)
+			if err == nil {
+				m.config.metrics.RecordIdempotencyCache(true)
+				// Fails only once headers are sent, which no handler has done yet
+				_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataKeyReplayed, "true"))
+				return resp, nil
+			}
+			// e.g. response type no longer registered after a deployment, the call is re-executed
+			logger.Warn("failed to unmarshal cached response", zap.Error(err), zap.String("key", key))
+		}
+		m.config.metrics.RecordIdempotencyCache(false)
+
+		ctx = WithValue(ctx, key)
+		resp, handlerErr := handler(ctx, req)
+
+		stored := m.buildGRPCResponse(ctx, resp, handlerErr)
+		stored.Fingerprint = fingerprint
+		setCtx, cancel := storeWriteContext(ctx)
+		defer cancel()
+		err = m.store.Set(setCtx, key, stored, m.config.cacheExpiry)
+		if err != nil {
+			logger.Error("failed to store response", zap.Error(err), zap.String("key", key))
+			m.config.metrics.RecordIdempotencyStoreError(opSet)
+		}
+		return resp, handlerErr
+	}
+}
+
+func (m *Middleware) buildGRPCResponse(ctx context.Context, resp any, handlerErr error) *Response {
+	reason := ""
+	var body []byte
+	switch {
+	case handlerErr != nil:
+		reason = "call failed"
+	case ctx.Err() != nil:
+		reason = "context done"
+	default:
+		var err error
+		body, err = marshalResponse(resp)
+		if err != nil {
+			reason = "response not serializable"
+		} else if m.config.maxBodySize > 0 && len(body) > m.config.maxBodySize {
+			reason = "body exceeds max size"
+		}
+	}
+
+	if reason != "" {
+		logx.FromContext(ctx, m.logger).Debug("idempotent response not cacheable", zap.String("reason", reason))
+		return &Response{State: StateFailed}
+	}
+	return &Response{State: StateCompleted, Body: body}
+}
+
+var errNotProtoMessage = errors.New("response is not a proto message")
+
+// fingerprintMessage returns the hex encoded SHA-256 of the deterministic serialization of req,
+// empty when req is not a proto message, not checked on replay.
+func fingerprintMessage(req any) string {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return ""
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+	return hashBytes(b)
+}
+
+func marshalResponse(resp any) ([]byte, error) {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil, errNotProtoMessage
+	}
+	anyMsg, err := anypb.New(msg)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(anyMsg)
+}
+
+func unmarshalResponse(body []byte) (proto.Message, error) {
+	anyMsg := &anypb.Any{}
+	err := proto.Unmarshal(body, anyMsg)
+	if err != nil {
+		return nil, err
+	}
+	return anyMsg.UnmarshalNew()
+}
@@ -0,0 +1,170 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const testFullMethod = "/svc/Create"
+
+var testTenantID = uuid.MustParse("0b5f4f3e-8c1d-4a52-9a57-6f0a3b2d1c4e")
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: testFullMethod}
+	policies := map[string]Policy{testFullMethod: PolicyRequired}
+	principal := func(context.Context) string { return "user-1" }
+
+	incoming := func(pairs ...string) context.Context {
+		return metadata.NewIncomingContext(t.Context(), metadata.Pairs(pairs...))
+	}
+
+	t.Run("method without policy passed through", func(t *testing.T) {
+		m := NewMiddleware(zap.NewNop(), NewMemStore(DefaultLockConfig))
+		calls := 0
+		_, err := m.UnaryServerInterceptor(policies, principal)(t.Context(), nil,
+			&grpc.UnaryServerInfo{FullMethod: "/svc/Get"}, func(context.Context, any) (any, error) {
+				calls++
+				return wrapperspb.String("ok"), nil
+			})
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("key required", func(t *testing.T) {
+		m := NewMiddleware(zap.NewNop(), NewMemStore(DefaultLockConfig))
+		_, err := m.UnaryServerInterceptor(policies, principal)(t.Context(), nil, info,
+			func(context.Context, any) (any, error) {
+				t.Fatal("handler must not be called")
+				return nil, nil
+			})
+		assert.ErrorIs(t, err, ErrKeyRequired)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		m := NewMiddleware(zap.NewNop(), NewMemStore(DefaultLockConfig))
+		_, err := m.UnaryServerInterceptor(policies, principal)(incoming(MetadataKey, "bad key"), nil, info,
+			func(context.Context, any) (any, error) {
+				t.Fatal("handler must not be called")
+				return nil, nil
+			})
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("response replayed", func(t *testing.T) {
+		store := NewMemStore(DefaultLockConfig)
+		m := NewMiddleware(zap.NewNop(), store)
+		interceptor := m.UnaryServerInterceptor(policies, principal)
+
+		calls := 0
+		handler := func(ctx context.Context, _ any) (any, error) {
+			calls++
+			assert.NotEmpty(t, FromContext(ctx))
+			return wrapperspb.String("created"), nil
+		}
+
+		ctx := tenant.WithID(incoming(MetadataKey, "key-1"), testTenantID)
+		req := wrapperspb.String("request")
+		first, err := interceptor(ctx, req, info, handler)
+		require.NoError(t, err)
+		second, err := interceptor(ctx, wrapperspb.String("request"), info, handler)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+		assert.True(t, proto.Equal(first.(proto.Message), second.(proto.Message)))
+
+		key := Scope{Tenant: testTenantID.String(), Principal: "user-1", Method: scopeMethodGRPC, Route: testFullMethod}.
+			Key("key-1")
+		stored, err := store.Get(t.Context(), key)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.Equal(t, StateCompleted, stored.State)
+	})
+
+	t.Run("key reused with another request", func(t *testing.T) {
+		m := NewMiddleware(zap.NewNop(), NewMemStore(DefaultLockConfig))
+		interceptor := m.UnaryServerInterceptor(policies, principal)
+
+		calls := 0
+		handler := func(context.Context, any) (any, error) {
+			calls++
+			return wrapperspb.String("created"), nil
+		}
+
+		ctx := incoming(MetadataKey, "key-1")
+		_, err := interceptor(ctx, wrapperspb.String("a"), info, handler)
+		require.NoError(t, err)
+		_, err = interceptor(ctx, wrapperspb.String("b"), info, handler)
+
+		assert.ErrorIs(t, err, ErrKeyMismatch)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("keys scoped by resolved tenant", func(t *testing.T) {
+		m := NewMiddleware(zap.NewNop(), NewMemStore(DefaultLockConfig))
+		interceptor := m.UnaryServerInterceptor(policies, principal)
+
+		calls := 0
+		handler := func(context.Context, any) (any, error) {
+			calls++
+			return wrapperspb.String("created"), nil
+		}
+
+		req := wrapperspb.String("request")
+		_, err := interceptor(tenant.WithID(incoming(MetadataKey, "key-1"), testTenantID), req, info, handler)
+		require.NoError(t, err)
+		_, err = interceptor(tenant.WithID(incoming(MetadataKey, "key-1"), uuid.New()), req, info, handler)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("failed call re-executed", func(t *testing.T) {
+		m := NewMiddleware(zap.NewNop(), NewMemStore(DefaultLockConfig))
+		interceptor := m.UnaryServerInterceptor(policies, principal)
+
+		errFailed := errors.New("failed")
+		calls := 0
+		handler := func(context.Context, any) (any, error) {
+			calls++
+			if calls == 1 {
+				return nil, errFailed
+			}
+			return wrapperspb.String("created"), nil
+		}
+
+		ctx := incoming(MetadataKey, "key-1")
+		_, err := interceptor(ctx, nil, info, handler)
+		assert.ErrorIs(t, err, errFailed)
+		resp, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, "created", resp.(*wrapperspb.StringValue).GetValue())
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		store := NewMemStore(DefaultLockConfig)
+		m := NewMiddleware(zap.NewNop(), store)
+
+		key := Scope{Principal: "user-1", Method: scopeMethodGRPC, Route: testFullMethod}.Key("key-1")
+		require.NoError(t, store.Lock(t.Context(), key))
+
+		_, err := m.UnaryServerInterceptor(policies, principal)(incoming(MetadataKey, "key-1"), nil, info,
+			func(context.Context, any) (any, error) {
+				t.Fatal("handler must not be called")
+				return nil, nil
+			})
+		assert.ErrorIs(t, err, ErrInProgress)
+	})
+}
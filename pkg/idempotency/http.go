@@ -1,15 +1,23 @@
 package idempotency
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/logx"
 	"go.uber.org/zap"
 )
 
 const DefaultHeaderKey = "Idempotency-Key"
 const TenantIdHeaderKey = "X-Tenant-Id"
+const ReplayedHeaderKey = "Idempotent-Replayed"
 const keySeparator = "::"
 
 type Middleware struct {
@@ -36,75 +44,176 @@ func NewMiddleware(
 	}
 }
 
+// Handler applies the middleware with the configured default policy.
 func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return m.Policy(m.config.policy)(next)
+}
+
+// Policy applies the middleware with the given policy, allowing it to be configured per route.
+//
+//	router.With(m.Policy(idempotency.PolicyRequired)).Post("/resource", handler)
+func (m *Middleware) Policy(policy Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy == PolicyDisabled {
+			return next
+		}
+		return m.handler(policy, next)
+	}
+}
+
+func (m *Middleware) handler(policy Policy, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		key := m.config.extractor(r)
-		if key == "" || r.Method == http.MethodGet {
+		if r.Method == http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logger := logx.FromContext(r.Context(), m.logger)
+
+		rawKey := m.config.extractor(r)
+		if rawKey == "" {
+			if policy == PolicyRequired {
+				logger.Warn("idempotency key is required", zap.String("path", r.URL.Path))
+				m.config.errRespWriter(ErrKeyRequired, w, r)
+				return
+			}
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		err := m.store.Lock(r.Context(), key, m.config.lockOptions...)
+		err := m.config.keyValidator(rawKey)
+		if err != nil {
+			logger.Warn("invalid idempotency key", zap.Error(err))
+			m.config.errRespWriter(err, w, r)
+			return
+		}
+
+		key := rawKey
+		if m.config.scope != nil {
+			key = m.config.scope(r).Key(rawKey)
+		}
+
+		fingerprint := fingerprintBody(r)
+
+		lockStart := time.Now()
+		err = m.store.Lock(r.Context(), key, m.config.lockOptions...)
+		m.config.metrics.RecordIdempotencyLockWait(time.Since(lockStart))
 		if err != nil {
 			if errors.Is(err, ErrInProgress) {
-				m.logger.Warn("idempotent request in progress", zap.String("key", key))
-				m.config.errRespWriter(err, w)
+				logger.Warn("idempotent request in progress", zap.String("key", key))
+				m.config.metrics.RecordIdempotencyLockConflict()
+				m.config.errRespWriter(err, w, r)
 				return
 			}
-			m.logger.Error("idempotent lock error", zap.Error(err), zap.String("key", key))
-			m.config.errRespWriter(err, w)
+			logger.Error("idempotent lock error", zap.Error(err), zap.String("key", key))
+			m.config.metrics.RecordIdempotencyStoreError(opLock)
+			m.config.errRespWriter(err, w, r)
 			return
 		}
 
 		defer func(store Store, ctx context.Context, key string) {
+			ctx, cancel := storeWriteContext(ctx)
+			defer cancel()
 			err := store.Unlock(ctx, key)
 			if err != nil {
-				m.logger.Error("failed to unlock idempotent key",
+				logger.Error("failed to unlock idempotent key",
 					zap.Error(err), zap.String("key", key))
+				m.config.metrics.RecordIdempotencyStoreError(opUnlock)
 			}
 		}(m.store, r.Context(), key)
 
 		cached, err := m.store.Get(r.Context(), key)
 		if err != nil {
-			m.logger.Error("failed to retrieve cache response", zap.Error(err), zap.String("key", key))
-			m.config.errRespWriter(err, w)
+			logger.Error("failed to retrieve cache response", zap.Error(err), zap.String("key", key))
+			m.config.metrics.RecordIdempotencyStoreError(opGet)
+			m.config.errRespWriter(err, w, r)
 			return
 		}
-		if cached != nil {
+		if cached != nil && cached.State == StateCompleted {
+			// Records stored before fingerprints are replayed
+			if cached.Fingerprint != "" && cached.Fingerprint != fingerprint {
+				logger.Warn("idempotency key reused with another request", zap.String("key", key))
+				m.config.errRespWriter(ErrKeyMismatch, w, r)
+				return
+			}
+			m.config.metrics.RecordIdempotencyCache(true)
 			serveCachedResponse(cached, w)
 			return
 		}
+		m.config.metrics.RecordIdempotencyCache(false)
 
 		// run next with response copier
-		recorderWriter := newResponseRecorderWriter(w)
+		recorderWriter := newResponseRecorderWriter(w, m.config.maxBodySize)
 
 		// add idempotency key to request context and overwrite request
 		ctx := WithValue(r.Context(), key)
 		r = r.WithContext(ctx)
 
-		next.ServeHTTP(recorderWriter, r)
+		next.ServeHTTP(recorderWriter.writer(), r)
 
-		// cache response
-		err = m.store.Set(ctx, key,
-			&Response{
-				Status: recorderWriter.status,
-				Header: recorderWriter.cloneHeaders(),
-				Body:   recorderWriter.body.Bytes(),
-			},
-			m.config.cacheExpiry)
+		// cache response, failed responses are stored without body to be re-executed on retry
+		resp := m.buildResponse(ctx, recorderWriter)
+		resp.Fingerprint = fingerprint
+		setCtx, cancel := storeWriteContext(ctx)
+		defer cancel()
+		err = m.store.Set(setCtx, key, resp, m.config.cacheExpiry)
 		if err != nil {
-			m.logger.Error("failed to store response", zap.Error(err), zap.String("key", key))
+			logger.Error("failed to store response", zap.Error(err), zap.String("key", key))
+			m.config.metrics.RecordIdempotencyStoreError(opSet)
 		}
 	})
 }
 
-type ErrorResponseWriter func(err error, w http.ResponseWriter)
+func (m *Middleware) buildResponse(ctx context.Context, rec *responseRecorderWriter) *Response {
+	status := rec.statusCode()
+
+	reason := ""
+	switch {
+	case ctx.Err() != nil:
+		// request timed out or client disconnected, response may not have been fully written
+		reason = "context done"
+	case rec.hasWriteErr():
+		reason = "write error"
+	case rec.isStreaming():
+		reason = "streamed response"
+	case rec.isBodyTruncated:
+		reason = "body exceeds max size"
+	case !m.config.cacheableStatus(status):
+		reason = "status not cacheable"
+	}
 
-func DefaultErrorResponseWriter(err error, w http.ResponseWriter) {
+	if reason != "" {
+		logx.FromContext(ctx, m.logger).Debug("idempotent response not cacheable",
+			zap.String("reason", reason), zap.Int("status", status))
+		return &Response{
+			State:  StateFailed,
+			Status: status,
+		}
+	}
+
+	return &Response{
+		State:  StateCompleted,
+		Status: status,
+		Header: rec.handlerHeaders(),
+		Body:   rec.body.Bytes(),
+	}
+}
+
+type ErrorResponseWriter func(err error, w http.ResponseWriter, r *http.Request)
+
+func DefaultErrorResponseWriter(err error, w http.ResponseWriter, _ *http.Request) {
 	if errors.Is(err, ErrInProgress) {
 		http.Error(w, "request with the same idempotency key is already in progress", http.StatusConflict)
 		return
 	}
+	if errors.Is(err, ErrKeyRequired) || errors.Is(err, ErrInvalidKey) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, ErrKeyMismatch) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	http.Error(w, "internal server error", http.StatusInternalServerError)
 }
 
@@ -119,12 +228,19 @@ func TenantAndIdempotency(r *http.Request) string {
 	if key == "" {
 		return ""
 	}
-	tenantId := r.Header.Get(TenantIdHeaderKey)
+	return TenantKey(r.Header.Get(TenantIdHeaderKey), key)
+}
+
+// TenantKey builds the store key used by TenantAndIdempotency.
+func TenantKey(tenantId, key string) string {
 	return tenantId + keySeparator + key
 }
 
+// serveCachedResponse writes the headers set by the handler over the headers of the current request,
+// e.g. X-Request-Id, traceparent or CORS headers of outer middleware, which are kept.
 func serveCachedResponse(cached *Response, w http.ResponseWriter) {
-	clearAndCopyHeaders(w.Header(), cached.Header)
+	copyHeaders(w.Header(), cached.Header)
+	w.Header().Set(ReplayedHeaderKey, "true")
 	cStatus := cached.Status
 	if cStatus > 0 {
 		// Only if status is set write it, else default behaviour would result in 200
@@ -134,14 +250,47 @@ func serveCachedResponse(cached *Response, w http.ResponseWriter) {
 	_, _ = w.Write(cached.Body)
 }
 
-func clearAndCopyHeaders(dst, src http.Header) {
-	// ensure no prior headers leaks into response
-	for k := range dst {
-		dst.Del(k)
-	}
+func copyHeaders(dst, src http.Header) {
 	for k, vv := range src {
 		copyV := make([]string, len(vv))
 		copy(copyV, vv)
 		dst[k] = copyV
 	}
 }
+
+// maxFingerprintBytes of request bodies hashed, larger bodies are rejected by httpx.DecodeJSON and only
+// their prefix is fingerprinted, bounding the memory of requests buffered.
+const maxFingerprintBytes = httpx.DefaultMaxBodyBytes
+
+// fingerprintBody returns the hex encoded SHA-256 of the request body, which is replaced to be read again.
+// Read errors, e.g. a client disconnecting, are returned again when the handler reads the body.
+func fingerprintBody(r *http.Request) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return hashBytes(nil)
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxFingerprintBytes))
+	rest := r.Body
+	if err != nil {
+		rest = io.NopCloser(errReader{err: err})
+	}
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), rest), Closer: r.Body}
+	return hashBytes(body)
+}
+
+func hashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -3,15 +3,21 @@ package idempotency
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/test/faker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -250,7 +256,7 @@ func TestHandler_WithKey_LockFailed(t *testing.T) {
 				Return(tc.err)
 			// Expect error response to be written
 			mockErrWriter.On("WriteError",
-				mock.Anything, mock.Anything).
+				mock.Anything, mock.Anything, mock.Anything).
 				Return()
 
 			request := httptest.NewRequest(http.MethodPost, "/", nil)
@@ -305,7 +311,9 @@ func TestHandler_WithKey_CacheFound(t *testing.T) {
 	respWriter := httptest.NewRecorder()
 	request.Header.Set("Idempotency-Key", key)
 
-	respWriter.Header().Set("X-Fake-Header", "to-be-overwritten")
+	// Set by outer middleware for the current request, kept on replay
+	respWriter.Header().Set("X-Request-Id", "request-2")
+	respWriter.Header().Set("Content-Type", "text/plain")
 	middleware.Handler(mockHandler).
 		ServeHTTP(respWriter, request)
 
@@ -316,8 +324,9 @@ func TestHandler_WithKey_CacheFound(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, http.StatusCreated, result.StatusCode)
-	assert.Equal(t, "", result.Header.Get("X-Fake-Header"))
+	assert.Equal(t, "request-2", result.Header.Get("X-Request-Id"))
 	assert.Equal(t, "application/json", result.Header.Get("Content-Type"))
+	assert.Equal(t, "true", result.Header.Get("Idempotent-Replayed"))
 	assert.Equal(t, `{"message":"cached response"}`, buf.String())
 
 	mockHandler.AssertNumberOfCalls(t, "ServeHTTP", 0)
@@ -356,7 +365,7 @@ func TestHandler_WithKey_GetFailed(t *testing.T) {
 		Return(nil, errors.New("fake error"))
 	// Expect error response to be written
 	mockErrWriter.On("WriteError",
-		mock.Anything, mock.Anything).
+		mock.Anything, mock.Anything, mock.Anything).
 		Return()
 
 	request := httptest.NewRequest(http.MethodPost, "/", nil)
@@ -374,6 +383,520 @@ func TestHandler_WithKey_GetFailed(t *testing.T) {
 	mockErrWriter.AssertNumberOfCalls(t, "WriteError", 1)
 }
 
+func TestHandler_WithKey_FailedRecord_Rerun(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	key := "fake-key"
+
+	mockStore := &MockIdempotencyStore{}
+	mockErrWriter := &MockErrResponseWriter{}
+
+	middleware := NewMiddleware(logger, mockStore,
+		WithErrorResponseWriter(mockErrWriter.WriteError),
+	)
+
+	mockHandler := &faker.MockHandler{}
+	mockHandler.On("ServeHTTP", mock.Anything, mock.Anything).
+		Return()
+
+	mockStore.On("Lock",
+		mock.Anything, key, mock.Anything).
+		Return(nil)
+	mockStore.On("Unlock", mock.Anything, key).
+		Return(nil)
+	// Previous attempt failed
+	mockStore.On("Get", mock.Anything, key).
+		Return(&Response{
+			State:  StateFailed,
+			Status: http.StatusInternalServerError,
+		}, nil)
+	mockStore.On("Set",
+		mock.Anything, key, mock.Anything, mock.Anything).
+		Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	respWriter := httptest.NewRecorder()
+	request.Header.Set("Idempotency-Key", key)
+
+	middleware.Handler(mockHandler).
+		ServeHTTP(respWriter, request)
+
+	mockHandler.AssertNumberOfCalls(t, "ServeHTTP", 1)
+	mockStore.AssertNumberOfCalls(t, "Get", 1)
+	mockStore.AssertNumberOfCalls(t, "Set", 1)
+	mockErrWriter.AssertNumberOfCalls(t, "WriteError", 0)
+}
+
+func TestHandler_WithKey_Cacheability(t *testing.T) {
+	tt := []struct {
+		name          string
+		options       []Option
+		handlerFn     func(w http.ResponseWriter, r *http.Request)
+		expectedState State
+	}{
+		{
+			name: "created",
+			handlerFn: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{}`))
+			},
+			expectedState: StateCompleted,
+		},
+		{
+			name: "client error",
+			handlerFn: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			expectedState: StateCompleted,
+		},
+		{
+			name: "server error",
+			handlerFn: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expectedState: StateFailed,
+		},
+		{
+			name:    "status not in cacheable status codes",
+			options: []Option{WithCacheableStatusCodes(http.StatusCreated)},
+			handlerFn: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{}`))
+			},
+			expectedState: StateFailed,
+		},
+		{
+			name:    "body exceeds max size",
+			options: []Option{WithMaxBodySize(4)},
+			handlerFn: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"a":"b"}`))
+			},
+			expectedState: StateFailed,
+		},
+		{
+			name: "flushed",
+			handlerFn: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("data: 1\n\n"))
+				w.(http.Flusher).Flush()
+			},
+			expectedState: StateFailed,
+		},
+		{
+			name: "event stream",
+			handlerFn: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte("data: 1\n\n"))
+			},
+			expectedState: StateFailed,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			logger, err := zap.NewDevelopment()
+			assert.NoError(t, err)
+			key := "fake-key"
+
+			mockStore := &MockIdempotencyStore{}
+			mockErrWriter := &MockErrResponseWriter{}
+
+			options := append([]Option{WithErrorResponseWriter(mockErrWriter.WriteError)}, tc.options...)
+			middleware := NewMiddleware(logger, mockStore, options...)
+
+			mockStore.On("Lock",
+				mock.Anything, key, mock.Anything).
+				Return(nil)
+			mockStore.On("Unlock", mock.Anything, key).
+				Return(nil)
+			mockStore.On("Get", mock.Anything, key).
+				Return(nil, nil)
+			mockStore.On("Set",
+				mock.Anything, key, mock.Anything, mock.Anything).
+				Return(nil)
+
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			respWriter := httptest.NewRecorder()
+			request.Header.Set("Idempotency-Key", key)
+
+			middleware.Handler(http.HandlerFunc(tc.handlerFn)).
+				ServeHTTP(respWriter, request)
+
+			mockStore.AssertNumberOfCalls(t, "Set", 1)
+			stored := mockStore.storedResponse()
+			assert.Equal(t, tc.expectedState, stored.State)
+			if tc.expectedState == StateFailed {
+				assert.Nil(t, stored.Body)
+			}
+		})
+	}
+}
+
+func TestHandler_WithKey_ContextDone(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	key := "fake-key"
+
+	mockStore := &MockIdempotencyStore{}
+	mockErrWriter := &MockErrResponseWriter{}
+
+	middleware := NewMiddleware(logger, mockStore,
+		WithErrorResponseWriter(mockErrWriter.WriteError),
+	)
+
+	mockStore.On("Lock",
+		mock.Anything, key, mock.Anything).
+		Return(nil)
+	mockStore.On("Unlock", mock.Anything, key).
+		Return(nil)
+	mockStore.On("Get", mock.Anything, key).
+		Return(nil, nil)
+	mockStore.On("Set",
+		mock.Anything, key, mock.Anything, mock.Anything).
+		Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	respWriter := httptest.NewRecorder()
+	request.Header.Set("Idempotency-Key", key)
+
+	// Simulates timeout handler cancelling the context while handler is running
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	middleware.Handler(handler).
+		ServeHTTP(respWriter, request)
+
+	stored := mockStore.storedResponse()
+	assert.Equal(t, StateFailed, stored.State)
+	assert.Equal(t, http.StatusCreated, stored.Status)
+}
+
+func TestHandler_WithKey_ContextCancelled_StoreWritten(t *testing.T) {
+	key := "fake-key"
+
+	mockStore := &MockIdempotencyStore{}
+	middleware := NewMiddleware(zap.NewNop(), mockStore)
+
+	var setCtxErr, unlockCtxErr error
+	var setHasDeadline bool
+	mockStore.On("Lock", mock.Anything, key, mock.Anything).
+		Return(nil)
+	mockStore.On("Get", mock.Anything, key).
+		Return(nil, nil)
+	mockStore.On("Set", mock.Anything, key, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			setCtxErr = ctx.Err()
+			_, setHasDeadline = ctx.Deadline()
+		}).
+		Return(nil)
+	mockStore.On("Unlock", mock.Anything, key).
+		Run(func(args mock.Arguments) {
+			unlockCtxErr = args.Get(0).(context.Context).Err()
+		}).
+		Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request := httptest.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	request.Header.Set("Idempotency-Key", key)
+
+	// Client disconnects before the response is stored
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	middleware.Handler(handler).
+		ServeHTTP(httptest.NewRecorder(), request)
+
+	mockStore.AssertNumberOfCalls(t, "Set", 1)
+	assert.NoError(t, setCtxErr, "Set with the cancelled request context")
+	assert.True(t, setHasDeadline, "Set without a timeout")
+	assert.NoError(t, unlockCtxErr, "Unlock with the cancelled request context")
+	assert.Equal(t, StateFailed, mockStore.storedResponse().State)
+}
+
+func TestHandler_WithKey_Metrics(t *testing.T) {
+	tt := []struct {
+		name       string
+		lockErr    error
+		cached     *Response
+		getErr     error
+		setErr     error
+		assertions func(t *testing.T, metrics *MockMetricsRecorder)
+	}{
+		{
+			name:   "cache miss",
+			cached: nil,
+			assertions: func(t *testing.T, metrics *MockMetricsRecorder) {
+				metrics.AssertCalled(t, "RecordIdempotencyCache", false)
+				metrics.AssertNotCalled(t, "RecordIdempotencyStoreError", mock.Anything)
+			},
+		},
+		{
+			name:   "cache hit",
+			cached: &Response{State: StateCompleted, Status: http.StatusCreated},
+			assertions: func(t *testing.T, metrics *MockMetricsRecorder) {
+				metrics.AssertCalled(t, "RecordIdempotencyCache", true)
+			},
+		},
+		{
+			name:    "lock conflict",
+			lockErr: ErrInProgress,
+			assertions: func(t *testing.T, metrics *MockMetricsRecorder) {
+				metrics.AssertNumberOfCalls(t, "RecordIdempotencyLockConflict", 1)
+				metrics.AssertNotCalled(t, "RecordIdempotencyCache", mock.Anything)
+			},
+		},
+		{
+			name:    "lock error",
+			lockErr: errors.New("fake error"),
+			assertions: func(t *testing.T, metrics *MockMetricsRecorder) {
+				metrics.AssertCalled(t, "RecordIdempotencyStoreError", opLock)
+				metrics.AssertNotCalled(t, "RecordIdempotencyLockConflict")
+			},
+		},
+		{
+			name:   "get error",
+			getErr: errors.New("fake error"),
+			assertions: func(t *testing.T, metrics *MockMetricsRecorder) {
+				metrics.AssertCalled(t, "RecordIdempotencyStoreError", opGet)
+			},
+		},
+		{
+			name:   "set error",
+			setErr: errors.New("fake error"),
+			assertions: func(t *testing.T, metrics *MockMetricsRecorder) {
+				metrics.AssertCalled(t, "RecordIdempotencyStoreError", opSet)
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			logger, err := zap.NewDevelopment()
+			assert.NoError(t, err)
+			key := "fake-key"
+
+			mockStore := &MockIdempotencyStore{}
+			mockErrWriter := &MockErrResponseWriter{}
+			mockMetrics := &MockMetricsRecorder{}
+			mockMetrics.On("RecordIdempotencyLockWait", mock.Anything).Return()
+			mockMetrics.On("RecordIdempotencyLockConflict").Return()
+			mockMetrics.On("RecordIdempotencyCache", mock.Anything).Return()
+			mockMetrics.On("RecordIdempotencyStoreError", mock.Anything).Return()
+
+			middleware := NewMiddleware(logger, mockStore,
+				WithErrorResponseWriter(mockErrWriter.WriteError),
+				WithMetrics(mockMetrics),
+			)
+
+			mockStore.On("Lock",
+				mock.Anything, key, mock.Anything).
+				Return(tc.lockErr)
+			mockStore.On("Unlock", mock.Anything, key).
+				Return(nil)
+			mockStore.On("Get", mock.Anything, key).
+				Return(tc.cached, tc.getErr)
+			mockStore.On("Set",
+				mock.Anything, key, mock.Anything, mock.Anything).
+				Return(tc.setErr)
+			mockErrWriter.On("WriteError",
+				mock.Anything, mock.Anything, mock.Anything).
+				Return()
+
+			request := httptest.NewRequest(http.MethodPost, "/", nil)
+			respWriter := httptest.NewRecorder()
+			request.Header.Set("Idempotency-Key", key)
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			middleware.Handler(handler).
+				ServeHTTP(respWriter, request)
+
+			mockMetrics.AssertNumberOfCalls(t, "RecordIdempotencyLockWait", 1)
+			tc.assertions(t, mockMetrics)
+		})
+	}
+}
+
+func TestPolicy_Required_NoKey(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	mockStore := &MockIdempotencyStore{}
+	mockErrWriter := &MockErrResponseWriter{}
+	mockErrWriter.On("WriteError", ErrKeyRequired, mock.Anything, mock.Anything).
+		Return()
+
+	middleware := NewMiddleware(logger, mockStore,
+		WithErrorResponseWriter(mockErrWriter.WriteError),
+	)
+
+	mockHandler := &faker.MockHandler{}
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	respWriter := httptest.NewRecorder()
+
+	middleware.Policy(PolicyRequired)(mockHandler).
+		ServeHTTP(respWriter, request)
+
+	mockHandler.AssertNumberOfCalls(t, "ServeHTTP", 0)
+	mockStore.AssertNumberOfCalls(t, "Lock", 0)
+	mockErrWriter.AssertNumberOfCalls(t, "WriteError", 1)
+}
+
+func TestPolicy_Disabled(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	mockStore := &MockIdempotencyStore{}
+	mockErrWriter := &MockErrResponseWriter{}
+
+	middleware := NewMiddleware(logger, mockStore,
+		WithErrorResponseWriter(mockErrWriter.WriteError),
+		WithDefaultPolicy(PolicyDisabled),
+	)
+
+	mockHandler := &faker.MockHandler{}
+	mockHandler.On("ServeHTTP", mock.Anything, mock.Anything).
+		Return()
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.Header.Set("Idempotency-Key", "fake-key")
+	respWriter := httptest.NewRecorder()
+
+	middleware.Handler(mockHandler).
+		ServeHTTP(respWriter, request)
+
+	mockHandler.AssertNumberOfCalls(t, "ServeHTTP", 1)
+	mockStore.AssertNumberOfCalls(t, "Lock", 0)
+	mockErrWriter.AssertNumberOfCalls(t, "WriteError", 0)
+}
+
+func TestHandler_InvalidKey(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	mockStore := &MockIdempotencyStore{}
+	mockErrWriter := &MockErrResponseWriter{}
+	mockErrWriter.On("WriteError", mock.Anything, mock.Anything, mock.Anything).
+		Return()
+
+	middleware := NewMiddleware(logger, mockStore,
+		WithErrorResponseWriter(mockErrWriter.WriteError),
+	)
+
+	mockHandler := &faker.MockHandler{}
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.Header.Set("Idempotency-Key", "fake key")
+	respWriter := httptest.NewRecorder()
+
+	middleware.Handler(mockHandler).
+		ServeHTTP(respWriter, request)
+
+	mockHandler.AssertNumberOfCalls(t, "ServeHTTP", 0)
+	mockStore.AssertNumberOfCalls(t, "Lock", 0)
+	mockErrWriter.AssertNumberOfCalls(t, "WriteError", 1)
+	assert.ErrorIs(t, mockErrWriter.Calls[0].Arguments.Error(0), ErrInvalidKey)
+}
+
+func TestHandler_WithKeyScope(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	scopedKey := testTenantID.String() + "::user-1::POST::/::/::fake-key"
+
+	mockStore := &MockIdempotencyStore{}
+	mockErrWriter := &MockErrResponseWriter{}
+
+	middleware := NewMiddleware(logger, mockStore,
+		WithErrorResponseWriter(mockErrWriter.WriteError),
+		WithKeyScope(PrincipalRouteScope(func(r *http.Request) string {
+			return "user-1"
+		})),
+	)
+
+	mockHandler := &faker.MockHandler{}
+	mockHandler.On("ServeHTTP", mock.Anything, mock.Anything).
+		Return()
+
+	mockStore.On("Lock",
+		mock.Anything, scopedKey, mock.Anything).
+		Return(nil)
+	mockStore.On("Unlock", mock.Anything, scopedKey).
+		Return(nil)
+	mockStore.On("Get", mock.Anything, scopedKey).
+		Return(nil, nil)
+	mockStore.On("Set",
+		mock.Anything, scopedKey, mock.Anything, mock.Anything).
+		Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request = request.WithContext(tenant.WithID(request.Context(), testTenantID))
+	request.Header.Set("Idempotency-Key", "fake-key")
+	// Unverified, the resolved tenant scopes keys
+	request.Header.Set("X-Tenant-Id", "tenant-123")
+	respWriter := httptest.NewRecorder()
+
+	middleware.Handler(mockHandler).
+		ServeHTTP(respWriter, request)
+
+	mockHandler.AssertNumberOfCalls(t, "ServeHTTP", 1)
+	mockStore.AssertNumberOfCalls(t, "Lock", 1)
+	mockStore.AssertNumberOfCalls(t, "Set", 1)
+}
+
+func TestHandler_WithKey_Fingerprint(t *testing.T) {
+	store := NewMemStore(DefaultLockConfig)
+	middleware := NewMiddleware(zap.NewNop(), store)
+	var calls int
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		request.Header.Set("Idempotency-Key", "fake-key")
+		respWriter := httptest.NewRecorder()
+		handler.ServeHTTP(respWriter, request)
+		return respWriter
+	}
+
+	// Body is read again by the handler
+	first := serve(`{"name":"a"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, `{"name":"a"}`, first.Body.String())
+
+	replayed := serve(`{"name":"a"}`)
+	assert.Equal(t, "true", replayed.Header().Get(ReplayedHeaderKey))
+	assert.Equal(t, `{"name":"a"}`, replayed.Body.String())
+
+	mismatched := serve(`{"name":"b"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatched.Code)
+	assert.Empty(t, mismatched.Header().Get(ReplayedHeaderKey))
+	assert.Equal(t, 1, calls)
+}
+
+func TestFingerprintBody_Large(t *testing.T) {
+	body := strings.Repeat("a", int(maxFingerprintBytes)+10)
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	fingerprint := fingerprintBody(request)
+
+	assert.Equal(t, hashBytes([]byte(body[:maxFingerprintBytes])), fingerprint)
+	read, err := io.ReadAll(request.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(read))
+}
+
 func TestDefaultErrorResponseWriter(t *testing.T) {
 	tt := []struct {
 		name       string
@@ -387,6 +910,18 @@ func TestDefaultErrorResponseWriter(t *testing.T) {
 			errMessage: "request with the same idempotency key is already in progress\n",
 			statusCode: http.StatusConflict,
 		},
+		{
+			name:       "ErrKeyRequired",
+			err:        ErrKeyRequired,
+			errMessage: "idempotency key is required\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "ErrKeyMismatch",
+			err:        ErrKeyMismatch,
+			errMessage: "idempotency key was used with another request\n",
+			statusCode: http.StatusUnprocessableEntity,
+		},
 		{
 			name:       "other error",
 			err:        errors.New("unknown error"),
@@ -399,7 +934,7 @@ func TestDefaultErrorResponseWriter(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
-			DefaultErrorResponseWriter(tc.err, recorder)
+			DefaultErrorResponseWriter(tc.err, recorder, httptest.NewRequest(http.MethodPost, "/", nil))
 
 			result := recorder.Result()
 			defer result.Body.Close()
@@ -443,8 +978,8 @@ type MockErrResponseWriter struct {
 	mock.Mock
 }
 
-func (m *MockErrResponseWriter) WriteError(err error, w http.ResponseWriter) {
-	m.Called(err, w)
+func (m *MockErrResponseWriter) WriteError(err error, w http.ResponseWriter, r *http.Request) {
+	m.Called(err, w, r)
 }
 
 type MockIdempotencyStore struct {
@@ -469,9 +1004,118 @@ func (m *MockIdempotencyStore) Get(ctx context.Context, key string) (*Response,
 	return rArgs.Get(0).(*Response), rArgs.Error(1)
 }
 
+func (m *MockIdempotencyStore) Delete(ctx context.Context, key string) error {
+	rArgs := m.Called(ctx, key)
+	return rArgs.Error(0)
+}
+
+// storedResponse returns the response passed to the last Set call
+func (m *MockIdempotencyStore) storedResponse() *Response {
+	var resp *Response
+	for _, call := range m.Calls {
+		if call.Method == "Set" {
+			resp = call.Arguments.Get(2).(*Response)
+		}
+	}
+	return resp
+}
+
 func (m *MockIdempotencyStore) Set(
 	ctx context.Context, key string, resp *Response, expiryDuration time.Duration,
 ) error {
 	rArgs := m.Called(ctx, key, resp, expiryDuration)
 	return rArgs.Error(0)
 }
+
+type MockMetricsRecorder struct {
+	mock.Mock
+}
+
+func (m *MockMetricsRecorder) RecordIdempotencyLockWait(duration time.Duration) {
+	m.Called(duration)
+}
+
+func (m *MockMetricsRecorder) RecordIdempotencyLockConflict() {
+	m.Called()
+}
+
+func (m *MockMetricsRecorder) RecordIdempotencyCache(hit bool) {
+	m.Called(hit)
+}
+
+func (m *MockMetricsRecorder) RecordIdempotencyStoreError(operation string) {
+	m.Called(operation)
+}
+
+func TestHandler_WithKey_Compressed(t *testing.T) {
+	store := NewMemStore(DefaultLockConfig)
+	middleware := NewMiddleware(zap.NewNop(), store)
+	body := `{"data":"` + strings.Repeat("a", 2*httpx.DefaultCompressMinSize) + `"}`
+	key := "fake-key"
+
+	handler := httpx.CompressMiddleware()(middleware.Handler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			httpx.JsonResponse(http.StatusCreated, json.RawMessage(body), w)
+		},
+	)))
+
+	serve := func(acceptEncoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.Header.Set("Idempotency-Key", key)
+		request.Header.Set(httpx.HeaderKeyAcceptEncoding, acceptEncoding)
+		respWriter := httptest.NewRecorder()
+		handler.ServeHTTP(respWriter, request)
+		return respWriter
+	}
+
+	first := serve(httpx.EncodingGzip)
+	assert.Equal(t, httpx.EncodingGzip, first.Header().Get(httpx.HeaderKeyContentEncoding))
+
+	// Stored uncompressed, to be replayed in the encoding of each request
+	stored, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.JSONEq(t, body, string(stored.Body))
+	assert.Empty(t, stored.Header.Get(httpx.HeaderKeyContentEncoding))
+
+	replayed := serve("")
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, "true", replayed.Header().Get(ReplayedHeaderKey))
+	assert.Empty(t, replayed.Header().Get(httpx.HeaderKeyContentEncoding))
+	assert.Equal(t, []string{httpx.HeaderKeyAcceptEncoding}, replayed.Header().Values(httpx.HeaderKeyVary))
+	assert.JSONEq(t, body, replayed.Body.String())
+
+	replayed = serve(httpx.EncodingZstd)
+	assert.Equal(t, httpx.EncodingZstd, replayed.Header().Get(httpx.HeaderKeyContentEncoding))
+}
+
+func TestHandler_WithKey_ReplayKeepsRequestHeaders(t *testing.T) {
+	store := NewMemStore(DefaultLockConfig)
+	middleware := NewMiddleware(zap.NewNop(), store)
+	key := "fake-key"
+
+	handler := httpx.RequestIDMiddleware(middleware.Handler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Location", "/user/1/profile")
+			httpx.JsonResponse(http.StatusCreated, map[string]string{"id": "1"}, w)
+		},
+	)))
+
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.Header.Set("Idempotency-Key", key)
+		request.Header.Set(httpx.HeaderKeyRequestID, requestID)
+		respWriter := httptest.NewRecorder()
+		handler.ServeHTTP(respWriter, request)
+		return respWriter
+	}
+
+	serve("request-1")
+	stored, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Header.Get(httpx.HeaderKeyRequestID))
+
+	replayed := serve("request-2")
+	assert.Equal(t, "true", replayed.Header().Get(ReplayedHeaderKey))
+	assert.Equal(t, "request-2", replayed.Header().Get(httpx.HeaderKeyRequestID))
+	assert.Equal(t, "/user/1/profile", replayed.Header().Get("Location"))
+}
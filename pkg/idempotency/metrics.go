@@ -0,0 +1,26 @@
+package idempotency
+
+import "time"
+
+const (
+	opLock   = "lock"
+	opUnlock = "unlock"
+	opGet    = "get"
+	opSet    = "set"
+)
+
+// MetricsRecorder records idempotency middleware metrics.
+// Implemented by monitoring.Metrics.
+type MetricsRecorder interface {
+	RecordIdempotencyLockWait(duration time.Duration)
+	RecordIdempotencyLockConflict()
+	RecordIdempotencyCache(hit bool)
+	RecordIdempotencyStoreError(operation string)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) RecordIdempotencyLockWait(time.Duration) {}
+func (noopMetrics) RecordIdempotencyLockConflict()          {}
+func (noopMetrics) RecordIdempotencyCache(bool)             {}
+func (noopMetrics) RecordIdempotencyStoreError(string)      {}
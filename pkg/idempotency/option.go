@@ -1,20 +1,37 @@
 package idempotency
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
+
+const defaultMaxBodySize = 1 << 20 // 1 MiB
 
 type Config struct {
-	extractor     KeyExtractor
-	errRespWriter ErrorResponseWriter
-	lockOptions   []LockOption
-	cacheExpiry   time.Duration
+	extractor       KeyExtractor
+	errRespWriter   ErrorResponseWriter
+	lockOptions     []LockOption
+	cacheExpiry     time.Duration
+	cacheableStatus CacheableStatusFunc
+	maxBodySize     int
+	metrics         MetricsRecorder
+	policy          Policy
+	keyValidator    KeyValidator
+	scope           ScopeFunc
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		extractor:     DefaultKeyExtractor,
-		errRespWriter: DefaultErrorResponseWriter,
-		lockOptions:   []LockOption{},
-		cacheExpiry:   24 * time.Hour,
+		extractor:       DefaultKeyExtractor,
+		errRespWriter:   DefaultErrorResponseWriter,
+		lockOptions:     []LockOption{},
+		cacheExpiry:     24 * time.Hour,
+		cacheableStatus: DefaultCacheableStatus,
+		maxBodySize:     defaultMaxBodySize,
+		metrics:         noopMetrics{},
+		policy:          PolicyOptional,
+		keyValidator:    DefaultKeyValidator,
+		scope:           nil,
 	}
 }
 
@@ -43,3 +60,60 @@ func WithCacheExpiry(expiry time.Duration) Option {
 		c.cacheExpiry = expiry
 	}
 }
+
+// WithCacheableStatusCodes only caches responses with the given status codes.
+// Responses with other status codes are stored as StateFailed and re-executed on retry.
+func WithCacheableStatusCodes(codes ...int) Option {
+	allowed := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		allowed[code] = struct{}{}
+	}
+	return func(c *Config) {
+		c.cacheableStatus = func(status int) bool {
+			_, ok := allowed[status]
+			return ok
+		}
+	}
+}
+
+// WithMaxBodySize responses with body larger than size are not cached, 0 or less for no limit.
+func WithMaxBodySize(size int) Option {
+	return func(c *Config) {
+		c.maxBodySize = size
+	}
+}
+
+// WithMetrics records middleware metrics, e.g. monitoring.Metrics.
+func WithMetrics(metrics MetricsRecorder) Option {
+	return func(c *Config) {
+		c.metrics = metrics
+	}
+}
+
+// WithDefaultPolicy sets the policy used by Middleware.Handler.
+func WithDefaultPolicy(policy Policy) Option {
+	return func(c *Config) {
+		c.policy = policy
+	}
+}
+
+func WithKeyValidator(validator KeyValidator) Option {
+	return func(c *Config) {
+		c.keyValidator = validator
+	}
+}
+
+// WithKeyScope scopes extracted keys, see PrincipalRouteScope.
+func WithKeyScope(scope ScopeFunc) Option {
+	return func(c *Config) {
+		c.scope = scope
+	}
+}
+
+type CacheableStatusFunc func(status int) bool
+
+// DefaultCacheableStatus caches everything except server errors,
+// which are likely transient and should be re-executed on retry.
+func DefaultCacheableStatus(status int) bool {
+	return status < http.StatusInternalServerError
+}
@@ -23,7 +23,7 @@ func TestWithErrorResponseWriter(t *testing.T) {
 	config := &Config{}
 	assert.Nil(t, config.errRespWriter)
 
-	errRespWriter := func(err error, w http.ResponseWriter) {}
+	errRespWriter := func(err error, w http.ResponseWriter, r *http.Request) {}
 
 	WithErrorResponseWriter(errRespWriter)(config)
 
@@ -38,3 +38,48 @@ func TestWithCacheExpiry(t *testing.T) {
 
 	assert.Equal(t, 2*time.Hour, config.cacheExpiry)
 }
+
+func TestWithCacheableStatusCodes(t *testing.T) {
+	config := &Config{}
+	assert.Nil(t, config.cacheableStatus)
+
+	WithCacheableStatusCodes(http.StatusOK, http.StatusCreated)(config)
+
+	assert.True(t, config.cacheableStatus(http.StatusOK))
+	assert.True(t, config.cacheableStatus(http.StatusCreated))
+	assert.False(t, config.cacheableStatus(http.StatusBadRequest))
+}
+
+func TestWithMaxBodySize(t *testing.T) {
+	config := &Config{}
+	assert.Equal(t, 0, config.maxBodySize)
+
+	WithMaxBodySize(512)(config)
+
+	assert.Equal(t, 512, config.maxBodySize)
+}
+
+func TestDefaultCacheableStatus(t *testing.T) {
+	assert.True(t, DefaultCacheableStatus(http.StatusCreated))
+	assert.True(t, DefaultCacheableStatus(http.StatusConflict))
+	assert.False(t, DefaultCacheableStatus(http.StatusInternalServerError))
+	assert.False(t, DefaultCacheableStatus(http.StatusGatewayTimeout))
+}
+
+func TestWithDefaultPolicy(t *testing.T) {
+	config := DefaultConfig()
+	assert.Equal(t, PolicyOptional, config.policy)
+
+	WithDefaultPolicy(PolicyRequired)(config)
+
+	assert.Equal(t, PolicyRequired, config.policy)
+}
+
+func TestWithKeyScope(t *testing.T) {
+	config := DefaultConfig()
+	assert.Nil(t, config.scope)
+
+	WithKeyScope(PrincipalRouteScope(nil))(config)
+
+	assert.NotNil(t, config.scope)
+}
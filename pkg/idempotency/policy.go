@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"fmt"
+)
+
+// Policy determines how the middleware treats requests on a route.
+type Policy int
+
+const (
+	// PolicyOptional requests without a key are passed through.
+	PolicyOptional Policy = iota
+	// PolicyRequired requests without a key are rejected with ErrKeyRequired.
+	PolicyRequired
+	// PolicyDisabled middleware is not applied.
+	PolicyDisabled
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyOptional:
+		return "optional"
+	case PolicyRequired:
+		return "required"
+	case PolicyDisabled:
+		return "disabled"
+	default:
+		return "unknown"
+	}
+}
+
+const MaxKeyLength = 255
+
+type KeyValidator func(key string) error
+
+// DefaultKeyValidator accepts keys of up to MaxKeyLength visible ASCII characters, e.g. UUIDs.
+func DefaultKeyValidator(key string) error {
+	if len(key) > MaxKeyLength {
+		return fmt.Errorf("%w: exceeds %d characters", ErrInvalidKey, MaxKeyLength)
+	}
+	for _, c := range []byte(key) {
+		if c < '!' || c > '~' {
+			return fmt.Errorf("%w: contains invalid characters", ErrInvalidKey)
+		}
+	}
+	return nil
+}
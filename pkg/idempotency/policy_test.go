@@ -0,0 +1,34 @@
+package idempotency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultKeyValidator(t *testing.T) {
+	tt := []struct {
+		name    string
+		key     string
+		isValid bool
+	}{
+		{name: "uuid", key: "3f1c2a0e-6a2b-4f7b-9d52-7f0d6a8e9b41", isValid: true},
+		{name: "max length", key: strings.Repeat("a", MaxKeyLength), isValid: true},
+		{name: "exceeds max length", key: strings.Repeat("a", MaxKeyLength+1), isValid: false},
+		{name: "whitespace", key: "fake key", isValid: false},
+		{name: "control character", key: "fake\nkey", isValid: false},
+		{name: "non ascii", key: "fake-kéy", isValid: false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := DefaultKeyValidator(tc.key)
+			if tc.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidKey)
+			}
+		})
+	}
+}
@@ -2,48 +2,128 @@ package idempotency
 
 import (
 	"bytes"
+	"mime"
 	"net/http"
+	"slices"
+
+	"github.com/dyxj/bigbackend/pkg/httpx"
+)
+
+const contentTypeEventStream = "text/event-stream"
+
+// State of a stored idempotency record.
+type State int
+
+const (
+	// StateCompleted response is cacheable and is replayed on subsequent requests.
+	StateCompleted State = iota
+	// StateFailed response is not cacheable, subsequent requests re-executes the handler.
+	StateFailed
 )
 
+func (s State) String() string {
+	switch s {
+	case StateCompleted:
+		return "completed"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
 // Response a cache version of an HTTP response.ß
 type Response struct {
+	State  State
 	Status int
 	Header http.Header
 	Body   []byte
+	// Fingerprint of the request, responses are only replayed to requests of the same fingerprint
+	Fingerprint string
 }
 
+// responseRecorderWriter records the response body of an httpx.Interceptor.
 type responseRecorderWriter struct {
-	w                    http.ResponseWriter
-	status               int
-	body                 *bytes.Buffer
-	hasWroteStatusHeader bool
+	interceptor *httpx.Interceptor
+	body        *bytes.Buffer
+	// header snapshot taken when the status is written, excluding headers added by outer writers
+	// once the response is committed, e.g. Content-Encoding of compression middleware.
+	header http.Header
+	// baseline headers set before the next handler, e.g. X-Request-Id or RateLimit-* of outer middleware,
+	// specific to each request and never recorded.
+	baseline http.Header
+
+	// maxBodySize stops recording once exceeded, 0 or less for no limit
+	maxBodySize     int
+	isBodyTruncated bool
 }
 
-func newResponseRecorderWriter(w http.ResponseWriter) *responseRecorderWriter {
-	return &responseRecorderWriter{w: w, body: &bytes.Buffer{}}
+func newResponseRecorderWriter(w http.ResponseWriter, maxBodySize int) *responseRecorderWriter {
+	r := &responseRecorderWriter{body: &bytes.Buffer{}, baseline: cloneHeader(w.Header()), maxBodySize: maxBodySize}
+	r.interceptor = httpx.NewInterceptor(w, httpx.InterceptorHooks{
+		OnWriteHeader: r.snapshotHeader,
+		OnWrite:       r.record,
+	})
+	return r
 }
 
-func (r *responseRecorderWriter) Header() http.Header {
-	return r.w.Header()
+// writer to be passed to the next handler.
+func (r *responseRecorderWriter) writer() http.ResponseWriter {
+	return r.interceptor.Wrap()
 }
 
-func (r *responseRecorderWriter) Write(b []byte) (int, error) {
+func (r *responseRecorderWriter) record(b []byte) {
+	if r.isBodyTruncated {
+		return
+	}
+	if r.maxBodySize > 0 && r.body.Len()+len(b) > r.maxBodySize {
+		// Response would not be cached, release what has been recorded so far
+		r.isBodyTruncated = true
+		r.body = &bytes.Buffer{}
+		return
+	}
 	// bytes.Buffer.Write does not return an error, rather panics if buffer is too large
 	_, _ = r.body.Write(b)
-	return r.w.Write(b)
 }
 
-func (r *responseRecorderWriter) WriteHeader(statusCode int) {
-	// Ensure only a single write for status, as per net/http implementation
-	if !r.hasWroteStatusHeader {
-		r.hasWroteStatusHeader = true
-		r.status = statusCode
+func (r *responseRecorderWriter) statusCode() int {
+	return r.interceptor.Status()
+}
+
+// hasWriteErr e.g. client disconnected, client would not have received the full response
+func (r *responseRecorderWriter) hasWriteErr() bool {
+	return r.interceptor.WriteErr() != nil
+}
+
+// isStreaming flushed, hijacked and event stream responses are never cached.
+func (r *responseRecorderWriter) isStreaming() bool {
+	if r.interceptor.Flushed() || r.interceptor.Hijacked() {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(r.interceptor.Header().Get("Content-Type"))
+	return err == nil && mediaType == contentTypeEventStream
+}
+
+func (r *responseRecorderWriter) snapshotHeader(_ int) {
+	r.header = cloneHeader(r.interceptor.Header())
+}
+
+// handlerHeaders returns the headers set or changed by the next handler, as written with the status,
+// or as currently set when no status was written.
+func (r *responseRecorderWriter) handlerHeaders() http.Header {
+	header := r.header
+	if header == nil {
+		header = cloneHeader(r.interceptor.Header())
+	}
+	for k, vv := range header {
+		if slices.Equal(vv, r.baseline[k]) {
+			delete(header, k)
+		}
 	}
-	r.w.WriteHeader(statusCode)
+	return header
 }
 
-func (r *responseRecorderWriter) cloneHeaders() http.Header {
-	ori := r.w.Header()
+func cloneHeader(ori http.Header) http.Header {
 	clone := make(http.Header, len(ori))
 	for k, vv := range ori {
 		copyV := make([]string, len(vv))
@@ -2,10 +2,12 @@ package idempotency
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/dyxj/bigbackend/test/faker"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestResponseWriter_Header(t *testing.T) {
@@ -16,22 +18,24 @@ func TestResponseWriter_Header(t *testing.T) {
 			"X-Custom":     []string{"custom-value"},
 		})
 
-	writer := newResponseRecorderWriter(mockWriter)
+	writer := newResponseRecorderWriter(mockWriter, 0)
 
-	assert.Equal(t, "application/json", writer.Header().Get("Content-Type"))
-	assert.Equal(t, "custom-value", writer.Header().Get("X-Custom"))
-	mockWriter.AssertNumberOfCalls(t, "Header", 2)
+	assert.Equal(t, "application/json", writer.writer().Header().Get("Content-Type"))
+	assert.Equal(t, "custom-value", writer.writer().Header().Get("X-Custom"))
+	// Once more for the baseline
+	mockWriter.AssertNumberOfCalls(t, "Header", 3)
 }
 
 func TestResponseWriter_Write(t *testing.T) {
 	mockWriter := &faker.MockHTTPResponseWriter{}
 	testData := []byte(`{"message":"hello world"}`)
+	mockWriter.On("Header").Return(http.Header{})
 	mockWriter.On("Write", testData).
 		Return(len(testData), nil)
 
-	writer := newResponseRecorderWriter(mockWriter)
+	writer := newResponseRecorderWriter(mockWriter, 0)
 
-	n, err := writer.Write(testData)
+	n, err := writer.writer().Write(testData)
 
 	assert.NoError(t, err)
 	assert.Equal(t, len(testData), n)
@@ -42,39 +46,96 @@ func TestResponseWriter_Write(t *testing.T) {
 func TestResponseWriter_WriteHeader(t *testing.T) {
 	mockWriter := &faker.MockHTTPResponseWriter{}
 	statusCode := http.StatusCreated
+	mockWriter.On("Header").Return(http.Header{})
 	mockWriter.On("WriteHeader", statusCode).Return()
 	mockWriter.On("WriteHeader", http.StatusOK).Return()
 
-	writer := newResponseRecorderWriter(mockWriter)
+	writer := newResponseRecorderWriter(mockWriter, 0)
 
-	writer.WriteHeader(statusCode)
-	writer.WriteHeader(http.StatusOK) // This should not overwrite the first status
+	writer.writer().WriteHeader(statusCode)
+	writer.writer().WriteHeader(http.StatusOK) // This should not overwrite the first status
 
-	assert.Equal(t, statusCode, writer.status)
+	assert.Equal(t, statusCode, writer.statusCode())
 	mockWriter.AssertNumberOfCalls(t, "WriteHeader", 2)
 }
 
-func TestResponseWriter_CloneHeaders(t *testing.T) {
-	originalHeaders := http.Header{
+func TestResponseWriter_HandlerHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	// Set by outer middleware before the handler
+	rec.Header().Set("X-Request-Id", "request-1")
+	rec.Header().Set("X-Custom", "outer-value")
+
+	writer := newResponseRecorderWriter(rec, 0)
+	writer.writer().Header().Set("Content-Type", "application/json")
+	writer.writer().Header().Set("X-Custom", "custom-value")
+
+	handlerHeaders := writer.handlerHeaders()
+
+	assert.Equal(t, http.Header{
 		"Content-Type": []string{"application/json"},
 		"X-Custom":     []string{"custom-value"},
-	}
+	}, handlerHeaders)
+
+	// Modify handler headers and ensure original is unaffected
+	handlerHeaders.Set("X-Custom", "modified-value")
+
+	assert.Equal(t, "custom-value", rec.Header().Get("X-Custom"))
+	assert.Equal(t, "modified-value", handlerHeaders.Get("X-Custom"))
+}
 
+func TestResponseWriter_Write_ExceedsMaxBodySize(t *testing.T) {
 	mockWriter := &faker.MockHTTPResponseWriter{}
-	mockWriter.On("Header").
-		Return(originalHeaders)
+	mockWriter.On("Header").Return(http.Header{})
+	mockWriter.On("Write", mock.Anything).
+		Return(3, nil)
+
+	writer := newResponseRecorderWriter(mockWriter, 5)
 
-	writer := newResponseRecorderWriter(mockWriter)
+	_, err := writer.writer().Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.False(t, writer.isBodyTruncated)
 
-	clonedHeaders := writer.cloneHeaders()
+	_, err = writer.writer().Write([]byte("def"))
+	assert.NoError(t, err)
+	assert.True(t, writer.isBodyTruncated)
+	assert.Equal(t, 0, writer.body.Len())
+	mockWriter.AssertNumberOfCalls(t, "Write", 2)
+}
+
+func TestResponseWriter_Write_Error(t *testing.T) {
+	mockWriter := &faker.MockHTTPResponseWriter{}
+	mockWriter.On("Header").Return(http.Header{})
+	mockWriter.On("Write", mock.Anything).
+		Return(0, http.ErrHandlerTimeout)
+
+	writer := newResponseRecorderWriter(mockWriter, 0)
+
+	_, err := writer.writer().Write([]byte("abc"))
+
+	assert.ErrorIs(t, err, http.ErrHandlerTimeout)
+	assert.True(t, writer.hasWriteErr())
+}
+
+func TestResponseWriter_Flush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+
+	writer := newResponseRecorderWriter(recorder, 0)
+	writer.writer().(http.Flusher).Flush()
+
+	assert.True(t, writer.isStreaming())
+	assert.True(t, recorder.Flushed)
+}
 
-	assert.Equal(t, originalHeaders, clonedHeaders)
+func TestResponseWriter_HandlerHeaders_WrittenWithStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writer := newResponseRecorderWriter(rec, 0)
 
-	// Modify cloned headers and ensure original is unaffected
-	clonedHeaders.Set("X-Custom", "modified-value")
+	writer.writer().Header().Set("Content-Type", "application/json")
+	writer.writer().WriteHeader(http.StatusCreated)
+	// Added by an outer writer once committed, e.g. compression middleware
+	rec.Header().Set("Content-Encoding", "gzip")
 
-	assert.Equal(t, "custom-value", originalHeaders.Get("X-Custom"))
-	assert.Equal(t, "modified-value", clonedHeaders.Get("X-Custom"))
+	handlerHeaders := writer.handlerHeaders()
 
-	mockWriter.AssertNumberOfCalls(t, "Header", 1)
+	assert.Equal(t, http.Header{"Content-Type": []string{"application/json"}}, handlerHeaders)
 }
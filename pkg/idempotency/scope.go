@@ -0,0 +1,61 @@
+package idempotency
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/go-chi/chi/v5"
+)
+
+// Scope isolates keys, identical keys sent by different principals or to different resources do not collide.
+type Scope struct {
+	Tenant    string
+	Principal string
+	Method    string
+	Route     string
+	// Path resolved from Route, e.g. /user/123/profile, a key sent for another resource is another key
+	Path string
+}
+
+// Key builds the store key for key within scope.
+func (s Scope) Key(key string) string {
+	return strings.Join([]string{s.Tenant, s.Principal, s.Method, s.Route, s.Path, key}, keySeparator)
+}
+
+type ScopeFunc func(r *http.Request) Scope
+
+// PrincipalFunc returns the authenticated principal of a request, empty if anonymous.
+type PrincipalFunc func(r *http.Request) string
+
+// PrincipalRouteScope scopes keys by the tenant resolved into the context, see tenant.Middleware, principal,
+// route pattern and resolved path. Route pattern is only complete when the middleware is applied at route level,
+// e.g. chi.Router.With, after tenant.Middleware.
+func PrincipalRouteScope(principal PrincipalFunc) ScopeFunc {
+	return func(r *http.Request) Scope {
+		scope := Scope{
+			Method: r.Method,
+			Route:  routePattern(r),
+			Path:   r.URL.Path,
+		}
+		if id, ok := tenant.FromContext(r.Context()); ok {
+			scope.Tenant = id.String()
+		}
+		if principal != nil {
+			scope.Principal = principal(r)
+		}
+		return scope
+	}
+}
+
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return r.URL.Path
+	}
+	pattern := rctx.RoutePattern()
+	if pattern == "" {
+		return r.URL.Path
+	}
+	return pattern
+}
@@ -0,0 +1,62 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope_Key(t *testing.T) {
+	scope := Scope{
+		Tenant:    "tenant-123",
+		Principal: "user-1",
+		Method:    http.MethodPost,
+		Route:     "/user/{id}/profile",
+		Path:      "/user/123/profile",
+	}
+
+	assert.Equal(t, "tenant-123::user-1::POST::/user/{id}/profile::/user/123/profile::test-key", scope.Key("test-key"))
+}
+
+func TestPrincipalRouteScope(t *testing.T) {
+	scopeFn := PrincipalRouteScope(func(r *http.Request) string {
+		return "user-1"
+	})
+
+	var result Scope
+	router := chi.NewRouter()
+	router.Post("/user/{id}/profile", func(w http.ResponseWriter, r *http.Request) {
+		result = scopeFn(r)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/user/123/profile", nil)
+	request = request.WithContext(tenant.WithID(request.Context(), testTenantID))
+	// Unverified, ignored in favour of the resolved tenant
+	request.Header.Set("X-Tenant-Id", "tenant-123")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, Scope{
+		Tenant:    testTenantID.String(),
+		Principal: "user-1",
+		Method:    http.MethodPost,
+		Route:     "/user/{id}/profile",
+		Path:      "/user/123/profile",
+	}, result)
+}
+
+func TestPrincipalRouteScope_NoPrincipalNoRoute(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/user/123/profile", nil)
+	request.Header.Set("X-Tenant-Id", "tenant-123")
+
+	result := PrincipalRouteScope(nil)(request)
+
+	assert.Equal(t, Scope{
+		Method: http.MethodPost,
+		Route:  "/user/123/profile",
+		Path:   "/user/123/profile",
+	}, result)
+}
@@ -10,6 +10,17 @@ type Store interface {
 	Unlock(ctx context.Context, key string) error
 	Get(ctx context.Context, key string) (*Response, error)
 	Set(ctx context.Context, key string, resp *Response, expiry time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// storeWriteTimeout bounds writes to the store once a request is handled.
+const storeWriteTimeout = 5 * time.Second
+
+// storeWriteContext returns a context for writes to the store once a request is handled, which are made even if
+// the context of the request is cancelled, e.g. by a client disconnecting or the handler timeout. Otherwise
+// responses are not recorded and keys stay locked until the lock expires.
+func storeWriteContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), storeWriteTimeout)
 }
 
 type LockConfig struct {
@@ -100,6 +100,11 @@ func (s *MemStore) Set(ctx context.Context, key string, resp *Response, expiry t
 	return nil
 }
 
+func (s *MemStore) Delete(ctx context.Context, key string) error {
+	s.unset(key)
+	return nil
+}
+
 func (s *MemStore) unset(key string) {
 	s.muData.Lock()
 	defer s.muData.Unlock()
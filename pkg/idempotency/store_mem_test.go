@@ -189,3 +189,20 @@ func TestMemStore_Get_NotFound(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, result)
 }
+
+func TestMemStore_Delete(t *testing.T) {
+	t.Parallel()
+	store := NewMemStore(func() *LockConfig {
+		return &LockConfig{}
+	})
+
+	err := store.Set(context.Background(), "test-key", &Response{}, 0)
+	assert.NoError(t, err)
+
+	err = store.Delete(context.Background(), "test-key")
+	assert.NoError(t, err)
+
+	result, err := store.Get(context.Background(), "test-key")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
@@ -0,0 +1,60 @@
+package logx
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+const (
+	FieldKeyRequestID = "requestId"
+	FieldKeyTraceID   = "traceId"
+	FieldKeyUserID    = "userId"
+	FieldKeyTenantID  = "tenantId"
+)
+
+type fieldsCtxKey struct{}
+
+// WithFields returns a copy of ctx carrying fields, in addition to those already carried,
+// attached to loggers returned by FromContext.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing := Fields(ctx)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsCtxKey{}, merged)
+}
+
+// Fields carried by ctx.
+func Fields(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(fieldsCtxKey{}).([]zap.Field)
+	return fields
+}
+
+// FromContext returns logger with the fields carried by ctx, e.g. request ID, user and tenant.
+// Components keep their injected logger and derive a request scoped one:
+//
+//	logx.FromContext(ctx, g.logger).Debug("get user profile")
+func FromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
+
+func RequestID(id string) zap.Field {
+	return zap.String(FieldKeyRequestID, id)
+}
+
+func TraceID(id string) zap.Field {
+	return zap.String(FieldKeyTraceID, id)
+}
+
+func UserID(id string) zap.Field {
+	return zap.String(FieldKeyUserID, id)
+}
+
+func TenantID(id string) zap.Field {
+	return zap.String(FieldKeyTenantID, id)
+}
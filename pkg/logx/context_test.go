@@ -0,0 +1,44 @@
+package logx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(core)
+
+	ctx := WithFields(context.Background(), RequestID("req-1"))
+	ctx = WithFields(ctx, UserID("user-1"), TenantID("tenant-1"))
+
+	FromContext(ctx, logger).Info("message", zap.String("extra", "value"))
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, map[string]any{
+		FieldKeyRequestID: "req-1",
+		FieldKeyUserID:    "user-1",
+		FieldKeyTenantID:  "tenant-1",
+		"extra":           "value",
+	}, entries[0].ContextMap())
+}
+
+func TestFromContext_NoFields(t *testing.T) {
+	logger := zap.NewNop()
+
+	assert.Same(t, logger, FromContext(context.Background(), logger))
+}
+
+func TestWithFields_DoesNotModifyParent(t *testing.T) {
+	parent := WithFields(context.Background(), RequestID("req-1"))
+
+	_ = WithFields(parent, UserID("user-1"))
+	_ = WithFields(parent, TenantID("tenant-1"))
+
+	assert.Equal(t, []zap.Field{RequestID("req-1")}, Fields(parent))
+}
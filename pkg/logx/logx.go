@@ -5,7 +5,17 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-func InitLogger() (*zap.Logger, error) {
+type Option func(*zap.Config)
+
+// WithLevel shares level with the logger, changing it at runtime changes the level logged,
+// e.g. served by zap.AtomicLevel.ServeHTTP. Defaults to info.
+func WithLevel(level zap.AtomicLevel) Option {
+	return func(config *zap.Config) {
+		config.Level = level
+	}
+}
+
+func InitLogger(options ...Option) (*zap.Logger, error) {
 	config := zap.NewProductionConfig()
 	config.EncoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
 
@@ -15,6 +25,10 @@ func InitLogger() (*zap.Logger, error) {
 	}
 	config.DisableStacktrace = true
 
+	for _, opt := range options {
+		opt(&config)
+	}
+
 	logger, err := config.Build()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,49 @@
+package monitoring
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/dyxj/bigbackend/pkg/httpx"
+)
+
+// BuildInfo of the running binary, VCS fields are empty unless built from a repository, e.g. not with go run.
+type BuildInfo struct {
+	GoVersion    string `json:"goVersion"`
+	Path         string `json:"path"`
+	Version      string `json:"version"`
+	Revision     string `json:"revision"`
+	RevisionTime string `json:"revisionTime"`
+	Modified     bool   `json:"modified"`
+}
+
+// ReadBuildInfo returns the build information embedded in the binary by the go toolchain.
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path = bi.Main.Path
+	info.Version = bi.Main.Version
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.RevisionTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+func BuildInfoHandler() http.HandlerFunc {
+	info := ReadBuildInfo()
+	return func(w http.ResponseWriter, r *http.Request) {
+		httpx.JsonResponse(http.StatusOK, info, w)
+	}
+}
@@ -3,7 +3,9 @@ package monitoring
 import (
 	"net/http"
 	"net/http/pprof"
+	"sync/atomic"
 
+	"github.com/dyxj/bigbackend/pkg/httpx"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -19,10 +21,34 @@ func HealthCheckHandler(isShuttingDown func() bool) http.HandlerFunc {
 	}
 }
 
-func ReadinessCheckHandler(
-	isShuttingDown func() bool,
-	dbPing func() error,
-) http.HandlerFunc {
+// verboseParam of probes, responding with the JSON HealthReport rather than plain text.
+const verboseParam = "verbose"
+
+type probeConfig struct {
+	verbose bool
+}
+
+type ProbeOption func(*probeConfig)
+
+// WithVerbose responds with the HealthReport as JSON given ?verbose.
+// Reports expose check names and errors, only enable it on listeners private to operators.
+func WithVerbose() ProbeOption {
+	return func(c *probeConfig) {
+		c.verbose = true
+	}
+}
+
+func newProbeConfig(options []ProbeOption) probeConfig {
+	var c probeConfig
+	for _, opt := range options {
+		opt(&c)
+	}
+	return c
+}
+
+// ReadinessCheckHandler responds 200 while the critical checks of registry pass, 503 otherwise or once shutting down.
+func ReadinessCheckHandler(isShuttingDown func() bool, registry *HealthRegistry, options ...ProbeOption) http.HandlerFunc {
+	c := newProbeConfig(options)
 	return func(w http.ResponseWriter, r *http.Request) {
 		if isShuttingDown() {
 			w.WriteHeader(http.StatusServiceUnavailable)
@@ -30,16 +56,43 @@ func ReadinessCheckHandler(
 			return
 		}
 
-		// Check database connectivity
-		if err := dbPing(); err != nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("database not ready"))
+		c.writeReport(w, r, registry.Report(r.Context()), "ready", "not ready")
+	}
+}
+
+// StartupCheckHandler responds 503 until the critical checks of registry pass, e.g. migrations being applied.
+// Once passed, checks are no longer run and 200 is returned.
+func StartupCheckHandler(registry *HealthRegistry, options ...ProbeOption) http.HandlerFunc {
+	c := newProbeConfig(options)
+	var started atomic.Bool
+	return func(w http.ResponseWriter, r *http.Request) {
+		if started.Load() {
+			c.writeReport(w, r, HealthReport{Status: HealthStatusPass, Checks: []HealthCheckResult{}}, "started", "")
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ready"))
+		report := registry.Report(r.Context())
+		if report.Passed() {
+			started.Store(true)
+		}
+		c.writeReport(w, r, report, "started", "starting")
+	}
+}
+
+func (c probeConfig) writeReport(w http.ResponseWriter, r *http.Request, report HealthReport, passed, failed string) {
+	statusCode := http.StatusOK
+	body := passed
+	if !report.Passed() {
+		statusCode = http.StatusServiceUnavailable
+		body = failed
+	}
+
+	if c.verbose && r.URL.Query().Has(verboseParam) {
+		httpx.JsonResponse(statusCode, report, w)
+		return
 	}
+	w.WriteHeader(statusCode)
+	_, _ = w.Write([]byte(body))
 }
 
 func RegisterPprofRoutes(r chi.Router) {
@@ -0,0 +1,242 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHealthCheckTimeout  = 2 * time.Second
+	DefaultHealthCheckCacheTTL = time.Second
+)
+
+// HealthStatus of a check or of a report, following the terms of the health check response format draft.
+type HealthStatus string
+
+const (
+	HealthStatusPass HealthStatus = "pass"
+	// HealthStatusWarn of a failed non-critical check, the report is degraded but still passes
+	HealthStatusWarn HealthStatus = "warn"
+	HealthStatusFail HealthStatus = "fail"
+)
+
+// HealthCheckFunc reports a subsystem unhealthy by returning an error, ctx is done once the check timed out.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthMetrics records the latency and outcome of checks run, cached results are not recorded.
+// Implemented by Metrics.
+type HealthMetrics interface {
+	RecordHealthCheck(name string, healthy bool, duration time.Duration)
+}
+
+type noopHealthMetrics struct{}
+
+func (noopHealthMetrics) RecordHealthCheck(string, bool, time.Duration) {}
+
+// HealthCheckResult of a check, Error is empty when passed.
+type HealthCheckResult struct {
+	Name      string       `json:"name"`
+	Status    HealthStatus `json:"status"`
+	Critical  bool         `json:"critical"`
+	LatencyMs float64      `json:"latencyMs"`
+	Error     string       `json:"error,omitempty"`
+	CheckTime time.Time    `json:"checkTime"`
+}
+
+// HealthReport of every check of a HealthRegistry, in order of registration.
+type HealthReport struct {
+	Status HealthStatus        `json:"status"`
+	Checks []HealthCheckResult `json:"checks"`
+}
+
+// Passed is true unless a critical check failed.
+func (r HealthReport) Passed() bool {
+	return r.Status != HealthStatusFail
+}
+
+// HealthRegistry runs named checks registered by subsystems, see Register.
+//
+// Checks run concurrently, each bounded by its timeout. Results are cached for the cache TTL of a check,
+// probes of several load balancers or kubelets do not multiply the load on the subsystems checked.
+type HealthRegistry struct {
+	mu       sync.RWMutex
+	checks   []*healthCheck
+	defaults []HealthCheckOption
+	metrics  HealthMetrics
+	now      func() time.Time
+}
+
+type HealthRegistryOption func(*HealthRegistry)
+
+func WithHealthMetrics(metrics HealthMetrics) HealthRegistryOption {
+	return func(r *HealthRegistry) {
+		r.metrics = metrics
+	}
+}
+
+// WithCheckDefaults options applied to every registered check, before the options of the check.
+func WithCheckDefaults(options ...HealthCheckOption) HealthRegistryOption {
+	return func(r *HealthRegistry) {
+		r.defaults = options
+	}
+}
+
+func NewHealthRegistry(options ...HealthRegistryOption) *HealthRegistry {
+	r := &HealthRegistry{
+		metrics: noopHealthMetrics{},
+		now:     time.Now,
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+type healthCheck struct {
+	name     string
+	check    HealthCheckFunc
+	timeout  time.Duration
+	cacheTTL time.Duration
+	critical bool
+
+	// mu serializes runs, concurrent callers wait for and share the result of a single run
+	mu   sync.Mutex
+	last *HealthCheckResult
+}
+
+type HealthCheckOption func(*healthCheck)
+
+// WithCheckTimeout overrides DefaultHealthCheckTimeout, the check fails once exceeded.
+func WithCheckTimeout(d time.Duration) HealthCheckOption {
+	return func(c *healthCheck) {
+		c.timeout = d
+	}
+}
+
+// WithCheckCacheTTL overrides DefaultHealthCheckCacheTTL, zero runs the check on every report.
+func WithCheckCacheTTL(d time.Duration) HealthCheckOption {
+	return func(c *healthCheck) {
+		c.cacheTTL = d
+	}
+}
+
+// WithCritical sets whether a failed check fails the report, checks are critical by default.
+// Failed non-critical checks only degrade the report, e.g. a backlog of background work.
+func WithCritical(critical bool) HealthCheckOption {
+	return func(c *healthCheck) {
+		c.critical = critical
+	}
+}
+
+// Register adds a check named name, panics if name is already registered.
+func (r *HealthRegistry) Register(name string, check HealthCheckFunc, options ...HealthCheckOption) {
+	c := &healthCheck{
+		name:     name,
+		check:    check,
+		timeout:  DefaultHealthCheckTimeout,
+		cacheTTL: DefaultHealthCheckCacheTTL,
+		critical: true,
+	}
+	for _, opt := range r.defaults {
+		opt(c)
+	}
+	for _, opt := range options {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.checks {
+		if existing.name == name {
+			panic(fmt.Sprintf("monitoring: health check %q already registered", name))
+		}
+	}
+	r.checks = append(r.checks, c)
+}
+
+// Report runs the registered checks, or reuses their cached results, and returns their report.
+// The report fails if a critical check failed and is degraded if a non-critical check failed.
+func (r *HealthRegistry) Report(ctx context.Context) HealthReport {
+	r.mu.RLock()
+	checks := r.checks
+	r.mu.RUnlock()
+
+	report := HealthReport{Status: HealthStatusPass, Checks: make([]HealthCheckResult, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Go(func() {
+			report.Checks[i] = r.run(ctx, c)
+		})
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		switch {
+		case result.Status == HealthStatusFail:
+			report.Status = HealthStatusFail
+		case result.Status == HealthStatusWarn && report.Status == HealthStatusPass:
+			report.Status = HealthStatusWarn
+		}
+	}
+	return report
+}
+
+func (r *HealthRegistry) run(ctx context.Context, c *healthCheck) HealthCheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && r.now().Sub(c.last.CheckTime) < c.cacheTTL {
+		return *c.last
+	}
+
+	start := r.now()
+	err := runWithTimeout(ctx, c.check, c.timeout)
+	duration := r.now().Sub(start)
+	r.metrics.RecordHealthCheck(c.name, err == nil, duration)
+
+	result := HealthCheckResult{
+		Name:      c.name,
+		Status:    HealthStatusPass,
+		Critical:  c.critical,
+		LatencyMs: float64(duration) / float64(time.Millisecond),
+		CheckTime: start,
+	}
+	if err != nil {
+		result.Status = HealthStatusWarn
+		if c.critical {
+			result.Status = HealthStatusFail
+		}
+		result.Error = err.Error()
+	}
+
+	// Not cached when the caller gave up, e.g. a disconnected probe, the subsystem was not checked
+	if ctx.Err() == nil {
+		c.last = &result
+	}
+	return result
+}
+
+// runWithTimeout returns once check returned or timeout elapsed, checks ignoring ctx are left to return late.
+func runWithTimeout(ctx context.Context, check HealthCheckFunc, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- check(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %v", timeout)
+		}
+		return ctx.Err()
+	}
+}
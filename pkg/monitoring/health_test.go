@@ -0,0 +1,211 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type healthMetricsStub struct {
+	mu       sync.Mutex
+	recorded map[string]bool
+}
+
+func (m *healthMetricsStub) RecordHealthCheck(name string, healthy bool, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recorded[name] = healthy
+}
+
+func passingCheck(context.Context) error { return nil }
+
+func failingCheck(context.Context) error { return errors.New("unavailable") }
+
+func TestHealthRegistry_Report(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *HealthRegistry)
+		expected HealthStatus
+	}{
+		{
+			name:     "no checks",
+			register: func(*HealthRegistry) {},
+			expected: HealthStatusPass,
+		},
+		{
+			name: "passed",
+			register: func(r *HealthRegistry) {
+				r.Register("a", passingCheck)
+				r.Register("b", passingCheck, WithCritical(false))
+			},
+			expected: HealthStatusPass,
+		},
+		{
+			name: "non-critical failed",
+			register: func(r *HealthRegistry) {
+				r.Register("a", passingCheck)
+				r.Register("b", failingCheck, WithCritical(false))
+			},
+			expected: HealthStatusWarn,
+		},
+		{
+			name: "critical failed",
+			register: func(r *HealthRegistry) {
+				r.Register("a", failingCheck)
+				r.Register("b", failingCheck, WithCritical(false))
+			},
+			expected: HealthStatusFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewHealthRegistry()
+			tt.register(r)
+
+			report := r.Report(t.Context())
+			assert.Equal(t, tt.expected, report.Status)
+			assert.Equal(t, tt.expected != HealthStatusFail, report.Passed())
+		})
+	}
+}
+
+func TestHealthRegistry_Report_Results(t *testing.T) {
+	metrics := &healthMetricsStub{recorded: map[string]bool{}}
+	r := NewHealthRegistry(WithHealthMetrics(metrics))
+	r.Register("database", passingCheck)
+	r.Register("outbox", failingCheck, WithCritical(false))
+
+	report := r.Report(t.Context())
+
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "database", report.Checks[0].Name)
+	assert.Equal(t, HealthStatusPass, report.Checks[0].Status)
+	assert.True(t, report.Checks[0].Critical)
+	assert.Empty(t, report.Checks[0].Error)
+
+	assert.Equal(t, "outbox", report.Checks[1].Name)
+	assert.Equal(t, HealthStatusWarn, report.Checks[1].Status)
+	assert.False(t, report.Checks[1].Critical)
+	assert.Equal(t, "unavailable", report.Checks[1].Error)
+
+	assert.Equal(t, map[string]bool{"database": true, "outbox": false}, metrics.recorded)
+}
+
+func TestHealthRegistry_Report_Cached(t *testing.T) {
+	now := time.Now()
+	r := NewHealthRegistry()
+	r.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+	r.Register("a", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}, WithCheckCacheTTL(time.Second))
+
+	r.Report(t.Context())
+	r.Report(t.Context())
+	assert.Equal(t, int32(1), calls.Load())
+
+	now = now.Add(time.Second)
+	r.Report(t.Context())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestHealthRegistry_Report_Timeout(t *testing.T) {
+	r := NewHealthRegistry(WithCheckDefaults(WithCheckTimeout(10 * time.Millisecond)))
+	block := make(chan struct{})
+	defer close(block)
+	// Ignores ctx, the report does not wait for it
+	r.Register("slow", func(context.Context) error {
+		<-block
+		return nil
+	})
+
+	report := r.Report(t.Context())
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.Equal(t, "timed out after 10ms", report.Checks[0].Error)
+}
+
+func TestHealthRegistry_Register_Duplicate(t *testing.T) {
+	r := NewHealthRegistry()
+	r.Register("a", passingCheck)
+	assert.Panics(t, func() { r.Register("a", passingCheck) })
+}
+
+func TestReadinessCheckHandler(t *testing.T) {
+	serve := func(shuttingDown bool, check HealthCheckFunc, target string, options ...ProbeOption) *httptest.ResponseRecorder {
+		r := NewHealthRegistry()
+		r.Register("database", check)
+		w := httptest.NewRecorder()
+		ReadinessCheckHandler(func() bool { return shuttingDown }, r, options...).
+			ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := serve(false, passingCheck, "/readyz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ready", w.Body.String())
+
+	w = serve(false, failingCheck, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "not ready", w.Body.String())
+
+	w = serve(true, passingCheck, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "shutting down", w.Body.String())
+
+	// Reports are not exposed unless enabled
+	w = serve(false, failingCheck, "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "not ready", w.Body.String())
+
+	w = serve(false, failingCheck, "/readyz?verbose", WithVerbose())
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"status":"fail"`)
+	assert.Contains(t, w.Body.String(), `"error":"unavailable"`)
+}
+
+func TestStartupCheckHandler(t *testing.T) {
+	var migrated atomic.Bool
+	r := NewHealthRegistry(WithCheckDefaults(WithCheckCacheTTL(0)))
+	var calls atomic.Int32
+	r.Register("migrations", func(context.Context) error {
+		calls.Add(1)
+		if !migrated.Load() {
+			return errors.New("pending")
+		}
+		return nil
+	})
+	handler := StartupCheckHandler(r)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/startupz", nil))
+		return w
+	}
+
+	w := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "starting", w.Body.String())
+
+	migrated.Store(true)
+	w = serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "started", w.Body.String())
+
+	// Checks are no longer run once started
+	migrated.Store(false)
+	w = serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -1,13 +1,17 @@
 package monitoring
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/dyxj/bigbackend/pkg/httpx"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 )
 
 type Metrics struct {
@@ -16,12 +20,41 @@ type Metrics struct {
 	HTTPRequestDuration  *prometheus.HistogramVec
 	HTTPRequestsInFlight prometheus.Gauge
 
+	// gRPC metrics
+	GRPCRequestsTotal   *prometheus.CounterVec
+	GRPCRequestDuration *prometheus.HistogramVec
+
 	// Database metrics
 	DBConnectionsOpen prometheus.Gauge
 	DBConnectionsIdle prometheus.Gauge
 	DBQueryDuration   *prometheus.HistogramVec
 	DBQueryErrors     *prometheus.CounterVec
 
+	// Idempotency metrics
+	IdempotencyLockConflicts    prometheus.Counter
+	IdempotencyLockWaitDuration prometheus.Histogram
+	IdempotencyCacheLookups     *prometheus.CounterVec
+	IdempotencyStoreErrors      *prometheus.CounterVec
+
+	// Rate limit metrics
+	RateLimitedRequests  *prometheus.CounterVec
+	RateLimitStoreErrors *prometheus.CounterVec
+
+	// API version metrics
+	APIVersionRequests *prometheus.CounterVec
+
+	// Webhook metrics
+	WebhookDeliveries       *prometheus.CounterVec
+	WebhookDeliveryDuration *prometheus.HistogramVec
+
+	// Server-sent event metrics
+	SSESubscribers      prometheus.Gauge
+	SSESubscribersEnded *prometheus.CounterVec
+
+	// Health check metrics
+	HealthCheckDuration *prometheus.HistogramVec
+	HealthCheckStatus   *prometheus.GaugeVec
+
 	// Application metrics
 	AppInfo         *prometheus.GaugeVec
 	GoRoutinesCount prometheus.Gauge
@@ -56,6 +89,25 @@ func NewMetrics(namespace string) *Metrics {
 			},
 		),
 
+		// gRPC metrics
+		GRPCRequestsTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "grpc_requests_total",
+				Help:      "Total number of gRPC requests",
+			},
+			[]string{"method", "code"},
+		),
+		GRPCRequestDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "grpc_request_duration_seconds",
+				Help:      "gRPC request duration in seconds",
+				Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			},
+			[]string{"method", "code"},
+		),
+
 		// Database metrics
 		DBConnectionsOpen: promauto.NewGauge(
 			prometheus.GaugeOpts{
@@ -89,6 +141,122 @@ func NewMetrics(namespace string) *Metrics {
 			[]string{"operation", "table"},
 		),
 
+		// Idempotency metrics
+		IdempotencyLockConflicts: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "idempotency_lock_conflicts_total",
+				Help:      "Total number of requests rejected as idempotency key is in progress",
+			},
+		),
+		IdempotencyLockWaitDuration: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "idempotency_lock_wait_duration_seconds",
+				Help:      "Time spent acquiring idempotency key lock in seconds",
+				Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1, .25, .5, 1, 2.5},
+			},
+		),
+		IdempotencyCacheLookups: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "idempotency_cache_lookups_total",
+				Help:      "Total number of idempotency cache lookups",
+			},
+			[]string{"result"},
+		),
+		IdempotencyStoreErrors: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "idempotency_store_errors_total",
+				Help:      "Total number of idempotency store errors",
+			},
+			[]string{"operation"},
+		),
+
+		// Rate limit metrics
+		RateLimitedRequests: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "rate_limited_requests_total",
+				Help:      "Total number of requests rejected by rate limits",
+			},
+			[]string{"limit"},
+		),
+		RateLimitStoreErrors: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "rate_limit_store_errors_total",
+				Help:      "Total number of rate limit store errors, requests are allowed on error",
+			},
+			[]string{"limit"},
+		),
+
+		// API version metrics
+		APIVersionRequests: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "api_version_requests_total",
+				Help:      "Total number of API requests per version and route",
+			},
+			[]string{"version", "route", "deprecated"},
+		),
+
+		// Webhook metrics
+		WebhookDeliveries: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "webhook_deliveries_total",
+				Help:      "Total number of webhook delivery attempts per event type and result",
+			},
+			[]string{"event_type", "result"},
+		),
+		WebhookDeliveryDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "webhook_delivery_duration_seconds",
+				Help:      "Webhook delivery request duration in seconds",
+				Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			},
+			[]string{"event_type"},
+		),
+
+		// Server-sent event metrics
+		SSESubscribers: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "sse_subscribers",
+				Help:      "Current number of event stream subscribers",
+			},
+		),
+		SSESubscribersEnded: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "sse_subscribers_ended_total",
+				Help:      "Total number of event stream subscribers disconnected by the server per reason",
+			},
+			[]string{"reason"},
+		),
+
+		// Health check metrics
+		HealthCheckDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "health_check_duration_seconds",
+				Help:      "Health check duration in seconds per check",
+				Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+			},
+			[]string{"check"},
+		),
+		HealthCheckStatus: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "health_check_status",
+				Help:      "Outcome of the last health check run per check, 1 if healthy, 0 otherwise",
+			},
+			[]string{"check"},
+		),
+
 		// Application metrics
 		AppInfo: promauto.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -121,6 +289,11 @@ func (m *Metrics) RecordHTTPRequest(method, path, status string, duration time.D
 	m.HTTPRequestDuration.WithLabelValues(method, path, status).Observe(duration.Seconds())
 }
 
+func (m *Metrics) RecordGRPCRequest(method, code string, duration time.Duration) {
+	m.GRPCRequestsTotal.WithLabelValues(method, code).Inc()
+	m.GRPCRequestDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
 func (m *Metrics) RecordDBQuery(operation, table string, duration time.Duration, err error) {
 	m.DBQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
 	if err != nil {
@@ -128,19 +301,75 @@ func (m *Metrics) RecordDBQuery(operation, table string, duration time.Duration,
 	}
 }
 
+func (m *Metrics) RecordIdempotencyLockWait(duration time.Duration) {
+	m.IdempotencyLockWaitDuration.Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordIdempotencyLockConflict() {
+	m.IdempotencyLockConflicts.Inc()
+}
+
+func (m *Metrics) RecordIdempotencyCache(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.IdempotencyCacheLookups.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) RecordIdempotencyStoreError(operation string) {
+	m.IdempotencyStoreErrors.WithLabelValues(operation).Inc()
+}
+
+func (m *Metrics) RecordRateLimited(name string) {
+	m.RateLimitedRequests.WithLabelValues(name).Inc()
+}
+
+func (m *Metrics) RecordRateLimitStoreError(name string) {
+	m.RateLimitStoreErrors.WithLabelValues(name).Inc()
+}
+
+func (m *Metrics) RecordAPIVersionRequest(version, route string, deprecated bool) {
+	m.APIVersionRequests.WithLabelValues(version, route, strconv.FormatBool(deprecated)).Inc()
+}
+
+func (m *Metrics) RecordWebhookDelivery(eventType, result string, duration time.Duration) {
+	m.WebhookDeliveries.WithLabelValues(eventType, result).Inc()
+	if duration > 0 {
+		m.WebhookDeliveryDuration.WithLabelValues(eventType).Observe(duration.Seconds())
+	}
+}
+
+func (m *Metrics) RecordSSESubscribers(delta int) {
+	m.SSESubscribers.Add(float64(delta))
+}
+
+func (m *Metrics) RecordSSESubscriberEnded(reason string) {
+	m.SSESubscribersEnded.WithLabelValues(reason).Inc()
+}
+
+// RecordAppInfo sets the app_info gauge of the version and revision of info.
+func (m *Metrics) RecordAppInfo(info BuildInfo) {
+	m.AppInfo.WithLabelValues(info.Version, info.Revision).Set(1)
+}
+
+func (m *Metrics) RecordHealthCheck(name string, healthy bool, duration time.Duration) {
+	m.HealthCheckDuration.WithLabelValues(name).Observe(duration.Seconds())
+	status := 0.0
+	if healthy {
+		status = 1
+	}
+	m.HealthCheckStatus.WithLabelValues(name).Set(status)
+}
+
 func (m *Metrics) HTTPMetricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
 		m.HTTPRequestsInFlight.Inc()
 		defer m.HTTPRequestsInFlight.Dec()
 
-		// TODO fix this, use centralize response interceptor
-		//  add check if it already is instance of responseWriter
-		wrapper := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		interceptor := httpx.NewInterceptor(w, httpx.InterceptorHooks{})
 
-		next.ServeHTTP(wrapper, r)
-
-		duration := time.Since(start)
+		next.ServeHTTP(interceptor.Wrap(), r)
 
 		// Get route pattern instead of raw path for better grouping
 		routePattern := chi.RouteContext(r.Context()).RoutePattern()
@@ -151,18 +380,19 @@ func (m *Metrics) HTTPMetricsMiddleware(next http.Handler) http.Handler {
 		m.RecordHTTPRequest(
 			r.Method,
 			routePattern,
-			strconv.Itoa(wrapper.statusCode),
-			duration,
+			strconv.Itoa(interceptor.Status()),
+			interceptor.Duration(),
 		)
 	})
 }
 
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
+// GRPCMetricsInterceptor records unary calls by full method and status code,
+// errors are expected to be status errors, see grpcx.ErrorRegistry.
+func (m *Metrics) GRPCMetricsInterceptor(
+	ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.RecordGRPCRequest(info.FullMethod, status.Code(err).String(), time.Since(start))
+	return resp, err
 }
@@ -0,0 +1,72 @@
+package monitoring
+
+import (
+	"net/http"
+	"runtime"
+	"sync"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+)
+
+// ErrorResponseWriter writes the response of a failed request, e.g. httpx.ErrorRegistry.WriteError.
+type ErrorResponseWriter func(err error, w http.ResponseWriter, r *http.Request)
+
+// ProfilingRates of the block and mutex profiles served by RegisterPprofRoutes, both disabled when zero.
+// Sampling has a cost, rates are meant to be raised while investigating and reset afterwards.
+type ProfilingRates struct {
+	// BlockProfileRate samples one blocking event per rate nanoseconds blocked, see runtime.SetBlockProfileRate.
+	BlockProfileRate int `json:"blockProfileRate"`
+	// MutexProfileFraction samples one in fraction contention events, see runtime.SetMutexProfileFraction.
+	MutexProfileFraction int `json:"mutexProfileFraction"`
+}
+
+func (p ProfilingRates) Validate() *errorx.ValidationError {
+	properties := make(map[string]string)
+	if p.BlockProfileRate < 0 {
+		properties["blockProfileRate"] = "must not be negative"
+	}
+	if p.MutexProfileFraction < 0 {
+		properties["mutexProfileFraction"] = "must not be negative"
+	}
+	if len(properties) > 0 {
+		return &errorx.ValidationError{Properties: properties}
+	}
+	return nil
+}
+
+// ProfilingToggleHandler responds with the current ProfilingRates on GET and sets them on PUT.
+// Decoding errors are written by writeError.
+func ProfilingToggleHandler(writeError ErrorResponseWriter) http.HandlerFunc {
+	var mu sync.Mutex
+	// The runtime does not report the block profile rate, it is assumed disabled at startup
+	blockProfileRate := 0
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			rates, err := httpx.DecodeJSON[ProfilingRates](w, r)
+			if err != nil {
+				writeError(err, w, r)
+				return
+			}
+			runtime.SetBlockProfileRate(rates.BlockProfileRate)
+			runtime.SetMutexProfileFraction(rates.MutexProfileFraction)
+			blockProfileRate = rates.BlockProfileRate
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		httpx.JsonResponse(http.StatusOK, ProfilingRates{
+			BlockProfileRate: blockProfileRate,
+			// A negative fraction reports the current one without changing it
+			MutexProfileFraction: runtime.SetMutexProfileFraction(-1),
+		}, w)
+	}
+}
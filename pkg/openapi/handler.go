@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sync"
+
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// Handler serves the document of spec, built on first request as routes
+// are only complete once the router has been built.
+type Handler struct {
+	logger *zap.Logger
+	spec   *Spec
+	routes chi.Routes
+
+	once sync.Once
+	doc  *Document
+	err  error
+}
+
+func NewHandler(logger *zap.Logger, spec *Spec, routes chi.Routes) *Handler {
+	return &Handler{logger: logger, spec: spec, routes: routes}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.once.Do(func() {
+		h.doc, h.err = h.spec.Document(h.routes)
+	})
+	if h.err != nil {
+		h.logger.Error("failed to build openapi document", zap.Error(h.err))
+		httpx.InternalServerErrorResponse("", w, r)
+		return
+	}
+	httpx.JsonResponse(http.StatusOK, h.doc, w)
+}
+
+//go:embed ui
+var uiFS embed.FS
+
+var uiTemplate = template.Must(template.ParseFS(uiFS, "ui/index.html"))
+
+// UIContentSecurityPolicy allows the page of UIHandler, which loads its script and stylesheet from the same origin.
+const UIContentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'"
+
+// UIHandler serves a page rendering the document at specURL, to be mounted at path, e.g. "/docs".
+// Assets are embedded and served under path, the page loads nothing from other origins.
+func UIHandler(title, path, specURL string) http.Handler {
+	var page bytes.Buffer
+	// Template only fails on invalid data, which is fixed at this point
+	_ = uiTemplate.Execute(&page, struct{ Title, Path, SpecURL string }{title, path, specURL})
+
+	// Sub only fails on invalid paths, which is fixed at this point
+	assets, _ := fs.Sub(uiFS, "ui/assets")
+
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// Errors are likely due to client disconnect.
+		_, _ = w.Write(page.Bytes())
+	})
+	r.Get("/assets/{name}", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, assets, chi.URLParam(r, "name"))
+	})
+	return r
+}
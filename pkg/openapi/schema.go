@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"cloud.google.com/go/civil"
+	"github.com/google/uuid"
+)
+
+const componentSchemaRefPrefix = "#/components/schemas/"
+
+// formats of types encoded as JSON strings.
+var stringFormats = map[reflect.Type]string{
+	reflect.TypeFor[time.Time]():  "date-time",
+	reflect.TypeFor[civil.Date](): "date",
+	reflect.TypeFor[uuid.UUID]():  "uuid",
+}
+
+// schemaGenerator generates schemas from Go types, named structs are added to components.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf returns the schema of the type of v.
+func (g *schemaGenerator) schemaOf(v any) *Schema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if format, ok := stringFormats[t]; ok {
+		return &Schema{Type: "string", Format: format}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		// interfaces accept any value
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) structRef(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+
+	name, ok := g.names[t]
+	if !ok {
+		name = componentName(t)
+		g.names[t] = name
+		// registered before generating properties to support recursive types
+		g.schemas[name] = nil
+		g.schemas[name] = g.structSchema(t)
+	}
+	return &Schema{Ref: componentSchemaRefPrefix + name}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = g.schema(f.Type)
+		if isRequired(f, opts) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// isRequired fields validated as required, or always present in responses.
+func isRequired(f reflect.StructField, jsonOpts string) bool {
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return f.Tag.Get("validate") == "" &&
+		f.Type.Kind() != reflect.Pointer &&
+		!strings.Contains(jsonOpts, "omitempty")
+}
+
+// componentName qualifies the type name with its package, e.g. profile.Response as ProfileResponse.
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name := capitalize(pkg) + capitalize(t.Name())
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
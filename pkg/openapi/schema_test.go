@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name" validate:"required"`
+	Note      string            `json:"note,omitempty"`
+	Birthday  civil.Date        `json:"birthday" validate:"past"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Count     int64             `json:"count"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Children  []testItem        `json:"children,omitempty"`
+	Parent    *testItem         `json:"parent"`
+	Raw       []byte            `json:"raw,omitempty"`
+	Ignored   string            `json:"-"`
+}
+
+func TestSchemaGenerator(t *testing.T) {
+	gen := newSchemaGenerator()
+
+	ref := gen.schemaOf(testItem{})
+
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/OpenapiTestItem"}, ref)
+
+	s := gen.schemas["OpenapiTestItem"]
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, &Schema{Type: "string", Format: "uuid"}, s.Properties["id"])
+	assert.Equal(t, &Schema{Type: "string"}, s.Properties["name"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date"}, s.Properties["birthday"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, s.Properties["createdAt"])
+	assert.Equal(t, &Schema{Type: "integer", Format: "int64"}, s.Properties["count"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, s.Properties["labels"])
+	assert.Equal(t, &Schema{Type: "array", Items: ref}, s.Properties["children"])
+	assert.Equal(t, ref, s.Properties["parent"])
+	assert.Equal(t, &Schema{Type: "string", Format: "byte"}, s.Properties["raw"])
+	assert.NotContains(t, s.Properties, "Ignored")
+	assert.ElementsMatch(t, []string{"id", "name", "createdAt", "count"}, s.Required)
+}
+
+func TestSchemaGenerator_AnonymousStruct(t *testing.T) {
+	gen := newSchemaGenerator()
+
+	s := gen.schemaOf(struct {
+		Name string `json:"name"`
+	}{})
+
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, &Schema{Type: "string"}, s.Properties["name"])
+	assert.Empty(t, gen.schemas)
+}
@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const contentTypeJSON = "application/json"
+const bearerAuthScheme = "bearerAuth"
+
+var pathParamRegexp = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?}`)
+
+// Route describes an operation of a chi route.
+type Route struct {
+	Summary string
+	Tags    []string
+	// PathParams example values by name, used for the parameter schema, defaults to string.
+	PathParams map[string]any
+	// Headers request headers by name, true when required.
+	Headers map[string]bool
+	// Request body example value, nil for no body.
+	Request any
+	// Responses body example values by status, nil for no body.
+	Responses map[int]any
+	// ResponseContentType of Responses, defaults to application/json, e.g. text/event-stream.
+	ResponseContentType string
+	// Errors statuses responded with the error bodies of the Spec.
+	Errors []int
+	// Deprecated routes, e.g. of a deprecated API version.
+	Deprecated bool
+}
+
+// Spec collects route descriptions, the document is built from routes registered on a chi router.
+type Spec struct {
+	info       Info
+	servers    []Server
+	errBodies  map[string]any
+	operations map[string]Route
+	// bearerFormat enables bearer authentication when not empty
+	bearerFormat string
+}
+
+type Option func(*Spec)
+
+// WithServer adds a server URL, e.g. "/api/v1".
+func WithServer(url string) Option {
+	return func(s *Spec) {
+		s.servers = append(s.servers, Server{URL: url})
+	}
+}
+
+// WithErrorBody describes the body of Route.Errors responses for contentType.
+func WithErrorBody(contentType string, body any) Option {
+	return func(s *Spec) {
+		s.errBodies[contentType] = body
+	}
+}
+
+// WithBearerAuth requires a bearer token of format, e.g. "JWT", on every operation.
+func WithBearerAuth(format string) Option {
+	return func(s *Spec) {
+		s.bearerFormat = format
+	}
+}
+
+func NewSpec(info Info, options ...Option) *Spec {
+	s := &Spec{
+		info:       info,
+		errBodies:  make(map[string]any),
+		operations: make(map[string]Route),
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// Route describes the route of method and full chi pattern, e.g. "/api/v1/user/{id}/profile".
+func (s *Spec) Route(method, pattern string, route Route) {
+	s.operations[operationKey(method, pattern)] = route
+}
+
+// Document builds the document of described routes registered in routes.
+// Routes without a description are omitted, see Undocumented.
+func (s *Spec) Document(routes chi.Routes) (*Document, error) {
+	gen := newSchemaGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Servers: s.servers,
+		Paths:   make(map[string]PathItem),
+	}
+
+	err := chi.Walk(routes, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		pattern = normalizePattern(pattern)
+		route, ok := s.operations[operationKey(method, pattern)]
+		if !ok {
+			return nil
+		}
+
+		path := pathParamRegexp.ReplaceAllString(pattern, "{$1}")
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(method)] = s.operation(gen, method, pattern, route)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	doc.Components.Schemas = gen.schemas
+	if s.bearerFormat != "" {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			bearerAuthScheme: {Type: "http", Scheme: "bearer", BearerFormat: s.bearerFormat},
+		}
+		doc.Security = []SecurityRequirement{{bearerAuthScheme: {}}}
+	}
+	return doc, nil
+}
+
+// Undocumented returns "METHOD pattern" of routes with prefix without a description.
+func (s *Spec) Undocumented(routes chi.Routes, prefix string) ([]string, error) {
+	var undocumented []string
+	err := chi.Walk(routes, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		pattern = normalizePattern(pattern)
+		if !strings.HasPrefix(pattern, prefix) {
+			return nil
+		}
+		if _, ok := s.operations[operationKey(method, pattern)]; !ok {
+			undocumented = append(undocumented, operationKey(method, pattern))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk routes: %w", err)
+	}
+	return undocumented, nil
+}
+
+func (s *Spec) operation(gen *schemaGenerator, method, pattern string, route Route) *Operation {
+	op := &Operation{
+		OperationID: operationID(method, pattern),
+		Summary:     route.Summary,
+		Tags:        route.Tags,
+		Responses:   make(map[string]*Response),
+		Deprecated:  route.Deprecated,
+	}
+
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(pattern, -1) {
+		name := match[1]
+		schema := &Schema{Type: "string"}
+		if example, ok := route.PathParams[name]; ok {
+			schema = gen.schemaOf(example)
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	for _, name := range slices.Sorted(maps.Keys(route.Headers)) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     name,
+			In:       "header",
+			Required: route.Headers[name],
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{contentTypeJSON: {Schema: gen.schemaOf(route.Request)}},
+		}
+	}
+
+	responseContentType := cmp.Or(route.ResponseContentType, contentTypeJSON)
+	for status, body := range route.Responses {
+		resp := &Response{Description: http.StatusText(status)}
+		if body != nil {
+			resp.Content = map[string]MediaType{responseContentType: {Schema: gen.schemaOf(body)}}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+	for _, status := range route.Errors {
+		resp := &Response{Description: http.StatusText(status)}
+		if len(s.errBodies) > 0 {
+			resp.Content = make(map[string]MediaType, len(s.errBodies))
+			for contentType, body := range s.errBodies {
+				resp.Content[contentType] = MediaType{Schema: gen.schemaOf(body)}
+			}
+		}
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+
+	return op
+}
+
+func operationKey(method, pattern string) string {
+	return strings.ToUpper(method) + " " + pattern
+}
+
+// normalizePattern removes trailing slashes of mounted routers, e.g. "/api/v1/user/{id}/profile/".
+func normalizePattern(pattern string) string {
+	pattern = strings.ReplaceAll(pattern, "/*/", "/")
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+// operationID e.g. "post_api_v1_user_id_profile".
+func operationID(method, pattern string) string {
+	id := pathParamRegexp.ReplaceAllString(pattern, "$1")
+	id = strings.Trim(strings.ReplaceAll(id, "/", "_"), "_")
+	return strings.ToLower(method) + "_" + id
+}
@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouter() chi.Router {
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	apiRouter := chi.NewRouter()
+	apiRouter.Get("/item/{id}", noop)
+	apiRouter.Post("/item/{id:[0-9]+}/child", noop)
+
+	router := chi.NewRouter()
+	router.Mount("/api", apiRouter)
+	router.Get("/healthz", noop)
+	return router
+}
+
+func TestSpec_Document(t *testing.T) {
+	spec := NewSpec(Info{Title: "test", Version: "v1"}, WithErrorBody("application/json", testItem{}))
+	spec.Route(http.MethodGet, "/api/item/{id}", Route{
+		Summary:    "get item",
+		PathParams: map[string]any{"id": uuid.UUID{}},
+		Headers:    map[string]bool{"X-Tenant-Id": false},
+		Responses:  map[int]any{http.StatusOK: testItem{}, http.StatusNoContent: nil},
+		Errors:     []int{http.StatusNotFound},
+	})
+	spec.Route(http.MethodPost, "/api/item/{id:[0-9]+}/child", Route{
+		Request:    testItem{},
+		Responses:  map[int]any{http.StatusCreated: testItem{}},
+		Deprecated: true,
+	})
+	// not registered in router
+	spec.Route(http.MethodDelete, "/api/item/{id}", Route{})
+
+	doc, err := spec.Document(testRouter())
+	require.NoError(t, err)
+
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Len(t, doc.Paths, 2)
+
+	get := doc.Paths["/api/item/{id}"]["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "get_api_item_id", get.OperationID)
+	assert.Equal(t, []Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string", Format: "uuid"}},
+		{Name: "X-Tenant-Id", In: "header", Required: false, Schema: &Schema{Type: "string"}},
+	}, get.Parameters)
+	assert.Contains(t, get.Responses["200"].Content, "application/json")
+	assert.Empty(t, get.Responses["204"].Content)
+	assert.Equal(t, "Not Found", get.Responses["404"].Description)
+	assert.NotContains(t, doc.Paths["/api/item/{id}"], "delete")
+	assert.False(t, get.Deprecated)
+
+	post := doc.Paths["/api/item/{id}/child"]["post"]
+	require.NotNil(t, post)
+	assert.Equal(t, &Schema{Type: "string"}, post.Parameters[0].Schema)
+	require.NotNil(t, post.RequestBody)
+	assert.True(t, post.Deprecated)
+	assert.Contains(t, doc.Components.Schemas, "OpenapiTestItem")
+}
+
+func TestSpec_Document_ResponseContentType(t *testing.T) {
+	spec := NewSpec(Info{Title: "test", Version: "v1"})
+	spec.Route(http.MethodGet, "/healthz", Route{
+		Responses:           map[int]any{http.StatusOK: ""},
+		ResponseContentType: "text/plain",
+	})
+
+	doc, err := spec.Document(testRouter())
+	require.NoError(t, err)
+
+	content := doc.Paths["/healthz"]["get"].Responses["200"].Content
+	assert.Equal(t, map[string]MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}, content)
+}
+
+func TestSpec_Undocumented(t *testing.T) {
+	spec := NewSpec(Info{Title: "test", Version: "v1"})
+	spec.Route(http.MethodGet, "/api/item/{id}", Route{})
+
+	undocumented, err := spec.Undocumented(testRouter(), "/api")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"POST /api/item/{id:[0-9]+}/child"}, undocumented)
+}
+
+func TestSpec_Document_BearerAuth(t *testing.T) {
+	doc, err := NewSpec(Info{Title: "test", Version: "v1"}).Document(testRouter())
+	require.NoError(t, err)
+	assert.Empty(t, doc.Security)
+	assert.Empty(t, doc.Components.SecuritySchemes)
+
+	doc, err = NewSpec(Info{Title: "test", Version: "v1"}, WithBearerAuth("JWT")).Document(testRouter())
+	require.NoError(t, err)
+	assert.Equal(t, []SecurityRequirement{{"bearerAuth": {}}}, doc.Security)
+	assert.Equal(t, &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		doc.Components.SecuritySchemes["bearerAuth"])
+}
@@ -0,0 +1,203 @@
+// Renders the OpenAPI document of data-spec-url, served same-origin without inline scripts or styles.
+"use strict";
+
+(function () {
+  const root = document.getElementById("docs");
+  const methods = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+  const maxSchemaDepth = 6;
+
+  function el(tag, className, text) {
+    const node = document.createElement(tag);
+    if (className) {
+      node.className = className;
+    }
+    if (text !== undefined && text !== null) {
+      node.textContent = String(text);
+    }
+    return node;
+  }
+
+  function refName(ref) {
+    return ref.substring(ref.lastIndexOf("/") + 1);
+  }
+
+  function resolve(doc, schema) {
+    if (schema && schema.$ref) {
+      const schemas = (doc.components && doc.components.schemas) || {};
+      return { name: refName(schema.$ref), schema: schemas[refName(schema.$ref)] || {} };
+    }
+    return { name: "", schema: schema || {} };
+  }
+
+  function typeOf(doc, schema) {
+    const resolved = resolve(doc, schema);
+    const s = resolved.schema;
+    let type = Array.isArray(s.type) ? s.type.join(" | ") : s.type || "object";
+    if (type === "array" && s.items) {
+      type = typeOf(doc, s.items) + "[]";
+    } else if (resolved.name) {
+      type = resolved.name;
+    }
+    if (s.format) {
+      type += " (" + s.format + ")";
+    }
+    return type;
+  }
+
+  // renderSchema lists the properties of schema, references already visited are not expanded again.
+  function renderSchema(doc, schema, seen, depth) {
+    const resolved = resolve(doc, schema);
+    let s = resolved.schema;
+    if (s.type === "array" && s.items) {
+      return renderSchema(doc, s.items, seen, depth);
+    }
+    if (resolved.name) {
+      if (seen.has(resolved.name) || depth > maxSchemaDepth) {
+        return null;
+      }
+      seen = new Set(seen).add(resolved.name);
+    }
+    const properties = s.properties || {};
+    const names = Object.keys(properties);
+    if (names.length === 0) {
+      return null;
+    }
+
+    const required = new Set(s.required || []);
+    const list = el("ul", "schema");
+    names.forEach(function (name) {
+      const item = el("li");
+      item.appendChild(el("code", "name", name));
+      item.appendChild(el("span", "type", typeOf(doc, properties[name])));
+      if (required.has(name)) {
+        item.appendChild(el("span", "required", "required"));
+      }
+      const nested = renderSchema(doc, properties[name], seen, depth + 1);
+      if (nested) {
+        item.appendChild(nested);
+      }
+      list.appendChild(item);
+    });
+    return list;
+  }
+
+  function renderContent(doc, content) {
+    const section = el("div");
+    Object.keys(content || {}).forEach(function (mediaType) {
+      const schema = content[mediaType].schema;
+      section.appendChild(el("p", "media-type", mediaType + ": " + typeOf(doc, schema)));
+      const properties = renderSchema(doc, schema, new Set(), 0);
+      if (properties) {
+        section.appendChild(properties);
+      }
+    });
+    return section;
+  }
+
+  function renderParameters(parameters) {
+    const table = el("table", "parameters");
+    const head = el("tr");
+    ["Name", "In", "Type", "Description"].forEach(function (title) {
+      head.appendChild(el("th", "", title));
+    });
+    table.appendChild(head);
+    parameters.forEach(function (p) {
+      const row = el("tr");
+      const name = el("td");
+      name.appendChild(el("code", "name", p.name));
+      if (p.required) {
+        name.appendChild(el("span", "required", "required"));
+      }
+      row.appendChild(name);
+      row.appendChild(el("td", "", p.in));
+      row.appendChild(el("td", "type", p.schema ? p.schema.type || "" : ""));
+      row.appendChild(el("td", "", p.description || ""));
+      table.appendChild(row);
+    });
+    return table;
+  }
+
+  function renderOperation(doc, path, method, op) {
+    const details = el("details", "operation " + method);
+    if (op.operationId) {
+      details.id = op.operationId;
+    }
+    const summary = el("summary");
+    summary.appendChild(el("span", "method", method.toUpperCase()));
+    summary.appendChild(el("code", "path", path));
+    summary.appendChild(el("span", "summary", op.summary || ""));
+    if (op.deprecated) {
+      summary.appendChild(el("span", "deprecated", "deprecated"));
+      details.classList.add("is-deprecated");
+    }
+    details.appendChild(summary);
+
+    if (op.parameters && op.parameters.length > 0) {
+      details.appendChild(el("h4", "", "Parameters"));
+      details.appendChild(renderParameters(op.parameters));
+    }
+    if (op.requestBody) {
+      details.appendChild(el("h4", "", op.requestBody.required ? "Request body (required)" : "Request body"));
+      details.appendChild(renderContent(doc, op.requestBody.content));
+    }
+    details.appendChild(el("h4", "", "Responses"));
+    Object.keys(op.responses || {}).sort().forEach(function (status) {
+      const response = op.responses[status];
+      const item = el("div", "response");
+      item.appendChild(el("span", "status status-" + status.charAt(0), status));
+      item.appendChild(el("span", "", response.description || ""));
+      item.appendChild(renderContent(doc, response.content));
+      details.appendChild(item);
+    });
+    return details;
+  }
+
+  function render(doc) {
+    root.replaceChildren();
+    const info = doc.info || {};
+    const header = el("header");
+    header.appendChild(el("h1", "", info.title || "API"));
+    header.appendChild(el("span", "version", info.version || ""));
+    if (info.description) {
+      header.appendChild(el("p", "", info.description));
+    }
+    root.appendChild(header);
+
+    // Operations grouped by their first tag, in path order
+    const groups = new Map();
+    Object.keys(doc.paths || {}).sort().forEach(function (path) {
+      methods.forEach(function (method) {
+        const op = doc.paths[path][method];
+        if (!op) {
+          return;
+        }
+        const tag = (op.tags && op.tags[0]) || "default";
+        if (!groups.has(tag)) {
+          groups.set(tag, []);
+        }
+        groups.get(tag).push(renderOperation(doc, path, method, op));
+      });
+    });
+
+    groups.forEach(function (operations, tag) {
+      const section = el("section");
+      section.appendChild(el("h2", "", tag));
+      operations.forEach(function (op) {
+        section.appendChild(op);
+      });
+      root.appendChild(section);
+    });
+  }
+
+  fetch(root.dataset.specUrl, { headers: { Accept: "application/json" } })
+    .then(function (resp) {
+      if (!resp.ok) {
+        throw new Error("unexpected status " + resp.status);
+      }
+      return resp.json();
+    })
+    .then(render)
+    .catch(function (err) {
+      root.replaceChildren(el("p", "status error", "Failed to load " + root.dataset.specUrl + ": " + err.message));
+    });
+})();
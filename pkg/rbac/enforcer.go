@@ -0,0 +1,153 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"go.uber.org/zap"
+)
+
+const DefaultReloadInterval = time.Minute
+
+// ErrorResponseWriter writes the response of a denied request, e.g. httpx.ErrorRegistry.WriteError.
+type ErrorResponseWriter func(err error, w http.ResponseWriter, r *http.Request)
+
+// Enforcer checks permissions of the auth.Principal in the context against a Policy.
+//
+// The policy is loaded on first use and reloaded once the reload interval elapses,
+// the last loaded policy is kept when a reload fails. Denials are audit logged.
+type Enforcer struct {
+	logger      *zap.Logger
+	auditLogger *zap.Logger
+	loader      PolicyLoader
+	errWriter   ErrorResponseWriter
+
+	reloadInterval time.Duration
+	now            func() time.Time
+
+	mu       sync.Mutex
+	policy   *Policy
+	loadedAt time.Time
+}
+
+type Option func(*Enforcer)
+
+// WithReloadInterval overrides DefaultReloadInterval.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(e *Enforcer) {
+		e.reloadInterval = interval
+	}
+}
+
+func NewEnforcer(logger *zap.Logger, loader PolicyLoader, errWriter ErrorResponseWriter, options ...Option) *Enforcer {
+	e := &Enforcer{
+		logger:         logger,
+		auditLogger:    logger.Named("audit"),
+		loader:         loader,
+		errWriter:      errWriter,
+		reloadInterval: DefaultReloadInterval,
+		now:            time.Now,
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	return e
+}
+
+// Check returns nil when the principal holds every permission of perms, granted by its roles or directly.
+// Returns errorx.ErrUnauthorized without a principal and errorx.ErrForbidden when denied.
+func (e *Enforcer) Check(ctx context.Context, perms ...Permission) error {
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return auth.ErrMissingToken
+	}
+
+	policy, err := e.currentPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, perm := range perms {
+		if !policy.Allows(p.Roles, perm) && !slices.Contains(p.Permissions, string(perm)) {
+			e.audit(ctx, p, perm)
+			return fmt.Errorf("%w: missing permission %q", errorx.ErrForbidden, perm)
+		}
+	}
+	return nil
+}
+
+// RequirePermission rejects requests of principals not holding every permission of perms.
+// It must be applied after auth.Middleware.
+func (e *Enforcer) RequirePermission(perms ...Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := e.Check(r.Context(), perms...); err != nil {
+				e.errWriter(err, w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (e *Enforcer) audit(ctx context.Context, p auth.Principal, perm Permission) {
+	logx.FromContext(ctx, e.auditLogger).Warn("permission denied",
+		zap.String("subject", p.Subject),
+		zap.Strings("roles", p.Roles),
+		zap.Strings("permissions", p.Permissions),
+		zap.String("permission", string(perm)),
+	)
+}
+
+func (e *Enforcer) currentPolicy(ctx context.Context) (*Policy, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	if e.policy != nil && now.Sub(e.loadedAt) < e.reloadInterval {
+		return e.policy, nil
+	}
+
+	policy, err := e.loader(ctx)
+	if err != nil {
+		if e.policy == nil {
+			return nil, fmt.Errorf("failed to load policy: %w", err)
+		}
+		logx.FromContext(ctx, e.logger).Error("failed to reload policy, using last loaded policy", zap.Error(err))
+		// Retried after the next interval rather than on every check
+		e.loadedAt = now
+		return e.policy, nil
+	}
+
+	e.policy = policy
+	e.loadedAt = now
+	return policy, nil
+}
+
+// SubjectOrPermission extends base, granting access to resources of other subjects to holders of perm.
+// Denials of base other than errorx.ErrForbidden, e.g. unauthenticated, are returned as is.
+func SubjectOrPermission(base auth.Authorizer, e *Enforcer, perm Permission) auth.Authorizer {
+	return subjectOrPermission{base: base, enforcer: e, perm: perm}
+}
+
+type subjectOrPermission struct {
+	base     auth.Authorizer
+	enforcer *Enforcer
+	perm     Permission
+}
+
+func (a subjectOrPermission) AuthorizeSubject(ctx context.Context, subject string) error {
+	err := a.base.AuthorizeSubject(ctx, subject)
+	if err == nil || !errors.Is(err, errorx.ErrForbidden) {
+		return err
+	}
+	return a.enforcer.Check(ctx, a.perm)
+}
@@ -0,0 +1,158 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/pkg/auth"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var testPolicy = NewPolicy(map[string][]Permission{
+	"support": {"profiles:read_any"},
+})
+
+func noopErrWriter(error, http.ResponseWriter, *http.Request) {}
+
+func principalCtx(roles ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-1", Roles: roles})
+}
+
+func permissionsCtx(permissions ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{Subject: "apikey:1", Permissions: permissions})
+}
+
+func TestEnforcer_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		perms       []Permission
+		expectedErr error
+	}{
+		{name: "granted", ctx: principalCtx("support"), perms: []Permission{"profiles:read_any"}},
+		{name: "no permissions required", ctx: principalCtx()},
+		{name: "denied", ctx: principalCtx("support"), perms: []Permission{"profiles:erase"}, expectedErr: errorx.ErrForbidden},
+		{
+			name:        "all required",
+			ctx:         principalCtx("support"),
+			perms:       []Permission{"profiles:read_any", "profiles:erase"},
+			expectedErr: errorx.ErrForbidden,
+		},
+		{name: "anonymous", ctx: context.Background(), perms: []Permission{"profiles:read_any"}, expectedErr: errorx.ErrUnauthorized},
+		{name: "granted directly", ctx: permissionsCtx("profiles:erase"), perms: []Permission{"profiles:erase"}},
+		{
+			name:        "denied directly",
+			ctx:         permissionsCtx("profiles:read_any"),
+			perms:       []Permission{"profiles:erase"},
+			expectedErr: errorx.ErrForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEnforcer(zap.NewNop(), StaticLoader(testPolicy), noopErrWriter)
+
+			err := e.Check(tt.ctx, tt.perms...)
+
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestEnforcer_Check_AuditLog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	e := NewEnforcer(zap.New(core), StaticLoader(testPolicy), noopErrWriter)
+
+	require.NoError(t, e.Check(principalCtx("support"), "profiles:read_any"))
+	assert.Equal(t, 0, logs.Len())
+
+	require.Error(t, e.Check(principalCtx("support"), "profiles:erase"))
+	require.Equal(t, 1, logs.Len())
+
+	entry := logs.All()[0]
+	assert.Equal(t, "audit", entry.LoggerName)
+	assert.Equal(t, zapcore.WarnLevel, entry.Level)
+	fields := entry.ContextMap()
+	assert.Equal(t, "user-1", fields["subject"])
+	assert.Equal(t, "profiles:erase", fields["permission"])
+}
+
+func TestEnforcer_Reload(t *testing.T) {
+	loads := 0
+	var loadErr error
+	loader := func(context.Context) (*Policy, error) {
+		loads++
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return testPolicy, nil
+	}
+	now := time.Now()
+	e := NewEnforcer(zap.NewNop(), loader, noopErrWriter, WithReloadInterval(time.Minute))
+	e.now = func() time.Time { return now }
+
+	require.NoError(t, e.Check(principalCtx("support"), "profiles:read_any"))
+	require.NoError(t, e.Check(principalCtx("support"), "profiles:read_any"))
+	assert.Equal(t, 1, loads)
+
+	// Last loaded policy is kept when reloading fails
+	loadErr = errors.New("db unavailable")
+	now = now.Add(time.Minute)
+	require.NoError(t, e.Check(principalCtx("support"), "profiles:read_any"))
+	require.NoError(t, e.Check(principalCtx("support"), "profiles:read_any"))
+	assert.Equal(t, 2, loads)
+}
+
+func TestEnforcer_Check_LoadFailure(t *testing.T) {
+	loadErr := errors.New("db unavailable")
+	e := NewEnforcer(zap.NewNop(), func(context.Context) (*Policy, error) { return nil, loadErr }, noopErrWriter)
+
+	err := e.Check(principalCtx("support"), "profiles:read_any")
+
+	assert.ErrorIs(t, err, loadErr)
+	assert.NotErrorIs(t, err, errorx.ErrForbidden)
+}
+
+func TestEnforcer_RequirePermission(t *testing.T) {
+	var writtenErr error
+	errWriter := func(err error, w http.ResponseWriter, r *http.Request) {
+		writtenErr = err
+		w.WriteHeader(http.StatusForbidden)
+	}
+	e := NewEnforcer(zap.NewNop(), StaticLoader(testPolicy), errWriter)
+	handler := e.RequirePermission("profiles:read_any")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(principalCtx("support")))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(principalCtx("recruiter")))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.ErrorIs(t, writtenErr, errorx.ErrForbidden)
+}
+
+func TestSubjectOrPermission(t *testing.T) {
+	e := NewEnforcer(zap.NewNop(), StaticLoader(testPolicy), noopErrWriter)
+	authorizer := SubjectOrPermission(auth.NewSubjectAuthorizer(auth.DefaultAdminScope), e, "profiles:read_any")
+
+	assert.NoError(t, authorizer.AuthorizeSubject(principalCtx(), "user-1"))
+	assert.NoError(t, authorizer.AuthorizeSubject(principalCtx("support"), "user-2"))
+	assert.ErrorIs(t, authorizer.AuthorizeSubject(principalCtx("recruiter"), "user-2"), errorx.ErrForbidden)
+	assert.ErrorIs(t, authorizer.AuthorizeSubject(context.Background(), "user-2"), errorx.ErrUnauthorized)
+}
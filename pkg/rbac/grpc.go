@@ -0,0 +1,23 @@
+package rbac
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor rejects gRPC calls of principals not holding every permission required by their
+// full method in methods, as RequirePermission does requests. Methods not in methods are not checked.
+// It must run after auth.Middleware.UnaryServerInterceptor.
+func (e *Enforcer) UnaryServerInterceptor(methods map[string][]Permission) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		perms, ok := methods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		if err := e.Check(ctx, perms...); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,47 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestEnforcer_UnaryServerInterceptor(t *testing.T) {
+	e := NewEnforcer(zap.NewNop(), StaticLoader(testPolicy), noopErrWriter)
+	interceptor := e.UnaryServerInterceptor(map[string][]Permission{
+		"/svc/Read":  {"profiles:read_any"},
+		"/svc/Erase": {"profiles:erase"},
+	})
+
+	tests := []struct {
+		method      string
+		expectedErr error
+	}{
+		{method: "/svc/Read"},
+		{method: "/svc/Erase", expectedErr: errorx.ErrForbidden},
+		{method: "/svc/Unchecked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			called := false
+			_, err := interceptor(principalCtx("support"), nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(context.Context, any) (any, error) {
+					called = true
+					return nil, nil
+				})
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.False(t, called)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, called)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Permission e.g. "profiles:read_any", named "<resource>:<action>".
+type Permission string
+
+// PermissionAll grants every permission.
+const PermissionAll Permission = "*"
+
+// Policy maps roles to their permissions, it is immutable once created.
+type Policy struct {
+	roles map[string]map[Permission]struct{}
+}
+
+// NewPolicy creates a policy of permissions by role.
+func NewPolicy(roles map[string][]Permission) *Policy {
+	p := &Policy{roles: make(map[string]map[Permission]struct{}, len(roles))}
+	for role, permissions := range roles {
+		set := make(map[Permission]struct{}, len(permissions))
+		for _, perm := range permissions {
+			set[perm] = struct{}{}
+		}
+		p.roles[role] = set
+	}
+	return p
+}
+
+// Allows reports whether any of roles grants perm. Unknown roles grant nothing.
+func (p *Policy) Allows(roles []string, perm Permission) bool {
+	for _, role := range roles {
+		set := p.roles[role]
+		if _, ok := set[perm]; ok {
+			return true
+		}
+		if _, ok := set[PermissionAll]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePolicy parses a JSON policy of permissions by role, e.g.
+//
+//	{"roles": {"support": ["profiles:read_any"], "superadmin": ["*"]}}
+func ParsePolicy(data []byte) (*Policy, error) {
+	var doc struct {
+		Roles map[string][]Permission `json:"roles"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return NewPolicy(doc.Roles), nil
+}
+
+// PolicyLoader loads the current policy, e.g. from a file or the database.
+type PolicyLoader func(ctx context.Context) (*Policy, error)
+
+// FileLoader reads a JSON policy, see ParsePolicy, from path on every load.
+func FileLoader(path string) PolicyLoader {
+	return func(_ context.Context) (*Policy, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return ParsePolicy(data)
+	}
+}
+
+// StaticLoader always loads policy.
+func StaticLoader(policy *Policy) PolicyLoader {
+	return func(_ context.Context) (*Policy, error) {
+		return policy, nil
+	}
+}
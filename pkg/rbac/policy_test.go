@@ -0,0 +1,59 @@
+package rbac
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Allows(t *testing.T) {
+	policy := NewPolicy(map[string][]Permission{
+		"support":    {"profiles:read_any"},
+		"recruiter":  {"invitations:create"},
+		"superadmin": {PermissionAll},
+	})
+
+	tests := []struct {
+		name     string
+		roles    []string
+		perm     Permission
+		expected bool
+	}{
+		{name: "granted", roles: []string{"support"}, perm: "profiles:read_any", expected: true},
+		{name: "not granted", roles: []string{"support"}, perm: "profiles:erase"},
+		{name: "any role", roles: []string{"support", "recruiter"}, perm: "invitations:create", expected: true},
+		{name: "wildcard", roles: []string{"superadmin"}, perm: "profiles:erase", expected: true},
+		{name: "unknown role", roles: []string{"guest"}, perm: "profiles:read_any"},
+		{name: "no roles", perm: "profiles:read_any"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.Allows(tt.roles, tt.perm))
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`{"roles": {"support": ["profiles:read_any"]}}`))
+	require.NoError(t, err)
+	assert.True(t, policy.Allows([]string{"support"}, "profiles:read_any"))
+
+	_, err = ParsePolicy([]byte(`{"roles": ["support"]}`))
+	assert.Error(t, err)
+}
+
+func TestFileLoader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"roles": {"support": ["profiles:read_any"]}}`), 0o600))
+
+	policy, err := FileLoader(path)(t.Context())
+	require.NoError(t, err)
+	assert.True(t, policy.Allows([]string{"support"}, "profiles:read_any"))
+
+	_, err = FileLoader(filepath.Join(t.TempDir(), "missing.json"))(t.Context())
+	assert.Error(t, err)
+}
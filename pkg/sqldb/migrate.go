@@ -1,15 +1,22 @@
 package sqldb
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-const _currentMigrationVersion = 2
+const _currentMigrationVersion = 9
+
+var (
+	ErrMigrationPending = errors.New("database migrations pending")
+	ErrMigrationDirty   = errors.New("database migration failed, schema is dirty")
+)
 
 func RunMigration(db *sql.DB, migrationFileUrl *string) error {
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
@@ -37,3 +44,36 @@ func RunMigration(db *sql.DB, migrationFileUrl *string) error {
 
 	return nil
 }
+
+// CheckMigration returns ErrMigrationPending until the schema is migrated to the version applied by RunMigration,
+// e.g. while migrations are applied by another instance, and ErrMigrationDirty once a migration failed.
+// Newer versions applied by a later release are accepted.
+func CheckMigration(ctx context.Context, q Queryable) error {
+	rows, err := q.QueryContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1")
+	if err != nil {
+		return fmt.Errorf("failed to query migration version: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to query migration version: %w", err)
+		}
+		return ErrMigrationPending
+	}
+
+	var version int64
+	var dirty bool
+	err = rows.Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("failed to scan migration version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("%w: version %d", ErrMigrationDirty, version)
+	}
+	if version < _currentMigrationVersion {
+		return fmt.Errorf("%w: version %d of %d", ErrMigrationPending, version, _currentMigrationVersion)
+	}
+	return nil
+}
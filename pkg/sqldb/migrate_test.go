@@ -0,0 +1,73 @@
+package sqldb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMigration(t *testing.T) {
+	errQuery := errors.New("relation \"schema_migrations\" does not exist")
+
+	tests := []struct {
+		name     string
+		rows     *sqlmock.Rows
+		queryErr error
+		expected error
+	}{
+		{
+			name: "migrated",
+			rows: sqlmock.NewRows([]string{"version", "dirty"}).AddRow(_currentMigrationVersion, false),
+		},
+		{
+			name: "newer version",
+			rows: sqlmock.NewRows([]string{"version", "dirty"}).AddRow(_currentMigrationVersion+1, false),
+		},
+		{
+			name:     "older version",
+			rows:     sqlmock.NewRows([]string{"version", "dirty"}).AddRow(_currentMigrationVersion-1, false),
+			expected: ErrMigrationPending,
+		},
+		{
+			name:     "no version",
+			rows:     sqlmock.NewRows([]string{"version", "dirty"}),
+			expected: ErrMigrationPending,
+		},
+		{
+			name:     "dirty",
+			rows:     sqlmock.NewRows([]string{"version", "dirty"}).AddRow(_currentMigrationVersion, true),
+			expected: ErrMigrationDirty,
+		},
+		{
+			name:     "query error",
+			queryErr: errQuery,
+			expected: errQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			query := mock.ExpectQuery("SELECT version, dirty FROM schema_migrations")
+			if tt.queryErr != nil {
+				query.WillReturnError(tt.queryErr)
+			} else {
+				query.WillReturnRows(tt.rows)
+			}
+
+			err = CheckMigration(t.Context(), db)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
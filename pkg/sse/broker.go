@@ -0,0 +1,262 @@
+package sse
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultReplaySize       = 256
+	DefaultSubscriberBuffer = 32
+)
+
+var (
+	// ErrClosed ends subscriptions when the broker is closed, e.g. on server shutdown.
+	ErrClosed = errors.New("event broker closed")
+	// ErrSlowSubscriber ends subscriptions whose buffer is full, clients resume from the replay buffer.
+	ErrSlowSubscriber = errors.New("event subscriber too slow")
+)
+
+// entry of the replay buffer.
+type entry struct {
+	topic string
+	seq   uint64
+	event Event
+}
+
+// Broker fans out events published to a topic to its subscribers, within a single process.
+//
+// Published events are kept in a bounded replay buffer shared by all topics, allowing clients to resume
+// from the Last-Event-ID header after reconnecting. Publishing never blocks, subscribers whose buffer is
+// full are ended with ErrSlowSubscriber and resume once they reconnect, as long as the events they missed
+// are still in the replay buffer.
+type Broker struct {
+	mu sync.Mutex
+	// epoch identifies the broker in event IDs, IDs of another broker or process are not resumed
+	epoch  string
+	seq    uint64
+	replay []entry
+	// start index of the oldest entry once replay is full
+	start int
+	// topics subscriptions per topic
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+
+	replaySize       int
+	subscriberBuffer int
+	metrics          MetricsRecorder
+}
+
+type BrokerOption func(*Broker)
+
+// WithReplaySize overrides DefaultReplaySize, the number of latest events of all topics kept for resuming.
+func WithReplaySize(n int) BrokerOption {
+	return func(b *Broker) {
+		b.replaySize = n
+	}
+}
+
+// WithSubscriberBuffer overrides DefaultSubscriberBuffer, the number of events queued for a subscriber
+// before it is ended with ErrSlowSubscriber.
+func WithSubscriberBuffer(n int) BrokerOption {
+	return func(b *Broker) {
+		b.subscriberBuffer = n
+	}
+}
+
+func WithMetrics(metrics MetricsRecorder) BrokerOption {
+	return func(b *Broker) {
+		b.metrics = metrics
+	}
+}
+
+func NewBroker(options ...BrokerOption) *Broker {
+	epoch := make([]byte, 4)
+	_, _ = rand.Read(epoch)
+
+	b := &Broker{
+		epoch:            hex.EncodeToString(epoch),
+		topics:           make(map[string]map[*Subscription]struct{}),
+		replaySize:       DefaultReplaySize,
+		subscriberBuffer: DefaultSubscriberBuffer,
+		metrics:          noopMetrics{},
+	}
+	for _, opt := range options {
+		opt(b)
+	}
+	b.replay = make([]entry, 0, b.replaySize)
+	return b
+}
+
+// Publish sends an event of eventType to the subscribers of topic, returns the event with its assigned ID.
+// Events published once the broker is closed are dropped.
+func (b *Broker) Publish(topic string, eventType string, data []byte) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Event{Type: eventType, Data: data}
+	}
+
+	b.seq++
+	e := Event{ID: b.epoch + "-" + strconv.FormatUint(b.seq, 10), Type: eventType, Data: data}
+	b.addReplay(entry{topic: topic, seq: b.seq, event: e})
+
+	for sub := range b.topics[topic] {
+		select {
+		case sub.events <- e:
+		default:
+			b.end(sub, ErrSlowSubscriber)
+		}
+	}
+	return e
+}
+
+// Subscribe to events of topic published from now on. When lastEventID is set, events of topic published
+// after it are replayed first, see Subscription.Replay, unless it cannot be resumed, see Subscription.Missed.
+// Returns ErrClosed once the broker is closed.
+func (b *Broker) Subscribe(topic string, lastEventID string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	sub := &Subscription{broker: b, topic: topic, events: make(chan Event, b.subscriberBuffer)}
+	if lastEventID != "" {
+		sub.replay, sub.missed = b.replaySince(topic, lastEventID)
+	}
+
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[*Subscription]struct{})
+	}
+	b.topics[topic][sub] = struct{}{}
+	b.metrics.RecordSSESubscribers(1)
+
+	return sub, nil
+}
+
+// Close ends all subscriptions with ErrClosed and rejects new ones, e.g. on server shutdown,
+// allowing streams to end before connections are drained. Clients reconnect to another instance.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subs := range b.topics {
+		for sub := range subs {
+			b.end(sub, ErrClosed)
+		}
+	}
+}
+
+func (b *Broker) addReplay(e entry) {
+	if b.replaySize <= 0 {
+		return
+	}
+	if len(b.replay) < b.replaySize {
+		b.replay = append(b.replay, e)
+		return
+	}
+	b.replay[b.start] = e
+	b.start = (b.start + 1) % b.replaySize
+}
+
+// replaySince returns the events of topic published after lastEventID,
+// missed when events published since may no longer be in the replay buffer.
+func (b *Broker) replaySince(topic string, lastEventID string) ([]Event, bool) {
+	epoch, seqStr, ok := strings.Cut(lastEventID, "-")
+	if !ok || epoch != b.epoch {
+		return nil, true
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil || seq > b.seq {
+		return nil, true
+	}
+	// Sequences of the replay buffer are contiguous, as every published event is added
+	oldest := b.seq - uint64(len(b.replay)) + 1
+	if seq+1 < oldest {
+		return nil, true
+	}
+
+	var events []Event
+	for i := range b.replay {
+		e := b.replay[(b.start+i)%len(b.replay)]
+		if e.seq > seq && e.topic == topic {
+			events = append(events, e.event)
+		}
+	}
+	return events, false
+}
+
+// end removes sub and closes its events with err, a no-op when already ended.
+func (b *Broker) end(sub *Subscription, err error) {
+	subs, ok := b.topics[sub.topic]
+	if !ok {
+		return
+	}
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.topics, sub.topic)
+	}
+	sub.err = err
+	close(sub.events)
+
+	b.metrics.RecordSSESubscribers(-1)
+	if err != nil {
+		b.metrics.RecordSSESubscriberEnded(reason(err))
+	}
+}
+
+// Subscription to events of a topic, see Broker.Subscribe.
+type Subscription struct {
+	broker *Broker
+	topic  string
+	events chan Event
+	replay []Event
+	missed bool
+	// err ending the subscription, guarded by the broker
+	err error
+}
+
+// Events receives events published after the subscription, closed once the subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Replay returns the events published after the last event ID of Broker.Subscribe,
+// to be sent before Events.
+func (s *Subscription) Replay() []Event {
+	return s.replay
+}
+
+// Missed reports whether events published after the last event ID of Broker.Subscribe
+// could not be replayed, e.g. the replay buffer moved past it or it was issued by another process.
+// Clients should then reload their state.
+func (s *Subscription) Missed() bool {
+	return s.missed
+}
+
+// Err returns the error ending the subscription once Events is closed,
+// ErrSlowSubscriber or ErrClosed, nil when ended by Close.
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+// Close ends the subscription, e.g. when the client disconnects.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.end(s, nil)
+}
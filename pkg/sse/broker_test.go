@@ -0,0 +1,139 @@
+package sse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case e, ok := <-sub.Events():
+		require.True(t, ok, "subscription ended")
+		return e
+	default:
+		require.FailNow(t, "no event received")
+		return Event{}
+	}
+}
+
+func TestBroker_Publish(t *testing.T) {
+	b := NewBroker()
+	a1, err := b.Subscribe("a", "")
+	require.NoError(t, err)
+	a2, err := b.Subscribe("a", "")
+	require.NoError(t, err)
+	other, err := b.Subscribe("b", "")
+	require.NoError(t, err)
+
+	published := b.Publish("a", "changed", []byte(`{}`))
+	assert.NotEmpty(t, published.ID)
+
+	assert.Equal(t, published, receive(t, a1))
+	assert.Equal(t, published, receive(t, a2))
+	assert.Empty(t, other.Events())
+	assert.False(t, a1.Missed())
+	assert.Empty(t, a1.Replay())
+}
+
+func TestBroker_Subscribe_Resume(t *testing.T) {
+	b := NewBroker(WithReplaySize(4))
+	first := b.Publish("a", "changed", []byte("1"))
+	b.Publish("b", "changed", []byte("2"))
+	third := b.Publish("a", "changed", []byte("3"))
+
+	sub, err := b.Subscribe("a", first.ID)
+	require.NoError(t, err)
+	assert.False(t, sub.Missed())
+	assert.Equal(t, []Event{third}, sub.Replay())
+
+	sub, err = b.Subscribe("a", third.ID)
+	require.NoError(t, err)
+	assert.False(t, sub.Missed())
+	assert.Empty(t, sub.Replay())
+}
+
+func TestBroker_Subscribe_Missed(t *testing.T) {
+	b := NewBroker(WithReplaySize(2))
+	first := b.Publish("a", "changed", []byte("1"))
+	second := b.Publish("a", "changed", []byte("2"))
+	third := b.Publish("a", "changed", []byte("3"))
+	fourth := b.Publish("a", "changed", []byte("4"))
+
+	sub, err := b.Subscribe("a", second.ID)
+	require.NoError(t, err)
+	assert.False(t, sub.Missed(), "events after the last event ID are still buffered")
+	assert.Equal(t, []Event{third, fourth}, sub.Replay())
+
+	tests := []struct {
+		name        string
+		lastEventID string
+	}{
+		{name: "evicted from replay buffer", lastEventID: first.ID},
+		{name: "other broker", lastEventID: NewBroker().Publish("a", "changed", nil).ID},
+		{name: "ahead of broker", lastEventID: b.epoch + "-100"},
+		{name: "malformed", lastEventID: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := b.Subscribe("a", tt.lastEventID)
+			require.NoError(t, err)
+			assert.True(t, sub.Missed())
+			assert.Empty(t, sub.Replay())
+		})
+	}
+}
+
+func TestBroker_SlowSubscriber(t *testing.T) {
+	b := NewBroker(WithSubscriberBuffer(1))
+	slow, err := b.Subscribe("a", "")
+	require.NoError(t, err)
+
+	first := b.Publish("a", "changed", []byte("1"))
+	b.Publish("a", "changed", []byte("2"))
+
+	assert.Equal(t, first, receive(t, slow))
+	_, ok := <-slow.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, slow.Err(), ErrSlowSubscriber)
+
+	// Resumes from the replay buffer once reconnected
+	resumed, err := b.Subscribe("a", first.ID)
+	require.NoError(t, err)
+	assert.Len(t, resumed.Replay(), 1)
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := NewBroker()
+	sub, err := b.Subscribe("a", "")
+	require.NoError(t, err)
+
+	b.Close()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, sub.Err(), ErrClosed)
+
+	_, err = b.Subscribe("a", "")
+	assert.ErrorIs(t, err, ErrClosed)
+
+	// Ending an ended subscription is a no-op
+	sub.Close()
+	assert.ErrorIs(t, sub.Err(), ErrClosed)
+}
+
+func TestSubscription_Close(t *testing.T) {
+	b := NewBroker()
+	sub, err := b.Subscribe("a", "")
+	require.NoError(t, err)
+
+	sub.Close()
+	b.Publish("a", "changed", nil)
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.NoError(t, sub.Err())
+	assert.Empty(t, b.topics)
+}
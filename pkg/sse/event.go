@@ -0,0 +1,51 @@
+package sse
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event of a stream, see https://html.spec.whatwg.org/multipage/server-sent-events.html.
+type Event struct {
+	// ID assigned by Broker.Publish, sent back by clients in the Last-Event-ID header when reconnecting.
+	ID string
+	// Type dispatched to listeners of EventSource.addEventListener, "message" when empty.
+	Type string
+	Data []byte
+}
+
+// encode writes e in the event stream format, data spanning several lines is split into data fields.
+func (e Event) encode(w io.Writer) error {
+	var b bytes.Buffer
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Type != "" {
+		b.WriteString("event: " + e.Type + "\n")
+	}
+	for line := range strings.Lines(string(e.Data)) {
+		b.WriteString("data: " + strings.TrimRight(line, "\r\n") + "\n")
+	}
+	if len(e.Data) == 0 {
+		b.WriteString("data\n")
+	}
+	b.WriteString("\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// writeRetry advises clients of the delay before reconnecting.
+func writeRetry(w io.Writer, d time.Duration) error {
+	_, err := io.WriteString(w, "retry: "+strconv.FormatInt(d.Milliseconds(), 10)+"\n\n")
+	return err
+}
+
+// writeComment is ignored by clients, keeping idle connections open through proxies.
+func writeComment(w io.Writer, comment string) error {
+	_, err := io.WriteString(w, ": "+comment+"\n\n")
+	return err
+}
@@ -0,0 +1,41 @@
+package sse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_Encode(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			name:     "all fields",
+			event:    Event{ID: "e-1", Type: "changed", Data: []byte(`{"a":1}`)},
+			expected: "id: e-1\nevent: changed\ndata: {\"a\":1}\n\n",
+		},
+		{
+			name:     "multiple lines",
+			event:    Event{Data: []byte("a\r\nb\nc")},
+			expected: "data: a\ndata: b\ndata: c\n\n",
+		},
+		{
+			name:     "no data",
+			event:    Event{Type: EventTypeReset},
+			expected: "event: reset\ndata\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, tt.event.encode(&b))
+			assert.Equal(t, tt.expected, b.String())
+		})
+	}
+}
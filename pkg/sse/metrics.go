@@ -0,0 +1,30 @@
+package sse
+
+import "errors"
+
+// Reasons of subscriptions ended by the broker, see MetricsRecorder.
+const (
+	ReasonSlow   = "slow"
+	ReasonClosed = "closed"
+)
+
+// MetricsRecorder records broker metrics.
+// Implemented by monitoring.Metrics.
+type MetricsRecorder interface {
+	// RecordSSESubscribers adds delta to the number of open subscriptions.
+	RecordSSESubscribers(delta int)
+	// RecordSSESubscriberEnded records a subscription ended by the broker rather than the client.
+	RecordSSESubscriberEnded(reason string)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) RecordSSESubscribers(int)        {}
+func (noopMetrics) RecordSSESubscriberEnded(string) {}
+
+func reason(err error) string {
+	if errors.Is(err, ErrSlowSubscriber) {
+		return ReasonSlow
+	}
+	return ReasonClosed
+}
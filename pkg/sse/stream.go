@@ -0,0 +1,143 @@
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// HeaderKeyLastEventID sent by clients reconnecting, see Broker.Subscribe.
+	HeaderKeyLastEventID = "Last-Event-ID"
+
+	// EventTypeReset sent first when events may have been missed, see Subscription.Missed.
+	EventTypeReset = "reset"
+
+	DefaultHeartbeat    = 15 * time.Second
+	DefaultRetry        = 3 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+)
+
+type streamConfig struct {
+	heartbeat    time.Duration
+	retry        time.Duration
+	writeTimeout time.Duration
+}
+
+type StreamOption func(*streamConfig)
+
+// WithHeartbeat overrides DefaultHeartbeat, the interval of comments keeping idle connections open.
+func WithHeartbeat(d time.Duration) StreamOption {
+	return func(c *streamConfig) {
+		c.heartbeat = d
+	}
+}
+
+// WithRetry overrides DefaultRetry, the delay advised to clients before reconnecting.
+func WithRetry(d time.Duration) StreamOption {
+	return func(c *streamConfig) {
+		c.retry = d
+	}
+}
+
+// WithWriteTimeout overrides DefaultWriteTimeout, clients not reading within it are disconnected.
+func WithWriteTimeout(d time.Duration) StreamOption {
+	return func(c *streamConfig) {
+		c.writeTimeout = d
+	}
+}
+
+// Stream writes the events of sub as an event stream until the request context is done or sub ends,
+// sub is closed on return. An EventTypeReset event is sent first when sub missed events,
+// followed by replayed events.
+//
+// The read deadline of the server is cleared as the stream outlives it, each write is bounded by the write
+// timeout instead. Returns nil once the client disconnects, the error of sub when ended by the broker,
+// e.g. ErrClosed on shutdown, or the error of a failed write.
+func Stream(w http.ResponseWriter, r *http.Request, sub *Subscription, options ...StreamOption) error {
+	defer sub.Close()
+
+	config := streamConfig{
+		heartbeat:    DefaultHeartbeat,
+		retry:        DefaultRetry,
+		writeTimeout: DefaultWriteTimeout,
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+
+	rc := http.NewResponseController(w)
+	err := rc.SetReadDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disables response buffering of reverse proxies such as nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sw := &streamWriter{rc: rc, timeout: config.writeTimeout}
+	// The end of the response is written once the handler returns, after an idle period the deadline has passed
+	defer func() { _ = rc.SetWriteDeadline(time.Now().Add(config.writeTimeout)) }()
+
+	sw.write(func() error { return writeRetry(w, config.retry) })
+	if sub.Missed() {
+		sw.write(func() error { return Event{Type: EventTypeReset}.encode(w) })
+	}
+	for _, e := range sub.Replay() {
+		sw.write(func() error { return e.encode(w) })
+	}
+	sw.flush()
+	if sw.err != nil {
+		return sw.err
+	}
+
+	heartbeat := time.NewTicker(config.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case e, ok := <-sub.Events():
+			if !ok {
+				return sub.Err()
+			}
+			sw.write(func() error { return e.encode(w) })
+		case <-heartbeat.C:
+			sw.write(func() error { return writeComment(w, "heartbeat") })
+		}
+		sw.flush()
+		if sw.err != nil {
+			return sw.err
+		}
+	}
+}
+
+// streamWriter bounds writes by a deadline, keeping the first error.
+type streamWriter struct {
+	rc      *http.ResponseController
+	timeout time.Duration
+	err     error
+}
+
+func (sw *streamWriter) write(fn func() error) {
+	if sw.err != nil {
+		return
+	}
+	err := sw.rc.SetWriteDeadline(time.Now().Add(sw.timeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		sw.err = err
+		return
+	}
+	sw.err = fn()
+}
+
+func (sw *streamWriter) flush() {
+	if sw.err != nil {
+		return
+	}
+	sw.err = sw.rc.Flush()
+}
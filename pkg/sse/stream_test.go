@@ -0,0 +1,123 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamServer streams topic "a" of b, the result of Stream is sent to done.
+func streamServer(t *testing.T, b *Broker, done chan<- error, options ...StreamOption) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub, err := b.Subscribe("a", r.Header.Get(HeaderKeyLastEventID))
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		done <- Stream(w, r, sub, options...)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func openStream(t *testing.T, ctx context.Context, url string, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set(HeaderKeyLastEventID, lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// readBlock reads lines up to the blank line ending an event or comment.
+func readBlock(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestStream(t *testing.T) {
+	b := NewBroker()
+	done := make(chan error, 2)
+	server := streamServer(t, b, done, WithRetry(time.Second))
+
+	resp, body := openStream(t, t.Context(), server.URL, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "retry: 1000\n", readBlock(t, body))
+
+	published := b.Publish("a", "changed", []byte(`{}`))
+	assert.Equal(t, "id: "+published.ID+"\nevent: changed\ndata: {}\n", readBlock(t, body))
+
+	b.Close()
+	assert.ErrorIs(t, <-done, ErrClosed)
+}
+
+func TestStream_Resume(t *testing.T) {
+	b := NewBroker()
+	done := make(chan error, 2)
+	server := streamServer(t, b, done)
+
+	first := b.Publish("a", "changed", []byte("1"))
+	second := b.Publish("a", "changed", []byte("2"))
+
+	_, body := openStream(t, t.Context(), server.URL, first.ID)
+	readBlock(t, body)
+	assert.Equal(t, "id: "+second.ID+"\nevent: changed\ndata: 2\n", readBlock(t, body))
+
+	_, body = openStream(t, t.Context(), server.URL, "unknown-1")
+	readBlock(t, body)
+	assert.Equal(t, "event: reset\ndata\n", readBlock(t, body))
+}
+
+func TestStream_Heartbeat(t *testing.T) {
+	b := NewBroker()
+	done := make(chan error, 2)
+	server := streamServer(t, b, done, WithHeartbeat(10*time.Millisecond))
+
+	_, body := openStream(t, t.Context(), server.URL, "")
+	readBlock(t, body)
+	assert.Equal(t, ": heartbeat\n", readBlock(t, body))
+}
+
+func TestStream_ClientDisconnected(t *testing.T) {
+	b := NewBroker()
+	done := make(chan error, 2)
+	server := streamServer(t, b, done)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	_, body := openStream(t, ctx, server.URL, "")
+	readBlock(t, body)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "stream did not end")
+	}
+	assert.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.topics) == 0
+	}, time.Second, 5*time.Millisecond)
+}
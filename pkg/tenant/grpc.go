@@ -0,0 +1,24 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/dyxj/bigbackend/pkg/grpcx"
+	"google.golang.org/grpc"
+)
+
+// MetadataKey names the tenant of gRPC calls, the lowercase HeaderKey.
+const MetadataKey = "x-tenant-id"
+
+// UnaryServerInterceptor resolves the tenant of gRPC calls into the context as Middleware does, from claim
+// and MetadataKey. Rejections are returned as errors, e.g. mapped by grpcx.ErrorRegistry.
+func UnaryServerInterceptor(claim func(ctx context.Context) string, options ...Option) grpc.UnaryServerInterceptor {
+	res := newResolver(claim, options)
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id, err := res.resolve(ctx, grpcx.MetadataValue(ctx, MetadataKey))
+		if err != nil {
+			return nil, err
+		}
+		return handler(WithID(ctx, id), req)
+	}
+}
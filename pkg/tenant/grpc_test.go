@@ -0,0 +1,61 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name        string
+		claim       func(ctx context.Context) string
+		options     []Option
+		md          metadata.MD
+		expected    uuid.UUID
+		expectedErr error
+	}{
+		{name: "metadata", md: metadata.Pairs(MetadataKey, tenantA.String()), expected: tenantA},
+		{name: "claim", claim: claimOf(tenantB.String()), md: metadata.MD{}, expected: tenantB},
+		{
+			name:        "mismatch",
+			claim:       claimOf(tenantB.String()),
+			md:          metadata.Pairs(MetadataKey, tenantA.String()),
+			expectedErr: errorx.ErrForbidden,
+		},
+		{name: "required", md: metadata.MD{}, expectedErr: ErrRequired},
+		{name: "invalid", md: metadata.Pairs(MetadataKey, "not-a-uuid"), expectedErr: ErrInvalid},
+		{
+			name:        "unclaimed",
+			options:     []Option{WithHeaderGuard(func(context.Context) bool { return false })},
+			md:          metadata.Pairs(MetadataKey, tenantA.String()),
+			expectedErr: ErrUnclaimed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(t.Context(), tt.md)
+
+			var resolved uuid.UUID
+			_, err := UnaryServerInterceptor(tt.claim, tt.options...)(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+				id, ok := FromContext(ctx)
+				require.True(t, ok)
+				resolved = id
+				return nil, nil
+			})
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
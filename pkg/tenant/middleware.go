@@ -0,0 +1,100 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// HeaderKey names the tenant of callers without a tenant claim, e.g. operators acting on behalf of tenants.
+const HeaderKey = "X-Tenant-Id"
+
+type resolver struct {
+	claim       func(ctx context.Context) string
+	headerGuard func(ctx context.Context) bool
+}
+
+type Option func(*resolver)
+
+// WithHeaderGuard rejects tenants named by HeaderKey without a claim with ErrUnclaimed, unless guard
+// returns true, e.g. for principals allowed to act on behalf of any tenant. Without a guard the header is trusted.
+func WithHeaderGuard(guard func(ctx context.Context) bool) Option {
+	return func(r *resolver) {
+		r.headerGuard = guard
+	}
+}
+
+func newResolver(claim func(ctx context.Context) string, options []Option) resolver {
+	r := resolver{claim: claim}
+	for _, opt := range options {
+		opt(&r)
+	}
+	return r
+}
+
+// Middleware resolves the tenant of requests into the context, see FromContext.
+//
+// The tenant claimed by the credentials, returned by claim e.g. auth.PrincipalTenant, takes precedence
+// over HeaderKey, a header naming another tenant is rejected with ErrMismatch. A nil claim resolves
+// the header only. Requests without a tenant are rejected with ErrRequired, and malformed tenants
+// with ErrInvalid, rendered with errWriter e.g. ErrorRegistry.WriteError.
+func Middleware(
+	claim func(ctx context.Context) string,
+	errWriter func(err error, w http.ResponseWriter, r *http.Request),
+	options ...Option,
+) func(http.Handler) http.Handler {
+	res := newResolver(claim, options)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := res.resolve(r.Context(), r.Header.Get(HeaderKey))
+			if err != nil {
+				errWriter(err, w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithID(r.Context(), id)))
+		})
+	}
+}
+
+// resolve the tenant of ctx from the value of HeaderKey and the claim, see Middleware.
+func (res resolver) resolve(ctx context.Context, headerValue string) (uuid.UUID, error) {
+	header, err := parse(headerValue)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	claimed := uuid.Nil
+	if res.claim != nil {
+		claimed, err = parse(res.claim(ctx))
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	switch {
+	case claimed != uuid.Nil && header != uuid.Nil && claimed != header:
+		return uuid.Nil, ErrMismatch
+	case claimed != uuid.Nil:
+		return claimed, nil
+	case header != uuid.Nil && res.headerGuard != nil && !res.headerGuard(ctx):
+		return uuid.Nil, ErrUnclaimed
+	case header != uuid.Nil:
+		return header, nil
+	default:
+		return uuid.Nil, ErrRequired
+	}
+}
+
+// parse returns uuid.Nil for empty values, the nil UUID is not a valid tenant.
+func parse(value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, ErrInvalid
+	}
+	return id, nil
+}
@@ -0,0 +1,100 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	tenantA = uuid.MustParse("6f1c1b52-4bb4-4a8e-9d9e-0b6a3cf1d9f1")
+	tenantB = uuid.MustParse("0c7e5a55-3f8e-4d0e-8f4a-2b9d1e6c7a10")
+)
+
+func claimOf(value string) func(ctx context.Context) string {
+	return func(context.Context) string { return value }
+}
+
+// serve returns the tenant seen by the handler, or the error written by the middleware.
+func serve(t *testing.T, claim func(ctx context.Context) string, header string, options ...Option) (uuid.UUID, error) {
+	t.Helper()
+	var resolved uuid.UUID
+	var written error
+
+	handler := Middleware(claim, func(err error, w http.ResponseWriter, r *http.Request) {
+		written = err
+		w.WriteHeader(http.StatusBadRequest)
+	}, options...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := FromContext(r.Context())
+		require.True(t, ok)
+		resolved = id
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		r.Header.Set(HeaderKey, header)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return resolved, written
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		claim   func(ctx context.Context) string
+		header  string
+		want    uuid.UUID
+		wantErr error
+	}{
+		{name: "claim", claim: claimOf(tenantA.String()), want: tenantA},
+		{name: "header without claim", claim: claimOf(""), header: tenantB.String(), want: tenantB},
+		{name: "header with nil claim", header: tenantB.String(), want: tenantB},
+		{name: "header matching claim", claim: claimOf(tenantA.String()), header: tenantA.String(), want: tenantA},
+		{name: "header mismatching claim", claim: claimOf(tenantA.String()), header: tenantB.String(), wantErr: ErrMismatch},
+		{name: "missing", claim: claimOf(""), wantErr: ErrRequired},
+		{name: "malformed header", header: "tenant-a", wantErr: ErrInvalid},
+		{name: "malformed claim", claim: claimOf("tenant-a"), wantErr: ErrInvalid},
+		{name: "nil tenant", header: uuid.Nil.String(), wantErr: ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := serve(t, tt.claim, tt.header)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMiddleware_HeaderGuard(t *testing.T) {
+	deny := WithHeaderGuard(func(context.Context) bool { return false })
+	allow := WithHeaderGuard(func(context.Context) bool { return true })
+
+	_, err := serve(t, claimOf(""), tenantB.String(), deny)
+	assert.ErrorIs(t, err, ErrUnclaimed)
+	assert.ErrorIs(t, err, errorx.ErrForbidden)
+
+	got, err := serve(t, claimOf(""), tenantB.String(), allow)
+	require.NoError(t, err)
+	assert.Equal(t, tenantB, got)
+
+	// Claims are not guarded
+	got, err = serve(t, claimOf(tenantA.String()), tenantA.String(), deny)
+	require.NoError(t, err)
+	assert.Equal(t, tenantA, got)
+}
+
+func TestErrMismatch_IsForbidden(t *testing.T) {
+	assert.True(t, errors.Is(ErrMismatch, errorx.ErrForbidden))
+}
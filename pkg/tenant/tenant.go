@@ -0,0 +1,55 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/google/uuid"
+)
+
+var ErrRequired = errors.New("tenant is required")
+var ErrInvalid = errors.New("invalid tenant")
+
+// ErrMismatch wraps errorx.ErrForbidden, returned when the header names a tenant other than the claimed one.
+var ErrMismatch = fmt.Errorf("%w: tenant does not match credentials", errorx.ErrForbidden)
+
+// ErrUnclaimed wraps errorx.ErrForbidden, returned when the tenant is named by the header only and the caller
+// may not act on behalf of other tenants, see WithHeaderGuard.
+var ErrUnclaimed = fmt.Errorf("%w: tenant is not claimed by credentials", errorx.ErrForbidden)
+
+type contextKey struct{}
+type allContextKey struct{}
+
+// WithID sets the tenant of the request into the context.
+func WithID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant set by WithID, false if not set.
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(contextKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// Require returns the tenant set by WithID, ErrRequired if not set.
+func Require(ctx context.Context) (uuid.UUID, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return uuid.Nil, ErrRequired
+	}
+	return id, nil
+}
+
+// WithAll marks ctx of system jobs processing rows of every tenant, e.g. dispatching webhooks.
+// Only tables whose row level security policies honour AllSettingKey are opened up.
+func WithAll(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allContextKey{}, true)
+}
+
+// IsAll reports whether ctx was marked by WithAll.
+func IsAll(ctx context.Context) bool {
+	all, _ := ctx.Value(allContextKey{}).(bool)
+	return all
+}
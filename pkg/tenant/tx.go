@@ -0,0 +1,52 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SettingKey of the PostgreSQL setting compared against tenant_id by row level security policies.
+const SettingKey = "app.tenant_id"
+
+// AllSettingKey of the PostgreSQL setting granting transactions of WithAll contexts access to every tenant,
+// honoured only by policies of tables processed across tenants.
+const AllSettingKey = "app.tenant_all"
+
+// TxManager begins transactions scoped to the tenant of the context, implements sqldb.TransactionManager.
+//
+// The tenant is set with set_config is_local, equivalent to SET LOCAL, so it is discarded
+// when the transaction ends and never leaks to other requests sharing the pooled connection.
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// BeginTx returns ErrRequired when ctx has neither a tenant nor WithAll,
+// row level security would otherwise hide every row.
+func (m *TxManager) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	key, value := AllSettingKey, "on"
+	if !IsAll(ctx) {
+		id, err := Require(ctx)
+		if err != nil {
+			return nil, err
+		}
+		key, value = SettingKey, id.String()
+	}
+
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "SELECT set_config($1, $2, true)", key, value)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to set tenant: %w", err)
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,75 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxManager_BeginTx(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec("SELECT set_config").
+		WithArgs(SettingKey, tenantA.String()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	tx, err := NewTxManager(db).BeginTx(WithID(t.Context(), tenantA), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestTxManager_BeginTx_All(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec("SELECT set_config").
+		WithArgs(AllSettingKey, "on").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	tx, err := NewTxManager(db).BeginTx(WithAll(t.Context()), nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestTxManager_BeginTx_TenantRequired(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	_, err = NewTxManager(db).BeginTx(context.Background(), nil)
+
+	assert.ErrorIs(t, err, ErrRequired)
+	// No transaction is begun without a tenant
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestTxManager_BeginTx_SetConfigError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	errSetConfig := errors.New("set_config failed")
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec("SELECT set_config").WillReturnError(errSetConfig)
+	sqlMock.ExpectRollback()
+
+	_, err = NewTxManager(db).BeginTx(WithID(t.Context(), tenantA), nil)
+
+	assert.ErrorIs(t, err, errSetConfig)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sync"
@@ -100,6 +101,12 @@ func (e *Environment) run(ready chan struct{}, errorChan chan error) {
 		return
 	}
 
+	err = e.setupAppDBConn()
+	if err != nil {
+		errorChan <- err
+		return
+	}
+
 	if e.enableServer {
 		err = e.setupHttpTestServer()
 		if err != nil {
@@ -192,6 +199,54 @@ func (e *Environment) setupDBConn() error {
 	return nil
 }
 
+// appRole connected as by tests and servers once migrated. Superusers and roles with BYPASSRLS, such as the
+// owner of the container, bypass row level security, which tests of tenant isolation must not.
+const (
+	appRole         = "app"
+	appRolePassword = "password"
+)
+
+// setupAppDBConn creates appRole with privileges on the migrated tables, and replaces the connection of the
+// container owner with a connection as appRole.
+func (e *Environment) setupAppDBConn() error {
+	e.logger.Printf("setup app db connection")
+	stmts := []string{
+		fmt.Sprintf("CREATE ROLE %s LOGIN PASSWORD '%s' NOSUPERUSER NOBYPASSRLS", appRole, appRolePassword),
+		"GRANT USAGE ON SCHEMA public TO " + appRole,
+		// TRUNCATE of test cleanup ignores row level security
+		"GRANT SELECT, INSERT, UPDATE, DELETE, TRUNCATE ON ALL TABLES IN SCHEMA public TO " + appRole,
+		"GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO " + appRole,
+	}
+	for _, stmt := range stmts {
+		if _, err := e.dbConn.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to setup app role: %w", err)
+		}
+	}
+
+	connString, err := e.dbContainer.ConnectionString(context.Background(), "sslmode=disable")
+	if err != nil {
+		return err
+	}
+	connURL, err := url.Parse(connString)
+	if err != nil {
+		return err
+	}
+	connURL.User = url.UserPassword(appRole, appRolePassword)
+
+	dbConn, err := sql.Open("postgres", connURL.String())
+	if err != nil {
+		return err
+	}
+	if err = dbConn.Ping(); err != nil {
+		_ = dbConn.Close()
+		return fmt.Errorf("failed to connect as app role: %w", err)
+	}
+
+	e.closeDBConn()
+	e.dbConn = dbConn
+	return nil
+}
+
 // closeDBConn closes the database connection if it is not nil.
 func (e *Environment) closeDBConn() {
 	e.logger.Printf("close db connection")
@@ -249,7 +304,7 @@ func (e *Environment) setupHttpTestServer() error {
 	}
 
 	// Pass nil for metrics in test environment (monitoring not needed for tests)
-	srv := app.NewServer(logger, e.dbConn, cfg.HTTPServerConfig, nil)
+	srv := app.NewServer(logger, e.dbConn, cfg.HTTPServerConfig, cfg.AuthConfig, nil)
 
 	e.httptestServer = httptest.NewServer(srv.BuildRouter())
 	return nil
@@ -0,0 +1,340 @@
+package validx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/google/uuid"
+)
+
+const tagName = "validate"
+const ruleOmitEmpty = "omitempty"
+
+// Rule validates a field, param is the value after "=" in the tag, e.g. "3" for "min=3".
+// The returned error message is used as the validation error of the field.
+type Rule func(field reflect.Value, param string) error
+
+// Validator validates structs by their `validate` tags, e.g.
+//
+//	type CreateRequest struct {
+//		Email string `json:"email" validate:"required,email,max=254"`
+//		Role  string `json:"role" validate:"omitempty,oneof=admin member"`
+//	}
+//
+// Errors are keyed by JSON field name, nested structs are prefixed with their parent, e.g. "address.city".
+// Only the first failing rule of a field is reported. Rules are evaluated on zero values
+// unless the field is tagged with omitempty.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+	cache sync.Map // reflect.Type -> []fieldRules
+}
+
+// New creates a Validator with the built-in rules.
+func New() *Validator {
+	return &Validator{
+		rules: map[string]Rule{
+			"required": required,
+			"email":    email,
+			"min":      minRule,
+			"max":      maxRule,
+			"past":     past,
+			"uuid":     uuidRule,
+			"oneof":    oneOf,
+		},
+	}
+}
+
+var defaultValidator = New()
+
+// Struct validates s with the default Validator.
+func Struct(s any) *errorx.ValidationError {
+	return defaultValidator.Struct(s)
+}
+
+// Register adds a custom rule to the default Validator.
+func Register(name string, rule Rule) {
+	defaultValidator.Register(name, rule)
+}
+
+// Register adds or replaces rule, it should be called before validating.
+func (v *Validator) Register(name string, rule Rule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = rule
+}
+
+// Struct validates s, a struct or pointer to struct, returning nil when valid.
+func (v *Validator) Struct(s any) *errorx.ValidationError {
+	rv := reflect.ValueOf(s)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	properties := make(map[string]string)
+	v.validateStruct(rv, "", properties)
+
+	if len(properties) > 0 {
+		return &errorx.ValidationError{Properties: properties}
+	}
+	return nil
+}
+
+type tagRule struct {
+	name  string
+	param string
+}
+
+type fieldRules struct {
+	index     int
+	name      string
+	omitEmpty bool
+	rules     []tagRule
+	nested    bool
+}
+
+func (v *Validator) validateStruct(rv reflect.Value, prefix string, properties map[string]string) {
+	for _, f := range v.fieldsOf(rv.Type()) {
+		field := rv.Field(f.index)
+		name := prefix + f.name
+
+		if !(f.omitEmpty && field.IsZero()) {
+			if msg := v.validateField(field, f.rules); msg != "" {
+				properties[name] = msg
+				continue
+			}
+		}
+
+		if f.nested {
+			for field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					break
+				}
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct {
+				v.validateStruct(field, name+".", properties)
+			}
+		}
+	}
+}
+
+func (v *Validator) validateField(field reflect.Value, rules []tagRule) string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for _, r := range rules {
+		rule, ok := v.rules[r.name]
+		if !ok {
+			panic(fmt.Sprintf("validx: unknown rule %q", r.name))
+		}
+		if err := rule(field, r.param); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+func (v *Validator) fieldsOf(t reflect.Type) []fieldRules {
+	if cached, ok := v.cache.Load(t); ok {
+		return cached.([]fieldRules)
+	}
+
+	var fields []fieldRules
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		}
+
+		f := fieldRules{index: i, name: name, nested: isNestedStruct(sf.Type)}
+		if tag := sf.Tag.Get(tagName); tag != "" {
+			for _, raw := range strings.Split(tag, ",") {
+				ruleName, param, _ := strings.Cut(strings.TrimSpace(raw), "=")
+				if ruleName == ruleOmitEmpty {
+					f.omitEmpty = true
+					continue
+				}
+				f.rules = append(f.rules, tagRule{name: ruleName, param: param})
+			}
+		}
+		if len(f.rules) > 0 || f.nested {
+			fields = append(fields, f)
+		}
+	}
+
+	v.cache.Store(t, fields)
+	return fields
+}
+
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	civilDateType = reflect.TypeFor[civil.Date]()
+	uuidType      = reflect.TypeFor[uuid.UUID]()
+)
+
+// isNestedStruct structs validated field by field, excluding value types such as time.Time.
+func isNestedStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && t != civilDateType
+}
+
+func required(field reflect.Value, _ string) error {
+	if field.IsZero() {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+func email(field reflect.Value, _ string) error {
+	if field.Kind() != reflect.String || !IsEmail(field.String()) {
+		return errors.New("is not a valid email")
+	}
+	return nil
+}
+
+func minRule(field reflect.Value, param string) error {
+	n := mustParseInt(param)
+	switch field.Kind() {
+	case reflect.String:
+		if len([]rune(field.String())) < n {
+			return fmt.Errorf("must be at least %d characters", n)
+		}
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if field.Len() < n {
+			return fmt.Errorf("must contain at least %d items", n)
+		}
+	default:
+		if number, ok := toFloat(field); ok && number < float64(n) {
+			return fmt.Errorf("must be at least %d", n)
+		}
+	}
+	return nil
+}
+
+func maxRule(field reflect.Value, param string) error {
+	n := mustParseInt(param)
+	switch field.Kind() {
+	case reflect.String:
+		if len([]rune(field.String())) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+	case reflect.Slice, reflect.Map, reflect.Array:
+		if field.Len() > n {
+			return fmt.Errorf("must contain at most %d items", n)
+		}
+	default:
+		if number, ok := toFloat(field); ok && number > float64(n) {
+			return fmt.Errorf("must be at most %d", n)
+		}
+	}
+	return nil
+}
+
+// past accepts valid dates or times before today, or now respectively.
+func past(field reflect.Value, _ string) error {
+	errPast := errors.New("is invalid or in the future")
+	switch field.Type() {
+	case civilDateType:
+		d := field.Interface().(civil.Date)
+		if !d.IsValid() || d.IsZero() || !d.Before(civil.DateOf(time.Now())) {
+			return errPast
+		}
+	case timeType:
+		t := field.Interface().(time.Time)
+		if t.IsZero() || !t.Before(time.Now()) {
+			return errPast
+		}
+	default:
+		return errPast
+	}
+	return nil
+}
+
+// uuidRule accepts non nil uuid.UUID, or strings of non nil UUIDs.
+func uuidRule(field reflect.Value, _ string) error {
+	errUUID := errors.New("is not a valid uuid")
+	if field.Type() == uuidType {
+		if field.Interface().(uuid.UUID) == uuid.Nil {
+			return errUUID
+		}
+		return nil
+	}
+	if field.Kind() != reflect.String {
+		return errUUID
+	}
+	id, err := uuid.Parse(field.String())
+	if err != nil || id == uuid.Nil {
+		return errUUID
+	}
+	return nil
+}
+
+// oneOf accepts values of string or integer kinds, listed space separated, e.g. "oneof=PENDING ACCEPTED".
+func oneOf(field reflect.Value, param string) error {
+	options := strings.Fields(param)
+	var value string
+	switch field.Kind() {
+	case reflect.String:
+		value = field.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = strconv.FormatUint(field.Uint(), 10)
+	}
+	for _, option := range options {
+		if value == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+}
+
+func toFloat(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// mustParseInt panics on invalid tags, as they are programming errors.
+func mustParseInt(param string) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validx: invalid rule parameter %q", param))
+	}
+	return n
+}
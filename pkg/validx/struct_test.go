@@ -0,0 +1,194 @@
+package validx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type testStruct struct {
+	ID       uuid.UUID    `json:"id" validate:"uuid"`
+	OwnerID  string       `json:"ownerId" validate:"omitempty,uuid"`
+	Email    string       `json:"email" validate:"required,email"`
+	Name     string       `json:"name" validate:"min=2,max=5"`
+	Tags     []string     `json:"tags" validate:"max=2"`
+	Age      int          `json:"age" validate:"min=18,max=130"`
+	Role     string       `json:"role" validate:"oneof=admin member"`
+	Birthday civil.Date   `json:"birthday" validate:"past"`
+	Address  testAddress  `json:"address"`
+	Billing  *testAddress `json:"billing"`
+	Ignored  string       `json:"-" validate:"required"`
+	internal string       `validate:"required"`
+}
+
+func validTestStruct() testStruct {
+	return testStruct{
+		ID:       uuid.New(),
+		Email:    "john@example.com",
+		Name:     "john",
+		Age:      30,
+		Role:     "admin",
+		Birthday: civil.Date{Year: 1990, Month: 1, Day: 1},
+		Address:  testAddress{City: "kl"},
+	}
+}
+
+func TestStruct_Valid(t *testing.T) {
+	s := validTestStruct()
+
+	assert.Nil(t, Struct(s))
+	assert.Nil(t, Struct(&s))
+}
+
+func TestStruct_NotStruct(t *testing.T) {
+	var nilStruct *testStruct
+
+	assert.Nil(t, Struct(nilStruct))
+	assert.Nil(t, Struct("string"))
+}
+
+func TestStruct_Rules(t *testing.T) {
+	tests := []struct {
+		name string
+		mod  func(s *testStruct)
+		want map[string]string
+	}{
+		{
+			name: "required",
+			mod:  func(s *testStruct) { s.Email = "" },
+			want: map[string]string{"email": "is required"},
+		},
+		{
+			name: "email",
+			mod:  func(s *testStruct) { s.Email = "john" },
+			want: map[string]string{"email": "is not a valid email"},
+		},
+		{
+			name: "min length",
+			mod:  func(s *testStruct) { s.Name = "j" },
+			want: map[string]string{"name": "must be at least 2 characters"},
+		},
+		{
+			name: "max length counts runes",
+			mod:  func(s *testStruct) { s.Name = "jöhnny" },
+			want: map[string]string{"name": "must be at most 5 characters"},
+		},
+		{
+			name: "max items",
+			mod:  func(s *testStruct) { s.Tags = []string{"a", "b", "c"} },
+			want: map[string]string{"tags": "must contain at most 2 items"},
+		},
+		{
+			name: "min number",
+			mod:  func(s *testStruct) { s.Age = 17 },
+			want: map[string]string{"age": "must be at least 18"},
+		},
+		{
+			name: "oneof",
+			mod:  func(s *testStruct) { s.Role = "owner" },
+			want: map[string]string{"role": "must be one of admin, member"},
+		},
+		{
+			name: "past future date",
+			mod:  func(s *testStruct) { s.Birthday = civil.DateOf(time.Now().AddDate(0, 0, 1)) },
+			want: map[string]string{"birthday": "is invalid or in the future"},
+		},
+		{
+			name: "past today",
+			mod:  func(s *testStruct) { s.Birthday = civil.DateOf(time.Now()) },
+			want: map[string]string{"birthday": "is invalid or in the future"},
+		},
+		{
+			name: "past invalid date",
+			mod:  func(s *testStruct) { s.Birthday = civil.Date{Year: 2020, Month: 13, Day: 32} },
+			want: map[string]string{"birthday": "is invalid or in the future"},
+		},
+		{
+			name: "uuid nil",
+			mod:  func(s *testStruct) { s.ID = uuid.Nil },
+			want: map[string]string{"id": "is not a valid uuid"},
+		},
+		{
+			name: "omitempty skipped when empty",
+			mod:  func(s *testStruct) { s.OwnerID = "" },
+			want: nil,
+		},
+		{
+			name: "omitempty validated when set",
+			mod:  func(s *testStruct) { s.OwnerID = "not-a-uuid" },
+			want: map[string]string{"ownerId": "is not a valid uuid"},
+		},
+		{
+			name: "nested struct",
+			mod:  func(s *testStruct) { s.Address.City = "" },
+			want: map[string]string{"address.city": "is required"},
+		},
+		{
+			name: "nested pointer struct",
+			mod:  func(s *testStruct) { s.Billing = &testAddress{} },
+			want: map[string]string{"billing.city": "is required"},
+		},
+		{
+			name: "multiple fields",
+			mod: func(s *testStruct) {
+				s.Email = ""
+				s.Age = 200
+			},
+			want: map[string]string{"email": "is required", "age": "must be at most 130"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := validTestStruct()
+			tt.mod(&s)
+
+			vErr := Struct(s)
+
+			if tt.want == nil {
+				assert.Nil(t, vErr)
+				return
+			}
+			require.NotNil(t, vErr)
+			assert.Equal(t, tt.want, vErr.Properties)
+		})
+	}
+}
+
+func TestValidator_Register(t *testing.T) {
+	v := New()
+	v.Register("even", func(field reflect.Value, _ string) error {
+		if field.Int()%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+
+	type custom struct {
+		Count int `json:"count" validate:"even"`
+	}
+
+	assert.Nil(t, v.Struct(custom{Count: 2}))
+
+	vErr := v.Struct(custom{Count: 3})
+	require.NotNil(t, vErr)
+	assert.Equal(t, map[string]string{"count": "must be even"}, vErr.Properties)
+}
+
+func TestValidator_UnknownRule(t *testing.T) {
+	type unknown struct {
+		Name string `validate:"unknown"`
+	}
+
+	assert.Panics(t, func() { New().Struct(unknown{}) })
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrAddressNotAllowed returned when a receiver resolves to an address deliveries may not reach, see DialControl.
+var ErrAddressNotAllowed = errors.New("webhook receiver address not allowed")
+
+// deniedPrefixes of special purpose addresses not rejected as private, reaching internal networks through
+// carrier-grade NAT, or IPv4 addresses embedded by IPv6 transition mechanisms.
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // this network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("::/96"),          // IPv4-compatible
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+}
+
+// DialControl returns a net.Dialer Control rejecting connections to loopback, private, link-local, multicast,
+// unspecified and other special purpose addresses, see deniedPrefixes, unless within allowed. Receivers are URLs
+// of callers, without the guard they could reach the admin listener, internal services or cloud metadata such
+// as 169.254.169.254. IPv4-mapped addresses are checked as the IPv4 address they map.
+//
+// Addresses are checked at connect time, after resolution, hosts resolving to another address once
+// validated are rejected as well.
+func DialControl(allowed []netip.Prefix) func(network, address string, c syscall.RawConn) error {
+	return func(_, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrAddressNotAllowed, address)
+		}
+		addr := addrPort.Addr().Unmap()
+		for _, prefix := range allowed {
+			if prefix.Contains(addr) {
+				return nil
+			}
+		}
+		if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+			return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr)
+		}
+		for _, prefix := range deniedPrefixes {
+			if prefix.Contains(addr) {
+				return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr)
+			}
+		}
+		return nil
+	}
+}
+
+// NewDialer returns a dialer guarded by DialControl, with the timeouts of http.DefaultTransport.
+func NewDialer(allowed []netip.Prefix) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   DialControl(allowed),
+	}
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []netip.Prefix
+		address string
+		wantErr bool
+	}{
+		{name: "public", address: "93.184.215.14:443"},
+		{name: "public ipv6", address: "[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443"},
+		{name: "loopback", address: "127.0.0.1:9091", wantErr: true},
+		{name: "loopback ipv6", address: "[::1]:9091", wantErr: true},
+		{name: "private", address: "10.1.2.3:80", wantErr: true},
+		{name: "private 192.168", address: "192.168.0.1:80", wantErr: true},
+		{name: "unique local ipv6", address: "[fd00::1]:80", wantErr: true},
+		{name: "link-local metadata", address: "169.254.169.254:80", wantErr: true},
+		{name: "unspecified", address: "0.0.0.0:80", wantErr: true},
+		{name: "ipv4 mapped loopback", address: "[::ffff:127.0.0.1]:80", wantErr: true},
+		{name: "ipv4 mapped private", address: "[::ffff:10.1.2.3]:80", wantErr: true},
+		{name: "ipv4 mapped metadata", address: "[::ffff:a9fe:a9fe]:80", wantErr: true},
+		{name: "ipv4 mapped public", address: "[::ffff:93.184.215.14]:443"},
+		{name: "carrier-grade nat", address: "100.64.0.1:80", wantErr: true},
+		{name: "benchmarking", address: "198.18.0.1:80", wantErr: true},
+		{name: "reserved", address: "240.0.0.1:80", wantErr: true},
+		{name: "ipv4 compatible private", address: "[::10.1.2.3]:80", wantErr: true},
+		{name: "nat64 metadata", address: "[64:ff9b::a9fe:a9fe]:80", wantErr: true},
+		{name: "6to4 loopback", address: "[2002:7f00:1::1]:80", wantErr: true},
+		{name: "teredo", address: "[2001:0:4136:e378:8000:63bf:3fff:fdd2]:80", wantErr: true},
+		{
+			name:    "allowed carrier-grade nat",
+			allowed: []netip.Prefix{netip.MustParsePrefix("100.64.0.0/10")},
+			address: "100.64.0.1:80",
+		},
+		{
+			name:    "allowed loopback",
+			allowed: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+			address: "127.0.0.1:9091",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DialControl(tt.allowed)("tcp", tt.address, nil)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrAddressNotAllowed)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
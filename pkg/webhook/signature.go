@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of signed requests, following the Standard Webhooks specification
+// so that receivers may verify with its libraries.
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+)
+
+// DefaultTolerance between the signed timestamp and the receiver clock, bounding replays.
+const DefaultTolerance = 5 * time.Minute
+
+const (
+	secretPrefix     = "whsec_"
+	secretBytes      = 32
+	signatureVersion = "v1"
+)
+
+var (
+	ErrInvalidSecret    = errors.New("invalid webhook secret")
+	ErrMissingHeaders   = errors.New("missing webhook headers")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrTimestampExpired = errors.New("webhook timestamp outside of tolerance")
+)
+
+// GenerateSecret returns a random secret formatted as "whsec_<base64 key>".
+func GenerateSecret() string {
+	key := make([]byte, secretBytes)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(key)
+	return secretPrefix + base64.StdEncoding.EncodeToString(key)
+}
+
+// Sign returns the signature header value of body, "v1,<base64 HMAC-SHA256 of id.timestamp.body>".
+func Sign(secret, id string, timestamp time.Time, body []byte) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return signatureVersion + "," + base64.StdEncoding.EncodeToString(mac(key, id, timestamp.Unix(), body)), nil
+}
+
+// SetHeaders signs body and sets the id, timestamp and signature headers of a request.
+func SetHeaders(h http.Header, secret, id string, timestamp time.Time, body []byte) error {
+	signature, err := Sign(secret, id, timestamp, body)
+	if err != nil {
+		return err
+	}
+	h.Set(HeaderID, id)
+	h.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	h.Set(HeaderSignature, signature)
+	return nil
+}
+
+// Verify checks the headers of a received request against body, for receivers and tests.
+// The signature header may hold several space separated signatures, e.g. during secret rotation,
+// one matching signature suffices.
+func Verify(secret string, h http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	id, ts, signatures := h.Get(HeaderID), h.Get(HeaderTimestamp), h.Get(HeaderSignature)
+	if id == "" || ts == "" || signatures == "" {
+		return ErrMissingHeaders
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return ErrTimestampExpired
+	}
+
+	expected := mac(key, id, unix, body)
+	for _, s := range strings.Fields(signatures) {
+		version, encoded, ok := strings.Cut(s, ",")
+		if !ok || version != signatureVersion {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil && hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func mac(key []byte, id string, unix int64, body []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(id + "." + strconv.FormatInt(unix, 10) + "."))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, secretPrefix))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
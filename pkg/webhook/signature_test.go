@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	secret := GenerateSecret()
+
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+	key, err := decodeSecret(secret)
+	require.NoError(t, err)
+	assert.Len(t, key, secretBytes)
+	assert.NotEqual(t, secret, GenerateSecret())
+}
+
+func TestSign(t *testing.T) {
+	// Example of the Standard Webhooks specification
+	secret := "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	timestamp := time.Unix(1614265330, 0)
+	body := []byte(`{"test": 2432232314}`)
+
+	signature, err := Sign(secret, "msg_p5jXN8AQM9LWM0D4loKWxJek", timestamp, body)
+
+	require.NoError(t, err)
+	assert.Equal(t, "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=", signature)
+}
+
+func TestVerify(t *testing.T) {
+	secret := GenerateSecret()
+	now := time.Now()
+	body := []byte(`{"type":"user_profile.created"}`)
+
+	signed := func(t *testing.T, timestamp time.Time) http.Header {
+		h := http.Header{}
+		require.NoError(t, SetHeaders(h, secret, "evt_1", timestamp, body))
+		return h
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		header   func(t *testing.T) http.Header
+		body     []byte
+		expected error
+	}{
+		{
+			name:   "valid",
+			secret: secret,
+			header: func(t *testing.T) http.Header { return signed(t, now) },
+			body:   body,
+		},
+		{
+			name:   "one of several signatures",
+			secret: secret,
+			header: func(t *testing.T) http.Header {
+				h := signed(t, now)
+				h.Set(HeaderSignature, "v1,b3RoZXI= "+h.Get(HeaderSignature))
+				return h
+			},
+			body: body,
+		},
+		{
+			name:     "tampered body",
+			secret:   secret,
+			header:   func(t *testing.T) http.Header { return signed(t, now) },
+			body:     []byte(`{"type":"user_invitation.accepted"}`),
+			expected: ErrInvalidSignature,
+		},
+		{
+			name:     "other secret",
+			secret:   GenerateSecret(),
+			header:   func(t *testing.T) http.Header { return signed(t, now) },
+			body:     body,
+			expected: ErrInvalidSignature,
+		},
+		{
+			name:     "expired timestamp",
+			secret:   secret,
+			header:   func(t *testing.T) http.Header { return signed(t, now.Add(-DefaultTolerance-time.Second)) },
+			body:     body,
+			expected: ErrTimestampExpired,
+		},
+		{
+			name:     "future timestamp",
+			secret:   secret,
+			header:   func(t *testing.T) http.Header { return signed(t, now.Add(DefaultTolerance+time.Second)) },
+			body:     body,
+			expected: ErrTimestampExpired,
+		},
+		{
+			name:   "missing signature",
+			secret: secret,
+			header: func(t *testing.T) http.Header {
+				h := signed(t, now)
+				h.Del(HeaderSignature)
+				return h
+			},
+			body:     body,
+			expected: ErrMissingHeaders,
+		},
+		{
+			name:     "invalid secret",
+			secret:   "whsec_%%%",
+			header:   func(t *testing.T) http.Header { return signed(t, now) },
+			body:     body,
+			expected: ErrInvalidSecret,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header(t), tt.body, DefaultTolerance, now)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"github.com/dyxj/bigbackend/internal/user/invitation"
 	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,10 +46,12 @@ func BenchmarkUpdaterSQLDBUserInvitation_Multiple(b *testing.B) {
 	if err != nil {
 		b.Fatalf("failed to initialize logger: %v", err)
 	}
+	tenantID := uuid.New()
 	dbConn := testx.GlobalBenchEnv().DBConn()
+	conn := test.TenantConn(b, dbConn, tenantID)
 	creator := invitation.NewCreatorSQLDB(logger)
 	updater := invitation.NewUpdaterSQLDB(logger)
-	ctx := b.Context()
+	ctx := tenant.WithID(b.Context(), tenantID)
 
 	batchSizes := []int{1, 5, 10, 25, 50, 100}
 
@@ -55,7 +59,7 @@ func BenchmarkUpdaterSQLDBUserInvitation_Multiple(b *testing.B) {
 		b.Run(fmt.Sprintf("method=Single/BatchSize%d", batchSize), func(b *testing.B) {
 			// Pre-create records for all iterations
 			totalRecords := b.N * batchSize
-			records := createBenchmarkRecords(b, ctx, dbConn, creator, totalRecords)
+			records := createBenchmarkRecords(b, ctx, conn, creator, totalRecords)
 
 			// Pre-prepare all batches with updated status
 			batches := make([][]entity.UserInvitation, b.N)
@@ -74,7 +78,7 @@ func BenchmarkUpdaterSQLDBUserInvitation_Multiple(b *testing.B) {
 				batch := batches[i]
 
 				for j := range batch {
-					_, err := updater.UpdateInvitationTx(ctx, dbConn, batch[j])
+					_, err := updater.UpdateInvitationTx(ctx, conn, batch[j])
 					assert.NoError(b, err)
 				}
 			}
@@ -83,7 +87,7 @@ func BenchmarkUpdaterSQLDBUserInvitation_Multiple(b *testing.B) {
 		b.Run(fmt.Sprintf("method=Batch/BatchSize%d", batchSize), func(b *testing.B) {
 			// Pre-create records for all iterations
 			totalRecords := b.N * batchSize
-			records := createBenchmarkRecords(b, ctx, dbConn, creator, totalRecords)
+			records := createBenchmarkRecords(b, ctx, conn, creator, totalRecords)
 
 			// Pre-prepare all batches with updated status
 			batches := make([][]entity.UserInvitation, b.N)
@@ -101,7 +105,7 @@ func BenchmarkUpdaterSQLDBUserInvitation_Multiple(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				batch := batches[i]
 
-				_, err := updater.BatchUpdateInvitationTx(ctx, dbConn, batch)
+				_, err := updater.BatchUpdateInvitationTx(ctx, conn, batch)
 				assert.NoError(b, err)
 			}
 		})
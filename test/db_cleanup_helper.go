@@ -13,6 +13,26 @@ func TruncateUserInvitation(dbConn *sql.DB) {
 	truncateTable(dbConn, "user_invitation")
 }
 
+func TruncateRolePermission(dbConn *sql.DB) {
+	truncateTable(dbConn, "role_permission")
+}
+
+func TruncateAPIKey(dbConn *sql.DB) {
+	truncateTable(dbConn, "api_key")
+}
+
+func TruncateRateLimit(dbConn *sql.DB) {
+	truncateTable(dbConn, "rate_limit")
+}
+
+func TruncateWebhookSubscription(dbConn *sql.DB) {
+	truncateTable(dbConn, "webhook_subscription")
+}
+
+func TruncateWebhookDelivery(dbConn *sql.DB) {
+	truncateTable(dbConn, "webhook_delivery")
+}
+
 func truncateTable(dbConn *sql.DB, tableName string) {
 	_, err := dbConn.Exec("TRUNCATE TABLE " + tableName + " CASCADE;")
 	if err != nil {
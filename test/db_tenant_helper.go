@@ -0,0 +1,37 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"testing"
+
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/google/uuid"
+)
+
+// TenantConn returns a connection of dbConn with tenantID set for the session, so that statements
+// outside tenant.TxManager transactions pass row level security, e.g. seeding and assertions.
+// The setting is reset before the connection returns to the pool when the test ends.
+func TenantConn(t testing.TB, dbConn *sql.DB, tenantID uuid.UUID) *sql.Conn {
+	t.Helper()
+	ctx := context.Background()
+
+	conn, err := dbConn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	t.Cleanup(func() {
+		_, err := conn.ExecContext(ctx, "RESET "+tenant.SettingKey)
+		if err != nil {
+			log.Printf("failed to reset tenant: %v", err)
+		}
+		_ = conn.Close()
+	})
+
+	_, err = conn.ExecContext(ctx, "SELECT set_config($1, $2, false)", tenant.SettingKey, tenantID.String())
+	if err != nil {
+		t.Fatalf("failed to set tenant: %v", err)
+	}
+	return conn
+}
@@ -17,6 +17,8 @@ func UserInvitation() invitation.UserInvitation {
 		CreateTime: gofakeit.Date(),
 		UpdateTime: gofakeit.Date(),
 		Version:    0,
+		TenantID:   uuid.New(),
+		Inviter:    gofakeit.UUID(),
 	}
 }
 
@@ -30,5 +32,7 @@ func UserInvitationEntity() entity.UserInvitation {
 		CreateTime: gofakeit.Date(),
 		UpdateTime: gofakeit.Date(),
 		Version:    0,
+		TenantID:   uuid.New(),
+		Inviter:    gofakeit.UUID(),
 	}
 }
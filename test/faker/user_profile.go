@@ -22,6 +22,7 @@ func UserProfile() profile.UserProfile {
 		CreateTime:  gofakeit.Date(),
 		UpdateTime:  gofakeit.Date(),
 		Version:     0,
+		TenantID:    uuid.New(),
 	}
 }
 
@@ -35,6 +36,7 @@ func UserProfileEntity() entity.UserProfile {
 		CreateTime:  gofakeit.Date(),
 		UpdateTime:  gofakeit.Date(),
 		Version:     0,
+		TenantID:    uuid.New(),
 	}
 }
 
@@ -58,6 +60,23 @@ func (m *UserProfileCreatorRepoMock) InsertUserProfile(
 	return returnArgs.Get(0).(entity.UserProfile), returnArgs.Error(1)
 }
 
+type EventPublisherMock struct {
+	mock.Mock
+}
+
+func (m *EventPublisherMock) PublishTx(ctx context.Context, tx sqldb.Executable, eventType string, data any) error {
+	returnArgs := m.Called(ctx, tx, eventType, data)
+	return returnArgs.Error(0)
+}
+
+type UserNotifierMock struct {
+	mock.Mock
+}
+
+func (m *UserNotifierMock) NotifyUser(ctx context.Context, userID uuid.UUID, eventType string, data any) {
+	m.Called(ctx, userID, eventType, data)
+}
+
 type UserProfileCreatorMock struct {
 	mock.Mock
 }
@@ -0,0 +1,130 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/apikey"
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/testx"
+	"github.com/dyxj/bigbackend/test"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQL_APIKey(t *testing.T) {
+	logger, err := logx.InitLogger()
+	if err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+	dbConn := testx.GlobalEnv().DBConn()
+
+	creator := apikey.NewCreatorSQLDB(logger, dbConn)
+	getter := apikey.NewGetterSQLDB(logger, dbConn)
+	updater := apikey.NewUpdaterSQLDB(logger, dbConn)
+	usage := apikey.NewUsageSQLDB(logger, dbConn)
+
+	newKey := func(prefix string) entity.APIKey {
+		return entity.APIKey{
+			Name: "batch", Owner: "jobs", Prefix: prefix, SecretHash: "hash", Scope: "batch", TenantID: testTenantID,
+		}
+	}
+
+	t.Run("should insert and find by prefix", func(t *testing.T) {
+		ctx := t.Context()
+		t.Cleanup(func() {
+			test.TruncateAPIKey(dbConn)
+		})
+
+		inserted, err := creator.InsertAPIKey(ctx, newKey("0123456789ab"))
+		if err != nil {
+			t.Fatalf("failed to insert api key: %v", err)
+		}
+		assert.Equal(t, int32(1), inserted.Version)
+
+		found, err := getter.FindAPIKeyByPrefix(ctx, "0123456789ab")
+		assert.NoError(t, err)
+		assert.Equal(t, inserted.ID, found.ID)
+		assert.Equal(t, "batch", found.Scope)
+		assert.Equal(t, testTenantID, found.TenantID)
+		assert.Nil(t, found.RevokeTime)
+
+		_, err = getter.FindAPIKeyByPrefix(ctx, "ffffffffffff")
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+
+		_, err = creator.InsertAPIKey(ctx, newKey("0123456789ab"))
+		var uErr *errorx.UniqueViolationError
+		assert.ErrorAs(t, err, &uErr)
+	})
+
+	t.Run("should rotate until revoked", func(t *testing.T) {
+		ctx := t.Context()
+		t.Cleanup(func() {
+			test.TruncateAPIKey(dbConn)
+		})
+
+		inserted, err := creator.InsertAPIKey(ctx, newKey("0123456789ab"))
+		if err != nil {
+			t.Fatalf("failed to insert api key: %v", err)
+		}
+
+		rotated, err := updater.UpdateSecret(ctx, inserted.ID, "ba9876543210", "rotated")
+		assert.NoError(t, err)
+		assert.Equal(t, "ba9876543210", rotated.Prefix)
+		assert.Equal(t, "rotated", rotated.SecretHash)
+		assert.Equal(t, int32(2), rotated.Version)
+
+		revoked, err := updater.Revoke(ctx, inserted.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, revoked.RevokeTime)
+
+		// Revoking again keeps the original revoke time
+		revokedAgain, err := updater.Revoke(ctx, inserted.ID)
+		assert.NoError(t, err)
+		assert.True(t, revoked.RevokeTime.Equal(*revokedAgain.RevokeTime))
+
+		_, err = updater.UpdateSecret(ctx, inserted.ID, "ffffffffffff", "rotated")
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+
+		_, err = updater.Revoke(ctx, uuid.New())
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+
+	t.Run("should update last used times", func(t *testing.T) {
+		ctx := t.Context()
+		t.Cleanup(func() {
+			test.TruncateAPIKey(dbConn)
+		})
+
+		k1, err := creator.InsertAPIKey(ctx, newKey("0123456789ab"))
+		if err != nil {
+			t.Fatalf("failed to insert api key: %v", err)
+		}
+		k2, err := creator.InsertAPIKey(ctx, newKey("ba9876543210"))
+		if err != nil {
+			t.Fatalf("failed to insert api key: %v", err)
+		}
+
+		now := time.Now().Truncate(time.Microsecond)
+		err = usage.UpdateLastUsedTimes(ctx, map[uuid.UUID]time.Time{k1.ID: now, k2.ID: now})
+		assert.NoError(t, err)
+
+		// Older usage does not overwrite newer usage
+		err = usage.UpdateLastUsedTimes(ctx, map[uuid.UUID]time.Time{k1.ID: now.Add(-time.Hour)})
+		assert.NoError(t, err)
+
+		keys, err := getter.ListAPIKeys(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 2)
+		for _, k := range keys {
+			if assert.NotNil(t, k.LastUsedTime) {
+				assert.True(t, now.Equal(*k.LastUsedTime))
+			}
+			assert.Equal(t, int32(1), k.Version)
+		}
+	})
+}
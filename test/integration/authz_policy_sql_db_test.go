@@ -0,0 +1,57 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/dyxj/bigbackend/internal/authz"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/testx"
+	"github.com/dyxj/bigbackend/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQL_LoadPolicy(t *testing.T) {
+	logger, err := logx.InitLogger()
+	if err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+	dbConn := testx.GlobalEnv().DBConn()
+
+	t.Run("should load role permissions", func(t *testing.T) {
+		ctx := t.Context()
+
+		t.Cleanup(func() {
+			test.TruncateRolePermission(dbConn)
+		})
+
+		_, err := dbConn.ExecContext(ctx,
+			`INSERT INTO role_permission (role, permission) VALUES
+				('support', 'profiles:read_any'),
+				('support', 'profiles:erase'),
+				('recruiter', 'invitations:create')`)
+		if err != nil {
+			t.Fatalf("failed to insert role permissions: %v", err)
+		}
+
+		policy, err := authz.NewPolicyGetterSQLDB(logger, dbConn).LoadPolicy(ctx)
+		if err != nil {
+			t.Fatalf("failed to load policy: %v", err)
+		}
+
+		assert.True(t, policy.Allows([]string{"support"}, authz.ProfilesReadAny))
+		assert.True(t, policy.Allows([]string{"support"}, authz.ProfilesErase))
+		assert.False(t, policy.Allows([]string{"support"}, authz.InvitationsCreate))
+		assert.True(t, policy.Allows([]string{"recruiter"}, authz.InvitationsCreate))
+	})
+
+	t.Run("should load empty policy", func(t *testing.T) {
+		policy, err := authz.NewPolicyGetterSQLDB(logger, dbConn).LoadPolicy(t.Context())
+		if err != nil {
+			t.Fatalf("failed to load policy: %v", err)
+		}
+
+		assert.False(t, policy.Allows([]string{"support"}, authz.ProfilesReadAny))
+	})
+}
@@ -9,12 +9,15 @@ import (
 
 	"github.com/dyxj/bigbackend/internal/user/profile"
 	"github.com/dyxj/bigbackend/pkg/testx"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 var (
 	logger                 *zap.Logger
 	userProfileCreatorRepo profile.CreatorRepo
+	// testTenantID sent by API requests, see tenant.HeaderKey
+	testTenantID = uuid.MustParse("6f1c1b52-4bb4-4a8e-9d9e-0b6a3cf1d9f1")
 )
 
 func TestMain(m *testing.M) {
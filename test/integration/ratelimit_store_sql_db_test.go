@@ -0,0 +1,99 @@
+//go:build integration
+
+package integration
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/ratelimit"
+	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/testx"
+	"github.com/dyxj/bigbackend/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQL_RateLimitStore(t *testing.T) {
+	logger, err := logx.InitLogger()
+	if err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+	dbConn := testx.GlobalEnv().DBConn()
+
+	t.Run("should throttle after limit", func(t *testing.T) {
+		ctx := t.Context()
+		t.Cleanup(func() {
+			test.TruncateRateLimit(dbConn)
+		})
+
+		store := ratelimit.NewStoreSQLDB(logger, dbConn)
+		limit := httpx.RateLimit{Requests: 2, Period: time.Minute}
+
+		result, err := store.Allow(ctx, "read:ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+
+		result, err = store.Allow(ctx, "read:ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		result, err = store.Allow(ctx, "read:ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.InDelta(t, 30*time.Second, result.RetryAfter, float64(time.Second))
+
+		result, err = store.Allow(ctx, "read:ip:192.0.2.2", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("should allow exactly limit when concurrent", func(t *testing.T) {
+		ctx := t.Context()
+		t.Cleanup(func() {
+			test.TruncateRateLimit(dbConn)
+		})
+
+		store := ratelimit.NewStoreSQLDB(logger, dbConn)
+		limit := httpx.RateLimit{Requests: 5, Period: time.Hour}
+
+		var allowed atomic.Int32
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Go(func() {
+				result, err := store.Allow(ctx, "write:subject:user-1", limit)
+				assert.NoError(t, err)
+				if result.Allowed {
+					allowed.Add(1)
+				}
+			})
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(5), allowed.Load())
+	})
+
+	t.Run("should purge replenished keys", func(t *testing.T) {
+		ctx := t.Context()
+		t.Cleanup(func() {
+			test.TruncateRateLimit(dbConn)
+		})
+
+		store := ratelimit.NewStoreSQLDB(logger, dbConn)
+
+		_, err := store.Allow(ctx, "short", httpx.RateLimit{Requests: 1, Period: time.Millisecond})
+		assert.NoError(t, err)
+		_, err = store.Allow(ctx, "long", httpx.RateLimit{Requests: 1, Period: time.Hour})
+		assert.NoError(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+
+		purged, err := store.Purge(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+	})
+}
@@ -5,3 +5,7 @@ import "fmt"
 func buildUserProfileUrl(url string, userId string) string {
 	return fmt.Sprintf("%s/api/v1/user/%s/profile", url, userId)
 }
+
+func buildUserEventsUrl(url string, userId string) string {
+	return fmt.Sprintf("%s/api/v1/user/%s/events", url, userId)
+}
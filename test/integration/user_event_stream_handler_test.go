@@ -0,0 +1,94 @@
+//go:build integration
+
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dyxj/bigbackend/internal/user/profile"
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/sse"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/dyxj/bigbackend/pkg/testx"
+	"github.com/dyxj/bigbackend/test"
+	"github.com/dyxj/bigbackend/test/faker"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent reads the fields of the next event, skipping comments and the retry advice.
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if _, ok := fields["event"]; ok {
+				return fields
+			}
+			fields = make(map[string]string)
+			continue
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+func openUserEvents(t *testing.T, url string, userID uuid.UUID, lastEventID string) *bufio.Reader {
+	t.Helper()
+	request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, buildUserEventsUrl(url, userID.String()), nil)
+	require.NoError(t, err)
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
+	if lastEventID != "" {
+		request.Header.Set(sse.HeaderKeyLastEventID, lastEventID)
+	}
+
+	resp, err := testx.GlobalEnv().HttpTestServer().Client().Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return bufio.NewReader(resp.Body)
+}
+
+func TestUserEventStreamHandler_ProfileCreated(t *testing.T) {
+	testSrv := testx.GlobalEnv().HttpTestServer()
+	dbConn := testx.GlobalEnv().DBConn()
+	t.Cleanup(func() {
+		test.TruncateUserProfile(dbConn)
+	})
+
+	payload := faker.UserProfileCreateRequest()
+	events := openUserEvents(t, testSrv.URL, payload.UserID, "")
+
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(&payload))
+	request, err := http.NewRequest(http.MethodPost, buildUserProfileUrl(testSrv.URL, payload.UserID.String()), &buf)
+	require.NoError(t, err)
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
+	request.Header.Set(idempotency.DefaultHeaderKey, uuid.NewString())
+	request.Header.Set("Content-Type", "application/json")
+	resp, err := testSrv.Client().Do(request)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	event := readEvent(t, events)
+	assert.Equal(t, webhook.EventUserProfileCreated, event["event"])
+	assert.NotEmpty(t, event["id"])
+	var data profile.Response
+	require.NoError(t, json.Unmarshal([]byte(event["data"]), &data))
+	assert.Equal(t, payload.UserID, data.UserID)
+
+	// Event IDs unknown to the instance cannot be resumed, clients are told to reload their state
+	resumed := openUserEvents(t, testSrv.URL, payload.UserID, "unknown-1")
+	assert.Equal(t, sse.EventTypeReset, readEvent(t, resumed)["event"])
+}
@@ -13,10 +13,12 @@ import (
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/table"
 	"github.com/dyxj/bigbackend/internal/user/invitation"
 	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
 	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,8 +33,10 @@ func TestCreatorSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 		})
 
 		creator := invitation.NewCreatorSQLDB(logger)
+		tenantID := uuid.New()
+		ctx := tenant.WithID(context.Background(), tenantID)
 
-		tx, err := dbConn.Begin()
+		tx, err := tenant.NewTxManager(dbConn).BeginTx(ctx, nil)
 		assert.NoError(t, err)
 		defer func() {
 			err := tx.Rollback()
@@ -45,7 +49,7 @@ func TestCreatorSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 		assert.NoError(t, err)
 
 		inserted, err := creator.InsertUserInvitation(
-			context.Background(),
+			ctx,
 			tx,
 			input,
 		)
@@ -57,7 +61,7 @@ func TestCreatorSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 		err = table.UserInvitation.
 			SELECT(table.UserInvitation.AllColumns).
 			WHERE(table.UserInvitation.ID.EQ(postgres.UUID(inserted.ID))).
-			Query(dbConn, &selected)
+			Query(test.TenantConn(t, dbConn, tenantID), &selected)
 		assert.NoError(t, err)
 
 		assert.NotNil(t, inserted.ID, "inserted ID should not be nil")
@@ -66,12 +70,14 @@ func TestCreatorSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 		assert.Equal(t, int32(1), inserted.Version, "inserted Version should be 1")
 
 		assert.Equal(t, inserted.ID, selected.ID)
+		assert.Equal(t, tenantID, selected.TenantID)
 		assert.WithinDuration(t, inserted.CreateTime, selected.CreateTime, time.Second)
 		assert.WithinDuration(t, inserted.UpdateTime, selected.UpdateTime, time.Second)
 
 		assert.Equal(t, input.Email, selected.Email)
 		assert.Equal(t, input.Token, selected.Token)
 		assert.Equal(t, input.Status, selected.Status)
+		assert.Equal(t, input.Inviter, selected.Inviter)
 		assert.WithinDuration(t, input.ExpiryTime, selected.ExpiryTime, time.Second)
 	})
 }
@@ -8,9 +8,11 @@ import (
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/dyxj/bigbackend/internal/user/invitation"
 	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,12 +24,14 @@ func TestGetterSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 	dbConn := testx.GlobalEnv().DBConn()
 
 	t.Run("should list invitations successfully", func(t *testing.T) {
-		ctx := t.Context()
+		tenantID := uuid.New()
+		ctx := tenant.WithID(t.Context(), tenantID)
+		conn := test.TenantConn(t, dbConn, tenantID)
 		t.Cleanup(func() {
 			test.TruncateUserInvitation(dbConn)
 		})
 
-		getter := invitation.NewGetterSQLDB(logger, dbConn)
+		getter := invitation.NewGetterSQLDB(logger, conn)
 		creator := invitation.NewCreatorSQLDB(logger)
 
 		email := gofakeit.Email()
@@ -38,12 +42,12 @@ func TestGetterSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 		i2.Email = email
 		i2.Status = string(invitation.StatusExpired)
 
-		inserted1, err := creator.InsertUserInvitation(ctx, dbConn, i1)
+		inserted1, err := creator.InsertUserInvitation(ctx, conn, i1)
 		assert.NoError(t, err)
-		inserted2, err := creator.InsertUserInvitation(ctx, dbConn, i2)
+		inserted2, err := creator.InsertUserInvitation(ctx, conn, i2)
 		assert.NoError(t, err)
 
-		result, err := getter.ListByEmailTx(ctx, dbConn, email)
+		result, err := getter.ListByEmailTx(ctx, conn, email)
 		assert.NoError(t, err)
 
 		assert.Equal(t, 2, len(result))
@@ -52,12 +56,14 @@ func TestGetterSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 	})
 
 	t.Run("should list no invitations", func(t *testing.T) {
-		ctx := t.Context()
+		tenantID := uuid.New()
+		ctx := tenant.WithID(t.Context(), tenantID)
+		conn := test.TenantConn(t, dbConn, tenantID)
 		t.Cleanup(func() {
 			test.TruncateUserInvitation(dbConn)
 		})
 
-		getter := invitation.NewGetterSQLDB(logger, dbConn)
+		getter := invitation.NewGetterSQLDB(logger, conn)
 		creator := invitation.NewCreatorSQLDB(logger)
 
 		email := "notfound@email.com"
@@ -66,14 +72,51 @@ func TestGetterSQLDBUserInvitation_InsertUserInvitation(t *testing.T) {
 		i2 := faker.UserInvitationEntity()
 		i2.Status = string(invitation.StatusExpired)
 
-		_, err := creator.InsertUserInvitation(ctx, dbConn, i1)
+		_, err := creator.InsertUserInvitation(ctx, conn, i1)
 		assert.NoError(t, err)
-		_, err = creator.InsertUserInvitation(ctx, dbConn, i2)
+		_, err = creator.InsertUserInvitation(ctx, conn, i2)
 		assert.NoError(t, err)
 
-		result, err := getter.ListByEmailTx(ctx, dbConn, email)
+		result, err := getter.ListByEmailTx(ctx, conn, email)
 		assert.NoError(t, err)
 
 		assert.Equal(t, 0, len(result))
 	})
+
+	t.Run("should not list invitations of other tenants", func(t *testing.T) {
+		tenantID := uuid.New()
+		ctx := tenant.WithID(t.Context(), tenantID)
+		conn := test.TenantConn(t, dbConn, tenantID)
+		otherTenantID := uuid.New()
+		otherCtx := tenant.WithID(t.Context(), otherTenantID)
+		otherConn := test.TenantConn(t, dbConn, otherTenantID)
+		t.Cleanup(func() {
+			test.TruncateUserInvitation(dbConn)
+		})
+
+		getter := invitation.NewGetterSQLDB(logger, conn)
+		creator := invitation.NewCreatorSQLDB(logger)
+
+		email := gofakeit.Email()
+		i1 := faker.UserInvitationEntity()
+		i1.Email = email
+		i1.Status = string(invitation.StatusPending)
+		i2 := faker.UserInvitationEntity()
+		i2.Email = email
+		i2.Status = string(invitation.StatusPending)
+
+		// Pending invitations of an email are unique per tenant
+		inserted, err := creator.InsertUserInvitation(ctx, conn, i1)
+		assert.NoError(t, err)
+		_, err = creator.InsertUserInvitation(otherCtx, otherConn, i2)
+		assert.NoError(t, err)
+
+		// Statement without a tenant filter, rows of other tenants are hidden by row level security
+		result, err := getter.ListByEmailTx(ctx, conn, email)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, len(result))
+		assert.Equal(t, inserted.ID, result[0].ID)
+		assert.Equal(t, tenantID, result[0].TenantID)
+	})
 }
@@ -11,10 +11,12 @@ import (
 	"github.com/dyxj/bigbackend/internal/user/invitation"
 	"github.com/dyxj/bigbackend/pkg/logx"
 	"github.com/dyxj/bigbackend/pkg/sqldb"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
 	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,7 +28,9 @@ func TestUpdaterSQLDBUserInvitation_BatchUpdateInvitationTx(t *testing.T) {
 	dbConn := testx.GlobalEnv().DBConn()
 
 	t.Run("should batch update successfully", func(t *testing.T) {
-		ctx := t.Context()
+		tenantID := uuid.New()
+		ctx := tenant.WithID(t.Context(), tenantID)
+		conn := test.TenantConn(t, dbConn, tenantID)
 		t.Cleanup(func() {
 			test.TruncateUserInvitation(dbConn)
 		})
@@ -39,9 +43,9 @@ func TestUpdaterSQLDBUserInvitation_BatchUpdateInvitationTx(t *testing.T) {
 		i2 := faker.UserInvitationEntity()
 		i2.Status = string(invitation.StatusPending)
 
-		inserted1, err := creator.InsertUserInvitation(ctx, dbConn, i1)
+		inserted1, err := creator.InsertUserInvitation(ctx, conn, i1)
 		assert.NoError(t, err)
-		inserted2, err := creator.InsertUserInvitation(ctx, dbConn, i2)
+		inserted2, err := creator.InsertUserInvitation(ctx, conn, i2)
 		assert.NoError(t, err)
 
 		update1 := faker.UserInvitationEntity()
@@ -51,7 +55,7 @@ func TestUpdaterSQLDBUserInvitation_BatchUpdateInvitationTx(t *testing.T) {
 
 		inserted2.Status = string(invitation.StatusExpired)
 
-		result, err := updater.BatchUpdateInvitationTx(ctx, dbConn, []entity.UserInvitation{
+		result, err := updater.BatchUpdateInvitationTx(ctx, conn, []entity.UserInvitation{
 			update1,
 			inserted2,
 		})
@@ -80,7 +84,9 @@ func TestUpdaterSQLDBUserInvitation_BatchUpdateInvitationTx(t *testing.T) {
 	})
 
 	t.Run("should abort update if any failed", func(t *testing.T) {
-		ctx := t.Context()
+		tenantID := uuid.New()
+		ctx := tenant.WithID(t.Context(), tenantID)
+		conn := test.TenantConn(t, dbConn, tenantID)
 		t.Cleanup(func() {
 			test.TruncateUserInvitation(dbConn)
 		})
@@ -93,7 +99,7 @@ func TestUpdaterSQLDBUserInvitation_BatchUpdateInvitationTx(t *testing.T) {
 		i2 := faker.UserInvitationEntity()
 		i2.Status = string(invitation.StatusPending)
 
-		inserted1, err := creator.InsertUserInvitation(ctx, dbConn, i1)
+		inserted1, err := creator.InsertUserInvitation(ctx, conn, i1)
 		assert.NoError(t, err)
 
 		update1 := faker.UserInvitationEntity()
@@ -101,7 +107,7 @@ func TestUpdaterSQLDBUserInvitation_BatchUpdateInvitationTx(t *testing.T) {
 		update1.Status = string(invitation.StatusAccepted)
 		update1.Version = inserted1.Version
 
-		tx, err := dbConn.Begin()
+		tx, err := tenant.NewTxManager(dbConn).BeginTx(ctx, nil)
 		assert.NoError(t, err)
 		defer sqldb.TxRollback(tx, logger)
 
@@ -124,7 +130,7 @@ func TestUpdaterSQLDBUserInvitation_BatchUpdateInvitationTx(t *testing.T) {
 		err = table.UserInvitation.
 			SELECT(table.UserInvitation.AllColumns).
 			WHERE(table.UserInvitation.ID.EQ(postgres.UUID(inserted1.ID))).
-			Query(dbConn, &selected)
+			Query(conn, &selected)
 		assert.NoError(t, err)
 
 		assert.Equal(t, inserted1.ID, selected.ID)
@@ -15,6 +15,8 @@ import (
 	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
 	"github.com/dyxj/bigbackend/internal/user/profile"
 	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/idempotency"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
@@ -45,6 +47,9 @@ func TestUserProfileCreatorHandler_ShouldCreate(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to build request: %v", err)
 	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
+	request.Header.Set(idempotency.DefaultHeaderKey, uuid.NewString())
+	request.Header.Set("Content-Type", "application/json")
 
 	resp, err := testSrv.Client().Do(request)
 	if err != nil {
@@ -160,6 +165,9 @@ func TestUserProfileCreatorHandler_PayloadValidationError(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to build request: %v", err)
 			}
+			request.Header.Set(tenant.HeaderKey, testTenantID.String())
+			request.Header.Set(idempotency.DefaultHeaderKey, uuid.NewString())
+			request.Header.Set("Content-Type", "application/json")
 
 			resp, err := testSrv.Client().Do(request)
 			if err != nil {
@@ -178,6 +186,9 @@ func TestUserProfileCreatorHandler_PayloadValidationError(t *testing.T) {
 			}
 
 			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.NotEmpty(t, result.RequestID)
+			assert.Equal(t, resp.Header.Get(httpx.HeaderKeyRequestID), result.RequestID)
+			tc.errResp.RequestID = result.RequestID
 			assert.Equal(t, tc.errResp, result)
 		})
 	}
@@ -192,23 +203,20 @@ func TestUserProfileCreatorHandler_InvalidJsonError(t *testing.T) {
 	})
 
 	ttc := []struct {
-		name              string
-		mod               func(*profile.CreateRequest)
-		resultDetailError string
+		name string
+		mod  func(*profile.CreateRequest)
 	}{
 		{
 			name: "zero date of birth",
 			mod: func(input *profile.CreateRequest) {
 				input.DateOfBirth = civil.Date{}
 			},
-			resultDetailError: "parsing time \"0000-00-00\": month out of range",
 		},
 		{
 			name: "invalid date of birth",
 			mod: func(input *profile.CreateRequest) {
 				input.DateOfBirth = civil.Date{Year: 2024, Month: 13, Day: 32}
 			},
-			resultDetailError: "parsing time \"2024-13-32\": month out of range",
 		},
 	}
 
@@ -230,6 +238,9 @@ func TestUserProfileCreatorHandler_InvalidJsonError(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to build request: %v", err)
 			}
+			request.Header.Set(tenant.HeaderKey, testTenantID.String())
+			request.Header.Set(idempotency.DefaultHeaderKey, uuid.NewString())
+			request.Header.Set("Content-Type", "application/json")
 
 			resp, err := testSrv.Client().Do(request)
 			if err != nil {
@@ -248,8 +259,8 @@ func TestUserProfileCreatorHandler_InvalidJsonError(t *testing.T) {
 			}
 
 			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
-			assert.Equal(t, "invalid request body", result.Message)
-			assert.Equal(t, result.Details["error"], tc.resultDetailError)
+			assert.Equal(t, "validation failed", result.Message)
+			assert.Equal(t, map[string]string{"dateOfBirth": "is invalid"}, result.Details)
 		})
 	}
 }
@@ -278,6 +289,9 @@ func TestUserProfileCreatorHandler_URL_ID_payload_ID_mismatch(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to build request: %v", err)
 	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
+	request.Header.Set(idempotency.DefaultHeaderKey, uuid.NewString())
+	request.Header.Set("Content-Type", "application/json")
 
 	resp, err := testSrv.Client().Do(request)
 	if err != nil {
@@ -317,7 +331,7 @@ func TestUserProfileCreatorHandler_Unique_Violation(t *testing.T) {
 		DateOfBirth: payload.DateOfBirth,
 	}
 	existingUserProfile, err := userProfileCreatorRepo.
-		InsertUserProfile(context.Background(), dbConn, existingUserProfile)
+		InsertUserProfile(tenant.WithID(context.Background(), testTenantID), test.TenantConn(t, dbConn, testTenantID), existingUserProfile)
 	if err != nil {
 		t.Fatalf("failed to insert existing user profile: %v", err)
 	}
@@ -335,6 +349,9 @@ func TestUserProfileCreatorHandler_Unique_Violation(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to build request: %v", err)
 	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
+	request.Header.Set(idempotency.DefaultHeaderKey, uuid.NewString())
+	request.Header.Set("Content-Type", "application/json")
 
 	resp, err := testSrv.Client().Do(request)
 	if err != nil {
@@ -356,3 +373,100 @@ func TestUserProfileCreatorHandler_Unique_Violation(t *testing.T) {
 	assert.Equal(t, "user profile already exists", result.Message)
 	assert.Nil(t, result.Details)
 }
+
+func TestUserProfileCreatorHandler_IdempotencyKeyRequired(t *testing.T) {
+	testSrv := testx.GlobalEnv().HttpTestServer()
+
+	payload := faker.UserProfileCreateRequest()
+
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(&payload)
+	if err != nil {
+		t.Fatalf("failed to encode payload: %v", err)
+	}
+
+	request, err := http.NewRequest(
+		"POST",
+		buildUserProfileUrl(testSrv.URL, payload.UserID.String()),
+		&buf)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
+
+	resp, err := testSrv.Client().Do(request)
+	if err != nil {
+		t.Fatalf("failed to perform request: %v", err)
+	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Printf("failed to close response body: %v", err)
+		}
+	}()
+	var result httpx.ErrorResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, httpx.CodeIdempotencyError, result.Code)
+}
+
+func TestUserProfileCreatorHandler_IdempotentReplay(t *testing.T) {
+	testSrv := testx.GlobalEnv().HttpTestServer()
+
+	dbConn := testx.GlobalEnv().DBConn()
+	t.Cleanup(func() {
+		test.TruncateUserProfile(dbConn)
+	})
+
+	payload := faker.UserProfileCreateRequest()
+	idempotencyKey := uuid.NewString()
+
+	doRequest := func() (*http.Response, profile.Response) {
+		var buf bytes.Buffer
+		err := json.NewEncoder(&buf).Encode(&payload)
+		if err != nil {
+			t.Fatalf("failed to encode payload: %v", err)
+		}
+
+		request, err := http.NewRequest(
+			"POST",
+			buildUserProfileUrl(testSrv.URL, payload.UserID.String()),
+			&buf)
+		if err != nil {
+			t.Fatalf("failed to build request: %v", err)
+		}
+		request.Header.Set(tenant.HeaderKey, testTenantID.String())
+		request.Header.Set(idempotency.DefaultHeaderKey, idempotencyKey)
+		request.Header.Set("Content-Type", "application/json")
+
+		resp, err := testSrv.Client().Do(request)
+		if err != nil {
+			t.Fatalf("failed to perform request: %v", err)
+		}
+		defer func() {
+			err := resp.Body.Close()
+			if err != nil {
+				log.Printf("failed to close response body: %v", err)
+			}
+		}()
+		var result profile.Response
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		if err != nil {
+			t.Fatalf("failed to decode response body: %v", err)
+		}
+		return resp, result
+	}
+
+	firstResp, firstResult := doRequest()
+	secondResp, secondResult := doRequest()
+
+	assert.Equal(t, http.StatusCreated, firstResp.StatusCode)
+	assert.Equal(t, "", firstResp.Header.Get(idempotency.ReplayedHeaderKey))
+	assert.Equal(t, http.StatusCreated, secondResp.StatusCode)
+	assert.Equal(t, "true", secondResp.Header.Get(idempotency.ReplayedHeaderKey))
+	assert.Equal(t, firstResult, secondResult)
+}
@@ -15,10 +15,12 @@ import (
 	"github.com/dyxj/bigbackend/internal/user/profile"
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
 	"github.com/go-jet/jet/v2/postgres"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,8 +37,10 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 		})
 
 		creator := profile.NewCreatorSQLDB(logger)
+		tenantID := uuid.New()
+		ctx := tenant.WithID(context.Background(), tenantID)
 
-		tx, err := dbConn.Begin()
+		tx, err := tenant.NewTxManager(dbConn).BeginTx(ctx, nil)
 		if err != nil {
 			t.Fatalf("failed to begin transaction: %v", err)
 		}
@@ -53,7 +57,7 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 		}
 
 		inserted, err := creator.InsertUserProfile(
-			context.Background(),
+			ctx,
 			tx,
 			input,
 		)
@@ -67,7 +71,7 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 		err = table.UserProfile.
 			SELECT(table.UserProfile.AllColumns).
 			WHERE(table.UserProfile.ID.EQ(postgres.UUID(inserted.ID))).
-			Query(dbConn, &selected)
+			Query(test.TenantConn(t, dbConn, tenantID), &selected)
 		if err != nil {
 			t.Fatalf("failed to select inserted user profile: %v", err)
 		}
@@ -78,6 +82,7 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 		assert.Equal(t, int32(1), inserted.Version, "inserted Version should be 1")
 
 		assert.Equal(t, inserted.ID, selected.ID)
+		assert.Equal(t, tenantID, selected.TenantID)
 		assert.WithinDuration(t, inserted.CreateTime, selected.CreateTime, time.Second)
 		assert.WithinDuration(t, inserted.UpdateTime, selected.UpdateTime, time.Second)
 
@@ -93,8 +98,10 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 		})
 
 		creator := profile.NewCreatorSQLDB(logger)
+		tenantID := uuid.New()
+		ctx := tenant.WithID(context.Background(), tenantID)
 
-		tx, err := dbConn.Begin()
+		tx, err := tenant.NewTxManager(dbConn).BeginTx(ctx, nil)
 		if err != nil {
 			t.Fatalf("failed to begin transaction: %v", err)
 		}
@@ -108,7 +115,7 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 		input := faker.UserProfileEntity()
 
 		_, err = creator.InsertUserProfile(
-			context.Background(),
+			ctx,
 			tx,
 			input,
 		)
@@ -120,7 +127,7 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 		input2.UserID = input.UserID // duplicate userId
 
 		_, err = creator.InsertUserProfile(
-			context.Background(),
+			ctx,
 			tx,
 			input2,
 		)
@@ -131,4 +138,44 @@ func TestCreatorSQLDB_InsertUserProfile(t *testing.T) {
 			assert.Equal(t, fmt.Sprintf("unique violation error | userId:%s", input2.UserID), uErr.Error(), "expected unique violation error message")
 		}
 	})
+
+	t.Run("should insert duplicate userId of another tenant", func(t *testing.T) {
+		t.Cleanup(func() {
+			test.TruncateUserProfile(dbConn)
+		})
+
+		creator := profile.NewCreatorSQLDB(logger)
+		input := faker.UserProfileEntity()
+
+		for range 2 {
+			tenantID := uuid.New()
+			ctx := tenant.WithID(context.Background(), tenantID)
+			_, err := creator.InsertUserProfile(ctx, test.TenantConn(t, dbConn, tenantID), input)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+	})
+
+	t.Run("should fail to insert without tenant", func(t *testing.T) {
+		creator := profile.NewCreatorSQLDB(logger)
+
+		_, err := creator.InsertUserProfile(context.Background(), dbConn, faker.UserProfileEntity())
+
+		assert.ErrorIs(t, err, tenant.ErrRequired)
+	})
+
+	t.Run("should fail to insert into another tenant", func(t *testing.T) {
+		t.Cleanup(func() {
+			test.TruncateUserProfile(dbConn)
+		})
+
+		creator := profile.NewCreatorSQLDB(logger)
+		ctx := tenant.WithID(context.Background(), uuid.New())
+
+		// Session of another tenant, rejected by the row level security policy
+		_, err := creator.InsertUserProfile(ctx, test.TenantConn(t, dbConn, uuid.New()), faker.UserProfileEntity())
+
+		assert.Error(t, err)
+	})
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/dyxj/bigbackend/internal/user/profile"
 	"github.com/dyxj/bigbackend/pkg/httpx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
@@ -25,11 +26,11 @@ func TestUserProfileGetterHandler_Got(t *testing.T) {
 		test.TruncateUserProfile(dbConn)
 	})
 
-	ctx := t.Context()
+	ctx := tenant.WithID(t.Context(), testTenantID)
 
 	creator := profile.NewCreatorSQLDB(logger)
 	uProfile := faker.UserProfileEntity()
-	inserted, err := creator.InsertUserProfile(ctx, dbConn, uProfile)
+	inserted, err := creator.InsertUserProfile(ctx, test.TenantConn(t, dbConn, testTenantID), uProfile)
 	if err != nil {
 		t.Fatalf("failed to insert user profile: %v", err)
 	}
@@ -41,6 +42,7 @@ func TestUserProfileGetterHandler_Got(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to build request: %v", err)
 	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
 
 	resp, err := testSrv.Client().Do(request)
 	if err != nil {
@@ -88,6 +90,7 @@ func TestUserProfileGetterHandler_NotFound(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to build request: %v", err)
 	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
 
 	resp, err := testSrv.Client().Do(request)
 	if err != nil {
@@ -113,6 +116,46 @@ func TestUserProfileGetterHandler_NotFound(t *testing.T) {
 	assert.Nil(t, result.Details)
 }
 
+func TestUserProfileGetterHandler_NotFound_ProblemJSON(t *testing.T) {
+	testSrv := testx.GlobalEnv().HttpTestServer()
+
+	notFoundUserId := uuid.New()
+	url := buildUserProfileUrl(testSrv.URL, notFoundUserId.String())
+
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
+	request.Header.Set("Accept", "application/problem+json")
+
+	resp, err := testSrv.Client().Do(request)
+	if err != nil {
+		t.Fatalf("failed to perform request: %v", err)
+	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Printf("failed to close response body: %v", err)
+		}
+	}()
+	var result httpx.ProblemDetails
+	err = json.NewDecoder(resp.Body).
+		Decode(&result)
+	if err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	assert.Equal(t, httpx.ProblemTypePrefix+httpx.CodeEntityNotFound.String(), result.Type)
+	assert.Equal(t, http.StatusNotFound, result.Status)
+	assert.Equal(t, httpx.CodeEntityNotFound, result.ErrorCode)
+	assert.Equal(t, "entity not found", result.Detail)
+	assert.Equal(t, request.URL.Path, result.Instance)
+}
+
 func TestUserProfileGetterHandler_InvalidUserId(t *testing.T) {
 	testSrv := testx.GlobalEnv().HttpTestServer()
 
@@ -128,6 +171,7 @@ func TestUserProfileGetterHandler_InvalidUserId(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to build request: %v", err)
 	}
+	request.Header.Set(tenant.HeaderKey, testTenantID.String())
 
 	resp, err := testSrv.Client().Do(request)
 	if err != nil {
@@ -9,9 +9,11 @@ import (
 	"github.com/dyxj/bigbackend/internal/user/profile"
 	"github.com/dyxj/bigbackend/pkg/errorx"
 	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
 	"github.com/dyxj/bigbackend/pkg/testx"
 	"github.com/dyxj/bigbackend/test"
 	"github.com/dyxj/bigbackend/test/faker"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,18 +25,19 @@ func TestSQL_GetUserProfileByUserID(t *testing.T) {
 	dbConn := testx.GlobalEnv().DBConn()
 
 	t.Run("should get user profile successfully", func(t *testing.T) {
-		ctx := t.Context()
+		tenantID := uuid.New()
+		ctx := tenant.WithID(t.Context(), tenantID)
 
 		t.Cleanup(func() {
 			test.TruncateUserProfile(dbConn)
 		})
 
 		creator := profile.NewCreatorSQLDB(logger)
-		getter := profile.NewGetterSQLDB(logger, dbConn)
+		getter := profile.NewGetterSQLDB(logger, tenant.NewTxManager(dbConn))
 
 		uProfile := faker.UserProfileEntity()
 
-		inserted, err := creator.InsertUserProfile(ctx, dbConn, uProfile)
+		inserted, err := creator.InsertUserProfile(ctx, test.TenantConn(t, dbConn, tenantID), uProfile)
 		if err != nil {
 			t.Fatalf("failed to insert user profile: %v", err)
 		}
@@ -56,13 +59,13 @@ func TestSQL_GetUserProfileByUserID(t *testing.T) {
 	})
 
 	t.Run("should return not found error", func(t *testing.T) {
-		ctx := t.Context()
+		ctx := tenant.WithID(t.Context(), uuid.New())
 
 		t.Cleanup(func() {
 			test.TruncateUserProfile(dbConn)
 		})
 
-		getter := profile.NewGetterSQLDB(logger, dbConn)
+		getter := profile.NewGetterSQLDB(logger, tenant.NewTxManager(dbConn))
 
 		_, err := getter.FindUserProfileByUserID(ctx, faker.UserProfile().UserID)
 		if err == nil {
@@ -71,4 +74,48 @@ func TestSQL_GetUserProfileByUserID(t *testing.T) {
 
 		assert.ErrorIs(t, err, errorx.ErrNotFound)
 	})
+
+	t.Run("should connect as a role subject to row level security", func(t *testing.T) {
+		var super, bypassRLS bool
+		err := dbConn.QueryRowContext(t.Context(),
+			"SELECT rolsuper, rolbypassrls FROM pg_roles WHERE rolname = current_user",
+		).Scan(&super, &bypassRLS)
+		if err != nil {
+			t.Fatalf("failed to query role: %v", err)
+		}
+
+		assert.False(t, super)
+		assert.False(t, bypassRLS)
+	})
+
+	t.Run("should not get user profile of another tenant", func(t *testing.T) {
+		tenantID := uuid.New()
+		ctx := tenant.WithID(t.Context(), tenantID)
+
+		t.Cleanup(func() {
+			test.TruncateUserProfile(dbConn)
+		})
+
+		creator := profile.NewCreatorSQLDB(logger)
+		getter := profile.NewGetterSQLDB(logger, tenant.NewTxManager(dbConn))
+
+		uProfile := faker.UserProfileEntity()
+		_, err := creator.InsertUserProfile(ctx, test.TenantConn(t, dbConn, tenantID), uProfile)
+		if err != nil {
+			t.Fatalf("failed to insert user profile: %v", err)
+		}
+
+		// Statement filters by user ID only, rows of other tenants are hidden by row level security
+		_, err = getter.FindUserProfileByUserID(tenant.WithID(t.Context(), uuid.New()), uProfile.UserID)
+
+		assert.ErrorIs(t, err, errorx.ErrNotFound)
+	})
+
+	t.Run("should fail without tenant", func(t *testing.T) {
+		getter := profile.NewGetterSQLDB(logger, tenant.NewTxManager(dbConn))
+
+		_, err := getter.FindUserProfileByUserID(t.Context(), uuid.New())
+
+		assert.ErrorIs(t, err, tenant.ErrRequired)
+	})
 }
@@ -0,0 +1,165 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dyxj/bigbackend/internal/sqlgen/bigbackend/public/entity"
+	"github.com/dyxj/bigbackend/internal/webhook"
+	"github.com/dyxj/bigbackend/pkg/errorx"
+	"github.com/dyxj/bigbackend/pkg/logx"
+	"github.com/dyxj/bigbackend/pkg/tenant"
+	"github.com/dyxj/bigbackend/pkg/testx"
+	"github.com/dyxj/bigbackend/test"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQL_Webhook(t *testing.T) {
+	logger, err := logx.InitLogger()
+	if err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+	dbConn := testx.GlobalEnv().DBConn()
+	tm := tenant.NewTxManager(dbConn)
+	subscriptions := webhook.NewSubscriptionSQLDB(logger)
+	deliveries := webhook.NewDeliverySQLDB(logger)
+
+	inTx := func(t *testing.T, ctx context.Context, fn func(tx *sql.Tx)) {
+		t.Helper()
+		tx, err := tm.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		defer func() {
+			err := tx.Rollback()
+			if err != nil && !errors.Is(err, sql.ErrTxDone) {
+				t.Fatalf("failed to rollback transaction: %v", err)
+			}
+		}()
+		fn(tx)
+		require.NoError(t, tx.Commit())
+	}
+
+	insertSubscription := func(t *testing.T, ctx context.Context, eventTypes string) entity.WebhookSubscription {
+		t.Helper()
+		var inserted entity.WebhookSubscription
+		inTx(t, ctx, func(tx *sql.Tx) {
+			var err error
+			inserted, err = subscriptions.InsertSubscriptionTx(ctx, tx, entity.WebhookSubscription{
+				URL:        "https://example.com/hook",
+				Secret:     "whsec_c2VjcmV0",
+				EventTypes: eventTypes,
+				Status:     string(webhook.SubscriptionStatusEnabled),
+			})
+			require.NoError(t, err)
+		})
+		return inserted
+	}
+
+	cleanup := func() {
+		test.TruncateWebhookDelivery(dbConn)
+		test.TruncateWebhookSubscription(dbConn)
+	}
+
+	t.Run("should queue deliveries of subscribed tenant subscriptions", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		ctxA := tenant.WithID(context.Background(), uuid.New())
+		ctxB := tenant.WithID(context.Background(), uuid.New())
+
+		subscribed := insertSubscription(t, ctxA, webhook.EventUserProfileCreated)
+		_ = insertSubscription(t, ctxA, "other.event")
+		_ = insertSubscription(t, ctxB, webhook.EventUserProfileCreated)
+
+		eventID := uuid.New()
+		inTx(t, ctxA, func(tx *sql.Tx) {
+			queued, err := deliveries.InsertEventDeliveriesTx(ctxA, tx, eventID, webhook.EventUserProfileCreated, `{}`)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), queued)
+		})
+
+		inTx(t, ctxA, func(tx *sql.Tx) {
+			listed, err := deliveries.ListDeliveriesTx(ctxA, tx, subscribed.ID)
+			require.NoError(t, err)
+			require.Len(t, listed, 1)
+			assert.Equal(t, eventID, listed[0].EventID)
+			assert.Equal(t, string(webhook.DeliveryStatusPending), listed[0].Status)
+		})
+
+		inTx(t, ctxB, func(tx *sql.Tx) {
+			_, err := subscriptions.FindSubscriptionTx(ctxB, tx, subscribed.ID)
+			assert.ErrorIs(t, err, errorx.ErrNotFound)
+		})
+	})
+
+	t.Run("should claim due deliveries of every tenant once", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		ctxA := tenant.WithID(context.Background(), uuid.New())
+		ctxB := tenant.WithID(context.Background(), uuid.New())
+		all := tenant.WithAll(context.Background())
+
+		for _, ctx := range []context.Context{ctxA, ctxB} {
+			_ = insertSubscription(t, ctx, webhook.EventUserProfileCreated)
+			inTx(t, ctx, func(tx *sql.Tx) {
+				_, err := deliveries.InsertEventDeliveriesTx(ctx, tx, uuid.New(), webhook.EventUserProfileCreated, `{}`)
+				require.NoError(t, err)
+			})
+		}
+
+		now := time.Now()
+		inTx(t, all, func(tx *sql.Tx) {
+			oldest, err := deliveries.OldestDueDeliveryTimeTx(all, tx, now)
+			require.NoError(t, err)
+			require.NotNil(t, oldest)
+			assert.False(t, oldest.After(now))
+
+			claimed, err := deliveries.ClaimDueDeliveriesTx(all, tx, now, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Len(t, claimed, 2)
+			for _, c := range claimed {
+				assert.WithinDuration(t, now.Add(time.Minute), c.NextAttemptTime, time.Second)
+			}
+		})
+
+		inTx(t, all, func(tx *sql.Tx) {
+			claimed, err := deliveries.ClaimDueDeliveriesTx(all, tx, now, time.Minute, 10)
+			require.NoError(t, err)
+			assert.Empty(t, claimed)
+
+			// Claimed deliveries are not due until their lease expires
+			oldest, err := deliveries.OldestDueDeliveryTimeTx(all, tx, now)
+			require.NoError(t, err)
+			assert.Nil(t, oldest)
+		})
+	})
+
+	t.Run("should disable subscription after consecutive failures", func(t *testing.T) {
+		t.Cleanup(cleanup)
+		ctx := tenant.WithID(context.Background(), uuid.New())
+		inserted := insertSubscription(t, ctx, webhook.EventUserProfileCreated)
+
+		inTx(t, ctx, func(tx *sql.Tx) {
+			updated, err := subscriptions.IncrementFailuresTx(ctx, tx, inserted.ID, 2)
+			require.NoError(t, err)
+			assert.Equal(t, string(webhook.SubscriptionStatusEnabled), updated.Status)
+			assert.Equal(t, int32(1), updated.FailureCount)
+
+			updated, err = subscriptions.IncrementFailuresTx(ctx, tx, inserted.ID, 2)
+			require.NoError(t, err)
+			assert.Equal(t, string(webhook.SubscriptionStatusDisabled), updated.Status)
+			assert.NotNil(t, updated.DisableTime)
+		})
+
+		inTx(t, ctx, func(tx *sql.Tx) {
+			enabled, err := subscriptions.EnableSubscriptionTx(ctx, tx, inserted.ID)
+			require.NoError(t, err)
+			assert.Equal(t, string(webhook.SubscriptionStatusEnabled), enabled.Status)
+			assert.Equal(t, int32(0), enabled.FailureCount)
+			assert.Nil(t, enabled.DisableTime)
+		})
+	})
+}